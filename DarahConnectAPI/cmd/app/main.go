@@ -15,9 +15,10 @@ import (
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/database"
 	googleoauth "github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/googleOauth"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/mailer"
-	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/midtrans"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/revocation"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/server"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/timezone"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/worker"
 )
 
 func main() {
@@ -39,18 +40,34 @@ func main() {
 	err = googleoauth.InitGoogle(&cfg.GoogleOauth)
 	checkError(err)
 
-	_, err = midtrans.InitMidtrans(&cfg.MidtransConfig)
+	gateway, err := builder.BuildPaymentGateway(cfg)
 	checkError(err)
 
-	blockchain, err := service.NewBlockchainService(cfg.Blockchain)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+
+	// Tanpa blockchain hanya jika BLOCKCHAIN_MODE=disabled; konfigurasi rpc yang salah harus gagal saat startup
+	blockchain, err := service.NewBlockchainService(workerCtx, cfg.Blockchain)
+	checkError(err)
+
+	keys, err := builder.BuildKeySet(workerCtx, cfg, db)
+	checkError(err)
+
+	revocations := revocation.New(workerCtx, cfg.RedisConfig, db)
+
+	authorizer, err := builder.BuildAuthorizer(workerCtx, db)
 	checkError(err)
 
-	publicRoutes := builder.BuildPublicRoutes(cfg, db, cloudinaryService, mailer, blockchain)
-	privateRoutes := builder.BuildPrivateRoutes(cfg, db, cloudinaryService, mailer, blockchain)
+	publicRoutes := builder.BuildPublicRoutes(cfg, db, cloudinaryService, mailer, blockchain, gateway, keys, revocations, authorizer)
+	privateRoutes := builder.BuildPrivateRoutes(cfg, db, cloudinaryService, mailer, blockchain, gateway, keys, revocations, authorizer)
 
-	srv := server.NewServer(cfg, publicRoutes, privateRoutes)
+	waitWorkers := worker.Start(workerCtx, builder.BuildWorkers(cfg, db, mailer, blockchain, gateway, keys, authorizer)...)
+
+	srv := server.NewServer(cfg, keys, revocations, authorizer, builder.BuildHospitalResolver(db), builder.BuildAuditRecorder(db), publicRoutes, privateRoutes)
 	runServer(srv, cfg.PORT)
 	waitForShutdown(srv)
+
+	stopWorkers()
+	waitWorkers()
 }
 
 func checkError(err error) {
@@ -2,6 +2,8 @@ package configs
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/caarlos0/env/v6"
 	"github.com/joho/godotenv"
@@ -17,15 +19,121 @@ type Config struct {
 	SMTPConfig       SMTPConfig       `envPrefix:"SMTP_" mapstructure:"SMTP"`
 	CloudinaryConfig CloudinaryConfig `envPrefix:"CLOUDINARY_" mapstructure:"CLOUDINARY"`
 	MidtransConfig   MidtransConfig   `envPrefix:"MIDTRANS_" mapstructure:"MIDTRANS"`
+	Payment          PaymentConfig        `envPrefix:"PAYMENT_"`
 	GoogleOauth      GoogleOauth      `envPrefix:"GOOGLE_" mapstructure:"GOOGLE_"`
 	Blockchain       BlockchainConfig `envPrefix:"BLOCKCHAIN_"`
+	EmergencyAlert   EmergencyAlertConfig `envPrefix:"EMERGENCY_ALERT_"`
+	Eligibility      EligibilityConfig    `envPrefix:"ELIGIBILITY_"`
+	Outbox           OutboxConfig         `envPrefix:"OUTBOX_"`
+	WalletAuth       WalletAuthConfig     `envPrefix:"WALLET_AUTH_"`
+	Certificate      CertificateConfig    `envPrefix:"CERTIFICATE_"`
+	MFA              MFAConfig            `envPrefix:"MFA_"`
+	RBAC             RBACConfig           `envPrefix:"RBAC_"`
+	Inventory        InventoryConfig      `envPrefix:"INVENTORY_"`
+	Sealing          SealingConfig        `envPrefix:"SEALING_" mapstructure:"SEALING"`
 	VerifyEmailBaseURL string           `env:"VERIFY_EMAIL_BASE_URL" envDefault:"http://localhost:8081/verify-email"`
 }
 
 type BlockchainConfig struct {
+	Mode            string `env:"MODE" envDefault:"rpc"` // rpc, simulated, disabled
 	RPCURL          string `env:"SEPOLIA_RPC_URL"`
 	PrivateKey      string `env:"PRIVATE_KEY"`
 	ContractAddress string `env:"CONTRACT_ADDRESS"`
+	EscrowAddress   string `env:"ESCROW_ADDRESS"` // penerima sertifikat pendonor tanpa wallet, default wallet penerbit
+
+	// Pelacakan receipt transaksi mintSertifikat
+	Confirmations       int64         `env:"CONFIRMATIONS" envDefault:"3"`
+	ReceiptPollInterval time.Duration `env:"RECEIPT_POLL_INTERVAL" envDefault:"15s"`
+	StuckAfter          time.Duration `env:"STUCK_AFTER" envDefault:"5m"`
+	GasBumpPercent      int           `env:"GAS_BUMP_PERCENT" envDefault:"20"`
+	MaxResubmits        int           `env:"MAX_RESUBMITS" envDefault:"5"`
+	SimulatedBlockTime  time.Duration `env:"SIMULATED_BLOCK_TIME" envDefault:"2s"`
+
+	// Indexer event SertifikatDibuat. Kontrak di Sepolia di-deploy pada blok 8659270.
+	DeployBlock          uint64        `env:"DEPLOY_BLOCK" envDefault:"0"`
+	IndexerPollInterval  time.Duration `env:"INDEXER_POLL_INTERVAL" envDefault:"30s"`
+	IndexerBlockRange    uint64        `env:"INDEXER_BLOCK_RANGE" envDefault:"2000"`
+	ReconcileGracePeriod time.Duration `env:"RECONCILE_GRACE_PERIOD" envDefault:"1h"`
+}
+
+// EmergencyAlertConfig mengatur penyebaran notifikasi darurat ke pendonor terdekat. Email dikirim
+// lewat outbox sehingga lajunya mengikuti OUTBOX_BATCH_SIZE dan OUTBOX_POLL_INTERVAL.
+type EmergencyAlertConfig struct {
+	RadiusKm           float64 `env:"RADIUS_KM" envDefault:"25"`
+	MaxRecipients      int     `env:"MAX_RECIPIENTS" envDefault:"200"`
+	DailyLimitPerDonor int     `env:"DAILY_LIMIT_PER_DONOR" envDefault:"2"`
+}
+
+// EligibilityConfig mengatur aturan kelayakan pendonor darah lengkap
+type EligibilityConfig struct {
+	MinAge            int `env:"MIN_AGE" envDefault:"17"`
+	MaxAge            int `env:"MAX_AGE" envDefault:"65"`
+	IntervalDays      int `env:"INTERVAL_DAYS" envDefault:"60"`
+	YearlyLimitMale   int `env:"YEARLY_LIMIT_MALE" envDefault:"5"`
+	YearlyLimitFemale int `env:"YEARLY_LIMIT_FEMALE" envDefault:"4"`
+}
+
+// RBACConfig mengatur seberapa cepat perubahan role dari instance lain terbaca
+type RBACConfig struct {
+	ReloadInterval time.Duration `env:"RELOAD_INTERVAL" envDefault:"1m"`
+}
+
+// InventoryConfig mengatur worker yang menandai kantong darah kedaluwarsa
+type InventoryConfig struct {
+	ExpireInterval time.Duration `env:"EXPIRE_INTERVAL" envDefault:"15m"`
+}
+
+// OutboxConfig mengatur dispatcher yang mengirim side effect dari tabel outbox
+type CertificateConfig struct {
+	// Alamat verifikasi publik yang dituju QR code di PDF sertifikat, diakhiri nomor sertifikat
+	VerifyBaseURL string `env:"VERIFY_BASE_URL" envDefault:"http://localhost:8081/api/v1/certificate/verify"`
+}
+
+// WalletAuthConfig mengatur pesan tantangan yang ditandatangani wallet pengguna
+type WalletAuthConfig struct {
+	Domain       string        `env:"DOMAIN" envDefault:"localhost:8081"`
+	URI          string        `env:"URI" envDefault:"http://localhost:8081"`
+	ChainId      int64         `env:"CHAIN_ID" envDefault:"11155111"` // Sepolia
+	ChallengeTTL time.Duration `env:"CHALLENGE_TTL" envDefault:"5m"`
+}
+
+type MFAConfig struct {
+	Issuer            string        `env:"ISSUER" envDefault:"DarahConnect"`                           // nama penerbit di aplikasi authenticator
+	EnforcedRoles     []string      `env:"ENFORCED_ROLES" envDefault:"Administrator" envSeparator:","` // role yang wajib memakai TOTP
+	ChallengeTTL      time.Duration `env:"CHALLENGE_TTL" envDefault:"5m"`
+	MaxAttempts       int           `env:"MAX_ATTEMPTS" envDefault:"5"`
+	RecoveryCodeCount int           `env:"RECOVERY_CODE_COUNT" envDefault:"10"`
+}
+
+// SealingConfig menyimpan kunci untuk mengenkripsi rahasia di database (private key penandatangan
+// JWT dan secret TOTP). Kunci ini terpisah dari pengaturan JWT dan wajib diisi.
+type SealingConfig struct {
+	Key string `env:"KEY" mapstructure:"KEY"`
+}
+
+// minSealingKeyLength adalah panjang minimal SEALING_KEY
+const minSealingKeyLength = 32
+
+// Validate menolak kunci kosong, nilai contoh "secret", atau kunci yang terlalu pendek
+func (c SealingConfig) Validate() error {
+	switch {
+	case c.Key == "":
+		return errors.New("SEALING_KEY wajib diisi")
+	case c.Key == "secret":
+		return errors.New("SEALING_KEY tidak boleh memakai nilai contoh")
+	case len(c.Key) < minSealingKeyLength:
+		return fmt.Errorf("SEALING_KEY minimal %d karakter", minSealingKeyLength)
+	}
+	return nil
+}
+
+type OutboxConfig struct {
+	PollInterval time.Duration `env:"POLL_INTERVAL" envDefault:"2s"`
+	BatchSize    int           `env:"BATCH_SIZE" envDefault:"20"`
+	MaxAttempts  int           `env:"MAX_ATTEMPTS" envDefault:"10"`
+	BaseBackoff  time.Duration `env:"BASE_BACKOFF" envDefault:"10s"`
+	MaxBackoff   time.Duration `env:"MAX_BACKOFF" envDefault:"1h"`
+	Lease        time.Duration `env:"LEASE" envDefault:"5m"`
 }
 
 type RedisConfig struct {
@@ -41,7 +149,12 @@ type GoogleOauth struct {
 	RedirectURL  string `env:"REDIRECT_URL" mapstructure:"REDIRECT_URL"`
 }
 type JWTConfig struct {
-	SecretKey string `env:"SECRET_KEY" envDefault:"secret" mapstructure:"SECRET_KEY"`
+	Algorithm           string        `env:"ALGORITHM" envDefault:"RS256" mapstructure:"ALGORITHM"`   // RS256 atau EdDSA
+	AccessTTL           time.Duration `env:"ACCESS_TTL" envDefault:"15m" mapstructure:"ACCESS_TTL"`
+	RefreshTTL          time.Duration `env:"REFRESH_TTL" envDefault:"720h" mapstructure:"REFRESH_TTL"`
+	KeyRotationInterval time.Duration `env:"KEY_ROTATION_INTERVAL" envDefault:"720h" mapstructure:"KEY_ROTATION_INTERVAL"`
+	KeyPublishAhead     time.Duration `env:"KEY_PUBLISH_AHEAD" envDefault:"1h" mapstructure:"KEY_PUBLISH_AHEAD"` // kunci baru muncul di JWKS sebelum dipakai
+	KeyReloadInterval   time.Duration `env:"KEY_RELOAD_INTERVAL" envDefault:"1m" mapstructure:"KEY_RELOAD_INTERVAL"`
 }
 
 type SMTPConfig struct {
@@ -64,6 +177,23 @@ type MidtransConfig struct {
 	BaseURL   string `env:"BASE_URL"`
 	ClientKey string `env:"CLIENT_KEY"`
 	ServerKey string `env:"SERVER_KEY"`
+	Environment string `env:"ENVIRONMENT" envDefault:"sandbox"` // sandbox, production
+}
+
+// PaymentConfig memilih gateway pembayaran donasi
+type PaymentConfig struct {
+	Gateway string `env:"GATEWAY" envDefault:"midtrans"` // midtrans, fake
+	// Gateway fake menerima pembayaran tanpa uang sungguhan, harus diizinkan secara eksplisit
+	AllowFake bool `env:"ALLOW_FAKE" envDefault:"false"`
+	// Alamat publik API, dipakai gateway fake untuk halaman checkout dan webhook
+	PublicURL string `env:"PUBLIC_URL" envDefault:"http://localhost:8081"`
+
+	// Rekonsiliasi donasi pending yang webhook-nya tidak pernah diterima
+	ReconcileInterval  time.Duration `env:"RECONCILE_INTERVAL" envDefault:"5m"`
+	ReconcileAfter     time.Duration `env:"RECONCILE_AFTER" envDefault:"15m"`
+	ReconcileBatchSize int           `env:"RECONCILE_BATCH_SIZE" envDefault:"50"`
+	// Batas waktu pembayaran di gateway; Snap Midtrans kedaluwarsa setelah 24 jam
+	ExpireAfter time.Duration `env:"EXPIRE_AFTER" envDefault:"24h"`
 }
 
 type CloudinaryConfig struct {
@@ -83,6 +213,9 @@ func NewConfig(envPath string) (*Config, error) {
 	if err != nil {
 		return nil, errors.New("failed to parse env")
 	}
+	if err := cfg.Sealing.Validate(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
@@ -101,6 +234,9 @@ func NewConfigYaml(path string) (*Config, error) {
 	if err != nil {
 		return nil, errors.New("failed to parse config " + err.Error())
 	}
+	if err := cfg.Sealing.Validate(); err != nil {
+		return nil, err
+	}
 
 	return cfg, nil
 }
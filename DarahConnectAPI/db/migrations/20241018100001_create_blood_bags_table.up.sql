@@ -0,0 +1,23 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.blood_bags (
+    id BIGSERIAL PRIMARY KEY,
+    bag_number VARCHAR(255) NOT NULL UNIQUE,
+    hospital_id BIGINT NOT NULL REFERENCES public.hospitals(id),
+    donation_id BIGINT REFERENCES public.blood_donations(id),
+    request_id BIGINT REFERENCES public.blood_requests(id),
+    blood_type VARCHAR(10) NOT NULL,
+    component VARCHAR(50) NOT NULL,
+    volume_ml INT NOT NULL DEFAULT 0,
+    collected_at TIMESTAMPTZ NOT NULL,
+    expiry_date TIMESTAMPTZ NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'available',
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_blood_bags_stock ON public.blood_bags (hospital_id, blood_type, component, status);
+CREATE INDEX IF NOT EXISTS idx_blood_bags_expiry_date ON public.blood_bags (expiry_date);
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.emergency_alerts;
+
+ALTER TABLE public.users
+DROP COLUMN IF EXISTS latitude,
+DROP COLUMN IF EXISTS longitude;
+
+COMMIT;
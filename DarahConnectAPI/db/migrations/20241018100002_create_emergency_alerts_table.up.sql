@@ -0,0 +1,21 @@
+BEGIN;
+
+-- Lokasi pendonor untuk pencarian pendonor terdekat
+ALTER TABLE public.users
+ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION,
+ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+
+CREATE TABLE IF NOT EXISTS public.emergency_alerts (
+    id BIGSERIAL PRIMARY KEY,
+    request_id BIGINT NOT NULL REFERENCES public.blood_requests(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    distance_km DOUBLE PRECISION,
+    email_sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    CONSTRAINT uq_emergency_alerts_request_user UNIQUE (request_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_emergency_alerts_user_created_at ON public.emergency_alerts (user_id, created_at);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.health_screenings;
+
+ALTER TABLE public.health_passports
+DROP COLUMN IF EXISTS deferred_until;
+
+COMMIT;
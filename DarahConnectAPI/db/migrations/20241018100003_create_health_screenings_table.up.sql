@@ -0,0 +1,34 @@
+BEGIN;
+
+-- Tanggal penundaan health passport hasil skrining
+ALTER TABLE public.health_passports
+ADD COLUMN IF NOT EXISTS deferred_until TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS public.health_screenings (
+    id BIGSERIAL PRIMARY KEY,
+    health_passport_id BIGINT NOT NULL REFERENCES public.health_passports(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES public.users(id),
+    questionnaire_version INT NOT NULL,
+    recent_illness BOOLEAN NOT NULL DEFAULT FALSE,
+    taking_medication BOOLEAN NOT NULL DEFAULT FALSE,
+    medication_notes TEXT,
+    malaria_travel_return_date TIMESTAMPTZ,
+    tattoo_date TIMESTAMPTZ,
+    hemoglobin NUMERIC(4, 1) NOT NULL,
+    weight_kg NUMERIC(5, 1) NOT NULL,
+    systolic INT NOT NULL,
+    diastolic INT NOT NULL,
+    outcome VARCHAR(20) NOT NULL,
+    deferred_until TIMESTAMPTZ,
+    reasons JSONB NOT NULL DEFAULT '[]',
+    reviewed_by BIGINT REFERENCES public.users(id),
+    reviewed_at TIMESTAMPTZ,
+    review_decision VARCHAR(20),
+    review_note TEXT,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_health_screenings_passport_created_at ON public.health_screenings (health_passport_id, created_at);
+
+COMMIT;
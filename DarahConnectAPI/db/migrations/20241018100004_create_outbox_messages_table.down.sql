@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.outbox_messages;
+
+COMMIT;
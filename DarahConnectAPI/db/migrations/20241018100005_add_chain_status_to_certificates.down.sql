@@ -0,0 +1,19 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_certificates_chain_status;
+
+ALTER TABLE public.certificates
+    DROP COLUMN IF EXISTS chain_status,
+    DROP COLUMN IF EXISTS tx_nonce,
+    DROP COLUMN IF EXISTS gas_fee_cap,
+    DROP COLUMN IF EXISTS gas_tip_cap,
+    DROP COLUMN IF EXISTS raw_tx,
+    DROP COLUMN IF EXISTS replaced_tx_hashes,
+    DROP COLUMN IF EXISTS submit_attempts,
+    DROP COLUMN IF EXISTS block_number,
+    DROP COLUMN IF EXISTS confirmations,
+    DROP COLUMN IF EXISTS chain_error,
+    DROP COLUMN IF EXISTS submitted_at,
+    DROP COLUMN IF EXISTS confirmed_at;
+
+COMMIT;
@@ -0,0 +1,24 @@
+BEGIN;
+
+ALTER TABLE public.certificates
+    ADD COLUMN IF NOT EXISTS chain_status VARCHAR(20) NOT NULL DEFAULT 'queued', -- queued, submitted, confirmed, failed
+    ADD COLUMN IF NOT EXISTS tx_nonce BIGINT,
+    ADD COLUMN IF NOT EXISTS gas_fee_cap VARCHAR(78),
+    ADD COLUMN IF NOT EXISTS gas_tip_cap VARCHAR(78),
+    ADD COLUMN IF NOT EXISTS raw_tx TEXT,
+    ADD COLUMN IF NOT EXISTS replaced_tx_hashes JSONB,
+    ADD COLUMN IF NOT EXISTS submit_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS block_number BIGINT,
+    ADD COLUMN IF NOT EXISTS confirmations BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS chain_error TEXT,
+    ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS confirmed_at TIMESTAMPTZ;
+
+-- Sertifikat lama yang sudah punya tx hash dicek ulang receipt-nya oleh poller
+UPDATE public.certificates
+SET chain_status = 'submitted', submit_attempts = 1, submitted_at = created_at
+WHERE digital_signature IS NOT NULL AND digital_signature <> '';
+
+CREATE INDEX IF NOT EXISTS idx_certificates_chain_status ON public.certificates (chain_status, submitted_at);
+
+COMMIT;
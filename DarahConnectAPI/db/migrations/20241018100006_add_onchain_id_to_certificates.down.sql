@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_certificates_certificate_number;
+DROP INDEX IF EXISTS idx_certificates_onchain_id;
+
+ALTER TABLE public.certificates DROP COLUMN IF EXISTS issuer_address;
+ALTER TABLE public.certificates DROP COLUMN IF EXISTS onchain_id;
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+-- Id sertifikat di kontrak (dari event SertifikatDibuat), kunci untuk daftarSertifikat
+ALTER TABLE public.certificates ADD COLUMN IF NOT EXISTS onchain_id BIGINT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_certificates_onchain_id ON public.certificates (onchain_id) WHERE onchain_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_certificates_certificate_number ON public.certificates (certificate_number);
+
+-- Alamat penerbit yang menandatangani mintSertifikat. Verifikasi membandingkan catatan kontrak
+-- dengan alamat ini, bukan dengan kunci backend yang sedang aktif.
+ALTER TABLE public.certificates ADD COLUMN IF NOT EXISTS issuer_address VARCHAR(42) NOT NULL DEFAULT '';
+
+COMMIT;
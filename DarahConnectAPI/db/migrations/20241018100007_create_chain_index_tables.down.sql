@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.chain_certificate_events;
+DROP TABLE IF EXISTS public.chain_sync_states;
+
+COMMIT;
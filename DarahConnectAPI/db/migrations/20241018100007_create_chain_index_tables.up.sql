@@ -0,0 +1,27 @@
+BEGIN;
+
+-- Blok terakhir yang sudah diproses oleh masing-masing indexer
+CREATE TABLE IF NOT EXISTS public.chain_sync_states (
+    name VARCHAR(64) PRIMARY KEY,
+    last_block BIGINT NOT NULL,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS public.chain_certificate_events (
+    id BIGSERIAL PRIMARY KEY,
+    onchain_id BIGINT NOT NULL,
+    donor_address VARCHAR(42) NOT NULL,
+    certificate_number VARCHAR(78) NOT NULL,
+    certificate_id BIGINT REFERENCES public.certificates(id) ON DELETE SET NULL, -- NULL jika tidak ada di database
+    block_number BIGINT NOT NULL,
+    block_hash VARCHAR(66) NOT NULL,
+    tx_hash VARCHAR(66) NOT NULL,
+    log_index INT NOT NULL,
+    created_at TIMESTAMPTZ,
+    UNIQUE (tx_hash, log_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_chain_certificate_events_number ON public.chain_certificate_events (certificate_number);
+CREATE INDEX IF NOT EXISTS idx_chain_certificate_events_certificate ON public.chain_certificate_events (certificate_id);
+
+COMMIT;
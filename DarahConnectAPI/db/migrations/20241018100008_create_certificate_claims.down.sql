@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.certificate_claims;
+
+ALTER TABLE public.certificates DROP COLUMN IF EXISTS custodial;
+ALTER TABLE public.certificates DROP COLUMN IF EXISTS recipient_address;
+
+COMMIT;
@@ -0,0 +1,33 @@
+BEGIN;
+
+-- Alamat penerima di catatan blockchain. Pendonor tanpa wallet menerima sertifikat di alamat escrow.
+ALTER TABLE public.certificates ADD COLUMN IF NOT EXISTS recipient_address VARCHAR(42) NOT NULL DEFAULT '';
+ALTER TABLE public.certificates ADD COLUMN IF NOT EXISTS custodial BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE public.certificates c SET recipient_address = u.wallet_address
+FROM public.users u
+WHERE u.id = c.user_id AND c.digital_signature <> '' AND c.recipient_address = '';
+
+-- Riwayat klaim sertifikat escrow ke wallet pendonor
+CREATE TABLE IF NOT EXISTS public.certificate_claims (
+    id BIGSERIAL PRIMARY KEY,
+    certificate_id BIGINT NOT NULL REFERENCES public.certificates(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    from_address VARCHAR(42) NOT NULL,
+    to_address VARCHAR(42) NOT NULL,
+    previous_onchain_id BIGINT,
+    previous_tx_hash VARCHAR(66) NOT NULL DEFAULT '',
+    previous_state JSONB, -- data transaksi mint escrow, dipulihkan jika mint ulang gagal
+    onchain_id BIGINT,
+    tx_hash VARCHAR(66) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, completed, failed
+    error TEXT NOT NULL DEFAULT '',
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_certificate_claims_certificate ON public.certificate_claims (certificate_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_certificate_claims_pending ON public.certificate_claims (certificate_id) WHERE status = 'pending';
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS public.idx_users_wallet_address;
+DROP TABLE IF EXISTS public.wallet_challenges;
+
+COMMIT;
@@ -0,0 +1,27 @@
+BEGIN;
+
+-- Tantangan tanda tangan wallet untuk menghubungkan wallet atau masuk dengan wallet
+CREATE TABLE IF NOT EXISTS public.wallet_challenges (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT REFERENCES public.users(id) ON DELETE CASCADE, -- NULL untuk tantangan login
+    wallet_address VARCHAR(42) NOT NULL,
+    purpose VARCHAR(20) NOT NULL, -- link, login
+    nonce VARCHAR(64) NOT NULL UNIQUE,
+    message TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ
+);
+
+-- Satu alamat wallet hanya boleh terhubung ke satu akun. Jika sudah ada duplikat,
+-- wallet dipertahankan pada akun yang paling lama dan dilepas dari akun lainnya.
+UPDATE public.users u SET wallet_address = ''
+WHERE u.wallet_address <> ''
+  AND EXISTS (
+    SELECT 1 FROM public.users o
+    WHERE LOWER(o.wallet_address) = LOWER(u.wallet_address) AND o.id < u.id
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_wallet_address ON public.users (LOWER(wallet_address)) WHERE wallet_address <> '';
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.token_revocations;
+DROP TABLE IF EXISTS public.refresh_tokens;
+
+COMMIT;
@@ -0,0 +1,29 @@
+BEGIN;
+
+-- Refresh token disimpan sebagai hash SHA-256. Token dalam satu family berasal dari satu login
+-- dan dirotasi setiap kali dipakai.
+CREATE TABLE IF NOT EXISTS public.refresh_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    family_id VARCHAR(64) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    access_jti VARCHAR(64) NOT NULL, -- jti access token yang terbit bersama refresh token ini
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    replaced_by_id BIGINT REFERENCES public.refresh_tokens(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user ON public.refresh_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON public.refresh_tokens (family_id);
+
+-- Denylist access token jika Redis tidak tersedia. Key berbentuk revoked:jti:<jti> atau revoked:user:<id>.
+CREATE TABLE IF NOT EXISTS public.token_revocations (
+    key VARCHAR(128) PRIMARY KEY,
+    revoked_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_token_revocations_expires_at ON public.token_revocations (expires_at);
+
+COMMIT;
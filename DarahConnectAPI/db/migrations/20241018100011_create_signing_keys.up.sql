@@ -0,0 +1,16 @@
+BEGIN;
+
+-- Kunci penandatangan JWT. Private key disimpan terenkripsi dengan SEALING_KEY.
+CREATE TABLE IF NOT EXISTS public.signing_keys (
+    id BIGSERIAL PRIMARY KEY,
+    kid VARCHAR(64) NOT NULL UNIQUE,
+    algorithm VARCHAR(10) NOT NULL, -- RS256, EdDSA
+    private_key TEXT NOT NULL,
+    activates_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ, -- terisi setelah kunci digantikan kunci berikutnya
+    created_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_signing_keys_activates_at ON public.signing_keys (activates_at);
+
+COMMIT;
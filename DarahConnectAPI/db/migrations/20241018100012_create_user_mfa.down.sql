@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.mfa_challenges;
+DROP TABLE IF EXISTS public.mfa_recovery_codes;
+DROP TABLE IF EXISTS public.user_mfa;
+
+COMMIT;
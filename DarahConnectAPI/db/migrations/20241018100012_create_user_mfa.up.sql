@@ -0,0 +1,39 @@
+BEGIN;
+
+-- Secret TOTP per pengguna, disimpan terenkripsi dengan SEALING_KEY. enabled_at masih NULL
+-- selama pendaftaran belum dikonfirmasi dengan kode pertama.
+CREATE TABLE IF NOT EXISTS public.user_mfa (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL UNIQUE REFERENCES public.users(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    enabled_at TIMESTAMPTZ,
+    last_used_step BIGINT NOT NULL DEFAULT 0, -- periode TOTP terakhir yang dipakai, mencegah kode dipakai ulang
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+-- Kode pemulihan sekali pakai, disimpan sebagai hash SHA-256
+CREATE TABLE IF NOT EXISTS public.mfa_recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user ON public.mfa_recovery_codes (user_id);
+
+-- Token "mfa pending" yang diterbitkan setelah password benar dan ditukar dengan JWT setelah
+-- kode TOTP diverifikasi
+CREATE TABLE IF NOT EXISTS public.mfa_challenges (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    purpose VARCHAR(20) NOT NULL, -- verify, enroll
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ
+);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.role_permissions;
+DROP TABLE IF EXISTS public.permissions;
+DROP TABLE IF EXISTS public.roles;
+
+COMMIT;
@@ -0,0 +1,101 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.roles (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE, -- sama dengan users.role dan klaim role di JWT
+    description TEXT,
+    is_system BOOLEAN NOT NULL DEFAULT FALSE, -- role bawaan tidak bisa dihapus
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS public.permissions (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE, -- resource:aksi
+    description TEXT
+);
+
+CREATE TABLE IF NOT EXISTS public.role_permissions (
+    role_id BIGINT NOT NULL REFERENCES public.roles(id) ON DELETE CASCADE,
+    permission_id BIGINT NOT NULL REFERENCES public.permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+INSERT INTO public.roles (name, description, is_system, created_at, updated_at) VALUES
+    ('Administrator', 'Pengelola platform dengan semua permission', TRUE, NOW(), NOW()),
+    ('User', 'Pendonor dan pemohon darah', TRUE, NOW(), NOW())
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO public.permissions (name, description) VALUES
+    ('dashboard:donor', 'Melihat dashboard pendonor'),
+    ('dashboard:admin', 'Melihat dashboard admin'),
+    ('health_passport:own', 'Mengisi dan melihat health passport sendiri'),
+    ('health_passport:read', 'Melihat health passport semua pengguna'),
+    ('health_passport:verify', 'Memverifikasi health passport'),
+    ('health_passport:delete', 'Menghapus health passport'),
+    ('notification:own', 'Melihat notifikasi sendiri'),
+    ('notification:read', 'Melihat notifikasi semua pengguna'),
+    ('notification:create', 'Mengirim notifikasi'),
+    ('donor_registration:create', 'Mendaftar sebagai pendonor'),
+    ('donor_registration:read', 'Melihat pendaftaran donor'),
+    ('donor_registration:update', 'Mengubah pendaftaran donor'),
+    ('donor_registration:manage', 'Melihat dan mengubah pendaftaran donor pengguna lain'),
+    ('donor_schedule:own', 'Mengelola jadwal donor sendiri'),
+    ('donation:create', 'Berdonasi uang'),
+    ('donation:read', 'Melihat semua donasi uang'),
+    ('certificate:own', 'Melihat dan mengklaim sertifikat sendiri'),
+    ('certificate:read', 'Melihat sertifikat pengguna lain'),
+    ('certificate:mint', 'Memantau rekonsiliasi mint sertifikat'),
+    ('wallet:link', 'Menghubungkan wallet'),
+    ('blood_request:own', 'Membuat dan mengelola permintaan darah sendiri'),
+    ('blood_request:read', 'Melihat semua permintaan darah'),
+    ('blood_request:verify', 'Memverifikasi permintaan darah'),
+    ('blood_request:delete', 'Menghapus permintaan darah'),
+    ('blood_request:manage', 'Mengubah dan menghapus permintaan darah pengguna lain'),
+    ('campaign:manage', 'Membuat dan mengubah kampanye'),
+    ('blood_donation:own', 'Melihat donasi darah sendiri'),
+    ('blood_donation:create', 'Mencatat donasi darah'),
+    ('blood_donation:read', 'Melihat detail donasi darah'),
+    ('blood_donation:delete', 'Menghapus donasi darah'),
+    ('blood_donation:verify', 'Menyelesaikan donasi darah'),
+    ('blood_donation:manage', 'Melihat semua dan menghapus donasi darah pengguna lain'),
+    ('hospital:read', 'Melihat rumah sakit'),
+    ('hospital:create', 'Menambah rumah sakit'),
+    ('hospital:manage', 'Mengubah dan menghapus rumah sakit'),
+    ('inventory:read', 'Melihat stok darah'),
+    ('inventory:manage', 'Mengelola kantong darah'),
+    ('user:read', 'Melihat semua pengguna'),
+    ('user:delete', 'Menghapus pengguna'),
+    ('role:manage', 'Mengelola role dan permission')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO public.role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM public.roles r CROSS JOIN public.permissions p
+WHERE r.name = 'Administrator'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO public.role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM public.roles r JOIN public.permissions p ON p.name IN (
+    'dashboard:donor',
+    'health_passport:own',
+    'notification:own',
+    'donor_registration:create',
+    'donor_registration:read',
+    'donor_registration:update',
+    'donor_schedule:own',
+    'donation:create',
+    'certificate:own',
+    'wallet:link',
+    'blood_request:own',
+    'blood_request:delete',
+    'blood_donation:own',
+    'blood_donation:create',
+    'blood_donation:read',
+    'blood_donation:delete',
+    'hospital:read',
+    'hospital:create'
+)
+WHERE r.name = 'User'
+ON CONFLICT DO NOTHING;
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+
+INSERT INTO public.role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM public.roles r JOIN public.permissions p ON p.name = 'hospital:create'
+WHERE r.name = 'User'
+ON CONFLICT DO NOTHING;
+
+DELETE FROM public.roles WHERE name = 'Hospital Staff';
+DELETE FROM public.permissions WHERE name = 'hospital:staff';
+DROP TABLE IF EXISTS public.hospital_staff;
+
+COMMIT;
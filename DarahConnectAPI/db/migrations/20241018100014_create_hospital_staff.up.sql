@@ -0,0 +1,44 @@
+BEGIN;
+
+-- Keanggotaan staf rumah sakit. Pengguna dengan permission hospital:staff hanya melihat data
+-- rumah sakit yang tercatat di sini.
+CREATE TABLE IF NOT EXISTS public.hospital_staff (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    hospital_id BIGINT NOT NULL REFERENCES public.hospitals(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ,
+    UNIQUE (user_id, hospital_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_hospital_staff_hospital ON public.hospital_staff (hospital_id);
+
+INSERT INTO public.permissions (name, description) VALUES
+    ('hospital:staff', 'Akses dibatasi ke rumah sakit tempat pengguna bertugas')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO public.roles (name, description, is_system, created_at, updated_at) VALUES
+    ('Hospital Staff', 'Staf rumah sakit yang memverifikasi permintaan dan donor di rumah sakitnya', FALSE, NOW(), NOW())
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO public.role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM public.roles r JOIN public.permissions p ON p.name IN (
+    'hospital:staff',
+    'hospital:read',
+    'blood_request:read',
+    'blood_request:verify',
+    'donor_registration:read',
+    'donor_registration:update',
+    'donor_registration:manage',
+    'blood_donation:read',
+    'blood_donation:verify',
+    'blood_donation:manage'
+)
+WHERE r.name = 'Hospital Staff'
+ON CONFLICT DO NOTHING;
+
+-- Rumah sakit baru hanya ditambahkan oleh admin
+DELETE FROM public.role_permissions
+WHERE role_id = (SELECT id FROM public.roles WHERE name = 'User')
+  AND permission_id = (SELECT id FROM public.permissions WHERE name = 'hospital:create');
+
+COMMIT;
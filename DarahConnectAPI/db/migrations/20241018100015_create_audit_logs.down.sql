@@ -0,0 +1,7 @@
+BEGIN;
+
+DELETE FROM public.permissions WHERE name = 'audit_log:read';
+DROP TABLE IF EXISTS public.audit_logs;
+DROP FUNCTION IF EXISTS public.audit_logs_append_only();
+
+COMMIT;
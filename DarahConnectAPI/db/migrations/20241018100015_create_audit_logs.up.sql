@@ -0,0 +1,48 @@
+BEGIN;
+
+-- Audit log hanya boleh ditambah. Setiap baris menyimpan hash baris sebelumnya sehingga
+-- perubahan atau penghapusan baris lama memutus rantai hash.
+CREATE TABLE IF NOT EXISTS public.audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id BIGINT,
+    actor_role VARCHAR(100),
+    action VARCHAR(100) NOT NULL,
+    entity_type VARCHAR(64) NOT NULL,
+    entity_id BIGINT,
+    changes JSONB,
+    ip VARCHAR(64),
+    request_id VARCHAR(64),
+    method VARCHAR(10),
+    path TEXT,
+    prev_hash VARCHAR(64) NOT NULL, -- kosong untuk baris pertama
+    hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor ON public.audit_logs (actor_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON public.audit_logs (entity_type, entity_id, created_at);
+
+CREATE OR REPLACE FUNCTION public.audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs hanya boleh ditambah';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_logs_no_update_delete
+    BEFORE UPDATE OR DELETE ON public.audit_logs
+    FOR EACH ROW EXECUTE FUNCTION public.audit_logs_append_only();
+
+CREATE TRIGGER audit_logs_no_truncate
+    BEFORE TRUNCATE ON public.audit_logs
+    FOR EACH STATEMENT EXECUTE FUNCTION public.audit_logs_append_only();
+
+INSERT INTO public.permissions (name, description) VALUES
+    ('audit_log:read', 'Melihat dan memverifikasi audit log')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO public.role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM public.roles r JOIN public.permissions p ON p.name = 'audit_log:read'
+WHERE r.name = 'Administrator'
+ON CONFLICT DO NOTHING;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.payment_notifications;
+DROP INDEX IF EXISTS public.idx_donations_order_id;
+
+COMMIT;
@@ -0,0 +1,33 @@
+BEGIN;
+
+-- Kolom order_id sudah dipakai aplikasi tetapi belum pernah dibuat lewat migrasi
+ALTER TABLE public.donations
+ADD COLUMN IF NOT EXISTS order_id BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_donations_order_id ON public.donations (order_id);
+
+-- Semua HTTP notification Midtrans disimpan apa adanya, termasuk yang signature-nya tidak valid
+CREATE TABLE IF NOT EXISTS public.payment_notifications (
+    id BIGSERIAL PRIMARY KEY,
+    order_id VARCHAR(64),
+    transaction_id VARCHAR(64),
+    transaction_status VARCHAR(32),
+    fraud_status VARCHAR(32) NOT NULL DEFAULT '',
+    status_code VARCHAR(8),
+    gross_amount VARCHAR(32),
+    signature_valid BOOLEAN NOT NULL DEFAULT FALSE,
+    payload JSONB NOT NULL,
+    result VARCHAR(20) NOT NULL DEFAULT 'received', -- received, applied, duplicate, ignored, rejected, failed
+    message TEXT,
+    processed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_notifications_order ON public.payment_notifications (order_id, created_at);
+
+-- Satu status per order hanya diterapkan sekali meskipun Midtrans mengirim ulang notification.
+-- fraud_status ikut dihitung karena capture challenge bisa disusul capture accept.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_payment_notifications_applied
+    ON public.payment_notifications (order_id, transaction_status, fraud_status) WHERE result = 'applied';
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DELETE FROM public.permissions WHERE name = 'donation:refund';
+
+ALTER TABLE public.payment_notifications DROP COLUMN IF EXISTS gateway;
+ALTER TABLE public.donations DROP COLUMN IF EXISTS gateway;
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+-- Gateway yang memproses donasi; donasi lama seluruhnya lewat Midtrans
+ALTER TABLE public.donations
+ADD COLUMN IF NOT EXISTS gateway VARCHAR(20) NOT NULL DEFAULT 'midtrans';
+
+ALTER TABLE public.payment_notifications
+ADD COLUMN IF NOT EXISTS gateway VARCHAR(20) NOT NULL DEFAULT 'midtrans';
+
+INSERT INTO public.permissions (name, description) VALUES
+    ('donation:refund', 'Meminta refund donasi ke gateway pembayaran')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO public.role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM public.roles r JOIN public.permissions p ON p.name = 'donation:refund'
+WHERE r.name = 'Administrator'
+ON CONFLICT DO NOTHING;
+
+COMMIT;
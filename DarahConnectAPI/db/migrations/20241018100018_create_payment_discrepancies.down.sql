@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS public.idx_donations_status_created_at;
+DROP TABLE IF EXISTS public.payment_discrepancies;
+
+COMMIT;
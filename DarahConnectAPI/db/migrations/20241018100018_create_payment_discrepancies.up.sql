@@ -0,0 +1,24 @@
+BEGIN;
+
+-- Temuan rekonsiliasi donasi pending terhadap status transaksi di gateway
+CREATE TABLE IF NOT EXISTS public.payment_discrepancies (
+    id BIGSERIAL PRIMARY KEY,
+    donation_id BIGINT NOT NULL REFERENCES public.donations (id) ON DELETE CASCADE,
+    order_id VARCHAR(64) NOT NULL,
+    gateway VARCHAR(20) NOT NULL,
+    local_status VARCHAR(32) NOT NULL,
+    gateway_status VARCHAR(32),
+    local_amount BIGINT NOT NULL,
+    gateway_amount VARCHAR(32),
+    action VARCHAR(20) NOT NULL, -- applied, ignored, rejected, expired
+    message TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_discrepancies_created_at ON public.payment_discrepancies (created_at);
+CREATE INDEX IF NOT EXISTS idx_payment_discrepancies_donation ON public.payment_discrepancies (donation_id);
+
+-- Dipakai reconciler untuk mencari donasi pending yang sudah lama
+CREATE INDEX IF NOT EXISTS idx_donations_status_created_at ON public.donations (status, created_at);
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+ALTER TABLE public.donations
+DROP CONSTRAINT IF EXISTS donations_single_target,
+DROP COLUMN IF EXISTS fund_id,
+DROP COLUMN IF EXISTS blood_request_id;
+
+ALTER TABLE public.blood_requests
+DROP COLUMN IF EXISTS donation_deadline,
+DROP COLUMN IF EXISTS goal_amount;
+
+DROP TABLE IF EXISTS public.funds;
+
+-- Order id baru yang tidak berformat ORDER-<user>-<nomor> tidak bisa dikembalikan
+DROP INDEX IF EXISTS public.uq_donations_order_id;
+
+ALTER TABLE public.donations
+ALTER COLUMN order_id TYPE BIGINT
+USING CASE WHEN order_id ~ '^ORDER-[0-9]+-[0-9]+$' THEN split_part(order_id, '-', 3)::BIGINT END;
+
+CREATE INDEX IF NOT EXISTS idx_donations_order_id ON public.donations (order_id);
+
+COMMIT;
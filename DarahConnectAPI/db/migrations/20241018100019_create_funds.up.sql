@@ -0,0 +1,40 @@
+BEGIN;
+
+-- Order id disimpan utuh seperti yang dikirim ke gateway. Order lama berformat
+-- ORDER-<user>-<nomor> disusun ulang dari kolom lama.
+DROP INDEX IF EXISTS public.idx_donations_order_id;
+
+ALTER TABLE public.donations
+ALTER COLUMN order_id TYPE VARCHAR(64)
+USING CASE WHEN order_id IS NULL THEN NULL ELSE 'ORDER-' || user_id || '-' || order_id END;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_donations_order_id ON public.donations (order_id);
+
+-- Dana umum di luar campaign, misalnya operasional bank darah
+CREATE TABLE IF NOT EXISTS public.funds (
+    id BIGSERIAL PRIMARY KEY,
+    title VARCHAR(150) NOT NULL,
+    description TEXT,
+    goal_amount BIGINT NOT NULL CHECK (goal_amount > 0),
+    deadline TIMESTAMPTZ,
+    status VARCHAR(20) NOT NULL DEFAULT 'active', -- active, closed
+    created_by BIGINT REFERENCES public.users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Target penggalangan dana campaign; goal_amount 0 berarti campaign tidak menerima donasi uang
+ALTER TABLE public.blood_requests
+ADD COLUMN IF NOT EXISTS goal_amount BIGINT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS donation_deadline TIMESTAMPTZ;
+
+-- Donasi uang ditujukan ke paling banyak satu target
+ALTER TABLE public.donations
+ADD COLUMN IF NOT EXISTS blood_request_id BIGINT REFERENCES public.blood_requests (id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS fund_id BIGINT REFERENCES public.funds (id) ON DELETE SET NULL,
+ADD CONSTRAINT donations_single_target CHECK (blood_request_id IS NULL OR fund_id IS NULL);
+
+CREATE INDEX IF NOT EXISTS idx_donations_blood_request ON public.donations (blood_request_id) WHERE blood_request_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_donations_fund ON public.donations (fund_id) WHERE fund_id IS NOT NULL;
+
+COMMIT;
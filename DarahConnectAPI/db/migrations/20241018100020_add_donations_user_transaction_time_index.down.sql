@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP INDEX IF EXISTS public.idx_donations_user_transaction_time;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+-- Laporan donasi tahunan mengambil donasi berhasil milik satu pengguna berdasarkan waktu pembayaran
+CREATE INDEX IF NOT EXISTS idx_donations_user_transaction_time ON public.donations (user_id, transaction_time) WHERE status = 'success';
+
+COMMIT;
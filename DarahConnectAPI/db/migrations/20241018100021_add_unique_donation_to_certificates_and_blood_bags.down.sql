@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS public.idx_blood_bags_donation_id;
+DROP INDEX IF EXISTS public.idx_certificates_donation_id;
+
+COMMIT;
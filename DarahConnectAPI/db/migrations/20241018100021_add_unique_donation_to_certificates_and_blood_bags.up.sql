@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Satu donasi darah yang selesai hanya menerbitkan satu sertifikat dan satu kantong darah
+CREATE UNIQUE INDEX IF NOT EXISTS idx_certificates_donation_id ON public.certificates (donation_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_blood_bags_donation_id ON public.blood_bags (donation_id);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS public.idx_donations_user_transaction_time;
+CREATE INDEX IF NOT EXISTS idx_donations_user_transaction_time ON public.donations (user_id, transaction_time) WHERE status = 'success';
+
+ALTER TABLE public.donations DROP COLUMN IF EXISTS refunded_amount;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE public.donations ADD COLUMN IF NOT EXISTS refunded_amount BIGINT NOT NULL DEFAULT 0;
+
+-- Laporan tahunan juga memuat donasi yang direfund sebagian
+DROP INDEX IF EXISTS public.idx_donations_user_transaction_time;
+CREATE INDEX IF NOT EXISTS idx_donations_user_transaction_time ON public.donations (user_id, transaction_time) WHERE status IN ('success', 'partially_refunded');
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE public.emergency_alerts RENAME COLUMN email_queued_at TO email_sent_at;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+-- Email darurat kini dikirim oleh outbox dispatcher, kolom ini mencatat waktu email diantrekan
+ALTER TABLE public.emergency_alerts RENAME COLUMN email_sent_at TO email_queued_at;
+
+COMMIT;
@@ -1,115 +1,287 @@
 package builder
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
 
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/handler"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/router"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/worker"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/cloudinary"
 	googleoauth "github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/googleOauth"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/mailer"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/midtrans"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/payment"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/payment/fake"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/audit"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/rbac"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/revocation"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/route"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/token"
+	pkgworker "github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/worker"
 
 	"gorm.io/gorm"
 )
 
-func BuildPublicRoutes(cfg *configs.Config, db *gorm.DB, cloudinaryService *cloudinary.Service, mailer *mailer.Mailer, blockchain service.BlockchainService) []route.Route {
-	tokenUseCase := token.NewTokenUseCase(cfg.JWT.SecretKey)
+func BuildPublicRoutes(cfg *configs.Config, db *gorm.DB, cloudinaryService *cloudinary.Service, mailer *mailer.Mailer, blockchain service.BlockchainService, gateway payment.PaymentGateway, keys *token.KeySet, revocations revocation.Store, authorizer *rbac.Authorizer) []route.Route {
+	tokenUseCase := token.NewTokenUseCase(keys)
 
 	//repository
 	userRepository := repository.NewUserRepository(db)
+	walletChallengeRepository := repository.NewWalletChallengeRepository(db)
+	refreshTokenRepository := repository.NewRefreshTokenRepository(db)
+	userMFARepository := repository.NewUserMFARepository(db)
+	mfaChallengeRepository := repository.NewMFAChallengeRepository(db)
 	bloodRequestRepository := repository.NewBloodRequestRepository(db)
 	notificationRepository := repository.NewNotificationRepository(db)
+	emergencyAlertRepository := repository.NewEmergencyAlertRepository(db)
 	bloodDonationRepository := repository.NewBloodDonationRepository(db)
 	certificateRepository := repository.NewCertificateRepository(db)
+	certificateClaimRepository := repository.NewCertificateClaimRepository(db)
 	donorRegistrationRepository := repository.NewDonorRegistrationRepository(db)
 	donationsRepository := repository.NewDonationsRepository(db)
+	paymentNotificationRepository := repository.NewPaymentNotificationRepository(db)
+	paymentDiscrepancyRepository := repository.NewPaymentDiscrepancyRepository(db)
+	fundRepository := repository.NewFundRepository(db)
+	bloodBagRepository := repository.NewBloodBagRepository(db)
+	outboxRepository := repository.NewOutboxRepository(db)
+	chainIndexRepository := repository.NewChainIndexRepository(db)
+	auditLogRepository := repository.NewAuditLogRepository(db)
+	transactor := repository.NewTransactor(db)
 	//end
 
 	//service
-	userService := service.NewUserService(userRepository, tokenUseCase, cfg, mailer, cloudinaryService)
-	bloodRequestService := service.NewBloodRequestService(bloodRequestRepository, *cloudinaryService)
+	auditLogService := service.NewAuditLogService(auditLogRepository, transactor)
+	userService := service.NewUserService(userRepository, tokenUseCase, cfg, mailer, cloudinaryService, transactor, auditLogService)
+	walletAuthService := service.NewWalletAuthService(walletChallengeRepository, userRepository, &cfg.WalletAuth)
+	sessionService := service.NewSessionService(refreshTokenRepository, userRepository, transactor, revocations, tokenUseCase, &cfg.JWT)
+	mfaService := service.NewMFAService(userMFARepository, mfaChallengeRepository, userRepository, transactor, &cfg.MFA, &cfg.Sealing)
+	inventoryService := service.NewInventoryService(bloodBagRepository)
+	outboxService := service.NewOutboxService(outboxRepository)
+	eligibilityService := service.NewEligibilityService(userRepository, bloodDonationRepository, &cfg.Eligibility)
+	emergencyAlertService := service.NewEmergencyAlertService(emergencyAlertRepository, eligibilityService, outboxService, &cfg.EmergencyAlert)
+	bloodRequestService := service.NewBloodRequestService(bloodRequestRepository, donationsRepository, *cloudinaryService, inventoryService, emergencyAlertService, transactor, auditLogService)
 	notificationService := service.NewNotificationService(notificationRepository,userRepository)
-	bloodDonationService := service.NewBloodDonationService(bloodDonationRepository, *cloudinaryService)
-	certificateService := service.NewCertificateService(certificateRepository)
+	certificateService := service.NewCertificateService(certificateRepository, certificateClaimRepository, userRepository, bloodDonationRepository, transactor, outboxService, blockchain, &cfg.Blockchain, &cfg.Certificate)
+	bloodDonationService := service.NewBloodDonationService(bloodDonationRepository, *cloudinaryService, certificateService, inventoryService, outboxService, transactor, auditLogService)
+	certificateIndexerService := service.NewCertificateIndexerService(chainIndexRepository, transactor, blockchain, &cfg.Blockchain)
 	donorRegistrationService := service.NewDonorRegistrationService(donorRegistrationRepository)
-	// Buat instance midtransService
-	midtransService := midtrans.NewMidtransService(&cfg.MidtransConfig)
-	// Set donationsRepository
-	midtransService.DonationsRepository = donationsRepository
 	dashboardService := service.NewDashboardService(bloodDonationRepository, bloodRequestRepository, userRepository, certificateRepository)
-	googleAuthService := googleoauth.NewGoogleOAuthService(tokenUseCase, userService, &cfg.GoogleOauth)
-	donationService := service.NewDonationService(donationsRepository)
+	googleAuthService := googleoauth.NewGoogleOAuthService(sessionService, userService, mfaService, &cfg.GoogleOauth)
+	donationService := service.NewDonationService(donationsRepository, paymentNotificationRepository, paymentDiscrepancyRepository, bloodRequestRepository, fundRepository, userRepository, transactor, outboxService, gateway, &cfg.Payment)
+	fundService := service.NewFundService(fundRepository, donationsRepository, transactor, auditLogService)
 	//end
 
 	//handler
-	userHandler := handler.NewUserHandler(userService, cloudinaryService, googleAuthService)
-	bloodRequestHandler := handler.NewBloodRequestHandler(bloodRequestService, notificationService)
-	bloodDonationHandler := handler.NewBloodDonationHandler(bloodDonationService, notificationService, certificateService, donorRegistrationService, userService, blockchain)
+	userHandler := handler.NewUserHandler(userService, walletAuthService, sessionService, mfaService, cloudinaryService, googleAuthService)
+	bloodRequestHandler := handler.NewBloodRequestHandler(bloodRequestService, notificationService, userService, authorizer)
+	bloodDonationHandler := handler.NewBloodDonationHandler(bloodDonationService, notificationService, certificateService, donorRegistrationService, userService, authorizer)
 	dashboardHandler := handler.NewDashboardHandler(dashboardService)
-	certificateHandler := handler.NewCertificateHandler(certificateService)
-	donationHandler := handler.NewDonationHandler(midtransService, notificationService, donationService)
+	certificateHandler := handler.NewCertificateHandler(certificateService, certificateIndexerService, authorizer)
+	donationHandler := handler.NewDonationHandler(notificationService, donationService)
+	fundHandler := handler.NewFundHandler(fundService)
 	//end
 
-	return router.PublicRoutes(userHandler, bloodRequestHandler, bloodDonationHandler, certificateHandler, donationHandler, dashboardHandler)
+	routes := router.PublicRoutes(userHandler, bloodRequestHandler, bloodDonationHandler, certificateHandler, donationHandler, dashboardHandler, fundHandler)
+	if provider, ok := gateway.(payment.RouteProvider); ok {
+		routes = append(routes, provider.Routes()...)
+	}
+	return routes
 }
 
-func BuildPrivateRoutes(cfg *configs.Config, db *gorm.DB, cloudinaryService *cloudinary.Service, mailer *mailer.Mailer, blockchain service.BlockchainService) []route.Route {
-	tokenUseCase := token.NewTokenUseCase(cfg.JWT.SecretKey)
+func BuildPrivateRoutes(cfg *configs.Config, db *gorm.DB, cloudinaryService *cloudinary.Service, mailer *mailer.Mailer, blockchain service.BlockchainService, gateway payment.PaymentGateway, keys *token.KeySet, revocations revocation.Store, authorizer *rbac.Authorizer) []route.Route {
+	tokenUseCase := token.NewTokenUseCase(keys)
 
 	//repository
 	userRepository := repository.NewUserRepository(db)
+	walletChallengeRepository := repository.NewWalletChallengeRepository(db)
+	refreshTokenRepository := repository.NewRefreshTokenRepository(db)
+	userMFARepository := repository.NewUserMFARepository(db)
+	mfaChallengeRepository := repository.NewMFAChallengeRepository(db)
 	notificationRepository := repository.NewNotificationRepository(db)
+	emergencyAlertRepository := repository.NewEmergencyAlertRepository(db)
 	healthPassportRepository := repository.NewHealthPassportRepository(db)
+	healthScreeningRepository := repository.NewHealthScreeningRepository(db)
 	bloodRequestRepository := repository.NewBloodRequestRepository(db)
 	donorRegistrationRepository := repository.NewDonorRegistrationRepository(db)
 	donorScheduleRepository := repository.NewDonorScheduleRepository(db)
 	hospitalRepository := repository.NewHospitalRepository(db)
+	hospitalStaffRepository := repository.NewHospitalStaffRepository(db)
 	bloodDonationRepository := repository.NewBloodDonationRepository(db)
 	certificateRepository := repository.NewCertificateRepository(db)
+	certificateClaimRepository := repository.NewCertificateClaimRepository(db)
 	donationsRepository := repository.NewDonationsRepository(db)
+	paymentNotificationRepository := repository.NewPaymentNotificationRepository(db)
+	paymentDiscrepancyRepository := repository.NewPaymentDiscrepancyRepository(db)
+	fundRepository := repository.NewFundRepository(db)
+	bloodBagRepository := repository.NewBloodBagRepository(db)
+	outboxRepository := repository.NewOutboxRepository(db)
+	chainIndexRepository := repository.NewChainIndexRepository(db)
+	roleRepository := repository.NewRoleRepository(db)
+	auditLogRepository := repository.NewAuditLogRepository(db)
+	transactor := repository.NewTransactor(db)
 	//end
 
 	//service
-	userService := service.NewUserService(userRepository, tokenUseCase, cfg, mailer,cloudinaryService)
+	auditLogService := service.NewAuditLogService(auditLogRepository, transactor)
+	userService := service.NewUserService(userRepository, tokenUseCase, cfg, mailer, cloudinaryService, transactor, auditLogService)
+	walletAuthService := service.NewWalletAuthService(walletChallengeRepository, userRepository, &cfg.WalletAuth)
+	sessionService := service.NewSessionService(refreshTokenRepository, userRepository, transactor, revocations, tokenUseCase, &cfg.JWT)
+	mfaService := service.NewMFAService(userMFARepository, mfaChallengeRepository, userRepository, transactor, &cfg.MFA, &cfg.Sealing)
 	notificationService := service.NewNotificationService(notificationRepository,userRepository)
-	healthPassportService := service.NewHealthPassportService(healthPassportRepository)
-	bloodRequestService := service.NewBloodRequestService(bloodRequestRepository, *cloudinaryService)
+	healthPassportService := service.NewHealthPassportService(healthPassportRepository, healthScreeningRepository, transactor, auditLogService)
+	inventoryService := service.NewInventoryService(bloodBagRepository)
+	outboxService := service.NewOutboxService(outboxRepository)
+	eligibilityService := service.NewEligibilityService(userRepository, bloodDonationRepository, &cfg.Eligibility)
+	emergencyAlertService := service.NewEmergencyAlertService(emergencyAlertRepository, eligibilityService, outboxService, &cfg.EmergencyAlert)
+	bloodRequestService := service.NewBloodRequestService(bloodRequestRepository, donationsRepository, *cloudinaryService, inventoryService, emergencyAlertService, transactor, auditLogService)
 	donorRegistrationService := service.NewDonorRegistrationService(donorRegistrationRepository)
 	donorScheduleService := service.NewDonorScheduleService(donorScheduleRepository)
 	hospitalService := service.NewHospitalService(hospitalRepository)
-	bloodDonationService := service.NewBloodDonationService(bloodDonationRepository, *cloudinaryService)
-	certificateService := service.NewCertificateService(certificateRepository)
-
-	// Buat instance midtransService
-	midtransService := midtrans.NewMidtransService(&cfg.MidtransConfig)
-	// Set donationsRepository
-	midtransService.DonationsRepository = donationsRepository
-	googleAuthService := googleoauth.NewGoogleOAuthService(tokenUseCase, userService, &cfg.GoogleOauth)
-	donationService := service.NewDonationService(donationsRepository)
+	hospitalStaffService := service.NewHospitalStaffService(hospitalStaffRepository, hospitalRepository, userRepository, transactor, auditLogService)
+	certificateService := service.NewCertificateService(certificateRepository, certificateClaimRepository, userRepository, bloodDonationRepository, transactor, outboxService, blockchain, &cfg.Blockchain, &cfg.Certificate)
+	bloodDonationService := service.NewBloodDonationService(bloodDonationRepository, *cloudinaryService, certificateService, inventoryService, outboxService, transactor, auditLogService)
+	certificateIndexerService := service.NewCertificateIndexerService(chainIndexRepository, transactor, blockchain, &cfg.Blockchain)
+
+	googleAuthService := googleoauth.NewGoogleOAuthService(sessionService, userService, mfaService, &cfg.GoogleOauth)
+	donationService := service.NewDonationService(donationsRepository, paymentNotificationRepository, paymentDiscrepancyRepository, bloodRequestRepository, fundRepository, userRepository, transactor, outboxService, gateway, &cfg.Payment)
+	fundService := service.NewFundService(fundRepository, donationsRepository, transactor, auditLogService)
 	dashboardService := service.NewDashboardService(bloodDonationRepository, bloodRequestRepository, userRepository, certificateRepository)
+	roleService := service.NewRoleService(roleRepository, userRepository, transactor, authorizer, auditLogService)
 
 	//end
 
 	//handler
-	userHandler := handler.NewUserHandler(userService, cloudinaryService, googleAuthService)
+	userHandler := handler.NewUserHandler(userService, walletAuthService, sessionService, mfaService, cloudinaryService, googleAuthService)
 	notificationHandler := handler.NewNotificationHandler(notificationService)
 	healthPassportHandler := handler.NewHealthPassportHandler(healthPassportService)
-	bloodRequestHandler := handler.NewBloodRequestHandler(bloodRequestService, notificationService)
-	donorRegistrationHandler := handler.NewDonorRegistrationHandler(donorRegistrationService, healthPassportService, notificationService, bloodRequestService)
+	bloodRequestHandler := handler.NewBloodRequestHandler(bloodRequestService, notificationService, userService, authorizer)
+	donorRegistrationHandler := handler.NewDonorRegistrationHandler(donorRegistrationService, healthPassportService, notificationService, bloodRequestService, eligibilityService, authorizer)
 
 	donorScheduleHandler := handler.NewDonorScheduleHandler(donorScheduleService)
-	hospitalHandler := handler.NewHospitalHandler(hospitalService)
-	bloodDonationHandler := handler.NewBloodDonationHandler(bloodDonationService, notificationService, certificateService, donorRegistrationService, userService, blockchain)
+	hospitalHandler := handler.NewHospitalHandler(hospitalService, hospitalStaffService)
+	bloodDonationHandler := handler.NewBloodDonationHandler(bloodDonationService, notificationService, certificateService, donorRegistrationService, userService, authorizer)
 
-	certificateHandler := handler.NewCertificateHandler(certificateService)
-	donationHandler := handler.NewDonationHandler(midtransService, notificationService,donationService)
+	certificateHandler := handler.NewCertificateHandler(certificateService, certificateIndexerService, authorizer)
+	donationHandler := handler.NewDonationHandler(notificationService, donationService)
+	fundHandler := handler.NewFundHandler(fundService)
 	dashboardHandler := handler.NewDashboardHandler(dashboardService)
+	inventoryHandler := handler.NewInventoryHandler(inventoryService)
+	eligibilityHandler := handler.NewEligibilityHandler(eligibilityService)
+	auditLogHandler := handler.NewAuditLogHandler(auditLogService)
+	roleHandler := handler.NewRoleHandler(roleService, sessionService)
 	//end
 
-	return router.PrivateRoutes(userHandler, notificationHandler, healthPassportHandler, bloodRequestHandler, donorRegistrationHandler, donorScheduleHandler, hospitalHandler, bloodDonationHandler, certificateHandler, donationHandler, dashboardHandler)
+	return router.PrivateRoutes(userHandler, notificationHandler, healthPassportHandler, bloodRequestHandler, donorRegistrationHandler, donorScheduleHandler, hospitalHandler, bloodDonationHandler, certificateHandler, donationHandler, dashboardHandler, inventoryHandler, eligibilityHandler, roleHandler, auditLogHandler, fundHandler)
+}
+
+// BuildKeySet memuat signing key JWT dari database dan membuat kunci pertama jika belum ada
+func BuildKeySet(ctx context.Context, cfg *configs.Config, db *gorm.DB) (*token.KeySet, error) {
+	keys := token.NewKeySet()
+	signingKeyService := service.NewSigningKeyService(repository.NewSigningKeyRepository(db), repository.NewTransactor(db), keys, &cfg.JWT, &cfg.Sealing)
+	if err := signingKeyService.Rotate(ctx); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// BuildPaymentGateway memilih gateway pembayaran donasi sesuai PAYMENT_GATEWAY
+func BuildPaymentGateway(cfg *configs.Config) (payment.PaymentGateway, error) {
+	switch cfg.Payment.Gateway {
+	case "midtrans":
+		return midtrans.NewGateway(&cfg.MidtransConfig)
+	case "fake":
+		// Gateway fake menandai donasi lunas tanpa pembayaran, jangan sampai aktif di production
+		if cfg.MidtransConfig.Environment == "production" {
+			return nil, errors.New("gateway pembayaran fake tidak boleh dipakai saat MIDTRANS_ENVIRONMENT=production")
+		}
+		if !cfg.Payment.AllowFake {
+			return nil, errors.New("gateway pembayaran fake harus diizinkan dengan PAYMENT_ALLOW_FAKE=true")
+		}
+		log.Println("!!! PERINGATAN: gateway pembayaran FAKE aktif, donasi dapat ditandai lunas tanpa pembayaran. Hanya untuk development !!!")
+		return fake.NewGateway(&cfg.Payment)
+	default:
+		return nil, fmt.Errorf("gateway pembayaran %q tidak dikenal", cfg.Payment.Gateway)
+	}
+}
+
+// BuildAuthorizer memuat permission setiap role dari database
+func BuildAuthorizer(ctx context.Context, db *gorm.DB) (*rbac.Authorizer, error) {
+	authorizer := rbac.NewAuthorizer()
+	transactor := repository.NewTransactor(db)
+	auditLogService := service.NewAuditLogService(repository.NewAuditLogRepository(db), transactor)
+	roleService := service.NewRoleService(repository.NewRoleRepository(db), repository.NewUserRepository(db), transactor, authorizer, auditLogService)
+	if err := roleService.Load(ctx); err != nil {
+		return nil, err
+	}
+	return authorizer, nil
+}
+
+// BuildHospitalResolver mencari rumah sakit tempat staf bertugas untuk membatasi aksesnya
+func BuildHospitalResolver(db *gorm.DB) rbac.HospitalResolver {
+	transactor := repository.NewTransactor(db)
+	auditLogService := service.NewAuditLogService(repository.NewAuditLogRepository(db), transactor)
+	return service.NewHospitalStaffService(repository.NewHospitalStaffRepository(db), repository.NewHospitalRepository(db), repository.NewUserRepository(db), transactor, auditLogService)
+}
+
+// BuildAuditRecorder mencatat aksi admin yang tidak dicatat service ke audit log
+func BuildAuditRecorder(db *gorm.DB) audit.Recorder {
+	return service.NewAuditLogService(repository.NewAuditLogRepository(db), repository.NewTransactor(db))
+}
+
+func BuildWorkers(cfg *configs.Config, db *gorm.DB, mailer *mailer.Mailer, blockchain service.BlockchainService, gateway payment.PaymentGateway, keys *token.KeySet, authorizer *rbac.Authorizer) []pkgworker.Worker {
+	//repository
+	userRepository := repository.NewUserRepository(db)
+	notificationRepository := repository.NewNotificationRepository(db)
+	bloodDonationRepository := repository.NewBloodDonationRepository(db)
+	certificateRepository := repository.NewCertificateRepository(db)
+	certificateClaimRepository := repository.NewCertificateClaimRepository(db)
+	outboxRepository := repository.NewOutboxRepository(db)
+	chainIndexRepository := repository.NewChainIndexRepository(db)
+	signingKeyRepository := repository.NewSigningKeyRepository(db)
+	roleRepository := repository.NewRoleRepository(db)
+	auditLogRepository := repository.NewAuditLogRepository(db)
+	donationsRepository := repository.NewDonationsRepository(db)
+	paymentNotificationRepository := repository.NewPaymentNotificationRepository(db)
+	paymentDiscrepancyRepository := repository.NewPaymentDiscrepancyRepository(db)
+	bloodRequestRepository := repository.NewBloodRequestRepository(db)
+	fundRepository := repository.NewFundRepository(db)
+	bloodBagRepository := repository.NewBloodBagRepository(db)
+	transactor := repository.NewTransactor(db)
+	//end
+
+	//service
+	auditLogService := service.NewAuditLogService(auditLogRepository, transactor)
+	notificationService := service.NewNotificationService(notificationRepository, userRepository)
+	outboxService := service.NewOutboxService(outboxRepository)
+	certificateService := service.NewCertificateService(certificateRepository, certificateClaimRepository, userRepository, bloodDonationRepository, transactor, outboxService, blockchain, &cfg.Blockchain, &cfg.Certificate)
+	certificateIndexerService := service.NewCertificateIndexerService(chainIndexRepository, transactor, blockchain, &cfg.Blockchain)
+	signingKeyService := service.NewSigningKeyService(signingKeyRepository, transactor, keys, &cfg.JWT, &cfg.Sealing)
+	roleService := service.NewRoleService(roleRepository, userRepository, transactor, authorizer, auditLogService)
+	donationService := service.NewDonationService(donationsRepository, paymentNotificationRepository, paymentDiscrepancyRepository, bloodRequestRepository, fundRepository, userRepository, transactor, outboxService, gateway, &cfg.Payment)
+	inventoryService := service.NewInventoryService(bloodBagRepository)
+	//end
+
+	outboxHandlers := service.NewOutboxHandlers(certificateService, notificationService, mailer)
+
+	workers := []pkgworker.Worker{
+		worker.NewOutboxDispatcher(outboxRepository, outboxHandlers, &cfg.Outbox),
+		worker.NewSigningKeyRotator(signingKeyService, &cfg.JWT),
+		worker.NewPermissionReloader(roleService, &cfg.RBAC),
+		worker.NewPaymentReconciler(donationService, &cfg.Payment),
+		worker.NewBloodBagExpirer(inventoryService, &cfg.Inventory),
+	}
+	if cfg.Blockchain.Mode != service.BlockchainModeDisabled {
+		workers = append(workers,
+			worker.NewCertificateReceiptPoller(certificateService, &cfg.Blockchain),
+			worker.NewCertificateIndexer(certificateIndexerService, &cfg.Blockchain),
+		)
+	}
+	return workers
 }
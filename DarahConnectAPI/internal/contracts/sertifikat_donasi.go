@@ -32,12 +32,34 @@ var (
 // SertifikatDonasiMetaData contains all meta data concerning the SertifikatDonasi contract.
 var SertifikatDonasiMetaData = &bind.MetaData{
 	ABI: "[{\"inputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"owner\",\"type\":\"address\"}],\"name\":\"OwnableInvalidOwner\",\"type\":\"error\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"OwnableUnauthorizedAccount\",\"type\":\"error\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"previousOwner\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"OwnershipTransferred\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"id\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"pendonor\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"nomorSertifikat\",\"type\":\"uint256\"}],\"name\":\"SertifikatDibuat\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"daftarSertifikat\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"id\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"pendonor\",\"type\":\"address\"},{\"internalType\":\"string\",\"name\":\"namaPendonor\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"tanggalDonasi\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"nomorSertifikat\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"alamatPendonor\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"penerbit\",\"type\":\"string\"},{\"internalType\":\"address\",\"name\":\"adminVerifikator\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_pendonor\",\"type\":\"address\"},{\"internalType\":\"string\",\"name\":\"_namaPendonor\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"_nomorSertifikat\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"_alamatPendonor\",\"type\":\"string\"}],\"name\":\"mintSertifikat\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"owner\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"renounceOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"transferOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
+	Bin: "0x608060405234801561001057600080fd5b5033600073ffffffffffffffffffffffffffffffffffffffff168173ffffffffffffffffffffffffffffffffffffffff16036100845760006040517f1e4fbdf700000000000000000000000000000000000000000000000000000000815260040161007b919061019e565b60405180910390fd5b6100938161009960201b60201c565b506101b9565b60008060009054906101000a900473ffffffffffffffffffffffffffffffffffffffff169050816000806101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff1602179055508173ffffffffffffffffffffffffffffffffffffffff168173ffffffffffffffffffffffffffffffffffffffff167f8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e060405160405180910390a35050565b600073ffffffffffffffffffffffffffffffffffffffff82169050919050565b60006101888261015d565b9050919050565b6101988161017d565b82525050565b60006020820190506101b3600083018461018f565b92915050565b610f04806101c86000396000f3fe608060405234801561001057600080fd5b50600436106100575760003560e01c80630bacf3a01461005c578063715018a6146100785780638da5cb5b14610082578063f2fde38b146100a0578063f55f6ba9146100bc575b600080fd5b610076600480360381019061007191906108d2565b6100f3565b005b610080610399565b005b61008a6103ad565b6040516100979190610988565b60405180910390f35b6100ba60048036038101906100b591906109a3565b6103d6565b005b6100d660048036038101906100d191906109d0565b61045c565b6040516100ea989796959493929190610a9c565b60405180910390f35b6100fb61067c565b6002600081548092919061010e90610b5e565b9190505550600060025490506040518061010001604052808281526020018873ffffffffffffffffffffffffffffffffffffffff16815260200187878080601f016020809104026020016040519081016040528093929190818152602001838380828437600081840152601f19601f82011690508083019250505050505050815260200142815260200185815260200184848080601f016020809104026020016040519081016040528093929190818152602001838380828437600081840152601f19601f8201169050808301925050505050505081526020016040518060400160405280600c81526020017f4461726168436f6e6e656374000000000000000000000000000000000000000081525081526020013373ffffffffffffffffffffffffffffffffffffffff16815250600160008381526020019081526020016000206000820151816000015560208201518160010160006101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff16021790555060408201518160020190816102b69190610de1565b50606082015181600301556080820151816004015560a08201518160050190816102e09190610de1565b5060c08201518160060190816102f69190610de1565b5060e08201518160070160006101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff1602179055509050508673ffffffffffffffffffffffffffffffffffffffff16817f2dd29160cc9010dcd6255f2e140c0c97e43efef714a91f2ed5606e13a8de8bd4866040516103889190610eb3565b60405180910390a350505050505050565b6103a161067c565b6103ab6000610703565b565b60008060009054906101000a900473ffffffffffffffffffffffffffffffffffffffff16905090565b6103de61067c565b600073ffffffffffffffffffffffffffffffffffffffff168173ffffffffffffffffffffffffffffffffffffffff16036104505760006040517f1e4fbdf70000000000000000000000000000000000000000000000000000000081526004016104479190610988565b60405180910390fd5b61045981610703565b50565b60016020528060005260406000206000915090508060000154908060010160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff16908060020180546104ab90610c04565b80601f01602080910402602001604051908101604052809291908181526020018280546104d790610c04565b80156105245780601f106104f957610100808354040283529160200191610524565b820191906000526020600020905b81548152906001019060200180831161050757829003601f168201915b50505050509080600301549080600401549080600501805461054590610c04565b80601f016020809104026020016040519081016040528092919081815260200182805461057190610c04565b80156105be5780601f10610593576101008083540402835291602001916105be565b820191906000526020600020905b8154815290600101906020018083116105a157829003601f168201915b5050505050908060060180546105d390610c04565b80601f01602080910402602001604051908101604052809291908181526020018280546105ff90610c04565b801561064c5780601f106106215761010080835404028352916020019161064c565b820191906000526020600020905b81548152906001019060200180831161062f57829003601f168201915b5050505050908060070160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff16905088565b6106846107c7565b73ffffffffffffffffffffffffffffffffffffffff166106a26103ad565b73ffffffffffffffffffffffffffffffffffffffff1614610701576106c56107c7565b6040517f118cdaa70000000000000000000000000000000000000000000000000000000081526004016106f89190610988565b60405180910390fd5b565b60008060009054906101000a900473ffffffffffffffffffffffffffffffffffffffff169050816000806101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff1602179055508173ffffffffffffffffffffffffffffffffffffffff168173ffffffffffffffffffffffffffffffffffffffff167f8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e060405160405180910390a35050565b600033905090565b600080fd5b600080fd5b600073ffffffffffffffffffffffffffffffffffffffff82169050919050565b6000610804826107d9565b9050919050565b610814816107f9565b811461081f57600080fd5b50565b6000813590506108318161080b565b92915050565b600080fd5b600080fd5b600080fd5b60008083601f84011261085c5761085b610837565b5b8235905067ffffffffffffffff8111156108795761087861083c565b5b60208301915083600182028301111561089557610894610841565b5b9250929050565b6000819050919050565b6108af8161089c565b81146108ba57600080fd5b50565b6000813590506108cc816108a6565b92915050565b600080600080600080608087890312156108ef576108ee6107cf565b5b60006108fd89828a01610822565b965050602087013567ffffffffffffffff81111561091e5761091d6107d4565b5b61092a89828a01610846565b9550955050604061093d89828a016108bd565b935050606087013567ffffffffffffffff81111561095e5761095d6107d4565b5b61096a89828a01610846565b92509250509295509295509295565b610982816107f9565b82525050565b600060208201905061099d6000830184610979565b92915050565b6000602082840312156109b9576109b86107cf565b5b60006109c784828501610822565b91505092915050565b6000602082840312156109e6576109e56107cf565b5b60006109f4848285016108bd565b91505092915050565b610a068161089c565b82525050565b600081519050919050565b600082825260208201905092915050565b60005b83811015610a46578082015181840152602081019050610a2b565b60008484015250505050565b6000601f19601f8301169050919050565b6000610a6e82610a0c565b610a788185610a17565b9350610a88818560208601610a28565b610a9181610a52565b840191505092915050565b600061010082019050610ab2600083018b6109fd565b610abf602083018a610979565b8181036040830152610ad18189610a63565b9050610ae060608301886109fd565b610aed60808301876109fd565b81810360a0830152610aff8186610a63565b905081810360c0830152610b138185610a63565b9050610b2260e0830184610979565b9998505050505050505050565b7f4e487b7100000000000000000000000000000000000000000000000000000000600052601160045260246000fd5b6000610b698261089c565b91507fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff8203610b9b57610b9a610b2f565b5b600182019050919050565b7f4e487b7100000000000000000000000000000000000000000000000000000000600052604160045260246000fd5b7f4e487b7100000000000000000000000000000000000000000000000000000000600052602260045260246000fd5b60006002820490506001821680610c1c57607f821691505b602082108103610c2f57610c2e610bd5565b5b50919050565b60008190508160005260206000209050919050565b60006020601f8301049050919050565b600082821b905092915050565b600060088302610c977fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff82610c5a565b610ca18683610c5a565b95508019841693508086168417925050509392505050565b6000819050919050565b6000610cde610cd9610cd48461089c565b610cb9565b61089c565b9050919050565b6000819050919050565b610cf883610cc3565b610d0c610d0482610ce5565b848454610c67565b825550505050565b600090565b610d21610d14565b610d2c818484610cef565b505050565b5b81811015610d5057610d45600082610d19565b600181019050610d32565b5050565b601f821115610d9557610d6681610c35565b610d6f84610c4a565b81016020851015610d7e578190505b610d92610d8a85610c4a565b830182610d31565b50505b505050565b600082821c905092915050565b6000610db860001984600802610d9a565b1980831691505092915050565b6000610dd18383610da7565b9150826002028217905092915050565b610dea82610a0c565b67ffffffffffffffff811115610e0357610e02610ba6565b5b610e0d8254610c04565b610e18828285610d54565b600060209050601f831160018114610e4b5760008415610e39578287015190505b610e438582610dc5565b865550610eab565b601f198416610e5986610c35565b60005b82811015610e8157848901518255600182019150602085019450602081019050610e5c565b86831015610e9e5784890151610e9a601f891682610da7565b8355505b6001600288020188555050505b505050505050565b6000602082019050610ec860008301846109fd565b9291505056fea26469706673582212206677082b8cae7f4c8fb68936867d0c9d71cc46134ef5f5e55cb0829eabfabf2464736f6c63430008180033",
 }
 
 // SertifikatDonasiABI is the input ABI used to generate the binding from.
 // Deprecated: Use SertifikatDonasiMetaData.ABI instead.
 var SertifikatDonasiABI = SertifikatDonasiMetaData.ABI
 
+// SertifikatDonasiBin is the compiled bytecode used for deploying new contracts.
+// Deprecated: Use SertifikatDonasiMetaData.Bin instead.
+var SertifikatDonasiBin = SertifikatDonasiMetaData.Bin
+
+// DeploySertifikatDonasi deploys a new Ethereum contract, binding an instance of SertifikatDonasi to it.
+func DeploySertifikatDonasi(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *SertifikatDonasi, error) {
+	parsed, err := SertifikatDonasiMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(SertifikatDonasiBin), backend)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &SertifikatDonasi{SertifikatDonasiCaller: SertifikatDonasiCaller{contract: contract}, SertifikatDonasiTransactor: SertifikatDonasiTransactor{contract: contract}, SertifikatDonasiFilterer: SertifikatDonasiFilterer{contract: contract}}, nil
+}
+
 // SertifikatDonasi is an auto generated Go binding around an Ethereum contract.
 type SertifikatDonasi struct {
 	SertifikatDonasiCaller     // Read-only binding to the contract
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// AuditLog hanya ditambah, tidak pernah diubah atau dihapus. Hash dihitung dari PrevHash dan
+// isi baris sehingga rantai dapat diverifikasi ulang.
+type AuditLog struct {
+	Id         int64     `json:"id"`
+	ActorId    *int64    `json:"actor_id"`
+	ActorRole  string    `json:"actor_role"`
+	Action     string    `json:"action"`
+	EntityType string    `json:"entity_type"`
+	EntityId   *int64    `json:"entity_id"`
+	Changes    string    `json:"changes" gorm:"type:jsonb"` // {"field": {"before": ..., "after": ...}}
+	IP         string    `json:"ip" gorm:"column:ip"`
+	RequestId  string    `json:"request_id"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	PrevHash   string    `json:"prev_hash"`
+	Hash       string    `json:"hash"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (AuditLog) TableName() string {
+	return "public.audit_logs"
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+type BloodBag struct {
+	Id          int64      `json:"id"`
+	BagNumber   string     `json:"bag_number"`
+	HospitalId  int64      `json:"hospital_id"`
+	Hospital    Hospital   `json:"hospital" gorm:"foreignKey:HospitalId;references:Id"`
+	DonationId  *int64     `json:"donation_id"`
+	RequestId   *int64     `json:"request_id"`
+	BloodType   string     `json:"blood_type"` // e.g., "A+", "O-", etc.
+	Component   string     `json:"component"`  // 'whole_blood', 'red_cells', 'plasma', 'platelets'
+	VolumeMl    int64      `json:"volume_ml"`
+	CollectedAt time.Time  `json:"collected_at"`
+	ExpiryDate  time.Time  `json:"expiry_date"`
+	Status      string     `json:"status"` // 'available', 'reserved', 'used', 'expired', 'discarded'
+	UsedAt      *time.Time `json:"used_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (BloodBag) TableName() string {
+	return "public.blood_bags"
+}
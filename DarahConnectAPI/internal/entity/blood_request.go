@@ -23,8 +23,13 @@ type BloodRequest struct {
 	EventType      string    `json:"event_type"`
 	UrlFile        string    `json:"url_file"`
 	PublicId       string    `json:"public_id"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	Distance       *float64  `json:"distance_km,omitempty" gorm:"->;-:migration"` // jarak rumah sakit, hanya terisi pada pencarian berdasarkan lokasi
+	// Target donasi uang; 0 berarti campaign tidak menggalang dana
+	GoalAmount       int64                `json:"goal_amount"`
+	DonationDeadline *time.Time           `json:"donation_deadline"`
+	Fundraising      *FundraisingProgress `json:"fundraising,omitempty" gorm:"-"` // hanya terisi pada detail campaign
+	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
 }
 
 func (BloodRequest) TableName() string {
@@ -0,0 +1,51 @@
+package entity
+
+import "time"
+
+// Status klaim sertifikat escrow
+const (
+	ClaimStatusPending   = "pending"   // mint ulang ke wallet pendonor sedang diproses
+	ClaimStatusCompleted = "completed" // sertifikat sudah tercatat atas nama wallet pendonor
+	ClaimStatusFailed    = "failed"
+)
+
+// CertificateClaim mencatat perpindahan sertifikat dari alamat escrow ke wallet pendonor.
+// Kontrak tidak mendukung transfer sehingga sertifikat di-mint ulang, catatan escrow tetap ada di blockchain.
+type CertificateClaim struct {
+	Id                int64      `json:"id"`
+	CertificateId     int64      `json:"certificate_id"`
+	UserId            int64      `json:"user_id"`
+	FromAddress       string     `json:"from_address"`
+	ToAddress         string     `json:"to_address"`
+	PreviousOnChainId *int64     `json:"previous_onchain_id" gorm:"column:previous_onchain_id"`
+	PreviousTxHash    string     `json:"previous_tx_hash"`
+	OnChainId         *int64     `json:"onchain_id" gorm:"column:onchain_id"`
+	TxHash            string     `json:"tx_hash"`
+	Status            string     `json:"status"`
+	Error             string     `json:"error"`
+	CompletedAt       *time.Time `json:"completed_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+
+	// PreviousState kosong untuk klaim yang dibuat sebelum kolomnya ada
+	PreviousState *CertificateEscrowState `json:"-" gorm:"serializer:json"`
+}
+
+func (CertificateClaim) TableName() string {
+	return "public.certificate_claims"
+}
+
+// CertificateEscrowState adalah data transaksi mint escrow saat sertifikat diklaim. Catatan escrow
+// tetap berlaku di blockchain, sehingga sertifikat dikembalikan ke data ini jika mint ulang gagal.
+type CertificateEscrowState struct {
+	IssuerAddress    string     `json:"issuer_address"`
+	TxNonce          *int64     `json:"tx_nonce"`
+	GasFeeCap        string     `json:"gas_fee_cap"`
+	GasTipCap        string     `json:"gas_tip_cap"`
+	ReplacedTxHashes []string   `json:"replaced_tx_hashes"`
+	SubmitAttempts   int        `json:"submit_attempts"`
+	BlockNumber      *int64     `json:"block_number"`
+	Confirmations    int64      `json:"confirmations"`
+	SubmittedAt      *time.Time `json:"submitted_at"`
+	ConfirmedAt      *time.Time `json:"confirmed_at"`
+}
@@ -2,16 +2,40 @@ package entity
 
 import "time"
 
+// Status sertifikat di blockchain
+const (
+	ChainStatusQueued    = "queued"    // menunggu dikirim oleh outbox dispatcher
+	ChainStatusSubmitted = "submitted" // transaksi sudah dikirim, menunggu receipt dan konfirmasi
+	ChainStatusConfirmed = "confirmed" // transaksi berhasil dengan jumlah konfirmasi yang cukup
+	ChainStatusFailed    = "failed"    // transaksi reverted atau tidak pernah ditambang
+)
+
 type Certificate struct {
-	Id                int64     `json:"id"`
-	UserId            int64     `json:"user_id"`
-	User              User      `json:"user" gorm:"foreignKey:UserId;references:Id"`
-	DonationId        int64     `json:"donation_id"` // Reference to the blood donation
-	Donation          BloodDonation  `json:"blood_donation" gorm:"foreignKey:DonationId;references:Id"`
-	CertificateNumber string    `json:"certificate_number"` // Unique identifier for the certificate
-	DigitalSignature  string    `json:"digital_signature"` // Digital signature of the certificate
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	Id                int64         `json:"id"`
+	UserId            int64         `json:"user_id"`
+	User              User          `json:"user" gorm:"foreignKey:UserId;references:Id"`
+	DonationId        int64         `json:"donation_id"` // Reference to the blood donation
+	Donation          BloodDonation `json:"blood_donation" gorm:"foreignKey:DonationId;references:Id"`
+	CertificateNumber string        `json:"certificate_number"` // Unique identifier for the certificate
+	DigitalSignature  string        `json:"digital_signature"`  // Transaction hash mintSertifikat yang terakhir dikirim
+	ChainStatus       string        `json:"chain_status"`       // queued, submitted, confirmed, failed
+	TxNonce           *int64        `json:"tx_nonce"`
+	GasFeeCap         string        `json:"gas_fee_cap"` // wei
+	GasTipCap         string        `json:"gas_tip_cap"` // wei
+	RawTx             string        `json:"-"`           // transaksi bertanda tangan (hex) untuk dikirim ulang
+	ReplacedTxHashes  []string      `json:"replaced_tx_hashes" gorm:"serializer:json"`
+	SubmitAttempts    int           `json:"submit_attempts"`
+	BlockNumber       *int64        `json:"block_number"`
+	OnChainId         *int64        `json:"onchain_id" gorm:"column:onchain_id"` // id sertifikat di kontrak
+	RecipientAddress  string        `json:"recipient_address"`                   // pendonor di catatan kontrak
+	Custodial         bool          `json:"custodial"`                           // di-mint ke escrow dan belum diklaim
+	IssuerAddress     string        `json:"issuer_address"`                      // penerbit yang menandatangani mint
+	Confirmations     int64         `json:"confirmations"`
+	ChainError        string        `json:"chain_error"`
+	SubmittedAt       *time.Time    `json:"submitted_at"`
+	ConfirmedAt       *time.Time    `json:"confirmed_at"`
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
 }
 
 func (Certificate) TableName() string {
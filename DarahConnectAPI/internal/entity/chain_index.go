@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// ChainSyncState menyimpan blok terakhir yang sudah diproses oleh sebuah indexer
+type ChainSyncState struct {
+	Name      string    `json:"name" gorm:"primaryKey"`
+	LastBlock int64     `json:"last_block"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (ChainSyncState) TableName() string {
+	return "public.chain_sync_states"
+}
+
+// ChainCertificateEvent adalah event SertifikatDibuat yang sudah diindeks
+type ChainCertificateEvent struct {
+	Id                int64     `json:"id"`
+	OnChainId         int64     `json:"onchain_id" gorm:"column:onchain_id"`
+	DonorAddress      string    `json:"donor_address"`
+	CertificateNumber string    `json:"certificate_number"`
+	CertificateId     *int64    `json:"certificate_id"` // nil jika nomor sertifikat tidak ada di database
+	BlockNumber       int64     `json:"block_number"`
+	BlockHash         string    `json:"block_hash"`
+	TxHash            string    `json:"tx_hash"`
+	LogIndex          int       `json:"log_index"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func (ChainCertificateEvent) TableName() string {
+	return "public.chain_certificate_events"
+}
@@ -8,9 +8,14 @@ type Donation struct {
 	Id        int64     `gorm:"primaryKey" json:"id"`
 	UserId    int64     `json:"user_id"`
 	User      User      `json:"user" gorm:"foreignKey:UserId;references:Id"`
-	OrderId   int64     `json:"order_id"`
+	OrderId   string    `json:"order_id"` // order id yang dikirim ke gateway pembayaran
 	Amount    int64     `json:"amount"`
+	RefundedAmount int64 `json:"refunded_amount"` // total refund yang dilaporkan gateway
 	Status    string    `json:"status"`
+	Gateway   string    `json:"gateway"`
+	// Target penggalangan dana; keduanya kosong untuk donasi umum
+	BloodRequestId *int64 `json:"blood_request_id"`
+	FundId         *int64 `json:"fund_id"`
 	TransactionTime time.Time `json:"transaction_time"`
 	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
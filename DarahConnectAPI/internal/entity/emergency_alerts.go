@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+type EmergencyAlert struct {
+	Id           int64        `json:"id"`
+	RequestId    int64        `json:"request_id"`
+	BloodRequest BloodRequest `json:"blood_request" gorm:"foreignKey:RequestId;references:Id"`
+	UserId       int64        `json:"user_id"`
+	User         User         `json:"user" gorm:"foreignKey:UserId;references:Id"`
+	DistanceKm   float64      `json:"distance_km"`
+	// EmailQueuedAt adalah waktu email dimasukkan ke outbox, bukan waktu email terkirim
+	EmailQueuedAt *time.Time `json:"email_queued_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (EmergencyAlert) TableName() string {
+	return "public.emergency_alerts"
+}
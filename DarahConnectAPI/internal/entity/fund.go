@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+// Fund adalah penggalangan dana umum yang tidak terikat ke campaign
+type Fund struct {
+	Id          int64                `json:"id"`
+	Title       string               `json:"title"`
+	Description string               `json:"description"`
+	GoalAmount  int64                `json:"goal_amount"`
+	Deadline    *time.Time           `json:"deadline"`
+	Status      string               `json:"status"` // active, closed
+	CreatedBy   int64                `json:"created_by"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+	Fundraising *FundraisingProgress `json:"fundraising,omitempty" gorm:"-"`
+}
+
+func (Fund) TableName() string {
+	return "public.funds"
+}
+
+// FundraisingProgress adalah perolehan donasi uang yang sudah dibayar untuk satu target
+type FundraisingProgress struct {
+	GoalAmount      int64      `json:"goal_amount"`
+	CollectedAmount int64      `json:"collected_amount"`
+	DonorCount      int64      `json:"donor_count"`
+	Percentage      float64    `json:"percentage"`
+	Deadline        *time.Time `json:"deadline"`
+	Open            bool       `json:"open"` // masih menerima donasi
+}
@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+type HealthScreening struct {
+	Id                      int64             `json:"id"`
+	HealthPassportId        int64             `json:"health_passport_id"`
+	UserId                  int64             `json:"user_id"`
+	QuestionnaireVersion    int               `json:"questionnaire_version"`
+	RecentIllness           bool              `json:"recent_illness"`
+	TakingMedication        bool              `json:"taking_medication"`
+	MedicationNotes         string            `json:"medication_notes"`
+	MalariaTravelReturnDate *time.Time        `json:"malaria_travel_return_date"`
+	TattooDate              *time.Time        `json:"tattoo_date"`
+	Hemoglobin              float64           `json:"hemoglobin"`
+	WeightKg                float64           `json:"weight_kg"`
+	Systolic                int               `json:"systolic"`
+	Diastolic               int               `json:"diastolic"`
+	Outcome                 string            `json:"outcome"` // active, deferred, review
+	DeferredUntil           *time.Time        `json:"deferred_until"`
+	Reasons                 []ScreeningReason `json:"reasons" gorm:"serializer:json"`
+	ReviewedBy              *int64            `json:"reviewed_by"`
+	ReviewedAt              *time.Time        `json:"reviewed_at"`
+	ReviewDecision          string            `json:"review_decision"`
+	ReviewNote              string            `json:"review_note"`
+	CreatedAt               time.Time         `json:"created_at"`
+	UpdatedAt               time.Time         `json:"updated_at"`
+}
+
+// ScreeningReason adalah alasan penundaan atau peninjauan dari hasil skrining
+type ScreeningReason struct {
+	Code    string     `json:"code"`
+	Message string     `json:"message"`
+	Until   *time.Time `json:"until,omitempty"`
+}
+
+func (HealthScreening) TableName() string {
+	return "public.health_screenings"
+}
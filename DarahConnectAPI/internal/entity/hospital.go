@@ -10,6 +10,7 @@ type Hospital struct {
 	Province  string    `json:"province"`
 	Latitude  float64   `json:"latitude"`
 	Longitude float64   `json:"longitude"`
+	Distance  *float64  `json:"distance_km,omitempty" gorm:"->;-:migration"` // hanya terisi pada pencarian berdasarkan lokasi
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
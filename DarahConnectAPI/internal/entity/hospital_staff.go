@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// HospitalStaff menghubungkan pengguna dengan rumah sakit tempatnya bertugas
+type HospitalStaff struct {
+	Id         int64     `json:"id"`
+	UserId     int64     `json:"user_id"`
+	User       User      `json:"user" gorm:"foreignKey:UserId;references:Id"`
+	HospitalId int64     `json:"hospital_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (HospitalStaff) TableName() string {
+	return "public.hospital_staff"
+}
@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+type OutboxMessage struct {
+	Id            int64      `json:"id"`
+	EventType     string     `json:"event_type"` // certificate.mint, email.send, notification.create
+	AggregateType string     `json:"aggregate_type"`
+	AggregateId   int64      `json:"aggregate_id"`
+	Payload       string     `json:"payload" gorm:"type:jsonb"`
+	Status        string     `json:"status"` // pending, processing, done, failed
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error"`
+	ProcessedAt   *time.Time `json:"processed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (OutboxMessage) TableName() string {
+	return "public.outbox_messages"
+}
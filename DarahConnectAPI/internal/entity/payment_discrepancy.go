@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// PaymentDiscrepancy adalah donasi pending yang statusnya berbeda dengan gateway saat direkonsiliasi
+type PaymentDiscrepancy struct {
+	Id            int64     `json:"id"`
+	DonationId    int64     `json:"donation_id"`
+	OrderId       string    `json:"order_id"`
+	Gateway       string    `json:"gateway"`
+	LocalStatus   string    `json:"local_status"`
+	GatewayStatus string    `json:"gateway_status"` // kosong jika order tidak ditemukan di gateway
+	LocalAmount   int64     `json:"local_amount"`
+	GatewayAmount string    `json:"gateway_amount"`
+	Action        string    `json:"action"` // applied, ignored, rejected, expired
+	Message       string    `json:"message"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (PaymentDiscrepancy) TableName() string {
+	return "public.payment_discrepancies"
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// PaymentNotification adalah notifikasi gateway pembayaran mentah beserta hasil pemrosesannya
+type PaymentNotification struct {
+	Id                int64      `json:"id"`
+	Gateway           string     `json:"gateway"`
+	OrderId           string     `json:"order_id"`
+	TransactionId     string     `json:"transaction_id"`
+	TransactionStatus string     `json:"transaction_status"`
+	FraudStatus       string     `json:"fraud_status"`
+	StatusCode        string     `json:"status_code"`
+	GrossAmount       string     `json:"gross_amount"`
+	SignatureValid    bool       `json:"signature_valid"`
+	Payload           string     `json:"payload" gorm:"type:jsonb"`
+	Result            string     `json:"result"` // received, applied, duplicate, ignored, rejected, failed
+	Message           string     `json:"message"`
+	ProcessedAt       *time.Time `json:"processed_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+func (PaymentNotification) TableName() string {
+	return "public.payment_notifications"
+}
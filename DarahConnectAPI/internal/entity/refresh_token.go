@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// RefreshToken adalah refresh token yang sudah diterbitkan. Token asli hanya dikirim ke klien,
+// database menyimpan hash-nya.
+type RefreshToken struct {
+	Id           int64      `json:"id"`
+	UserId       int64      `json:"user_id"`
+	FamilyId     string     `json:"family_id"` // sama untuk semua token hasil rotasi dari satu login
+	TokenHash    string     `json:"-"`
+	AccessJti    string     `json:"-"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	ReplacedById *int64     `json:"replaced_by_id"` // terisi jika token sudah dirotasi
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (RefreshToken) TableName() string {
+	return "public.refresh_tokens"
+}
@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// Role dikenali lewat nama yang disimpan di users.role dan klaim role JWT
+type Role struct {
+	Id          int64        `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	IsSystem    bool         `json:"is_system"`
+	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions;joinForeignKey:RoleId;joinReferences:PermissionId"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+func (Role) TableName() string {
+	return "public.roles"
+}
+
+type Permission struct {
+	Id          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (Permission) TableName() string {
+	return "public.permissions"
+}
+
+type RolePermission struct {
+	RoleId       int64 `json:"role_id"`
+	PermissionId int64 `json:"permission_id"`
+}
+
+func (RolePermission) TableName() string {
+	return "public.role_permissions"
+}
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// SigningKey adalah kunci penandatangan JWT beserta masa berlakunya
+type SigningKey struct {
+	Id          int64      `json:"id"`
+	Kid         string     `json:"kid"`
+	Algorithm   string     `json:"algorithm"`
+	PrivateKey  string     `json:"-"` // PKCS#8 terenkripsi AES-GCM
+	ActivatesAt time.Time  `json:"activates_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (SigningKey) TableName() string {
+	return "public.signing_keys"
+}
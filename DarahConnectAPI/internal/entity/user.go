@@ -19,6 +19,8 @@ type User struct {
 	PublicId           string    `json:"public_id"`
 	UrlFile            string    `json:"url_file"`
 	WalletAddress      string    `json:"wallet_address"`
+	Latitude           *float64  `json:"latitude"`
+	Longitude          *float64  `json:"longitude"`
 	TokenExpiresAt     time.Time `json:"token_expires_at"`
 	CreatedAt          time.Time `json:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at"`
@@ -0,0 +1,52 @@
+package entity
+
+import "time"
+
+// Tujuan token mfa pending
+const (
+	MFAChallengeVerify = "verify" // pengguna sudah mendaftarkan TOTP
+	MFAChallengeEnroll = "enroll" // role wajib MFA tetapi TOTP belum didaftarkan
+)
+
+// UserMFA adalah secret TOTP milik pengguna
+type UserMFA struct {
+	Id           int64      `json:"id"`
+	UserId       int64      `json:"user_id"`
+	Secret       string     `json:"-"` // base32, terenkripsi
+	EnabledAt    *time.Time `json:"enabled_at"`
+	LastUsedStep int64      `json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (UserMFA) TableName() string {
+	return "public.user_mfa"
+}
+
+type MFARecoveryCode struct {
+	Id        int64      `json:"id"`
+	UserId    int64      `json:"user_id"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (MFARecoveryCode) TableName() string {
+	return "public.mfa_recovery_codes"
+}
+
+// MFAChallenge adalah token mfa pending yang menunggu kode TOTP
+type MFAChallenge struct {
+	Id        int64      `json:"id"`
+	UserId    int64      `json:"user_id"`
+	TokenHash string     `json:"-"`
+	Purpose   string     `json:"purpose"`
+	Attempts  int        `json:"attempts"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (MFAChallenge) TableName() string {
+	return "public.mfa_challenges"
+}
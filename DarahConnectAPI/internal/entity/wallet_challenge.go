@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+// Tujuan tantangan tanda tangan wallet
+const (
+	WalletChallengeLink  = "link"
+	WalletChallengeLogin = "login"
+)
+
+// WalletChallenge adalah pesan bernonce yang harus ditandatangani wallet untuk membuktikan
+// kepemilikannya. Setiap tantangan hanya bisa dipakai sekali.
+type WalletChallenge struct {
+	Id            int64      `json:"id"`
+	UserId        *int64     `json:"user_id"`
+	WalletAddress string     `json:"wallet_address"`
+	Purpose       string     `json:"purpose"`
+	Nonce         string     `json:"nonce"`
+	Message       string     `json:"message"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	UsedAt        *time.Time `json:"used_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func (WalletChallenge) TableName() string {
+	return "public.wallet_challenges"
+}
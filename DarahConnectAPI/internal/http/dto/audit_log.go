@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+type GetAllAuditLogRequest struct {
+	Page       int64      `query:"page"`
+	Limit      int64      `query:"limit"`
+	ActorId    int64      `query:"actor_id"`
+	Action     string     `query:"action"`
+	EntityType string     `query:"entity_type"`
+	EntityId   int64      `query:"entity_id"`
+	RequestId  string     `query:"request_id"`
+	From       *time.Time `query:"from"`
+	To         *time.Time `query:"to"`
+	Order      string     `query:"order" validate:"omitempty,oneof=asc desc"`
+}
+
+// AuditLogVerifyResponse adalah hasil pemeriksaan rantai hash audit log
+type AuditLogVerifyResponse struct {
+	Valid    bool   `json:"valid"`
+	Checked  int64  `json:"checked"`
+	BrokenAt *int64 `json:"broken_at,omitempty"` // id baris pertama yang hash-nya tidak cocok
+	LastHash string `json:"last_hash"`
+}
@@ -0,0 +1,51 @@
+package dto
+
+import "time"
+
+type BloodBagCreateRequest struct {
+	HospitalId  int64     `json:"hospital_id" form:"hospital_id" validate:"required"`
+	BloodType   string    `json:"blood_type" form:"blood_type" validate:"required"` // e.g., "A+", "O-", etc.
+	Component   string    `json:"component" form:"component" validate:"required,oneof=whole_blood red_cells plasma platelets"`
+	VolumeMl    int64     `json:"volume_ml" form:"volume_ml"`
+	CollectedAt time.Time `json:"collected_at" form:"collected_at"`
+	ExpiryDate  time.Time `json:"expiry_date" form:"expiry_date"`
+}
+
+type BloodBagUpdateRequest struct {
+	Id         int64     `param:"id" validate:"required"`
+	Status     string    `json:"status" form:"status" validate:"omitempty,oneof=available reserved used expired discarded"`
+	VolumeMl   int64     `json:"volume_ml" form:"volume_ml"`
+	ExpiryDate time.Time `json:"expiry_date" form:"expiry_date"`
+}
+
+type BloodBagByIdRequest struct {
+	Id int64 `param:"id" validate:"required"`
+}
+
+type GetAllBloodBagRequest struct {
+	Page       int64  `query:"page"`
+	Limit      int64  `query:"limit"`
+	Sort       string `query:"sort"`
+	Order      string `query:"order"`
+	HospitalId int64  `query:"hospital_id"`
+	BloodType  string `query:"blood_type"`
+	Component  string `query:"component"`
+	Status     string `query:"status"`
+}
+
+type GetBloodStockRequest struct {
+	HospitalId int64  `query:"hospital_id"`
+	BloodType  string `query:"blood_type"`
+	Component  string `query:"component"`
+}
+
+// BloodStockResponse adalah ringkasan stok kantong darah yang tersedia per rumah sakit
+type BloodStockResponse struct {
+	HospitalId    int64      `json:"hospital_id"`
+	HospitalName  string     `json:"hospital_name"`
+	BloodType     string     `json:"blood_type"`
+	Component     string     `json:"component"`
+	Units         int64      `json:"units"`
+	TotalVolumeMl int64      `json:"total_volume_ml"`
+	NearestExpiry *time.Time `json:"nearest_expiry"`
+}
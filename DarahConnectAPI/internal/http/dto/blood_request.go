@@ -28,6 +28,9 @@ type CampaignCreateRequest struct {
 	SlotsAvailable int64                 `json:"slots_available" form:"slots_available"`
 	SlotsBooked    int64                 `json:"slots_booked" form:"slots_booked"`
 	Image          *multipart.FileHeader `json:"image" form:"image"`
+	// Target donasi uang campaign; kosong berarti campaign tidak menggalang dana
+	GoalAmount       int64      `json:"goal_amount" form:"goal_amount" validate:"omitempty,min=0"`
+	DonationDeadline *time.Time `json:"donation_deadline" form:"donation_deadline"`
 }
 
 type BloodRequestUpdateRequest struct {
@@ -44,14 +47,16 @@ type BloodRequestUpdateRequest struct {
 }
 
 type CampaignUpdateRequest struct {
-	Id             int64                 `param:"id" validate:"required"`
-	EventName      string                `json:"event_name" form:"event_name"`
-	EventDate      time.Time             `json:"event_date" form:"event_date"`
-	StartTime      time.Time             `json:"start_time" form:"start_time"`
-	EndTime        time.Time             `json:"end_time" form:"end_time"`
-	Image          *multipart.FileHeader `json:"image" form:"image"`
-	SlotsAvailable int64                 `json:"slots_available" form:"slots_available"`
-	SlotsBooked    int64                 `json:"slots_booked" form:"slots_booked"`
+	Id               int64                 `param:"id" validate:"required"`
+	EventName        string                `json:"event_name" form:"event_name"`
+	EventDate        time.Time             `json:"event_date" form:"event_date"`
+	StartTime        time.Time             `json:"start_time" form:"start_time"`
+	EndTime          time.Time             `json:"end_time" form:"end_time"`
+	Image            *multipart.FileHeader `json:"image" form:"image"`
+	SlotsAvailable   int64                 `json:"slots_available" form:"slots_available"`
+	SlotsBooked      int64                 `json:"slots_booked" form:"slots_booked"`
+	GoalAmount       *int64                `json:"goal_amount" form:"goal_amount" validate:"omitempty,min=0"`
+	DonationDeadline *time.Time            `json:"donation_deadline" form:"donation_deadline"`
 }
 
 type BloodRequestByIdRequest struct {
@@ -66,7 +71,6 @@ type BloodRequestByHospitalIdRequest struct {
 	HospitalId int64 `param:"hospital_id" validate:"required"`
 }
 
-
 type GetAllBloodRequestRequest struct {
 	Page         int64  `query:"page"`
 	Limit        int64  `query:"limit"`
@@ -81,4 +85,10 @@ type GetAllBloodRequestRequest struct {
 	UrgencyLevel string `query:"urgency_level"`
 	BloodType    string `query:"blood_type"`
 	EventType    string `query:"event_type"`
-}
\ No newline at end of file
+	// Pencarian berdasarkan jarak rumah sakit dari titik lat/lng, radius_km opsional
+	Lat      *float64 `query:"lat" validate:"required_with=Lng,omitempty,min=-90,max=90"`
+	Lng      *float64 `query:"lng" validate:"required_with=Lat,omitempty,min=-180,max=180"`
+	RadiusKm float64  `query:"radius_km" validate:"min=0"`
+	// BloodTypes diisi oleh service dari hasil pencocokan golongan darah, bukan dari query
+	BloodTypes []string `query:"-" json:"-"`
+}
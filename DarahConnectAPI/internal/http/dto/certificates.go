@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type CertificateGetByIdRequest struct {
 	Id int64 `param:"id" validate:"required"`
 }
@@ -18,6 +20,56 @@ type GetAllCertificateRequest struct {
 	Search     string `query:"search"`
 	Sort       string `query:"sort"`
 	Order      string `query:"order"`
-	UserId     string  `query:"user_id"`
-	DonationId string  `query:"donation_id"`
+	UserId     string `query:"user_id"`
+	DonationId string `query:"donation_id"`
+}
+
+type CertificateVerifyRequest struct {
+	Number string `param:"number" validate:"required,numeric"`
+}
+
+// CertificateRecord adalah isi sertifikat yang dibandingkan antara database dan kontrak
+type CertificateRecord struct {
+	CertificateNumber string     `json:"certificate_number"`
+	DonorAddress      string     `json:"donor_address"`
+	DonorName         string     `json:"donor_name"`
+	DonorAlamat       string     `json:"donor_alamat"`
+	Issuer            string     `json:"issuer"` // alamat wallet yang menerbitkan sertifikat
+	Penerbit          string     `json:"penerbit,omitempty"`
+	DonationDate      *time.Time `json:"donation_date"`
+}
+
+// CertificateVerificationResponse adalah hasil verifikasi publik sebuah sertifikat
+type CertificateVerificationResponse struct {
+	CertificateNumber string             `json:"certificate_number"`
+	Match             bool               `json:"match"`
+	Message           string             `json:"message"`
+	ChainStatus       string             `json:"chain_status"`
+	TxHash            string             `json:"tx_hash"`
+	OnChainId         *int64             `json:"onchain_id"`
+	Database          CertificateRecord  `json:"database"`
+	OnChain           *CertificateRecord `json:"on_chain"`
+	Mismatches        []string           `json:"mismatches"`
+}
+
+// ChainReconciliationItem adalah satu temuan rekonsiliasi antara event SertifikatDibuat dan tabel certificates
+type ChainReconciliationItem struct {
+	CertificateId       *int64 `json:"certificate_id"`
+	CertificateNumber   string `json:"certificate_number"`
+	OnChainId           *int64 `json:"onchain_id"`
+	TxHash              string `json:"tx_hash"`
+	BlockNumber         *int64 `json:"block_number"`
+	ChainStatus         string `json:"chain_status,omitempty"`
+	OnChainDonorAddress string `json:"onchain_donor_address,omitempty"`
+	DbDonorAddress      string `json:"db_donor_address,omitempty"`
+}
+
+// ChainReconciliationReport adalah laporan rekonsiliasi sertifikat untuk admin
+type ChainReconciliationReport struct {
+	LastIndexedBlock int64                     `json:"last_indexed_block"`
+	HeadBlock        uint64                    `json:"head_block"`
+	OnChainOnly      []ChainReconciliationItem `json:"onchain_only"` // event tanpa sertifikat di database
+	DbOnly           []ChainReconciliationItem `json:"db_only"`      // sertifikat tanpa event di blockchain
+	Mismatched       []ChainReconciliationItem `json:"mismatched"`   // alamat pendonor berbeda
+	Duplicates       []ChainReconciliationItem `json:"duplicates"`   // nomor sertifikat di-mint lebih dari sekali
 }
@@ -1,18 +1,16 @@
 package dto
 
-type DonationsCreate struct {
-	OrderID  string `json:"order_id" form:"order_id"`
-	Transaction_time string `json:"transaction_time" form:"transaction_time" validate:"required"`
-	Transaction_status string `json:"transaction_status" form:"transaction_status" validate:"required"`
-}
+import "time"
 
 type PaymentRequest struct {
-	OrderID  string `json:"order_id" form:"order_id"`
 	UserId 	int64 `json:"user_id" form:"user_id"`
-	Amount   int64 `json:"amount" form:"amount" validate:"required"`
+	Amount   int64 `json:"amount" form:"amount" validate:"required,min=1"`
 	Fullname string `json:"fullname" form:"fullname"`
 	Email    string `json:"email" form:"email"`
 	Phone    string `json:"phone" form:"phone"`
+	// Target penggalangan dana, paling banyak satu; kosong berarti donasi umum
+	BloodRequestId *int64 `json:"blood_request_id" form:"blood_request_id" validate:"omitempty,min=1,excluded_with=FundId"`
+	FundId         *int64 `json:"fund_id" form:"fund_id" validate:"omitempty,min=1"`
 }
 
 type GetAllDonation struct {
@@ -23,8 +21,33 @@ type GetAllDonation struct {
 	OrderId  string `query:"order_id"`
 	Order string `query:"order"`
 	Status string `query:"status"`
+	BloodRequestId int64 `query:"blood_request_id"`
+	FundId int64 `query:"fund_id"`
 }
 
 type GetByDonationId struct{
 	Id int64 `param:"Id" validate:"required"`
-}
\ No newline at end of file
+}
+
+// DonationRefundRequest meminta refund ke gateway; amount kosong berarti seluruh nominal donasi
+type DonationRefundRequest struct {
+	Id     int64  `param:"id" validate:"required"`
+	Amount int64  `json:"amount" validate:"omitempty,min=1"`
+	Reason string `json:"reason" validate:"required"`
+}
+
+type GetAllPaymentDiscrepancyRequest struct {
+	Page       int64      `query:"page"`
+	Limit      int64      `query:"limit"`
+	DonationId int64      `query:"donation_id"`
+	Action     string     `query:"action" validate:"omitempty,oneof=applied ignored rejected expired"`
+	From       *time.Time `query:"from"`
+	To         *time.Time `query:"to"`
+}
+
+// DonationStatementRequest meminta laporan donasi satu tahun milik pengguna yang login
+type DonationStatementRequest struct {
+	UserId int64  `json:"-"`
+	Year   int    `query:"year" validate:"required,min=2000,max=9999"`
+	Format string `query:"format" validate:"omitempty,oneof=pdf csv"`
+}
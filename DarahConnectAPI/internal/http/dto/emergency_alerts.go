@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+// EmergencyDonorFilter adalah kriteria pencarian pendonor untuk notifikasi darurat
+type EmergencyDonorFilter struct {
+	RequestId          int64
+	ExcludeUserId      int64
+	BloodTypes         []string
+	Lat                float64
+	Lng                float64
+	RadiusKm           float64
+	LastDonationBefore time.Time // pendonor terakhir donor sebelum waktu ini
+	AlertedSince       time.Time // awal jendela perhitungan batas notifikasi harian
+	DailyLimit         int
+	Limit              int
+}
+
+// EmergencyDonor adalah kandidat pendonor beserta jaraknya ke rumah sakit
+type EmergencyDonor struct {
+	Id         int64     `json:"id"`
+	Name       string    `json:"name"`
+	Email      string    `json:"email"`
+	Gender     string    `json:"gender"`
+	BirthDate  time.Time `json:"birth_date"`
+	BloodType  string    `json:"blood_type"`
+	DistanceKm float64   `json:"distance_km"`
+}
@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+type FundCreateRequest struct {
+	CreatedBy   int64      `json:"-"`
+	Title       string     `json:"title" validate:"required,max=150"`
+	Description string     `json:"description"`
+	GoalAmount  int64      `json:"goal_amount" validate:"required,min=1"`
+	Deadline    *time.Time `json:"deadline"`
+}
+
+type FundUpdateRequest struct {
+	Id          int64      `param:"id" validate:"required"`
+	Title       string     `json:"title" validate:"omitempty,max=150"`
+	Description string     `json:"description"`
+	GoalAmount  int64      `json:"goal_amount" validate:"omitempty,min=1"`
+	Deadline    *time.Time `json:"deadline"`
+	Status      string     `json:"status" validate:"omitempty,oneof=active closed"`
+}
+
+type FundByIdRequest struct {
+	Id int64 `param:"id" validate:"required"`
+}
+
+type GetAllFundRequest struct {
+	Page   int64  `query:"page"`
+	Limit  int64  `query:"limit"`
+	Search string `query:"search"`
+	Status string `query:"status" validate:"omitempty,oneof=active closed"`
+}
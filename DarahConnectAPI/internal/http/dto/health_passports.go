@@ -1,8 +1,13 @@
 package dto
 
+import "time"
+
 type  HealthPassportUpdateRequest struct {
 	Id             int64     `param:"id" validate:"required"`
-	Status         string    `json:"status" form:"status"`          //'Active', 'Expired', 'Suspended'
+	Status         string    `json:"status" form:"status" validate:"required,oneof=active deferred expired suspended"`
+	DeferredUntil  *time.Time `json:"deferred_until" form:"deferred_until" validate:"required_if=Status deferred"`
+	Note           string    `json:"note" form:"note"`
+	ReviewerId     int64     `json:"-"`
 }
 
 type GetAllHealthPassportRequest struct {
@@ -21,3 +26,18 @@ type HealthPassportByIdRequest struct {
 type HealthPassportByUserIdRequest struct {
 	UserId int64 `param:"user_id" validate:"required"`
 }
+
+// HealthScreeningRequest adalah jawaban kuesioner skrining kesehatan dari pendonor
+type HealthScreeningRequest struct {
+	UserId                  int64      `json:"-"`
+	QuestionnaireVersion    int        `json:"questionnaire_version" form:"questionnaire_version" validate:"required"`
+	RecentIllness           bool       `json:"recent_illness" form:"recent_illness"`
+	TakingMedication        bool       `json:"taking_medication" form:"taking_medication"`
+	MedicationNotes         string     `json:"medication_notes" form:"medication_notes"`
+	MalariaTravelReturnDate *time.Time `json:"malaria_travel_return_date" form:"malaria_travel_return_date"`
+	TattooDate              *time.Time `json:"tattoo_date" form:"tattoo_date"`
+	Hemoglobin              float64    `json:"hemoglobin" form:"hemoglobin" validate:"required,gt=0,lt=30"`
+	WeightKg                float64    `json:"weight_kg" form:"weight_kg" validate:"required,gt=0,lt=400"`
+	Systolic                int        `json:"systolic" form:"systolic" validate:"required,gt=0,lt=300"`
+	Diastolic               int        `json:"diastolic" form:"diastolic" validate:"required,gt=0,lt=200"`
+}
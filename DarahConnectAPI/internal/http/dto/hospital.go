@@ -35,4 +35,18 @@ type GetAllHospitalRequest struct {
 	Order    string `query:"order"`
 	Province string `query:"province"`
 	City     string `query:"city"`
+	// Pencarian berdasarkan jarak dari titik lat/lng, radius_km opsional
+	Lat      *float64 `query:"lat" validate:"required_with=Lng,omitempty,min=-90,max=90"`
+	Lng      *float64 `query:"lng" validate:"required_with=Lat,omitempty,min=-180,max=180"`
+	RadiusKm float64  `query:"radius_km" validate:"min=0"`
+}
+
+type HospitalStaffRequest struct {
+	Id     int64 `param:"id" validate:"required"`
+	UserId int64 `json:"user_id" form:"user_id" validate:"required"`
+}
+
+type RemoveHospitalStaffRequest struct {
+	Id     int64 `param:"id" validate:"required"`
+	UserId int64 `param:"user_id" validate:"required"`
 }
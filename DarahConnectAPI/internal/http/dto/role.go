@@ -0,0 +1,24 @@
+package dto
+
+type RoleByIdRequest struct {
+	Id int64 `param:"id" validate:"required"`
+}
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" validate:"required,max=255"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" validate:"required,min=1,dive,required"`
+}
+
+type UpdateRoleRequest struct {
+	Id          int64    `param:"id" validate:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" validate:"required,min=1,dive,required"`
+}
+
+// AssignRoleRequest mengganti role pengguna; sesi pengguna tersebut dicabut agar token baru
+// membawa role yang baru
+type AssignRoleRequest struct {
+	Id   int64  `param:"id" validate:"required"`
+	Role string `json:"role" validate:"required"`
+}
@@ -31,6 +31,8 @@ type UpdateUserRequest struct {
 	BloodType string                `json:"blood_type" form:"blood_type"`
 	BirthDate string                `json:"birth_date" form:"birth_date"`
 	Address   string                `json:"address" form:"address"`
+	Latitude  *float64              `json:"latitude" form:"latitude" validate:"omitempty,min=-90,max=90"`
+	Longitude *float64              `json:"longitude" form:"longitude" validate:"omitempty,min=-180,max=180"`
 	Image     *multipart.FileHeader `json:"image" form:"image"`
 }
 
@@ -55,8 +57,89 @@ type GetUserByIdByUserRequest struct {
 	Email  string `json:"email" form:"email" validate:"required"`
 }
 
+// TokenResponse adalah access token berumur pendek beserta refresh token untuk memperbaruinya
+type TokenResponse struct {
+	Token            string    `json:"token"`
+	RefreshToken     string    `json:"refresh_token"`
+	ExpiresIn        int64     `json:"expires_in"` // umur access token dalam detik
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// LogoutRequest mengakhiri sesi saat ini, refresh token bersifat opsional
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// WalletChallengeRequest meminta pesan yang harus ditandatangani wallet
+type WalletChallengeRequest struct {
+	WalletAddress string `json:"wallet_address" validate:"required,eth_addr"`
+}
+
+type WalletChallengeResponse struct {
+	Nonce     string    `json:"nonce"`
+	Message   string    `json:"message"` // ditandatangani apa adanya dengan personal_sign
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// WalletAddressRequest menghubungkan wallet ke akun dengan tanda tangan atas pesan tantangan
 type WalletAddressRequest struct {
-	WalletAddress string `json:"wallet_address" validate:"required"`
+	WalletAddress string `json:"wallet_address" validate:"required,eth_addr"`
+	Nonce         string `json:"nonce" validate:"required"`
+	Signature     string `json:"signature" validate:"required"`
+}
+
+type WalletLoginRequest struct {
+	WalletAddress string `json:"wallet_address" validate:"required,eth_addr"`
+	Nonce         string `json:"nonce" validate:"required"`
+	Signature     string `json:"signature" validate:"required"`
+}
+
+// MFAChallengeResponse dikirim sebagai pengganti token saat login membutuhkan kode TOTP
+type MFAChallengeResponse struct {
+	MFARequired        bool      `json:"mfa_required"`
+	EnrollmentRequired bool      `json:"enrollment_required"` // role wajib MFA tetapi TOTP belum didaftarkan
+	MFAToken           string    `json:"mfa_token"`
+	ExpiresAt          time.Time `json:"expires_at"`
+}
+
+type MFAEnrollRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+}
+
+type MFAEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+	QRCode          string `json:"qr_code"` // data URI PNG dari provisioning URI
+}
+
+// MFALoginRequest menukar token mfa pending dengan JWT memakai kode TOTP atau kode pemulihan
+type MFALoginRequest struct {
+	MFAToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code" validate:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recovery_code" validate:"required_without=Code"`
+}
+
+type MFALoginResponse struct {
+	*TokenResponse
+	RecoveryCodes []string `json:"recovery_codes,omitempty"` // hanya dikirim saat pendaftaran TOTP selesai
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type MFAStatusResponse struct {
+	Enabled                bool  `json:"enabled"`
+	Enforced               bool  `json:"enforced"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
 }
 
 type ResetPasswordRequest struct {
@@ -84,4 +167,9 @@ type GetAllUserRequest struct {
 	Sort      string `query:"sort"`
 	Order     string `query:"order"`
 	BloodType string `query:"blood_type"`
+	Role      string `query:"role"`
+	// BloodTypes diisi oleh service dari hasil pencocokan golongan darah, bukan dari query
+	BloodTypes []string `query:"-" json:"-"`
+	// ExcludeRole diisi oleh service untuk menyembunyikan satu role, bukan dari query
+	ExcludeRole string `query:"-" json:"-"`
 }
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/response"
+)
+
+type AuditLogHandler struct {
+	auditLogService service.AuditLogService
+}
+
+func NewAuditLogHandler(auditLogService service.AuditLogService) AuditLogHandler {
+	return AuditLogHandler{auditLogService}
+}
+
+func (h *AuditLogHandler) GetAll(ctx echo.Context) error {
+	var req dto.GetAllAuditLogRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	auditLogs, total, err := h.auditLogService.GetAll(ctx.Request().Context(), req)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponseWithPagi("berhasil menampilkan audit log", auditLogs, req.Page, req.Limit, total))
+}
+
+// Verify menghitung ulang rantai hash untuk mendeteksi baris audit log yang diubah atau dihapus
+func (h *AuditLogHandler) Verify(ctx echo.Context) error {
+	result, err := h.auditLogService.Verify(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	if !result.Valid {
+		return ctx.JSON(http.StatusOK, response.SuccessResponse("rantai hash audit log rusak", result))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("rantai hash audit log valid", result))
+}
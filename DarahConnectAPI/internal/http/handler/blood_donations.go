@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/rbac"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/response"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/token"
 
@@ -18,7 +20,7 @@ type BloodDonationHandler struct {
 	certificateService       service.CertificateService
 	donorRegistrationService service.DonorRegistrationService
 	userService              service.UserService
-	blockchainService        service.BlockchainService
+	authorizer               *rbac.Authorizer
 }
 
 func NewBloodDonationHandler(
@@ -27,7 +29,7 @@ func NewBloodDonationHandler(
 	certificateService service.CertificateService,
 	donorRegistrationService service.DonorRegistrationService,
 	userService service.UserService,
-	blockchain service.BlockchainService,
+	authorizer *rbac.Authorizer,
 ) BloodDonationHandler {
 	return BloodDonationHandler{
 		bloodDonationService,
@@ -35,7 +37,7 @@ func NewBloodDonationHandler(
 		certificateService,
 		donorRegistrationService,
 		userService,
-		blockchain,
+		authorizer,
 	}
 }
 
@@ -100,7 +102,7 @@ func (h *BloodDonationHandler) GetById(ctx echo.Context) error {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data donasi darah: "+err.Error()))
 	}
 
-	if claimsData.Role == "User" {
+	if !h.authorizer.Allowed(claimsData.Role, rbac.BloodDonationManage) {
 		// Check if the user is the owner of the donation
 		if bloodDonation.UserId != claimsData.Id {
 			return ctx.JSON(http.StatusForbidden, response.ErrorResponse(http.StatusForbidden, "Tidak memiliki izin"))
@@ -159,14 +161,6 @@ func (h *BloodDonationHandler) Create(ctx echo.Context) error {
 		return ctx.JSON(http.StatusInternalServerError, "tidak dapat mendapatkan informasi pengguna dari klaim")
 	}
 
-	user, err := h.userService.GetById(ctx.Request().Context(), claimsData.Id)
-	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, "tidak dapat mendapatkan informasi pengguna dari klaim")
-	}
-	if user.WalletAddress == "" && req.Status == "pending" {
-		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Alamat wallet diperlukan"))
-	}
-
 	req.UserId = claimsData.Id
 
 	donorRegistration, err := h.donorRegistrationService.GetById(ctx.Request().Context(), req.RegistrationId)
@@ -228,7 +222,7 @@ func (h *BloodDonationHandler) Update(ctx echo.Context) error {
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data donasi darah: "+err.Error()))
 	}
-	if claimsData.Role == "User" && bloodDonation.UserId != claimsData.Id {
+	if !h.authorizer.Allowed(claimsData.Role, rbac.BloodDonationManage) && bloodDonation.UserId != claimsData.Id {
 		return ctx.JSON(http.StatusForbidden, response.ErrorResponse(http.StatusForbidden, "Tidak memiliki izin"))
 	}
 
@@ -257,51 +251,15 @@ func (h *BloodDonationHandler) Update(ctx echo.Context) error {
 // admin
 func (h *BloodDonationHandler) StatusBloodDonation(ctx echo.Context) error {
 	var req dto.BloodDonationUpdateRequest
-	var notif dto.NotificationCreateRequest
 	if err := ctx.Bind(&req); err != nil {
 		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
 	}
 
-	bloodDonation, err := h.bloodDonationService.GetById(ctx.Request().Context(), req.Id)
-	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data donasi darah: "+err.Error()))
-	}
-
-	if bloodDonation.Status != "pending" {
-		return ctx.JSON(http.StatusForbidden, response.ErrorResponse(http.StatusForbidden, "Donasi darah tidak bisa diubah"))
-	}
-
-	user, err := h.userService.GetById(ctx.Request().Context(), bloodDonation.UserId)
-	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data pengguna: "+err.Error()))
-	}
-
-	notif.UserId = bloodDonation.UserId
-	notif.Title = "Status Donasi Darah"
-	if req.Status == "completed" {
-		donorAlamat := bloodDonation.Hospital.Address + ", " + bloodDonation.Hospital.City + ", " + bloodDonation.Hospital.Province
-		txHash, certificateNumber, errBlockchain := h.blockchainService.CreateCertificate(user.WalletAddress, user.Name, donorAlamat)
-		if errBlockchain != nil {
-			return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal membuat sertifikat blockchain: "+errBlockchain.Error()))
+	if err := h.bloodDonationService.UpdateStatus(ctx.Request().Context(), req); err != nil {
+		if errors.Is(err, service.ErrBloodDonationNotPending) {
+			return ctx.JSON(http.StatusForbidden, response.ErrorResponse(http.StatusForbidden, err.Error()))
 		}
-		_, err = h.certificateService.Create(ctx.Request().Context(), bloodDonation, certificateNumber, txHash)
-		if err != nil {
-			return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal membuat sertifikat: "+err.Error()))
-		}
-		notif.Message = "Status donasi darah anda telah " + req.Status + " dengan nomor sertifikat " + certificateNumber + " dan digital signature (transaktion hash) " + txHash
-
-	} else {
-		notif.Message = "Status donasi darah anda telah " + req.Status
-	}
-
-	_, err = h.bloodDonationService.Update(ctx.Request().Context(), req, bloodDonation)
-	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal memperbarui status donasi darah: "+err.Error()))
-	}
-
-	notif.NotificationType = "information"
-	if err := h.notificationService.Create(ctx.Request().Context(), notif); err != nil {
-		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal membuat notifikasi: "+err.Error()))
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 	}
 	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil memperbarui status donasi darah dan membuat sertifikat", nil))
 }
@@ -328,7 +286,7 @@ func (h *BloodDonationHandler) Delete(ctx echo.Context) error {
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data donasi darah: "+err.Error()))
 	}
-	if claimsData.Role == "User" {
+	if !h.authorizer.Allowed(claimsData.Role, rbac.BloodDonationManage) {
 		if bloodDonation.UserId != claimsData.Id {
 			return ctx.JSON(http.StatusForbidden, response.ErrorResponse(http.StatusForbidden, "Tidak memiliki izin"))
 		}
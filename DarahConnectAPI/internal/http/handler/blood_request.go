@@ -1,12 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/rbac"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/response"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/token"
 
@@ -16,15 +18,21 @@ import (
 type BloodRequestHandler struct {
 	bloodRequestService service.BloodRequestService
 	notificationService service.NotificationService
+	userService         service.UserService
+	authorizer          *rbac.Authorizer
 }
 
 func NewBloodRequestHandler(
 	bloodRequestService service.BloodRequestService,
 	notificationService service.NotificationService,
+	userService service.UserService,
+	authorizer *rbac.Authorizer,
 	) BloodRequestHandler {
 	return BloodRequestHandler{
 		bloodRequestService,
 		notificationService,
+		userService,
+		authorizer,
 	}
 }
 
@@ -147,6 +155,10 @@ func (h *BloodRequestHandler) GetBloodRequests(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
 	}
 
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
 	bloodRequests, total, err := h.bloodRequestService.GetAllBloodRequest(ctx.Request().Context(), req)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data permintaan darah: "+err.Error()))
@@ -179,6 +191,61 @@ func (h *BloodRequestHandler) GetBloodRequestByUser(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, response.SuccessResponseWithPagi("berhasil menampilkan semua permintaan darah", bloodRequests, req.Page, req.Limit, total))
 }
 
+// GetCompatibleBloodRequests menampilkan permintaan darah yang bisa dibantu sesuai golongan darah pengguna
+func (h *BloodRequestHandler) GetCompatibleBloodRequests(ctx echo.Context) error {
+	var req dto.GetAllBloodRequestRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	// Retrieve user claims from the JWT token
+	claims, ok := ctx.Get("user").(*jwt.Token)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data pengguna"))
+	}
+
+	// Extract user information from claims
+	claimsData, ok := claims.Claims.(*token.JwtCustomClaims)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan informasi pengguna dari token"))
+	}
+
+	user, err := h.userService.GetById(ctx.Request().Context(), claimsData.Id)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data pengguna: "+err.Error()))
+	}
+
+	bloodRequests, total, err := h.bloodRequestService.GetCompatibleBloodRequest(ctx.Request().Context(), user.BloodType, req)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponseWithPagi("berhasil menampilkan permintaan darah yang sesuai golongan darah anda", bloodRequests, req.Page, req.Limit, total))
+}
+
+// GetCompatibleDonors menampilkan pendonor yang golongan darahnya cocok dengan permintaan darah
+func (h *BloodRequestHandler) GetCompatibleDonors(ctx echo.Context) error {
+	var req dto.GetAllUserRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Format id tidak valid"))
+	}
+
+	bloodRequest, err := h.bloodRequestService.GetById(ctx.Request().Context(), id)
+	if err != nil {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+
+	users, total, err := h.userService.GetCompatibleDonors(ctx.Request().Context(), bloodRequest.BloodType, req)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponseWithPagi("berhasil menampilkan pendonor yang cocok", users, req.Page, req.Limit, total))
+}
+
 func (h *BloodRequestHandler) GetBloodRequestsByAdmin(ctx echo.Context) error {
 	var req dto.GetAllBloodRequestRequest
 	if err := ctx.Bind(&req); err != nil {
@@ -198,6 +265,10 @@ func (h *BloodRequestHandler) GetCampaigns(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
 	}
 
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
 	bloodRequests, total, err := h.bloodRequestService.GetAllCampaign(ctx.Request().Context(), req)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data kampanye: "+err.Error()))
@@ -262,7 +333,7 @@ func (h *BloodRequestHandler) UpdateBloodRequest(ctx echo.Context) error {
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 	}
-	if claimsData.Role == "User" {
+	if !h.authorizer.Allowed(claimsData.Role, rbac.BloodRequestManage) {
 		if claimsData.Id != bloodRequest.UserId {
 			return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Anda tidak memiliki izin untuk memperbarui permintaan ini"))
 		}
@@ -331,7 +402,10 @@ func (h *BloodRequestHandler) StatusBloodRequest(ctx echo.Context) error {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 	}
 
-	if err := h.bloodRequestService.UpdateBloodRequest(ctx.Request().Context(), req, bloodRequest); err != nil {
+	if err := h.bloodRequestService.UpdateStatus(ctx.Request().Context(), req, bloodRequest); err != nil {
+		if errors.Is(err, service.ErrBloodStockUnavailable) {
+			return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		}
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 	}
 
@@ -377,7 +451,7 @@ func (h *BloodRequestHandler) DeleteBloodRequest(ctx echo.Context) error {
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 	}
-	if claimsData.Role == "User" {
+	if !h.authorizer.Allowed(claimsData.Role, rbac.BloodRequestManage) {
 		if claimsData.Id != bloodRequest.UserId {
 			return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Anda tidak mempunyai akses"))
 		}
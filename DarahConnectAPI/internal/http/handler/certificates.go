@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/rbac"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/response"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/token"
 
@@ -13,11 +15,13 @@ import (
 )
 
 type CertificateHandler struct {
-	certificateHandler service.CertificateService
+	certificateHandler        service.CertificateService
+	certificateIndexerService service.CertificateIndexerService
+	authorizer                *rbac.Authorizer
 }
 
-func NewCertificateHandler(certificateHandler service.CertificateService) CertificateHandler {
-	return CertificateHandler{certificateHandler}
+func NewCertificateHandler(certificateHandler service.CertificateService, certificateIndexerService service.CertificateIndexerService, authorizer *rbac.Authorizer) CertificateHandler {
+	return CertificateHandler{certificateHandler, certificateIndexerService, authorizer}
 }
 
 func (h *CertificateHandler) GetAll(ctx echo.Context) error {
@@ -33,6 +37,27 @@ func (h *CertificateHandler) GetAll(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, response.SuccessResponseWithPagi("berhasil mengambil semua sertifikat", certificates, req.Page, req.Limit, total))
 }
 
+// Verify dapat diakses tanpa login oleh rumah sakit atau pihak lain yang ingin memeriksa sertifikat
+func (h *CertificateHandler) Verify(ctx echo.Context) error {
+	var req dto.CertificateVerifyRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	verification, err := h.certificateHandler.Verify(ctx.Request().Context(), req.Number)
+	if errors.Is(err, service.ErrCertificateNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusBadGateway, response.ErrorResponse(http.StatusBadGateway, "Gagal membaca sertifikat dari blockchain: "+err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil memverifikasi sertifikat", verification))
+}
+
 func (h *CertificateHandler) GetById(ctx echo.Context) error {
 	var req dto.CertificateGetByIdRequest
 	if err := ctx.Bind(&req); err != nil {
@@ -56,7 +81,7 @@ func (h *CertificateHandler) GetById(ctx echo.Context) error {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data sertifikat: "+err.Error()))
 	}
 
-	if claimsData.Role == "User" {
+	if !h.authorizer.Allowed(claimsData.Role, rbac.CertificateRead) {
 		if certificate.UserId != claimsData.Id {
 			return ctx.JSON(http.StatusForbidden, response.ErrorResponse(http.StatusForbidden, "Tidak memiliki izin"))
 		}
@@ -65,6 +90,45 @@ func (h *CertificateHandler) GetById(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil mengambil sertifikat", certificate))
 }
 
+// GetPDF mengunduh sertifikat dalam bentuk PDF, pengguna hanya bisa mengunduh sertifikatnya sendiri
+func (h *CertificateHandler) GetPDF(ctx echo.Context) error {
+	var req dto.CertificateGetByIdRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	// Retrieve user claims from the JWT token
+	claims, ok := ctx.Get("user").(*jwt.Token)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data pengguna"))
+	}
+
+	// Extract user information from claims
+	claimsData, ok := claims.Claims.(*token.JwtCustomClaims)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan informasi pengguna dari token"))
+	}
+
+	certificate, err := h.certificateHandler.GetById(ctx.Request().Context(), req.Id)
+	if err != nil {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, "Sertifikat tidak ditemukan"))
+	}
+
+	if !h.authorizer.Allowed(claimsData.Role, rbac.CertificateRead) {
+		if certificate.UserId != claimsData.Id {
+			return ctx.JSON(http.StatusForbidden, response.ErrorResponse(http.StatusForbidden, "Tidak memiliki izin"))
+		}
+	}
+
+	pdf, err := h.certificateHandler.RenderPDF(ctx.Request().Context(), certificate.Id)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal membuat PDF sertifikat: "+err.Error()))
+	}
+
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, `inline; filename="sertifikat-`+certificate.CertificateNumber+`.pdf"`)
+	return ctx.Blob(http.StatusOK, "application/pdf", pdf)
+}
+
 func (h *CertificateHandler) GetByUser(ctx echo.Context) error {
 	var req dto.GetAllCertificateRequest
 	if err := ctx.Bind(&req); err != nil {
@@ -88,4 +152,69 @@ func (h *CertificateHandler) GetByUser(ctx echo.Context) error {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data sertifikat pengguna: "+err.Error()))
 	}
 	return ctx.JSON(http.StatusOK, response.SuccessResponseWithPagi("berhasil mengambil sertifikat pengguna", certificates, req.Page, req.Limit, total))
-}
\ No newline at end of file
+}
+// Claim meminta sertifikat yang tersimpan di escrow di-mint ulang ke wallet pendonor
+func (h *CertificateHandler) Claim(ctx echo.Context) error {
+	var req dto.CertificateGetByIdRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	// Retrieve user claims from the JWT token
+	claims, ok := ctx.Get("user").(*jwt.Token)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data pengguna"))
+	}
+
+	// Extract user information from claims
+	claimsData, ok := claims.Claims.(*token.JwtCustomClaims)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan informasi pengguna dari token"))
+	}
+
+	claim, err := h.certificateHandler.Claim(ctx.Request().Context(), claimsData.Id, req.Id)
+	if errors.Is(err, service.ErrCertificateNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal mengklaim sertifikat: "+err.Error()))
+	}
+	return ctx.JSON(http.StatusAccepted, response.SuccessResponse("klaim sertifikat sedang diproses", claim))
+}
+
+func (h *CertificateHandler) GetClaims(ctx echo.Context) error {
+	var req dto.CertificateGetByIdRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	// Retrieve user claims from the JWT token
+	claims, ok := ctx.Get("user").(*jwt.Token)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data pengguna"))
+	}
+
+	// Extract user information from claims
+	claimsData, ok := claims.Claims.(*token.JwtCustomClaims)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan informasi pengguna dari token"))
+	}
+
+	certificateClaims, err := h.certificateHandler.GetClaims(ctx.Request().Context(), claimsData.Id, req.Id)
+	if errors.Is(err, service.ErrCertificateNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan riwayat klaim sertifikat: "+err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil mengambil riwayat klaim sertifikat", certificateClaims))
+}
+
+// admin
+func (h *CertificateHandler) GetReconciliation(ctx echo.Context) error {
+	report, err := h.certificateIndexerService.Report(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan laporan rekonsiliasi sertifikat: "+err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil menampilkan laporan rekonsiliasi sertifikat", report))
+}
@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
@@ -11,37 +13,46 @@ import (
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/token"
 
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
-	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/midtrans"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
 )
 
 type DonationHandler struct {
-	midtransService midtrans.MidtransService
 	notificationService service.NotificationService
 	donationService service.DonationsService
 }
 
-func NewDonationHandler(midtransService midtrans.MidtransService, notificationService service.NotificationService, donationService service.DonationsService) *DonationHandler {
+func NewDonationHandler(notificationService service.NotificationService, donationService service.DonationsService) *DonationHandler {
 	return &DonationHandler{
-		midtransService: midtransService,
 		notificationService: notificationService,
 		donationService: donationService,
 	}
 }
 
 
+// WebHookTransaction menerima notifikasi gateway pembayaran. Body dibaca mentah karena disimpan
+// apa adanya. Notifikasi yang sudah diproses atau diabaikan tetap dibalas 200 agar gateway
+// tidak mengirim ulang.
 func (h *DonationHandler) WebHookTransaction(ctx echo.Context) error {
-	var req dto.DonationsCreate
-	if err := ctx.Bind(&req); err != nil {
+	payload, err := io.ReadAll(io.LimitReader(ctx.Request().Body, 1<<20))
+	if err != nil {
 		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
 	}
 
-	err := h.midtransService.WebHookTransaction(ctx.Request().Context(), &req)
+	result, err := h.donationService.HandleNotification(ctx.Request().Context(), payload)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal memproses transaksi webhook: "+err.Error()))
+		switch {
+		case errors.Is(err, service.ErrNotificationPayload):
+			return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		case errors.Is(err, service.ErrNotificationSignature):
+			return ctx.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, err.Error()))
+		default:
+			return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal memproses transaksi webhook: "+err.Error()))
+		}
 	}
 
-	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil memperbarui status donasi", nil))
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("notifikasi pembayaran diproses", map[string]interface{}{
+		"result": result,
+	}))
 }
 
 func (h *DonationHandler) CreateTransaction(ctx echo.Context) error {
@@ -65,14 +76,18 @@ func (h *DonationHandler) CreateTransaction(ctx echo.Context) error {
 	if !ok {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan informasi pengguna dari token"))
 	}
-	// Generate order ID
-	req.OrderID = "ORDER-" + strconv.FormatInt(claimsData.Id, 10) + "-" + time.Now().Format("20060102150405")
 	req.Fullname = claimsData.Name
 	req.Email = claimsData.Email
 	req.UserId = claimsData.Id
 
-	redirectURL, err := h.midtransService.CreateTransaction(ctx.Request().Context(), req)
+	redirectURL, err := h.donationService.CreateTransaction(ctx.Request().Context(), req)
 	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDonationTargetNotFound):
+			return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+		case errors.Is(err, service.ErrDonationTargetClosed):
+			return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+		}
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal membuat transaksi: "+err.Error()))
 	}
 	notificationData := dto.NotificationCreateRequest{
@@ -116,4 +131,83 @@ func (h *DonationHandler) GetDonation(ctx echo.Context) error {
 	}
 
 	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil mendapatkan data", donation))
-}
\ No newline at end of file
+}
+
+// RefundDonation meminta refund ke gateway. Status donasi baru berubah setelah gateway
+// mengirim notifikasi refund.
+func (h *DonationHandler) RefundDonation(ctx echo.Context) error {
+	var req dto.DonationRefundRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	if err := h.donationService.Refund(ctx.Request().Context(), req); err != nil {
+		if errors.Is(err, service.ErrDonationNotRefundable) {
+			return ctx.JSON(http.StatusConflict, response.ErrorResponse(http.StatusConflict, err.Error()))
+		}
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return ctx.JSON(http.StatusAccepted, response.SuccessResponse("refund diajukan ke gateway pembayaran", nil))
+}
+
+// GetDiscrepancies menampilkan selisih status donasi yang ditemukan reconciler pembayaran
+func (h *DonationHandler) GetDiscrepancies(ctx echo.Context) error {
+	var req dto.GetAllPaymentDiscrepancyRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	discrepancies, total, err := h.donationService.GetDiscrepancies(ctx.Request().Context(), req)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan selisih pembayaran: "+err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, response.SuccessResponseWithPagi("berhasil menampilkan selisih pembayaran", discrepancies, req.Page, req.Limit, total))
+}
+
+// GetStatement mengunduh laporan donasi satu tahun milik pengguna yang login dalam bentuk PDF atau CSV
+func (h *DonationHandler) GetStatement(ctx echo.Context) error {
+	var req dto.DonationStatementRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	claims, ok := ctx.Get("user").(*jwt.Token)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data pengguna"))
+	}
+
+	// Extract user information from claims
+	claimsData, ok := claims.Claims.(*token.JwtCustomClaims)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan informasi pengguna dari token"))
+	}
+	req.UserId = claimsData.Id
+
+	statement, err := h.donationService.RenderStatement(ctx.Request().Context(), req)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal membuat laporan donasi: "+err.Error()))
+	}
+
+	contentType := "application/pdf"
+	if req.Format == "csv" {
+		contentType = "text/csv; charset=utf-8"
+	} else {
+		req.Format = "pdf"
+	}
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="laporan-donasi-%d.%s"`, req.Year, req.Format))
+	return ctx.Blob(http.StatusOK, contentType, statement)
+}
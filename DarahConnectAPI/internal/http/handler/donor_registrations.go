@@ -6,6 +6,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/rbac"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/response"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/timezone"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/token"
@@ -19,6 +20,8 @@ type DonorRegistrationHandler struct {
 	healthPassportService    service.HealthPassportService
 	notificationService service.NotificationService
 	bloodRequestService service.BloodRequestService
+	eligibilityService  service.EligibilityService
+	authorizer          *rbac.Authorizer
 }
 
 func NewDonorRegistrationHandler(
@@ -26,13 +29,16 @@ func NewDonorRegistrationHandler(
 	healthPassportService service.HealthPassportService,
 	notificationService service.NotificationService,
 	bloodRequestService service.BloodRequestService,
+	eligibilityService service.EligibilityService,
+	authorizer *rbac.Authorizer,
 ) DonorRegistrationHandler {
 	return DonorRegistrationHandler{
 		donorRegistrationService,
 		healthPassportService,
 		notificationService,
 		bloodRequestService,
-
+		eligibilityService,
+		authorizer,
 	}
 }
 
@@ -52,7 +58,7 @@ func (h *DonorRegistrationHandler) GetDonorRegistrations(ctx echo.Context) error
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan informasi pengguna dari token"))
 	}
 
-	if claimsData.Role != "user" {
+	if !h.authorizer.Allowed(claimsData.Role, rbac.DonorRegistrationManage) {
 		req.UserId = claimsData.Id
 	}
 
@@ -88,7 +94,7 @@ func (h *DonorRegistrationHandler) GetDonorRegistration(ctx echo.Context) error
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "pendaftaran donor tidak ditemukan"))
 	}
 
-	if claimsData.Role == "User" && donorRegistration.UserId != claimsData.Id {
+	if !h.authorizer.Allowed(claimsData.Role, rbac.DonorRegistrationManage) && donorRegistration.UserId != claimsData.Id {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Anda tidak memiliki akses"))
 	}
 
@@ -159,10 +165,31 @@ func (h *DonorRegistrationHandler) CreateDonorRegistration(ctx echo.Context) err
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Anda belum memiliki health passport, silahkan untuk mengisi health passport terlebih dahulu"))
 	}
 
+	switch healthPassport.Status {
+	case "active":
+	case "deferred":
+		if healthPassport.DeferredUntil != nil {
+			return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "health passport ditunda sampai "+healthPassport.DeferredUntil.In(timezone.JakartaLocation).Format("02-01-2006")))
+		}
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "health passport sedang ditunda"))
+	case "pending_review":
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "health passport masih menunggu peninjauan admin"))
+	default:
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "health passport tidak aktif"))
+	}
+
 	if time.Now().In(timezone.JakartaLocation).After(healthPassport.ExpiryDate) {
 		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "health passport sudah expired"))
 	}
 
+	eligibility, err := h.eligibilityService.Check(ctx.Request().Context(), req.UserId)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal memeriksa kelayakan donor: "+err.Error()))
+	}
+	if !eligibility.Eligible {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponseWithData(http.StatusBadRequest, "Anda belum memenuhi syarat untuk donor darah", eligibility))
+	}
+
 	if err := h.donorRegistrationService.Create(ctx.Request().Context(), req); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal membuat pendaftaran donor: "+err.Error()))
 	}
@@ -210,7 +237,7 @@ func (h *DonorRegistrationHandler) UpdateDonorRegistration(ctx echo.Context) err
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "pendaftaran donor tidak ditemukan"))
 	}
 
-	if claimsData.Role == "User"{
+	if !h.authorizer.Allowed(claimsData.Role, rbac.DonorRegistrationManage) {
 		if donorRegistration.UserId != claimsData.Id {
 			return ctx.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Tidak memiliki izin"))
 		}
@@ -258,7 +285,7 @@ func (h *DonorRegistrationHandler) DeleteDonorRegistration(ctx echo.Context) err
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data pendaftaran donor: "+err.Error()))
 	}
-	if claimsData.Role == "User"{
+	if !h.authorizer.Allowed(claimsData.Role, rbac.DonorRegistrationManage) {
 		if claimsData.Id != donorRegistration.UserId {
 			return ctx.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "Tidak memiliki izin"))
 		}
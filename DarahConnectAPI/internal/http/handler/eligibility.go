@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/response"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/token"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+)
+
+type EligibilityHandler struct {
+	eligibilityService service.EligibilityService
+}
+
+func NewEligibilityHandler(eligibilityService service.EligibilityService) EligibilityHandler {
+	return EligibilityHandler{eligibilityService}
+}
+
+func (h *EligibilityHandler) GetEligibility(ctx echo.Context) error {
+	claims, ok := ctx.Get("user").(*jwt.Token)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data pengguna"))
+	}
+
+	claimsData, ok := claims.Claims.(*token.JwtCustomClaims)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan informasi pengguna dari token"))
+	}
+
+	result, err := h.eligibilityService.Check(ctx.Request().Context(), claimsData.Id)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan status kelayakan donor: "+err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil menampilkan status kelayakan donor", result))
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/response"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/token"
+)
+
+type FundHandler struct {
+	fundService service.FundService
+}
+
+func NewFundHandler(fundService service.FundService) FundHandler {
+	return FundHandler{fundService}
+}
+
+func (h *FundHandler) GetAll(ctx echo.Context) error {
+	var req dto.GetAllFundRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	funds, total, err := h.fundService.GetAll(ctx.Request().Context(), req)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data dana: "+err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponseWithPagi("berhasil menampilkan semua dana", funds, req.Page, req.Limit, total))
+}
+
+func (h *FundHandler) GetById(ctx echo.Context) error {
+	var req dto.FundByIdRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	fund, err := h.fundService.GetById(ctx.Request().Context(), req.Id)
+	if err != nil {
+		if errors.Is(err, service.ErrFundNotFound) {
+			return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+		}
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil menampilkan dana", fund))
+}
+
+func (h *FundHandler) Create(ctx echo.Context) error {
+	var req dto.FundCreateRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	claims, ok := ctx.Get("user").(*jwt.Token)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data pengguna"))
+	}
+	claimsData, ok := claims.Claims.(*token.JwtCustomClaims)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan informasi pengguna dari token"))
+	}
+	req.CreatedBy = claimsData.Id
+
+	fund, err := h.fundService.Create(ctx.Request().Context(), req)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal membuat dana: "+err.Error()))
+	}
+	return ctx.JSON(http.StatusCreated, response.SuccessResponse("berhasil membuat dana", fund))
+}
+
+func (h *FundHandler) Update(ctx echo.Context) error {
+	var req dto.FundUpdateRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	fund, err := h.fundService.Update(ctx.Request().Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrFundNotFound) {
+			return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+		}
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal memperbarui dana: "+err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil memperbarui dana", fund))
+}
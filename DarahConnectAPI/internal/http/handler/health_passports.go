@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -73,7 +74,20 @@ func (h *HealthPassportHandler) GetHealthPassportByUser(ctx echo.Context) error
 		response.SuccessResponse("berhasil menampilkan health passport", healthPassport))
 }
 
+func (h *HealthPassportHandler) GetQuestionnaire(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil menampilkan kuesioner skrining kesehatan", h.healthPassportService.GetQuestionnaire()))
+}
+
 func (h *HealthPassportHandler) CreateHealthPassport(ctx echo.Context) error {
+	var req dto.HealthScreeningRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
 	// Retrieve user claims from the JWT token
 	claims, ok := ctx.Get("user").(*jwt.Token)
 	if !ok {
@@ -84,19 +98,24 @@ func (h *HealthPassportHandler) CreateHealthPassport(ctx echo.Context) error {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan informasi pengguna dari token"))
 	}
 
+	req.UserId = claimsData.Id
 	healthPassport,_ := h.healthPassportService.GetByUserId(ctx.Request().Context(), claimsData.Id)
 	
 	if healthPassport != nil {
-		if err := h.healthPassportService.UpdateByUser(ctx.Request().Context(), healthPassport); err != nil {
+		if err := h.healthPassportService.UpdateByUser(ctx.Request().Context(), req, healthPassport); err != nil {
+			if errors.Is(err, service.ErrHealthPassportUnderReview) || errors.Is(err, service.ErrHealthPassportDeferred) {
+				return ctx.JSON(http.StatusForbidden, response.ErrorResponse(http.StatusForbidden, err.Error()))
+			}
 			return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal memperbarui health passport: "+err.Error()))
 		}
-		return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil memperbarui health passport", nil))
+		return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil memperbarui health passport", healthPassport))
 	}
 
-	if err := h.healthPassportService.Create(ctx.Request().Context(), claimsData.Id); err != nil {
+	healthPassport, err := h.healthPassportService.Create(ctx.Request().Context(), req)
+	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal membuat health passport: "+err.Error()))
 	}
-	return ctx.JSON(http.StatusCreated, response.SuccessResponse("berhasil membuat health passport", nil))
+	return ctx.JSON(http.StatusCreated, response.SuccessResponse("berhasil membuat health passport", healthPassport))
 }
 
 func (h *HealthPassportHandler) UpdateStatusHealthPassport(ctx echo.Context) error {
@@ -105,6 +124,20 @@ func (h *HealthPassportHandler) UpdateStatusHealthPassport(ctx echo.Context) err
 		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
 	}
 
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	claims, ok := ctx.Get("user").(*jwt.Token)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data pengguna"))
+	}
+	claimsData, ok := claims.Claims.(*token.JwtCustomClaims)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan informasi pengguna dari token"))
+	}
+	req.ReviewerId = claimsData.Id
+
 	healthPassport, err := h.healthPassportService.GetById(ctx.Request().Context(), req.Id)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError,
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
@@ -11,11 +12,12 @@ import (
 )
 
 type HospitalHandler struct {
-	hospitalHandler service.HospitalService
+	hospitalHandler      service.HospitalService
+	hospitalStaffService service.HospitalStaffService
 }
 
-func NewHospitalHandler(hospitalHandler service.HospitalService) HospitalHandler {
-	return HospitalHandler{hospitalHandler}
+func NewHospitalHandler(hospitalHandler service.HospitalService, hospitalStaffService service.HospitalStaffService) HospitalHandler {
+	return HospitalHandler{hospitalHandler, hospitalStaffService}
 }
 
 func (h *HospitalHandler) GetAll(ctx echo.Context) error {
@@ -24,6 +26,10 @@ func (h *HospitalHandler) GetAll(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
 	}
 
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
 	hospitals, total, err := h.hospitalHandler.GetAll(ctx.Request().Context(), req)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "Gagal mendapatkan data rumah sakit: "+err.Error()))
@@ -87,3 +93,58 @@ func (h *HospitalHandler) Delete(ctx echo.Context) error {
 	}
 	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil menghapus rumah sakit", nil))
 }
+
+func (h *HospitalHandler) GetStaff(ctx echo.Context) error {
+	var req dto.HospitalGetByIdRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	staff, err := h.hospitalStaffService.GetByHospital(ctx.Request().Context(), req.Id)
+	if err != nil {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil menampilkan staf rumah sakit", staff))
+}
+
+// AddStaff mendaftarkan pengguna sebagai staf rumah sakit. Aksesnya baru dibatasi ke rumah sakit
+// tersebut jika role pengguna memiliki permission hospital:staff.
+func (h *HospitalHandler) AddStaff(ctx echo.Context) error {
+	var req dto.HospitalStaffRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	staff, err := h.hospitalStaffService.Add(ctx.Request().Context(), req.Id, req.UserId)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusCreated, response.SuccessResponse("berhasil menambahkan staf rumah sakit", staff))
+}
+
+func (h *HospitalHandler) RemoveStaff(ctx echo.Context) error {
+	var req dto.RemoveHospitalStaffRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	if err := h.hospitalStaffService.Remove(ctx.Request().Context(), req.Id, req.UserId); err != nil {
+		if errors.Is(err, service.ErrHospitalStaffNotFound) {
+			return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+		}
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil menghapus staf rumah sakit", nil))
+}
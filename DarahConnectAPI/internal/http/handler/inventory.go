@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/response"
+)
+
+type InventoryHandler struct {
+	inventoryService service.InventoryService
+}
+
+func NewInventoryHandler(inventoryService service.InventoryService) InventoryHandler {
+	return InventoryHandler{inventoryService}
+}
+
+func (h *InventoryHandler) GetStock(ctx echo.Context) error {
+	var req dto.GetBloodStockRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	stock, err := h.inventoryService.GetStock(ctx.Request().Context(), req)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil menampilkan stok darah", stock))
+}
+
+func (h *InventoryHandler) GetAll(ctx echo.Context) error {
+	var req dto.GetAllBloodBagRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	bloodBags, total, err := h.inventoryService.GetAll(ctx.Request().Context(), req)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponseWithPagi("berhasil menampilkan semua kantong darah", bloodBags, req.Page, req.Limit, total))
+}
+
+func (h *InventoryHandler) GetById(ctx echo.Context) error {
+	var req dto.BloodBagByIdRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	bloodBag, err := h.inventoryService.GetById(ctx.Request().Context(), req.Id)
+	if err != nil {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil menampilkan kantong darah berdasarkan id", bloodBag))
+}
+
+func (h *InventoryHandler) Create(ctx echo.Context) error {
+	var req dto.BloodBagCreateRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	bloodBag, err := h.inventoryService.Create(ctx.Request().Context(), req)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil menambahkan kantong darah", bloodBag))
+}
+
+func (h *InventoryHandler) Update(ctx echo.Context) error {
+	var req dto.BloodBagUpdateRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	bloodBag, err := h.inventoryService.GetById(ctx.Request().Context(), req.Id)
+	if err != nil {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+
+	if err := h.inventoryService.Update(ctx.Request().Context(), req, bloodBag); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil memperbarui kantong darah", bloodBag))
+}
+
+func (h *InventoryHandler) Delete(ctx echo.Context) error {
+	var req dto.BloodBagByIdRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Gagal memproses permintaan: "+err.Error()))
+	}
+
+	if err := h.inventoryService.Delete(ctx.Request().Context(), req.Id); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil menghapus kantong darah", nil))
+}
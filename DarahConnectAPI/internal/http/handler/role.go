@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/response"
+)
+
+type RoleHandler struct {
+	roleService    service.RoleService
+	sessionService service.SessionService
+}
+
+func NewRoleHandler(roleService service.RoleService, sessionService service.SessionService) RoleHandler {
+	return RoleHandler{roleService, sessionService}
+}
+
+func (h *RoleHandler) GetRoles(ctx echo.Context) error {
+	roles, err := h.roleService.GetAll(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil menampilkan semua role", roles))
+}
+
+func (h *RoleHandler) GetPermissions(ctx echo.Context) error {
+	permissions, err := h.roleService.GetPermissions(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil menampilkan semua permission", permissions))
+}
+
+func (h *RoleHandler) CreateRole(ctx echo.Context) error {
+	var req dto.CreateRoleRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	role, err := h.roleService.Create(ctx.Request().Context(), req)
+	if err != nil {
+		return ctx.JSON(roleErrorStatus(err), response.ErrorResponse(roleErrorStatus(err), err.Error()))
+	}
+	return ctx.JSON(http.StatusCreated, response.SuccessResponse("berhasil membuat role", role))
+}
+
+func (h *RoleHandler) UpdateRole(ctx echo.Context) error {
+	var req dto.UpdateRoleRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	role, err := h.roleService.Update(ctx.Request().Context(), req)
+	if err != nil {
+		return ctx.JSON(roleErrorStatus(err), response.ErrorResponse(roleErrorStatus(err), err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil memperbarui role", role))
+}
+
+func (h *RoleHandler) DeleteRole(ctx echo.Context) error {
+	var req dto.RoleByIdRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	if err := h.roleService.Delete(ctx.Request().Context(), req.Id); err != nil {
+		return ctx.JSON(roleErrorStatus(err), response.ErrorResponse(roleErrorStatus(err), err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil menghapus role", nil))
+}
+
+// AssignRole mengganti role pengguna lalu mencabut semua sesinya, karena role tersimpan di JWT
+func (h *RoleHandler) AssignRole(ctx echo.Context) error {
+	var req dto.AssignRoleRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	user, err := h.roleService.AssignRole(ctx.Request().Context(), req)
+	if err != nil {
+		return ctx.JSON(roleErrorStatus(err), response.ErrorResponse(roleErrorStatus(err), err.Error()))
+	}
+
+	if err := h.sessionService.LogoutAll(ctx.Request().Context(), user.Id); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil mengganti role pengguna", user))
+}
+
+func roleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrRoleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrRoleExists), errors.Is(err, service.ErrRoleInUse):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrRoleSystem), errors.Is(err, service.ErrRoleAdministrator):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
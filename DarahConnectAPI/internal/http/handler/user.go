@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/cloudinary"
@@ -17,12 +20,15 @@ import (
 
 type UserHandler struct {
 	userService        service.UserService
+	walletAuthService  service.WalletAuthService
+	sessionService     service.SessionService
+	mfaService         service.MFAService
 	cloudinaryService  *cloudinary.Service
 	GoogleOauthService *googleoauth.Service
 }
 
-func NewUserHandler(userService service.UserService, cloudinaryService *cloudinary.Service, GoogleOauthService *googleoauth.Service) UserHandler {
-	return UserHandler{userService, cloudinaryService, GoogleOauthService}
+func NewUserHandler(userService service.UserService, walletAuthService service.WalletAuthService, sessionService service.SessionService, mfaService service.MFAService, cloudinaryService *cloudinary.Service, GoogleOauthService *googleoauth.Service) UserHandler {
+	return UserHandler{userService, walletAuthService, sessionService, mfaService, cloudinaryService, GoogleOauthService}
 }
 
 func (h *UserHandler) GetUsers(ctx echo.Context) error {
@@ -84,21 +90,298 @@ func (h *UserHandler) Login(ctx echo.Context) error {
 			response.ErrorResponse(http.StatusBadRequest, err.Error()))
 	}
 
-	token, isVerified, err := h.userService.Login(ctx.Request().Context(), loginRequest.Email, loginRequest.Password)
+	user, err := h.userService.Login(ctx.Request().Context(), loginRequest.Email, loginRequest.Password)
 	if err != nil {
 		return ctx.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, err.Error()))
 	}
-	if !isVerified {
+	if !user.IsVerified {
 		return ctx.JSON(http.StatusOK, response.SuccessResponse("Email belum diverifikasi, silahkan verifikasi email anda", map[string]interface{}{
-			"verify_expired_at": token,
+			"verify_expired_at": user.TokenExpiresAt.Format(time.RFC3339),
 		}))
 	}
 
-	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil masuk", map[string]interface{}{
-		"token": token,
+	return h.startSession(ctx, user)
+}
+
+// startSession menerbitkan JWT, atau token mfa pending jika pengguna harus memasukkan kode TOTP
+func (h *UserHandler) startSession(ctx echo.Context, user *entity.User) error {
+	challenge, err := h.mfaService.StartLogin(ctx.Request().Context(), user)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	if challenge != nil {
+		return ctx.JSON(http.StatusOK, response.SuccessResponse("masukkan kode verifikasi dua langkah", challenge))
+	}
+
+	session, err := h.sessionService.Issue(ctx.Request().Context(), user)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil masuk", session))
+}
+
+// LoginMFA menukar token mfa pending dengan JWT setelah kode TOTP atau kode pemulihan benar
+func (h *UserHandler) LoginMFA(ctx echo.Context) error {
+	var req dto.MFALoginRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	user, recoveryCodes, err := h.mfaService.CompleteLogin(ctx.Request().Context(), req)
+	if err != nil {
+		return ctx.JSON(mfaErrorStatus(err), response.ErrorResponse(mfaErrorStatus(err), err.Error()))
+	}
+
+	session, err := h.sessionService.Issue(ctx.Request().Context(), user)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil masuk", dto.MFALoginResponse{
+		TokenResponse: session,
+		RecoveryCodes: recoveryCodes,
 	}))
 }
 
+// LoginMFAEnroll membuat secret TOTP untuk role wajib MFA yang belum pernah mendaftarkannya
+func (h *UserHandler) LoginMFAEnroll(ctx echo.Context) error {
+	var req dto.MFAEnrollRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	enrollment, err := h.mfaService.EnrollWithToken(ctx.Request().Context(), req.MFAToken)
+	if err != nil {
+		return ctx.JSON(mfaErrorStatus(err), response.ErrorResponse(mfaErrorStatus(err), err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("pindai QR code dengan aplikasi authenticator lalu masukkan kodenya", enrollment))
+}
+
+func (h *UserHandler) GetMFAStatus(ctx echo.Context) error {
+	claims, ok := ctx.Get("user").(*jwt.Token)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "gagal mendapatkan data pengguna"))
+	}
+
+	// Extract user information from claims
+	claimsData, ok := claims.Claims.(*token.JwtCustomClaims)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "gagal mendapatkan informasi pengguna dari token"))
+	}
+
+	user, err := h.userService.GetById(ctx.Request().Context(), claimsData.Id)
+	if err != nil {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+
+	status, err := h.mfaService.Status(ctx.Request().Context(), user)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil menampilkan status verifikasi dua langkah", status))
+}
+
+func (h *UserHandler) EnrollMFA(ctx echo.Context) error {
+	claims, ok := ctx.Get("user").(*jwt.Token)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "gagal mendapatkan data pengguna"))
+	}
+
+	// Extract user information from claims
+	claimsData, ok := claims.Claims.(*token.JwtCustomClaims)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "gagal mendapatkan informasi pengguna dari token"))
+	}
+
+	user, err := h.userService.GetById(ctx.Request().Context(), claimsData.Id)
+	if err != nil {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+
+	enrollment, err := h.mfaService.Enroll(ctx.Request().Context(), user)
+	if err != nil {
+		return ctx.JSON(mfaErrorStatus(err), response.ErrorResponse(mfaErrorStatus(err), err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("pindai QR code dengan aplikasi authenticator lalu masukkan kodenya", enrollment))
+}
+
+// ActivateMFA mengaktifkan TOTP setelah kode pertama dari aplikasi authenticator benar
+func (h *UserHandler) ActivateMFA(ctx echo.Context) error {
+	var req dto.MFACodeRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	claims, ok := ctx.Get("user").(*jwt.Token)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "gagal mendapatkan data pengguna"))
+	}
+
+	// Extract user information from claims
+	claimsData, ok := claims.Claims.(*token.JwtCustomClaims)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "gagal mendapatkan informasi pengguna dari token"))
+	}
+
+	recoveryCodes, err := h.mfaService.Activate(ctx.Request().Context(), claimsData.Id, req.Code)
+	if err != nil {
+		return ctx.JSON(mfaErrorStatus(err), response.ErrorResponse(mfaErrorStatus(err), err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("verifikasi dua langkah aktif, simpan kode pemulihan anda", dto.MFARecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+	}))
+}
+
+func (h *UserHandler) RegenerateMFARecoveryCodes(ctx echo.Context) error {
+	var req dto.MFACodeRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	claims, ok := ctx.Get("user").(*jwt.Token)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "gagal mendapatkan data pengguna"))
+	}
+
+	// Extract user information from claims
+	claimsData, ok := claims.Claims.(*token.JwtCustomClaims)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "gagal mendapatkan informasi pengguna dari token"))
+	}
+
+	recoveryCodes, err := h.mfaService.RegenerateRecoveryCodes(ctx.Request().Context(), claimsData.Id, req.Code)
+	if err != nil {
+		return ctx.JSON(mfaErrorStatus(err), response.ErrorResponse(mfaErrorStatus(err), err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil membuat kode pemulihan baru", dto.MFARecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+	}))
+}
+
+func (h *UserHandler) DisableMFA(ctx echo.Context) error {
+	var req dto.MFACodeRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	claims, ok := ctx.Get("user").(*jwt.Token)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "gagal mendapatkan data pengguna"))
+	}
+
+	// Extract user information from claims
+	claimsData, ok := claims.Claims.(*token.JwtCustomClaims)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "gagal mendapatkan informasi pengguna dari token"))
+	}
+
+	user, err := h.userService.GetById(ctx.Request().Context(), claimsData.Id)
+	if err != nil {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+
+	if err := h.mfaService.Disable(ctx.Request().Context(), user, req.Code); err != nil {
+		return ctx.JSON(mfaErrorStatus(err), response.ErrorResponse(mfaErrorStatus(err), err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("verifikasi dua langkah dinonaktifkan", nil))
+}
+
+func mfaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrMFATokenInvalid), errors.Is(err, service.ErrMFACodeInvalid):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrMFAEnforced):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrMFANotEnrolled), errors.Is(err, service.ErrMFAAlreadyEnabled):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// RefreshToken menukar refresh token dengan access token dan refresh token baru
+func (h *UserHandler) RefreshToken(ctx echo.Context) error {
+	var req dto.RefreshTokenRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	session, err := h.sessionService.Refresh(ctx.Request().Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrRefreshTokenInvalid) || errors.Is(err, service.ErrRefreshTokenReused) {
+			return ctx.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, err.Error()))
+		}
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil memperbarui sesi", session))
+}
+
+// Logout mencabut access token yang sedang dipakai dan refresh token yang dikirim
+func (h *UserHandler) Logout(ctx echo.Context) error {
+	var req dto.LogoutRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	claims, ok := ctx.Get("user").(*jwt.Token)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "gagal mendapatkan data pengguna"))
+	}
+
+	// Extract user information from claims
+	claimsData, ok := claims.Claims.(*token.JwtCustomClaims)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "gagal mendapatkan informasi pengguna dari token"))
+	}
+
+	if err := h.sessionService.Logout(ctx.Request().Context(), claimsData, req.RefreshToken); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil keluar", nil))
+}
+
+// LogoutAll mengakhiri semua sesi pengguna di semua perangkat
+func (h *UserHandler) LogoutAll(ctx echo.Context) error {
+	claims, ok := ctx.Get("user").(*jwt.Token)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "gagal mendapatkan data pengguna"))
+	}
+
+	// Extract user information from claims
+	claimsData, ok := claims.Claims.(*token.JwtCustomClaims)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "gagal mendapatkan informasi pengguna dari token"))
+	}
+
+	if err := h.sessionService.LogoutAll(ctx.Request().Context(), claimsData.Id); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil keluar dari semua perangkat", nil))
+}
+
 func (h *UserHandler) Register(ctx echo.Context) error {
 	var req dto.UserRegisterRequest
 
@@ -149,6 +432,11 @@ func (h *UserHandler) DeleteUser(ctx echo.Context) error {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 	}
 
+	// Token pengguna yang dihapus langsung tidak berlaku
+	if err := h.sessionService.LogoutAll(ctx.Request().Context(), user.Id); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
 	err = h.userService.Delete(ctx.Request().Context(), user)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
@@ -237,6 +525,10 @@ func (h *UserHandler) UpdateUser(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
 	}
 
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
 	// Langkah 2: Tangani file upload secara manual dan terpisah.
 	// Ini membuat penanganan file opsional menjadi lebih eksplisit dan aman.
 	if imageFile, err := ctx.FormFile("image"); err != nil {
@@ -304,12 +596,45 @@ func (h *UserHandler) CallbackGoogleAuth(ctx echo.Context) error {
 	return ctx.Redirect(http.StatusTemporaryRedirect, data)
 }
 
+// WalletChallenge membuat pesan yang harus ditandatangani wallet sebelum dihubungkan ke akun
+func (h *UserHandler) WalletChallenge(ctx echo.Context) error {
+	var req dto.WalletChallengeRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Alamat wallet tidak valid: "+err.Error()))
+	}
+
+	claims, ok := ctx.Get("user").(*jwt.Token)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "gagal mendapatkan data pengguna"))
+	}
+
+	// Extract user information from claims
+	claimsData, ok := claims.Claims.(*token.JwtCustomClaims)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "gagal mendapatkan informasi pengguna dari token"))
+	}
+
+	challenge, err := h.walletAuthService.Challenge(ctx.Request().Context(), &claimsData.Id, entity.WalletChallengeLink, req)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("silahkan tanda tangani pesan dengan wallet anda", challenge))
+}
+
 func (h *UserHandler) WalletAddress(ctx echo.Context) error {
 	var req dto.WalletAddressRequest
 	if err := ctx.Bind(&req); err != nil {
 		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
 	}
 
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Alamat wallet tidak valid: "+err.Error()))
+	}
+
 	claims, ok := ctx.Get("user").(*jwt.Token)
 	if !ok {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "gagal mendapatkan data pengguna"))
@@ -326,8 +651,44 @@ func (h *UserHandler) WalletAddress(ctx echo.Context) error {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 	}
 
-	if err := h.userService.WalletAddress(ctx.Request().Context(), user, req); err != nil {
-		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	if err := h.walletAuthService.Link(ctx.Request().Context(), user, req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
 	}
 	return ctx.JSON(http.StatusOK, response.SuccessResponse("berhasil memperbarui alamat dompet", nil))
 }
+
+func (h *UserHandler) LoginWalletChallenge(ctx echo.Context) error {
+	var req dto.WalletChallengeRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Alamat wallet tidak valid: "+err.Error()))
+	}
+
+	challenge, err := h.walletAuthService.Challenge(ctx.Request().Context(), nil, entity.WalletChallengeLogin, req)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("silahkan tanda tangani pesan dengan wallet anda", challenge))
+}
+
+// LoginWallet menerbitkan token yang sama dengan login email untuk pemilik wallet yang sudah terhubung
+func (h *UserHandler) LoginWallet(ctx echo.Context) error {
+	var req dto.WalletLoginRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Data tidak valid: "+err.Error()))
+	}
+
+	user, err := h.walletAuthService.Login(ctx.Request().Context(), req)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, err.Error()))
+	}
+
+	return h.startSession(ctx, user)
+}
@@ -5,15 +5,10 @@ import (
 
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/handler"
 
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/rbac"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/route"
 )
 
-var (
-	adminOnly = []string{"Administrator"}
-	userOnly  = []string{"User"}
-	allRoles  = []string{"Administrator", "User"}
-)
-
 func PublicRoutes(
 	userHandler handler.UserHandler,
 	bloodRequestHandler handler.BloodRequestHandler,
@@ -21,6 +16,7 @@ func PublicRoutes(
 	certificateHandler handler.CertificateHandler,
 	donationHandler *handler.DonationHandler,
 	dashboardHandler handler.Dashboard,
+	fundHandler handler.FundHandler,
 ) []route.Route {
 	return []route.Route{
 		{
@@ -34,6 +30,31 @@ func PublicRoutes(
 			Path:    "login",
 			Handler: userHandler.Login,
 		},
+		{
+			Method:  http.MethodPost,
+			Path:    "login/mfa",
+			Handler: userHandler.LoginMFA,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "login/mfa/enroll",
+			Handler: userHandler.LoginMFAEnroll,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "refresh-token",
+			Handler: userHandler.RefreshToken,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "login/wallet/challenge",
+			Handler: userHandler.LoginWalletChallenge,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "login/wallet",
+			Handler: userHandler.LoginWallet,
+		},
 		{
 			Method:  http.MethodGet,
 			Path:    "login/:provider",
@@ -91,7 +112,23 @@ func PublicRoutes(
 			Path:    "donation/webhook",
 			Handler: donationHandler.WebHookTransaction,
 		},
+		// Fund Handler
+		{
+			Method:  http.MethodGet,
+			Path:    "funds",
+			Handler: fundHandler.GetAll,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "fund/:id",
+			Handler: fundHandler.GetById,
+		},
 		// Certificate Handler
+		{
+			Method:  http.MethodGet,
+			Path:    "certificate/verify/:number",
+			Handler: certificateHandler.Verify,
+		},
 	}
 }
 
@@ -107,368 +144,599 @@ func PrivateRoutes(
 	certificateHandler handler.CertificateHandler,
 	donationHandler *handler.DonationHandler,
 	dashboardHandler handler.Dashboard,
+	inventoryHandler handler.InventoryHandler,
+	eligibilityHandler handler.EligibilityHandler,
+	roleHandler handler.RoleHandler,
+	auditLogHandler handler.AuditLogHandler,
+	fundHandler handler.FundHandler,
 ) []route.Route {
 	return []route.Route{
 		// =============================================
-		// USER ONLY ROUTES
+		// DONOR ROUTES (permission bawaan role User)
 		// =============================================
-		// Dashboard - User Only
+		// Dashboard - Donor
 		{
-			Method:  http.MethodGet,
-			Path:    "user/dashboard",
-			Handler: dashboardHandler.DashboardUser,
-			Roles:   userOnly,
+			Method:     http.MethodGet,
+			Path:       "user/dashboard",
+			Handler:    dashboardHandler.DashboardUser,
+			Permission: rbac.DashboardDonor,
 		},
-		// Health Passport - User Only
+		// Health Passport - Donor
 		{
-			Method:  http.MethodGet,
-			Path:    "user/health-passport",
-			Handler: healthPassportHandler.GetHealthPassportByUser,
-			Roles:   userOnly,
+			Method:     http.MethodGet,
+			Path:       "user/health-passport",
+			Handler:    healthPassportHandler.GetHealthPassportByUser,
+			Permission: rbac.HealthPassportOwn,
 		},
 		{
-			Method:  http.MethodPost,
-			Path:    "user/health-passport",
-			Handler: healthPassportHandler.CreateHealthPassport,
-			Roles:   userOnly,
+			Method:     http.MethodPost,
+			Path:       "user/health-passport",
+			Handler:    healthPassportHandler.CreateHealthPassport,
+			Permission: rbac.HealthPassportOwn,
 		},
-		// Notification - User Only
 		{
-			Method:  http.MethodGet,
-			Path:    "user/notifications/",
-			Handler: notificationHandler.GetNotificationsByUser,
-			Roles:   userOnly,
+			Method:     http.MethodGet,
+			Path:       "user/health-passport/questionnaire",
+			Handler:    healthPassportHandler.GetQuestionnaire,
+			Permission: rbac.HealthPassportOwn,
 		},
+		// Eligibility - Donor
 		{
-			Method:  http.MethodGet,
-			Path:    "user/notifications/:id",
-			Handler: notificationHandler.GetNotificationByUser,
-			Roles:   userOnly,
+			Method:     http.MethodGet,
+			Path:       "user/eligibility",
+			Handler:    eligibilityHandler.GetEligibility,
+			Permission: rbac.HealthPassportOwn,
 		},
+		// Notification - Donor
 		{
-			Method:  http.MethodGet,
-			Path:    "user/notifications/count",
-			Handler: notificationHandler.GetUnreadNotificationCount,
-			Roles:   userOnly,
+			Method:     http.MethodGet,
+			Path:       "user/notifications/",
+			Handler:    notificationHandler.GetNotificationsByUser,
+			Permission: rbac.NotificationOwn,
 		},
-		// Donor Registration - User Only
 		{
-			Method:  http.MethodPost,
-			Path:    "user/donor-registration",
-			Handler: donorRegistrationHandler.CreateDonorRegistration,
-			Roles:   userOnly,
+			Method:     http.MethodGet,
+			Path:       "user/notifications/:id",
+			Handler:    notificationHandler.GetNotificationByUser,
+			Permission: rbac.NotificationOwn,
 		},
 		{
-			Method:  http.MethodGet,
-			Path:    "user/schedules",
-			Handler: donorScheduleHandler.GetDonorSchedules,
-			Roles:   userOnly,
+			Method:     http.MethodGet,
+			Path:       "user/notifications/count",
+			Handler:    notificationHandler.GetUnreadNotificationCount,
+			Permission: rbac.NotificationOwn,
 		},
+		// Donor Registration - Donor
 		{
-			Method:  http.MethodGet,
-			Path:    "user/schedule/:id",
-			Handler: donorScheduleHandler.GetDonorSchedule,
-			Roles:   userOnly,
+			Method:     http.MethodPost,
+			Path:       "user/donor-registration",
+			Handler:    donorRegistrationHandler.CreateDonorRegistration,
+			Permission: rbac.DonorRegistrationCreate,
 		},
 		{
-			Method:  http.MethodPost,
-			Path:    "user/schedule/",
-			Handler: donorScheduleHandler.CreateDonorSchedule,
-			Roles:   userOnly,
+			Method:     http.MethodGet,
+			Path:       "user/schedules",
+			Handler:    donorScheduleHandler.GetDonorSchedules,
+			Permission: rbac.DonorScheduleOwn,
 		},
 		{
-			Method:  http.MethodPut,
-			Path:    "user/schedule/:id",
-			Handler: donorScheduleHandler.UpdateDonorSchedule,
-			Roles:   userOnly,
+			Method:     http.MethodGet,
+			Path:       "user/schedule/:id",
+			Handler:    donorScheduleHandler.GetDonorSchedule,
+			Permission: rbac.DonorScheduleOwn,
 		},
 		{
-			Method:  http.MethodDelete,
-			Path:    "user/schedule/:id",
-			Handler: donorScheduleHandler.DeleteDonorSchedule,
-			Roles:   userOnly,
+			Method:     http.MethodPost,
+			Path:       "user/schedule/",
+			Handler:    donorScheduleHandler.CreateDonorSchedule,
+			Permission: rbac.DonorScheduleOwn,
 		},
 		{
-			Method:  http.MethodPost,
-			Path:    "user/donation/transaction",
-			Handler: donationHandler.CreateTransaction,
-			Roles:   userOnly,
+			Method:     http.MethodPut,
+			Path:       "user/schedule/:id",
+			Handler:    donorScheduleHandler.UpdateDonorSchedule,
+			Permission: rbac.DonorScheduleOwn,
 		},
 		{
-			Method:  http.MethodGet,
-			Path:    "user/certificates",
-			Handler: certificateHandler.GetByUser,
-			Roles:   userOnly,
+			Method:     http.MethodDelete,
+			Path:       "user/schedule/:id",
+			Handler:    donorScheduleHandler.DeleteDonorSchedule,
+			Permission: rbac.DonorScheduleOwn,
 		},
 		{
-			Method:  http.MethodGet,
-			Path:    "user/certificate/:id",
-			Handler: certificateHandler.GetById,
-			Roles:   userOnly,
+			Method:     http.MethodPost,
+			Path:       "user/donation/transaction",
+			Handler:    donationHandler.CreateTransaction,
+			Permission: rbac.DonationCreate,
 		},
 		{
-			Method:  http.MethodGet,
-			Path:    "user/blood-request",
-			Handler: bloodRequestHandler.GetBloodRequestByUser,
-			Roles:   userOnly,
+			Method:     http.MethodGet,
+			Path:       "user/donations/statement",
+			Handler:    donationHandler.GetStatement,
+			Permission: rbac.DonationCreate,
 		},
 		{
-			Method:  http.MethodPost,
-			Path:    "user/create-blood-request",
-			Handler: bloodRequestHandler.CreateBloodRequest,
-			Roles:   userOnly,
+			Method:     http.MethodGet,
+			Path:       "user/certificates",
+			Handler:    certificateHandler.GetByUser,
+			Permission: rbac.CertificateOwn,
 		},
 		{
-			Method:  http.MethodPut,
-			Path:    "user/update-blood-request/:id",
-			Handler: bloodRequestHandler.UpdateBloodRequest,
-			Roles:   userOnly,
+			Method:     http.MethodGet,
+			Path:       "user/certificate/:id",
+			Handler:    certificateHandler.GetById,
+			Permission: rbac.CertificateOwn,
 		},
 		{
-			Method:  http.MethodGet,
-			Path:    "user/blood-donations",
-			Handler: bloodDonationHandler.GetByUser,
-			Roles:   userOnly,
+			Method:     http.MethodGet,
+			Path:       "user/certificate/:id/pdf",
+			Handler:    certificateHandler.GetPDF,
+			Permission: rbac.CertificateOwn,
 		},
 		{
-			Method:  http.MethodPost,
-			Path:    "user/wallet-address",
-			Handler: userHandler.WalletAddress,
-			Roles:   userOnly,
+			Method:     http.MethodGet,
+			Path:       "user/blood-request",
+			Handler:    bloodRequestHandler.GetBloodRequestByUser,
+			Permission: rbac.BloodRequestOwn,
 		},
-		// Certificate - User Only
 		{
-			Method:  http.MethodGet,
-			Path:    "user/certificates",
-			Handler: certificateHandler.GetByUser,
-			Roles:   userOnly,
+			Method:     http.MethodGet,
+			Path:       "user/blood-request/compatible",
+			Handler:    bloodRequestHandler.GetCompatibleBloodRequests,
+			Permission: rbac.BloodRequestOwn,
+		},
+		{
+			Method:     http.MethodPost,
+			Path:       "user/create-blood-request",
+			Handler:    bloodRequestHandler.CreateBloodRequest,
+			Permission: rbac.BloodRequestOwn,
+		},
+		{
+			Method:     http.MethodPut,
+			Path:       "user/update-blood-request/:id",
+			Handler:    bloodRequestHandler.UpdateBloodRequest,
+			Permission: rbac.BloodRequestOwn,
+		},
+		{
+			Method:     http.MethodGet,
+			Path:       "user/blood-donations",
+			Handler:    bloodDonationHandler.GetByUser,
+			Permission: rbac.BloodDonationOwn,
+		},
+		{
+			Method:     http.MethodPost,
+			Path:       "user/wallet-address/challenge",
+			Handler:    userHandler.WalletChallenge,
+			Permission: rbac.WalletLink,
+		},
+		{
+			Method:     http.MethodPost,
+			Path:       "user/wallet-address",
+			Handler:    userHandler.WalletAddress,
+			Permission: rbac.WalletLink,
+		},
+		// Certificate - Donor
+		{
+			Method:     http.MethodGet,
+			Path:       "user/certificates",
+			Handler:    certificateHandler.GetByUser,
+			Permission: rbac.CertificateOwn,
+		},
+		{
+			Method:     http.MethodPost,
+			Path:       "user/certificate/:id/claim",
+			Handler:    certificateHandler.Claim,
+			Permission: rbac.CertificateOwn,
+		},
+		{
+			Method:     http.MethodGet,
+			Path:       "user/certificate/:id/claims",
+			Handler:    certificateHandler.GetClaims,
+			Permission: rbac.CertificateOwn,
 		},
 		// =============================================
-		// ADMIN ONLY ROUTES
+		// ADMIN ROUTES (permission bawaan role Administrator)
 		// =============================================
-		// Dasboard - Admin Only
+		// Dasboard - Admin
 		{
-			Method:  http.MethodGet,
-			Path:    "admin/dashboard",
-			Handler: dashboardHandler.DashboardAdmin,
-			Roles:   adminOnly,
+			Method:     http.MethodGet,
+			Path:       "admin/dashboard",
+			Handler:    dashboardHandler.DashboardAdmin,
+			Permission: rbac.DashboardAdmin,
 		},
-		// Health Passport - Admin Only
+		// Certificate - Admin
 		{
-			Method:  http.MethodGet,
-			Path:    "admin/health-passports",
-			Handler: healthPassportHandler.GetHealthPassports,
-			Roles:   adminOnly,
+			Method:     http.MethodGet,
+			Path:       "admin/certificates/reconciliation",
+			Handler:    certificateHandler.GetReconciliation,
+			Permission: rbac.CertificateMint,
 		},
+		// Health Passport - Admin
 		{
-			Method:  http.MethodGet,
-			Path:    "admin/health-passport/:id",
-			Handler: healthPassportHandler.GetHealthPassport,
-			Roles:   adminOnly,
+			Method:     http.MethodGet,
+			Path:       "admin/health-passports",
+			Handler:    healthPassportHandler.GetHealthPassports,
+			Permission: rbac.HealthPassportRead,
 		},
 		{
-			Method:  http.MethodPut,
-			Path:    "admin/health-passport/:id",
-			Handler: healthPassportHandler.UpdateStatusHealthPassport,
-			Roles:   adminOnly,
+			Method:     http.MethodGet,
+			Path:       "admin/health-passport/:id",
+			Handler:    healthPassportHandler.GetHealthPassport,
+			Permission: rbac.HealthPassportRead,
 		},
 		{
-			Method:  http.MethodDelete,
-			Path:    "admin/health-passport/:id",
-			Handler: healthPassportHandler.DeleteHealthPassport,
-			Roles:   adminOnly,
+			Method:     http.MethodPut,
+			Path:       "admin/health-passport/:id",
+			Handler:    healthPassportHandler.UpdateStatusHealthPassport,
+			Permission: rbac.HealthPassportVerify,
 		},
-		// Blood Request - Admin Only
 		{
-			Method:  http.MethodGet,
-			Path:    "admin/blood-requests",
-			Handler: bloodRequestHandler.GetBloodRequestsByAdmin,
-			Roles:   adminOnly,
+			Method:     http.MethodDelete,
+			Path:       "admin/health-passport/:id",
+			Handler:    healthPassportHandler.DeleteHealthPassport,
+			Permission: rbac.HealthPassportDelete,
 		},
+		// Blood Request - Admin
 		{
-			Method:  http.MethodPut,
-			Path:    "admin/blood-request/:id",
-			Handler: bloodRequestHandler.StatusBloodRequest,
-			Roles:   adminOnly,
+			Method:     http.MethodGet,
+			Path:       "admin/blood-requests",
+			Handler:    bloodRequestHandler.GetBloodRequestsByAdmin,
+			Permission: rbac.BloodRequestRead,
 		},
-		// Blood Request/Campaign - Admin Only
 		{
-			Method:  http.MethodPost,
-			Path:    "admin-campaign",
-			Handler: bloodRequestHandler.CreateCampaign,
-			Roles:   adminOnly,
+			Method:     http.MethodPut,
+			Path:       "admin/blood-request/:id",
+			Handler:    bloodRequestHandler.StatusBloodRequest,
+			Permission: rbac.BloodRequestVerify,
 		},
 		{
-			Method:  http.MethodPut,
-			Path:    "admin/campaign/:id",
-			Handler: bloodRequestHandler.UpdateCampaign,
-			Roles:   adminOnly,
+			Method:     http.MethodGet,
+			Path:       "admin/blood-request/:id/donors",
+			Handler:    bloodRequestHandler.GetCompatibleDonors,
+			Permission: rbac.BloodRequestRead,
 		},
-		// Notification - Admin Only
+		// Blood Request/Campaign - Admin
 		{
-			Method:  http.MethodGet,
-			Path:    "admin/notifications",
-			Handler: notificationHandler.GetNotifications,
-			Roles:   adminOnly,
+			Method:     http.MethodPost,
+			Path:       "admin-campaign",
+			Handler:    bloodRequestHandler.CreateCampaign,
+			Permission: rbac.CampaignManage,
 		},
 		{
-			Method:  http.MethodGet,
-			Path:    "admin/notification/:id",
-			Handler: notificationHandler.GetNotification,
-			Roles:   adminOnly,
+			Method:     http.MethodPut,
+			Path:       "admin/campaign/:id",
+			Handler:    bloodRequestHandler.UpdateCampaign,
+			Permission: rbac.CampaignManage,
 		},
+		// Fund - Admin
 		{
-			Method:  http.MethodGet,
-			Path:    "admin/notifications/user/:user_id",
-			Handler: notificationHandler.GetNotificationByUserId,
-			Roles:   adminOnly,
+			Method:     http.MethodPost,
+			Path:       "admin/fund",
+			Handler:    fundHandler.Create,
+			Permission: rbac.CampaignManage,
 		},
 		{
-			Method:  http.MethodPost,
-			Path:    "admin/notification",
-			Handler: notificationHandler.CreateNotification,
-			Roles:   adminOnly,
+			Method:     http.MethodPut,
+			Path:       "admin/fund/:id",
+			Handler:    fundHandler.Update,
+			Permission: rbac.CampaignManage,
 		},
-		// User Management - Admin Only
+		// Notification - Admin
 		{
-			Method:  http.MethodGet,
-			Path:    "admin/users",
-			Handler: userHandler.GetUsers,
-			Roles:   adminOnly,
+			Method:     http.MethodGet,
+			Path:       "admin/notifications",
+			Handler:    notificationHandler.GetNotifications,
+			Permission: rbac.NotificationRead,
 		},
 		{
-			Method:  http.MethodGet,
-			Path:    "admin/users/:id",
-			Handler: userHandler.GetUser,
-			Roles:   adminOnly,
+			Method:     http.MethodGet,
+			Path:       "admin/notification/:id",
+			Handler:    notificationHandler.GetNotification,
+			Permission: rbac.NotificationRead,
 		},
 		{
-			Method:  http.MethodDelete,
-			Path:    "admin/users/:id",
-			Handler: userHandler.DeleteUser,
-			Roles:   adminOnly,
+			Method:     http.MethodGet,
+			Path:       "admin/notifications/user/:user_id",
+			Handler:    notificationHandler.GetNotificationByUserId,
+			Permission: rbac.NotificationRead,
 		},
 		{
-			Method:  http.MethodGet,
-			Path:    "blood-donations",
-			Handler: bloodDonationHandler.GetAll,
-			Roles:   adminOnly,
+			Method:     http.MethodPost,
+			Path:       "admin/notification",
+			Handler:    notificationHandler.CreateNotification,
+			Permission: rbac.NotificationCreate,
 		},
+		// User Management - Admin
 		{
-			Method:  http.MethodPut,
-			Path:    "blood-donation/:id/status",
-			Handler: bloodDonationHandler.StatusBloodDonation,
-			Roles:   adminOnly,
+			Method:     http.MethodGet,
+			Path:       "admin/users",
+			Handler:    userHandler.GetUsers,
+			Permission: rbac.UserRead,
 		},
-		// Hospital - Admin Only
 		{
-			Method:  http.MethodPut,
-			Path:    "admin/hospital/:id",
-			Handler: hospitalHandler.Update,
-			Roles:   adminOnly,
+			Method:     http.MethodGet,
+			Path:       "admin/users/:id",
+			Handler:    userHandler.GetUser,
+			Permission: rbac.UserRead,
 		},
 		{
-			Method:  http.MethodDelete,
-			Path:    "admin/hospital/:id",
-			Handler: hospitalHandler.Delete,
-			Roles:   adminOnly,
+			Method:     http.MethodDelete,
+			Path:       "admin/users/:id",
+			Handler:    userHandler.DeleteUser,
+			Permission: rbac.UserDelete,
+		},
+		{
+			Method:     http.MethodGet,
+			Path:       "blood-donations",
+			Handler:    bloodDonationHandler.GetAll,
+			Permission: rbac.BloodDonationManage,
+		},
+		{
+			Method:     http.MethodPut,
+			Path:       "blood-donation/:id/status",
+			Handler:    bloodDonationHandler.StatusBloodDonation,
+			Permission: rbac.BloodDonationVerify,
+		},
+		// Hospital - Admin
+		{
+			Method:     http.MethodPut,
+			Path:       "admin/hospital/:id",
+			Handler:    hospitalHandler.Update,
+			Permission: rbac.HospitalManage,
+		},
+		{
+			Method:     http.MethodDelete,
+			Path:       "admin/hospital/:id",
+			Handler:    hospitalHandler.Delete,
+			Permission: rbac.HospitalManage,
+		},
+		{
+			Method:     http.MethodGet,
+			Path:       "admin/hospital/:id/staff",
+			Handler:    hospitalHandler.GetStaff,
+			Permission: rbac.HospitalManage,
+		},
+		{
+			Method:     http.MethodPost,
+			Path:       "admin/hospital/:id/staff",
+			Handler:    hospitalHandler.AddStaff,
+			Permission: rbac.HospitalManage,
+		},
+		{
+			Method:     http.MethodDelete,
+			Path:       "admin/hospital/:id/staff/:user_id",
+			Handler:    hospitalHandler.RemoveStaff,
+			Permission: rbac.HospitalManage,
+		},
+		{
+			Method:     http.MethodGet,
+			Path:       "admin/donations",
+			Handler:    donationHandler.GetDonations,
+			Permission: rbac.DonationRead,
+		},
+		{
+			Method:     http.MethodGet,
+			Path:       "admin/donations/discrepancies",
+			Handler:    donationHandler.GetDiscrepancies,
+			Permission: rbac.DonationRead,
+		},
+		{
+			Method:     http.MethodGet,
+			Path:       "admin/donation/:id",
+			Handler:    donationHandler.GetDonation,
+			Permission: rbac.DonationRead,
+		},
+		{
+			Method:     http.MethodPost,
+			Path:       "admin/donation/:id/refund",
+			Handler:    donationHandler.RefundDonation,
+			Permission: rbac.DonationRefund,
+		},
+		// Blood Inventory - Admin
+		{
+			Method:     http.MethodGet,
+			Path:       "admin/inventory",
+			Handler:    inventoryHandler.GetStock,
+			Permission: rbac.InventoryRead,
+		},
+		{
+			Method:     http.MethodGet,
+			Path:       "admin/inventory/bags",
+			Handler:    inventoryHandler.GetAll,
+			Permission: rbac.InventoryRead,
+		},
+		{
+			Method:     http.MethodGet,
+			Path:       "admin/inventory/bags/:id",
+			Handler:    inventoryHandler.GetById,
+			Permission: rbac.InventoryRead,
+		},
+		{
+			Method:     http.MethodPost,
+			Path:       "admin/inventory/bags",
+			Handler:    inventoryHandler.Create,
+			Permission: rbac.InventoryManage,
+		},
+		{
+			Method:     http.MethodPut,
+			Path:       "admin/inventory/bags/:id",
+			Handler:    inventoryHandler.Update,
+			Permission: rbac.InventoryManage,
+		},
+		{
+			Method:     http.MethodDelete,
+			Path:       "admin/inventory/bags/:id",
+			Handler:    inventoryHandler.Delete,
+			Permission: rbac.InventoryManage,
+		},
+		// Role & Permission - Admin
+		{
+			Method:     http.MethodGet,
+			Path:       "admin/roles",
+			Handler:    roleHandler.GetRoles,
+			Permission: rbac.RoleManage,
+		},
+		{
+			Method:     http.MethodPost,
+			Path:       "admin/roles",
+			Handler:    roleHandler.CreateRole,
+			Permission: rbac.RoleManage,
+		},
+		{
+			Method:     http.MethodPut,
+			Path:       "admin/roles/:id",
+			Handler:    roleHandler.UpdateRole,
+			Permission: rbac.RoleManage,
+		},
+		{
+			Method:     http.MethodDelete,
+			Path:       "admin/roles/:id",
+			Handler:    roleHandler.DeleteRole,
+			Permission: rbac.RoleManage,
 		},
 		{
-			Method: http.MethodGet,
-			Path:    "admin/donations",
-			Handler: donationHandler.GetDonations,
-			Roles:   adminOnly,
+			Method:     http.MethodGet,
+			Path:       "admin/permissions",
+			Handler:    roleHandler.GetPermissions,
+			Permission: rbac.RoleManage,
 		},
 		{
-			Method: http.MethodGet,
-			Path:    "admin/donation/:id",
-			Handler: donationHandler.GetDonation,
-			Roles:   adminOnly,
+			Method:     http.MethodPut,
+			Path:       "admin/users/:id/role",
+			Handler:    roleHandler.AssignRole,
+			Permission: rbac.RoleManage,
+		},
+		// Audit Log - Admin
+		{
+			Method:     http.MethodGet,
+			Path:       "admin/audit-logs",
+			Handler:    auditLogHandler.GetAll,
+			Permission: rbac.AuditLogRead,
+		},
+		{
+			Method:     http.MethodGet,
+			Path:       "admin/audit-logs/verify",
+			Handler:    auditLogHandler.Verify,
+			Permission: rbac.AuditLogRead,
 		},
 		// =============================================
-		// ALL ROLES ROUTES (Admin & User)
+		// SHARED ROUTES (permission bawaan role Administrator & User)
 		// =============================================
-		// User Profile - All Roles
+		// Session - tanpa permission, semua pengguna yang login
+		{
+			Method:  http.MethodPost,
+			Path:    "logout",
+			Handler: userHandler.Logout,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "logout-all",
+			Handler: userHandler.LogoutAll,
+		},
+		// Verifikasi dua langkah - tanpa permission
+		{
+			Method:  http.MethodGet,
+			Path:    "user/mfa",
+			Handler: userHandler.GetMFAStatus,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "user/mfa/enroll",
+			Handler: userHandler.EnrollMFA,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "user/mfa/activate",
+			Handler: userHandler.ActivateMFA,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "user/mfa/recovery-codes",
+			Handler: userHandler.RegenerateMFARecoveryCodes,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "user/mfa",
+			Handler: userHandler.DisableMFA,
+		},
+		// User Profile - tanpa permission
 		{
 			Method:  http.MethodGet,
 			Path:    "user/profile",
 			Handler: userHandler.GetProfile,
-			Roles:   allRoles,
 		},
 		{
 			Method:  http.MethodPut,
 			Path:    "user/profile",
 			Handler: userHandler.UpdateUser,
-			Roles:   allRoles,
 		},
 		{
 			Method:  http.MethodPost,
 			Path:    "user/profile/picture",
 			Handler: userHandler.UpdateUser,
-			Roles:   allRoles,
 		},
-		// Donor Registration - All Roles
+		// Donor Registration - Shared
 		{
-			Method:  http.MethodGet,
-			Path:    "donor-registrations",
-			Handler: donorRegistrationHandler.GetDonorRegistrations,
-			Roles:   allRoles,
+			Method:     http.MethodGet,
+			Path:       "donor-registrations",
+			Handler:    donorRegistrationHandler.GetDonorRegistrations,
+			Permission: rbac.DonorRegistrationRead,
 		},
 		{
-			Method:  http.MethodGet,
-			Path:    "donor-registration/:id",
-			Handler: donorRegistrationHandler.GetDonorRegistration,
-			Roles:   allRoles,
+			Method:     http.MethodGet,
+			Path:       "donor-registration/:id",
+			Handler:    donorRegistrationHandler.GetDonorRegistration,
+			Permission: rbac.DonorRegistrationRead,
 		},
 		{
-			Method:  http.MethodPut,
-			Path:    "donor-registration/",
-			Handler: donorRegistrationHandler.UpdateDonorRegistration,
-			Roles:   allRoles,
+			Method:     http.MethodPut,
+			Path:       "donor-registration/",
+			Handler:    donorRegistrationHandler.UpdateDonorRegistration,
+			Permission: rbac.DonorRegistrationUpdate,
 		},
-		// Hospital - All Roles
+		// Hospital - Shared
 		{
-			Method:  http.MethodGet,
-			Path:    "hospital",
-			Handler: hospitalHandler.GetAll,
-			Roles:   allRoles,
+			Method:     http.MethodGet,
+			Path:       "hospital",
+			Handler:    hospitalHandler.GetAll,
+			Permission: rbac.HospitalRead,
 		},
 		{
-			Method:  http.MethodGet,
-			Path:    "hospital/:id",
-			Handler: hospitalHandler.GetById,
-			Roles:   allRoles,
+			Method:     http.MethodGet,
+			Path:       "hospital/:id",
+			Handler:    hospitalHandler.GetById,
+			Permission: rbac.HospitalRead,
 		},
 		{
-			Method:  http.MethodPost,
-			Path:    "hospital",
-			Handler: hospitalHandler.Create,
-			Roles:   allRoles,
+			Method:     http.MethodPost,
+			Path:       "hospital",
+			Handler:    hospitalHandler.Create,
+			Permission: rbac.HospitalCreate,
 		},
-		// Blood Donation - All Roles
+		// Blood Donation - Shared
 		{
-			Method:  http.MethodGet,
-			Path:    "blood-donation/:id",
-			Handler: bloodDonationHandler.GetById,
-			Roles:   allRoles,
+			Method:     http.MethodGet,
+			Path:       "blood-donation/:id",
+			Handler:    bloodDonationHandler.GetById,
+			Permission: rbac.BloodDonationRead,
 		},
 		{
-			Method:  http.MethodPost,
-			Path:    "blood-donation",
-			Handler: bloodDonationHandler.Create,
-			Roles:   allRoles,
+			Method:     http.MethodPost,
+			Path:       "blood-donation",
+			Handler:    bloodDonationHandler.Create,
+			Permission: rbac.BloodDonationCreate,
 		},
 		{
-			Method:  http.MethodDelete,
-			Path:    "blood-donation/:id",
-			Handler: bloodDonationHandler.Delete,
-			Roles:   allRoles,
+			Method:     http.MethodDelete,
+			Path:       "blood-donation/:id",
+			Handler:    bloodDonationHandler.Delete,
+			Permission: rbac.BloodDonationDelete,
 		},
 		{
-			Method:  http.MethodDelete,
-			Path:    "campaign/:id",
-			Handler: bloodRequestHandler.DeleteBloodRequest,
-			Roles:   allRoles,
+			Method:     http.MethodDelete,
+			Path:       "campaign/:id",
+			Handler:    bloodRequestHandler.DeleteBloodRequest,
+			Permission: rbac.BloodRequestDelete,
 		},
 	}
 }
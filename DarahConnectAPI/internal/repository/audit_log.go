@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+
+	"gorm.io/gorm"
+)
+
+// auditChainLock adalah kunci advisory PostgreSQL yang menyerialkan penambahan audit log
+// agar setiap baris merujuk hash baris tepat sebelumnya
+const auditChainLock = 7462001
+
+type AuditLogRepository interface {
+	// LockChain harus dipanggil di dalam transaksi; kunci dilepas saat transaksi selesai
+	LockChain(ctx context.Context) error
+	GetLastHash(ctx context.Context) (string, error)
+	Create(ctx context.Context, auditLog *entity.AuditLog) error
+	GetAll(ctx context.Context, req dto.GetAllAuditLogRequest) ([]entity.AuditLog, int64, error)
+	GetAfterId(ctx context.Context, id int64, limit int) ([]entity.AuditLog, error)
+}
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{db}
+}
+
+func (r *auditLogRepository) LockChain(ctx context.Context) error {
+	return dbWithContext(ctx, r.db).Exec("SELECT pg_advisory_xact_lock(?)", auditChainLock).Error
+}
+
+// GetLastHash mengembalikan hash baris terakhir, atau string kosong jika audit log masih kosong
+func (r *auditLogRepository) GetLastHash(ctx context.Context) (string, error) {
+	result := new(entity.AuditLog)
+	err := dbWithContext(ctx, r.db).Select("hash").Order("id DESC").First(result).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return result.Hash, nil
+}
+
+func (r *auditLogRepository) Create(ctx context.Context, auditLog *entity.AuditLog) error {
+	return dbWithContext(ctx, r.db).Create(auditLog).Error
+}
+
+// applyFilters menerapkan filter, sorting, dan pagination ke query GORM
+func (r *auditLogRepository) applyFilters(query *gorm.DB, req dto.GetAllAuditLogRequest) (*gorm.DB, dto.GetAllAuditLogRequest) {
+	if req.ActorId != 0 {
+		query = query.Where("actor_id = ?", req.ActorId)
+	}
+	if req.Action != "" {
+		query = query.Where("action = ?", req.Action)
+	}
+	if req.EntityType != "" {
+		query = query.Where("entity_type = ?", req.EntityType)
+	}
+	if req.EntityId != 0 {
+		query = query.Where("entity_id = ?", req.EntityId)
+	}
+	if req.RequestId != "" {
+		query = query.Where("request_id = ?", req.RequestId)
+	}
+	if req.From != nil {
+		query = query.Where("created_at >= ?", *req.From)
+	}
+	if req.To != nil {
+		query = query.Where("created_at <= ?", *req.To)
+	}
+
+	// Set default values jika tidak ada
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	orderBy := "desc"
+	if req.Order != "" {
+		orderBy = req.Order
+	}
+	query = query.Order("id " + orderBy)
+
+	return query, req
+}
+
+func (r *auditLogRepository) GetAll(ctx context.Context, req dto.GetAllAuditLogRequest) ([]entity.AuditLog, int64, error) {
+	var auditLogs []entity.AuditLog
+	var total int64
+
+	// Hitung total item sebelum pagination
+	dataQuery := dbWithContext(ctx, r.db).Model(&entity.AuditLog{})
+	dataQuery, req = r.applyFilters(dataQuery, req)
+	if err := dataQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Pagination
+	offset := (req.Page - 1) * req.Limit
+	dataQuery = dataQuery.Limit(int(req.Limit)).Offset(int(offset))
+
+	if err := dataQuery.Find(&auditLogs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return auditLogs, total, nil
+}
+
+// GetAfterId mengambil baris berikutnya secara berurutan untuk verifikasi rantai hash
+func (r *auditLogRepository) GetAfterId(ctx context.Context, id int64, limit int) ([]entity.AuditLog, error) {
+	auditLogs := make([]entity.AuditLog, 0)
+	if err := dbWithContext(ctx, r.db).Where("id > ?", id).Order("id ASC").Limit(limit).Find(&auditLogs).Error; err != nil {
+		return nil, err
+	}
+	return auditLogs, nil
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInsufficientStock dikembalikan ketika kantong darah yang tersedia tidak mencukupi
+var ErrInsufficientStock = errors.New("stok kantong darah tidak mencukupi")
+
+type BloodBagRepository interface {
+	Create(ctx context.Context, bloodBag *entity.BloodBag) error
+	GetById(ctx context.Context, id int64) (*entity.BloodBag, error)
+	GetAll(ctx context.Context, req dto.GetAllBloodBagRequest) ([]entity.BloodBag, int64, error)
+	GetByDonationId(ctx context.Context, donationId int64) (*entity.BloodBag, error)
+	GetStock(ctx context.Context, req dto.GetBloodStockRequest) ([]dto.BloodStockResponse, error)
+	Consume(ctx context.Context, hospitalId int64, bloodType string, candidates map[string][]string, quantity int64, requestId int64) ([]entity.BloodBag, error)
+	ExpireOutdated(ctx context.Context, now time.Time) (int64, error)
+	Update(ctx context.Context, bloodBag *entity.BloodBag) error
+	Delete(ctx context.Context, bloodBag *entity.BloodBag) error
+}
+
+type bloodBagRepository struct {
+	db *gorm.DB
+}
+
+func NewBloodBagRepository(db *gorm.DB) BloodBagRepository {
+	return &bloodBagRepository{db}
+}
+
+func (r *bloodBagRepository) applyFilters(query *gorm.DB, req dto.GetAllBloodBagRequest) (*gorm.DB, dto.GetAllBloodBagRequest) {
+	if req.HospitalId != 0 {
+		query = query.Where("hospital_id = ?", req.HospitalId)
+	}
+
+	if req.BloodType != "" {
+		query = query.Where("UPPER(blood_type) = ?", strings.ToUpper(req.BloodType))
+	}
+
+	if req.Component != "" {
+		query = query.Where("LOWER(component) = ?", strings.ToLower(req.Component))
+	}
+
+	// Kantong yang sudah lewat expiry_date dianggap expired walaupun worker belum menandainya
+	switch status := strings.ToLower(req.Status); status {
+	case "":
+	case "available":
+		query = query.Where("LOWER(status) = ? AND expiry_date > ?", status, time.Now())
+	case "expired":
+		query = query.Where("(LOWER(status) = ? OR (LOWER(status) = ? AND expiry_date <= ?))", status, "available", time.Now())
+	default:
+		query = query.Where("LOWER(status) = ?", status)
+	}
+
+	// Set default values jika tidak ada
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	// Sorting
+	sortBy := "created_at"
+	if req.Sort != "" {
+		sortBy = req.Sort
+	}
+
+	orderBy := "desc"
+	if req.Order != "" {
+		orderBy = req.Order
+	}
+
+	query = query.Order(sortBy + " " + orderBy)
+
+	return query, req
+}
+
+func (r *bloodBagRepository) Create(ctx context.Context, bloodBag *entity.BloodBag) error {
+	return dbWithContext(ctx, r.db).Create(bloodBag).Error
+}
+
+func (r *bloodBagRepository) GetById(ctx context.Context, id int64) (*entity.BloodBag, error) {
+	result := new(entity.BloodBag)
+	if err := dbWithContext(ctx, r.db).Where("id = ?", id).Preload("Hospital").First(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *bloodBagRepository) GetAll(ctx context.Context, req dto.GetAllBloodBagRequest) ([]entity.BloodBag, int64, error) {
+	var bloodBags []entity.BloodBag
+	var total int64
+
+	// Hitung total item sebelum pagination
+	dataQuery := dbWithContext(ctx, r.db).Model(&entity.BloodBag{}).Preload("Hospital")
+	dataQuery, req = r.applyFilters(dataQuery, req)
+	if err := dataQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Pagination
+	offset := (req.Page - 1) * req.Limit
+	dataQuery = dataQuery.Limit(int(req.Limit)).Offset(int(offset))
+
+	if err := dataQuery.Find(&bloodBags).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return bloodBags, total, nil
+}
+
+func (r *bloodBagRepository) GetByDonationId(ctx context.Context, donationId int64) (*entity.BloodBag, error) {
+	result := new(entity.BloodBag)
+	if err := dbWithContext(ctx, r.db).Where("donation_id = ?", donationId).First(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetStock mengelompokkan kantong darah yang masih tersedia dan belum kedaluwarsa per rumah sakit, golongan darah, dan komponen
+func (r *bloodBagRepository) GetStock(ctx context.Context, req dto.GetBloodStockRequest) ([]dto.BloodStockResponse, error) {
+	result := make([]dto.BloodStockResponse, 0)
+
+	query := dbWithContext(ctx, r.db).Model(&entity.BloodBag{}).
+		Select("blood_bags.hospital_id, hospitals.name AS hospital_name, blood_bags.blood_type, blood_bags.component, "+
+			"COUNT(blood_bags.id) AS units, COALESCE(SUM(blood_bags.volume_ml), 0) AS total_volume_ml, MIN(blood_bags.expiry_date) AS nearest_expiry").
+		Joins("LEFT JOIN hospitals ON hospitals.id = blood_bags.hospital_id").
+		Where("blood_bags.status = ? AND blood_bags.expiry_date > ?", "available", time.Now())
+
+	if req.HospitalId != 0 {
+		query = query.Where("blood_bags.hospital_id = ?", req.HospitalId)
+	}
+
+	if req.BloodType != "" {
+		query = query.Where("UPPER(blood_bags.blood_type) = ?", strings.ToUpper(req.BloodType))
+	}
+
+	if req.Component != "" {
+		query = query.Where("LOWER(blood_bags.component) = ?", strings.ToLower(req.Component))
+	}
+
+	err := query.Group("blood_bags.hospital_id, hospitals.name, blood_bags.blood_type, blood_bags.component").
+		Order("hospitals.name asc, blood_bags.blood_type asc, blood_bags.component asc").
+		Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Consume mengambil kantong darah yang tersedia dan menandainya sebagai terpakai untuk permintaan
+// darah tertentu dalam satu transaksi. candidates berisi golongan darah yang cocok per komponen.
+// Golongan darah yang sama persis didahulukan, lalu kedaluwarsa terdekat (FEFO).
+func (r *bloodBagRepository) Consume(ctx context.Context, hospitalId int64, bloodType string, candidates map[string][]string, quantity int64, requestId int64) ([]entity.BloodBag, error) {
+	bloodBags := make([]entity.BloodBag, 0)
+
+	err := dbWithContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		candidateQuery := tx.Session(&gorm.Session{NewDB: true})
+		for component, bloodTypes := range candidates {
+			candidateQuery = candidateQuery.Or("component = ? AND blood_type IN ?", component, bloodTypes)
+		}
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("hospital_id = ? AND status = ? AND expiry_date > ?", hospitalId, "available", time.Now()).
+			Where(candidateQuery).
+			Order(clause.OrderBy{Expression: clause.Expr{
+				SQL:                "CASE WHEN blood_type = ? THEN 0 ELSE 1 END, expiry_date ASC",
+				Vars:               []interface{}{bloodType},
+				WithoutParentheses: true,
+			}}).
+			Limit(int(quantity)).
+			Find(&bloodBags).Error
+		if err != nil {
+			return err
+		}
+
+		if int64(len(bloodBags)) < quantity {
+			return ErrInsufficientStock
+		}
+
+		ids := make([]int64, 0, len(bloodBags))
+		for _, bloodBag := range bloodBags {
+			ids = append(ids, bloodBag.Id)
+		}
+
+		usedAt := time.Now()
+		return tx.Model(&entity.BloodBag{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":     "used",
+			"request_id": requestId,
+			"used_at":    usedAt,
+			"updated_at": usedAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bloodBags, nil
+}
+
+// ExpireOutdated menandai kantong darah yang sudah melewati tanggal kedaluwarsa
+func (r *bloodBagRepository) ExpireOutdated(ctx context.Context, now time.Time) (int64, error) {
+	result := dbWithContext(ctx, r.db).Model(&entity.BloodBag{}).
+		Where("status = ? AND expiry_date <= ?", "available", now).
+		Updates(map[string]interface{}{"status": "expired", "updated_at": now})
+	return result.RowsAffected, result.Error
+}
+
+func (r *bloodBagRepository) Update(ctx context.Context, bloodBag *entity.BloodBag) error {
+	return dbWithContext(ctx, r.db).Model(bloodBag).Updates(bloodBag).Error
+}
+
+func (r *bloodBagRepository) Delete(ctx context.Context, bloodBag *entity.BloodBag) error {
+	return dbWithContext(ctx, r.db).Delete(bloodBag).Error
+}
@@ -3,22 +3,27 @@ package repository
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BloodDonationRepository interface {
 	Create(ctx context.Context, bloodDonation *entity.BloodDonation) error
 	GetById(ctx context.Context, id int64) (*entity.BloodDonation, error)
+	// GetByIdForUpdate mengunci baris donasi darah sampai transaksi selesai
+	GetByIdForUpdate(ctx context.Context, id int64) (*entity.BloodDonation, error)
 	GetAll(ctx context.Context, req dto.GetAllBloodDonationRequest) ([]entity.BloodDonation, int64, error)
 	GetByUserId(ctx context.Context, userId int64, req dto.GetAllBloodDonationRequest) ([]entity.BloodDonation, int64, error)
 	Update(ctx context.Context, bloodDonation *entity.BloodDonation) error
 	Delete(ctx context.Context, bloodDonation *entity.BloodDonation) error
 	GetByUser(ctx context.Context, userId int64) ([]entity.BloodDonation, error)
 	CountSuccessDonation(ctx context.Context) (int64, error)
+	GetCompletedByUserIds(ctx context.Context, userIds []int64, since time.Time) ([]entity.BloodDonation, error)
 }
 
 type bloodDonationRepository struct {
@@ -79,12 +84,20 @@ func (r *bloodDonationRepository) applyFilters(query *gorm.DB, req dto.GetAllBlo
 }
 
 func (r *bloodDonationRepository) Create(ctx context.Context, bloodDonation *entity.BloodDonation) error {
-	return r.db.WithContext(ctx).Create(bloodDonation).Error
+	return dbWithContext(ctx, r.db).Create(bloodDonation).Error
 }
 
 func (r *bloodDonationRepository) GetById(ctx context.Context, id int64) (*entity.BloodDonation, error) {
 	result := new(entity.BloodDonation)
-	if err := r.db.WithContext(ctx).Where("id = ?", id).Preload("Hospital").Preload("Registration").First(result).Error; err != nil {
+	if err := scopeHospital(ctx, dbWithContext(ctx, r.db), "hospital_id").Where("id = ?", id).Preload("Hospital").Preload("Registration").First(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *bloodDonationRepository) GetByIdForUpdate(ctx context.Context, id int64) (*entity.BloodDonation, error) {
+	result := new(entity.BloodDonation)
+	if err := scopeHospital(ctx, dbWithContext(ctx, r.db), "hospital_id").Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(result).Error; err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -95,7 +108,7 @@ func (r *bloodDonationRepository) GetAll(ctx context.Context, req dto.GetAllBloo
 	var total int64
 
 	// Hitung total item sebelum pagination
-	dataQuery := r.db.WithContext(ctx).Model(&entity.BloodDonation{}).Preload("Hospital").Preload("Registration")
+	dataQuery := scopeHospital(ctx, dbWithContext(ctx, r.db), "hospital_id").Model(&entity.BloodDonation{}).Preload("Hospital").Preload("Registration")
 	dataQuery, req = r.applyFilters(dataQuery, req)
 	if err := dataQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -117,7 +130,7 @@ func (r *bloodDonationRepository) GetByUserId(ctx context.Context, UserId int64,
 	var total int64
 
 	// Hitung total item sebelum pagination
-	dataQuery := r.db.WithContext(ctx).Model(&entity.BloodDonation{}).Where("user_id = ?", UserId).Preload("Hospital").Preload("Registration")
+	dataQuery := scopeHospital(ctx, dbWithContext(ctx, r.db), "hospital_id").Model(&entity.BloodDonation{}).Where("user_id = ?", UserId).Preload("Hospital").Preload("Registration")
 	dataQuery, req = r.applyFilters(dataQuery, req)
 	if err := dataQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -135,16 +148,16 @@ func (r *bloodDonationRepository) GetByUserId(ctx context.Context, UserId int64,
 }
 
 func (r *bloodDonationRepository) Update(ctx context.Context, bloodDonation *entity.BloodDonation) error {
-	return r.db.WithContext(ctx).Model(bloodDonation).Updates(bloodDonation).Error
+	return dbWithContext(ctx, r.db).Model(bloodDonation).Updates(bloodDonation).Error
 }
 
 func (r *bloodDonationRepository) Delete(ctx context.Context, bloodDonation *entity.BloodDonation) error {
-	return r.db.WithContext(ctx).Model(&entity.BloodDonation{}).Delete(bloodDonation).Error
+	return dbWithContext(ctx, r.db).Model(&entity.BloodDonation{}).Delete(bloodDonation).Error
 }
 
 func (r *bloodDonationRepository) GetByUser(ctx context.Context, userId int64) ([]entity.BloodDonation, error) {
 	result := make([]entity.BloodDonation, 0)
-	if err := r.db.WithContext(ctx).Where("user_id = ?", userId).Find(&result).Error; err != nil {
+	if err := scopeHospital(ctx, dbWithContext(ctx, r.db), "hospital_id").Where("user_id = ?", userId).Find(&result).Error; err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -152,8 +165,24 @@ func (r *bloodDonationRepository) GetByUser(ctx context.Context, userId int64) (
 
 func (r *bloodDonationRepository) CountSuccessDonation(ctx context.Context) (int64, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&entity.BloodDonation{}).Where("status = ?", "completed").Count(&count).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&entity.BloodDonation{}).Where("status = ?", "completed").Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
-}
\ No newline at end of file
+}
+
+// GetCompletedByUserIds mengambil donor darah yang sudah selesai milik beberapa pengguna sejak waktu tertentu
+// Sengaja tidak dibatasi rumah sakit karena kelayakan dihitung dari riwayat donor di semua rumah sakit.
+func (r *bloodDonationRepository) GetCompletedByUserIds(ctx context.Context, userIds []int64, since time.Time) ([]entity.BloodDonation, error) {
+	result := make([]entity.BloodDonation, 0)
+	if len(userIds) == 0 {
+		return result, nil
+	}
+	if err := dbWithContext(ctx, r.db).
+		Where("user_id IN ? AND status = ? AND donation_date >= ?", userIds, "completed", since).
+		Order("donation_date asc").
+		Find(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
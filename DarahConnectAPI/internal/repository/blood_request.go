@@ -8,6 +8,7 @@ import (
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BloodRequestRepository interface {
@@ -31,6 +32,11 @@ type bloodRequestRepository struct {
 	db *gorm.DB
 }
 
+// hospitalDistanceSQL menghitung jarak rumah sakit dari permintaan darah tanpa join,
+// sehingga tidak bentrok dengan join hospitals pada filter pencarian.
+var hospitalDistanceSQL = "(SELECT " + distanceSQL("geo_hospitals.latitude", "geo_hospitals.longitude") +
+	" FROM hospitals AS geo_hospitals WHERE geo_hospitals.id = blood_requests.hospital_id)"
+
 func NewBloodRequestRepository(db *gorm.DB) BloodRequestRepository {
 	return &bloodRequestRepository{db}
 }
@@ -48,8 +54,11 @@ func (r *bloodRequestRepository) applyFilters(query *gorm.DB, req dto.GetAllBloo
 		query = query.Where("LOWER(event_type) = ?", req.EventType)
 	}
 
-	if req.BloodType != "" {
-		query = query.Where("blood_type LIKE ?", "%"+req.BloodType+"%")
+	// Filter golongan darah: daftar hasil pencocokan ABO/Rh, atau golongan darah yang sama persis
+	if len(req.BloodTypes) > 0 {
+		query = query.Where("UPPER(blood_requests.blood_type) IN ?", req.BloodTypes)
+	} else if req.BloodType != "" {
+		query = query.Where("UPPER(blood_requests.blood_type) = ?", strings.ToUpper(strings.TrimSpace(req.BloodType)))
 	}
 
 	if req.MaxQuantity > 0 && req.MinQuantity > 0 {
@@ -70,6 +79,11 @@ func (r *bloodRequestRepository) applyFilters(query *gorm.DB, req dto.GetAllBloo
 				"%"+search+"%", "%"+search+"%", "%"+search+"%", "%"+search+"%", "%"+search+"%")
 	}
 
+	// Filter berdasarkan radius rumah sakit dari titik lokasi
+	if req.Lat != nil && req.Lng != nil && req.RadiusKm > 0 {
+		query = query.Where(hospitalDistanceSQL+" <= ?", append(distanceVars(*req.Lat, *req.Lng), req.RadiusKm)...)
+	}
+
 	// Set default values jika tidak ada
 	if req.Page <= 0 {
 		req.Page = 1
@@ -90,18 +104,36 @@ func (r *bloodRequestRepository) applyFilters(query *gorm.DB, req dto.GetAllBloo
 		orderBy = req.Order
 	}
 
+	// Urutkan berdasarkan rumah sakit terdekat jika lokasi diberikan dan tidak ada sorting lain
+	if req.Lat != nil && req.Lng != nil && req.Sort == "" {
+		query = query.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                hospitalDistanceSQL + " ASC",
+			Vars:               distanceVars(*req.Lat, *req.Lng),
+			WithoutParentheses: true,
+		}})
+		return query, req
+	}
+
 	query = query.Order(sortBy + " " + orderBy)
 
 	return query, req
 }
 
+// selectDistance menambahkan kolom distance (jarak rumah sakit) ke hasil query jika lokasi diberikan
+func (r *bloodRequestRepository) selectDistance(query *gorm.DB, req dto.GetAllBloodRequestRequest) *gorm.DB {
+	if req.Lat == nil || req.Lng == nil {
+		return query
+	}
+	return query.Select("blood_requests.*, "+hospitalDistanceSQL+" AS distance", distanceVars(*req.Lat, *req.Lng)...)
+}
+
 func (r *bloodRequestRepository) Create(ctx context.Context, bloodRequest *entity.BloodRequest) error {
-	return r.db.WithContext(ctx).Create(bloodRequest).Error
+	return dbWithContext(ctx, r.db).Create(bloodRequest).Error
 }
 
 func (r *bloodRequestRepository) GetById(ctx context.Context, id int64) (*entity.BloodRequest, error) {
 	result := new(entity.BloodRequest)
-	if err := r.db.WithContext(ctx).Model(&entity.BloodRequest{}).Where("id = ?", id).Preload("User").Preload("Hospital").First(result).Error; err != nil {
+	if err := scopeHospital(ctx, dbWithContext(ctx, r.db), "hospital_id").Model(&entity.BloodRequest{}).Where("id = ?", id).Preload("User").Preload("Hospital").First(result).Error; err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -112,7 +144,7 @@ func (r *bloodRequestRepository) GetAllBloodRequest(ctx context.Context, req dto
 	var total int64
 
 	// Hitung total item sebelum pagination
-	dataQuery := r.db.WithContext(ctx).Model(&entity.BloodRequest{}).Where("event_type = ? AND status = ?", "blood_request", "verified").Preload("User").Preload("Hospital")
+	dataQuery := scopeHospital(ctx, dbWithContext(ctx, r.db), "hospital_id").Model(&entity.BloodRequest{}).Where("event_type = ? AND status = ?", "blood_request", "verified").Preload("User").Preload("Hospital")
 	dataQuery, req = r.applyFilters(dataQuery, req)
 	if err := dataQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -120,7 +152,7 @@ func (r *bloodRequestRepository) GetAllBloodRequest(ctx context.Context, req dto
 
 	// Pagination
 	offset := (req.Page - 1) * req.Limit
-	dataQuery = dataQuery.Limit(int(req.Limit)).Offset(int(offset))
+	dataQuery = r.selectDistance(dataQuery, req).Limit(int(req.Limit)).Offset(int(offset))
 
 	if err := dataQuery.Find(&bloodRequest).Error; err != nil {
 		return nil, 0, err
@@ -134,7 +166,7 @@ func (r *bloodRequestRepository) GetAllAdminBloodRequest(ctx context.Context, re
 	var total int64
 
 	// Hitung total item sebelum pagination
-	dataQuery := r.db.WithContext(ctx).Model(&entity.BloodRequest{}).Preload("User").Preload("Hospital")
+	dataQuery := scopeHospital(ctx, dbWithContext(ctx, r.db), "hospital_id").Model(&entity.BloodRequest{}).Preload("User").Preload("Hospital")
 	dataQuery, req = r.applyFilters(dataQuery, req)
 	if err := dataQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -142,7 +174,7 @@ func (r *bloodRequestRepository) GetAllAdminBloodRequest(ctx context.Context, re
 
 	// Pagination
 	offset := (req.Page - 1) * req.Limit
-	dataQuery = dataQuery.Limit(int(req.Limit)).Offset(int(offset))
+	dataQuery = r.selectDistance(dataQuery, req).Limit(int(req.Limit)).Offset(int(offset))
 
 	if err := dataQuery.Find(&bloodRequest).Error; err != nil {
 		return nil, 0, err
@@ -156,7 +188,7 @@ func (r *bloodRequestRepository) GetAllCampaign(ctx context.Context, req dto.Get
 	var total int64
 
 	// Hitung total item sebelum pagination
-	dataQuery := r.db.WithContext(ctx).Model(&entity.BloodRequest{}).Where("event_type = ?", "campaign").Preload("User").Preload("Hospital")
+	dataQuery := scopeHospital(ctx, dbWithContext(ctx, r.db), "hospital_id").Model(&entity.BloodRequest{}).Where("event_type = ?", "campaign").Preload("User").Preload("Hospital")
 	dataQuery, req = r.applyFilters(dataQuery, req)
 	if err := dataQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -164,7 +196,7 @@ func (r *bloodRequestRepository) GetAllCampaign(ctx context.Context, req dto.Get
 
 	// Pagination
 	offset := (req.Page - 1) * req.Limit
-	dataQuery = dataQuery.Limit(int(req.Limit)).Offset(int(offset))
+	dataQuery = r.selectDistance(dataQuery, req).Limit(int(req.Limit)).Offset(int(offset))
 
 	if err := dataQuery.Find(&bloodRequest).Error; err != nil {
 		return nil, 0, err
@@ -178,7 +210,7 @@ func (r *bloodRequestRepository) GetByUserId(ctx context.Context, userId int64,
 	var total int64
 
 	// Hitung total item sebelum pagination
-	dataQuery := r.db.WithContext(ctx).Model(&entity.BloodRequest{}).Preload("User").Preload("Hospital").Where("user_id = ?", userId)
+	dataQuery := scopeHospital(ctx, dbWithContext(ctx, r.db), "hospital_id").Model(&entity.BloodRequest{}).Preload("User").Preload("Hospital").Where("user_id = ?", userId)
 	dataQuery, req = r.applyFilters(dataQuery, req)
 	if err := dataQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -186,7 +218,7 @@ func (r *bloodRequestRepository) GetByUserId(ctx context.Context, userId int64,
 
 	// Pagination
 	offset := (req.Page - 1) * req.Limit
-	dataQuery = dataQuery.Limit(int(req.Limit)).Offset(int(offset))
+	dataQuery = r.selectDistance(dataQuery, req).Limit(int(req.Limit)).Offset(int(offset))
 
 	if err := dataQuery.Find(&bloodRequest).Error; err != nil {
 		return nil, 0, err
@@ -200,7 +232,7 @@ func (r *bloodRequestRepository) GetByHospitalId(ctx context.Context, hospitalId
 	var total int64
 
 	// Hitung total item sebelum pagination
-	dataQuery := r.db.WithContext(ctx).Model(&entity.BloodRequest{}).Preload("User").Preload("Hospital").Where("hospital_id = ?", hospitalId)
+	dataQuery := scopeHospital(ctx, dbWithContext(ctx, r.db), "hospital_id").Model(&entity.BloodRequest{}).Preload("User").Preload("Hospital").Where("hospital_id = ?", hospitalId)
 	dataQuery, req = r.applyFilters(dataQuery, req)
 	if err := dataQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -208,7 +240,7 @@ func (r *bloodRequestRepository) GetByHospitalId(ctx context.Context, hospitalId
 
 	// Pagination
 	offset := (req.Page - 1) * req.Limit
-	dataQuery = dataQuery.Limit(int(req.Limit)).Offset(int(offset))
+	dataQuery = r.selectDistance(dataQuery, req).Limit(int(req.Limit)).Offset(int(offset))
 
 	if err := dataQuery.Find(&bloodRequest).Error; err != nil {
 		return nil, 0, err
@@ -219,16 +251,16 @@ func (r *bloodRequestRepository) GetByHospitalId(ctx context.Context, hospitalId
 
 
 func (r *bloodRequestRepository) Update(ctx context.Context, bloodRequest *entity.BloodRequest) error {
-	return r.db.WithContext(ctx).Model(bloodRequest).Updates(bloodRequest).Error
+	return dbWithContext(ctx, r.db).Model(bloodRequest).Updates(bloodRequest).Error
 }
 
 func (r *bloodRequestRepository) Delete(ctx context.Context, bloodRequest *entity.BloodRequest) error {
-	return r.db.WithContext(ctx).Delete(bloodRequest).Error
+	return dbWithContext(ctx, r.db).Delete(bloodRequest).Error
 }
 
 func (r *bloodRequestRepository) CountBloodRequest(ctx context.Context, status string, eventType string) (int64, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&entity.BloodRequest{}).Where("status = ? AND event_type = ?", status, eventType).Count(&count).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&entity.BloodRequest{}).Where("status = ? AND event_type = ?", status, eventType).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
@@ -236,7 +268,7 @@ func (r *bloodRequestRepository) CountBloodRequest(ctx context.Context, status s
 
 func (r *bloodRequestRepository) CountTotal(ctx context.Context, eventType string) (int64, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&entity.BloodRequest{}).Where("event_type = ?", eventType).Count(&count).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&entity.BloodRequest{}).Where("event_type = ?", eventType).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
@@ -244,7 +276,7 @@ func (r *bloodRequestRepository) CountTotal(ctx context.Context, eventType strin
 
 func (r *bloodRequestRepository) CountCampaignActive(ctx context.Context, status string, eventType string) (int64, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&entity.BloodRequest{}).Where("status != ? AND event_type = ?", status, eventType).Count(&count).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&entity.BloodRequest{}).Where("status != ? AND event_type = ?", status, eventType).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
@@ -252,7 +284,7 @@ func (r *bloodRequestRepository) CountCampaignActive(ctx context.Context, status
 
 func (r *bloodRequestRepository) CountAllTotal(ctx context.Context) (int64, error){
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&entity.BloodRequest{}).Count(&count).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&entity.BloodRequest{}).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
@@ -260,7 +292,7 @@ func (r *bloodRequestRepository) CountAllTotal(ctx context.Context) (int64, erro
 
 func (r *bloodRequestRepository) CountByMonth(ctx context.Context, month string, year string) (int64, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&entity.BloodRequest{}).Where("EXTRACT(MONTH FROM created_at) = ? AND EXTRACT(YEAR FROM created_at) = ?", month, year).Count(&count).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&entity.BloodRequest{}).Where("EXTRACT(MONTH FROM created_at) = ? AND EXTRACT(YEAR FROM created_at) = ?", month, year).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
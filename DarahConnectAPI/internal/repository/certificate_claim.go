@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type CertificateClaimRepository interface {
+	Create(ctx context.Context, claim *entity.CertificateClaim) error
+	GetPendingByCertificateId(ctx context.Context, certificateId int64) (*entity.CertificateClaim, error)
+	GetByCertificateId(ctx context.Context, certificateId int64) ([]entity.CertificateClaim, error)
+	Update(ctx context.Context, claim *entity.CertificateClaim) error
+}
+
+type certificateClaimRepository struct {
+	db *gorm.DB
+}
+
+func NewCertificateClaimRepository(db *gorm.DB) CertificateClaimRepository {
+	return &certificateClaimRepository{db}
+}
+
+func (r *certificateClaimRepository) Create(ctx context.Context, claim *entity.CertificateClaim) error {
+	return dbWithContext(ctx, r.db).Create(claim).Error
+}
+
+// GetPendingByCertificateId mengembalikan nil tanpa error jika sertifikat tidak sedang diklaim
+func (r *certificateClaimRepository) GetPendingByCertificateId(ctx context.Context, certificateId int64) (*entity.CertificateClaim, error) {
+	result := new(entity.CertificateClaim)
+	err := dbWithContext(ctx, r.db).Where("certificate_id = ? AND status = ?", certificateId, entity.ClaimStatusPending).First(result).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *certificateClaimRepository) GetByCertificateId(ctx context.Context, certificateId int64) ([]entity.CertificateClaim, error) {
+	claims := make([]entity.CertificateClaim, 0)
+	if err := dbWithContext(ctx, r.db).Where("certificate_id = ?", certificateId).Order("created_at DESC").Find(&claims).Error; err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (r *certificateClaimRepository) Update(ctx context.Context, claim *entity.CertificateClaim) error {
+	return dbWithContext(ctx, r.db).Model(claim).Select("*").Omit("created_at").Updates(claim).Error
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CertificateRepository interface {
@@ -16,6 +17,9 @@ type CertificateRepository interface {
 	GetAll(ctx context.Context, req dto.GetAllCertificateRequest) ([]entity.Certificate, int64, error)
 	GetByUser(ctx context.Context, userId int64, req dto.GetAllCertificateRequest) ([]entity.Certificate, int64, error)
 	GetByUserid(ctx context.Context, userId int64) ([]entity.Certificate, error)
+	GetByChainStatus(ctx context.Context, chainStatus string, limit int) ([]entity.Certificate, error)
+	GetByCertificateNumber(ctx context.Context, certificateNumber string) (*entity.Certificate, error)
+	GetDetailById(ctx context.Context, id int64) (*entity.Certificate, error)
 	Update(ctx context.Context, certificate *entity.Certificate) error
 	Delete(ctx context.Context, certificate *entity.Certificate) error
 }
@@ -69,19 +73,45 @@ func (r *certificateRepository) applyFilters(query *gorm.DB, req dto.GetAllCerti
 
 func (r *certificateRepository) GetByUserid(ctx context.Context, userId int64) ([]entity.Certificate, error) {
 	var certificates []entity.Certificate
-	if err := r.db.WithContext(ctx).Where("user_id = ?", userId).Find(&certificates).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("user_id = ?", userId).Find(&certificates).Error; err != nil {
+		return nil, err
+	}
+	return certificates, nil
+}
+
+// GetByChainStatus mengambil sertifikat dengan status blockchain tertentu, yang paling lama dikirim lebih dulu
+func (r *certificateRepository) GetByChainStatus(ctx context.Context, chainStatus string, limit int) ([]entity.Certificate, error) {
+	var certificates []entity.Certificate
+	if err := dbWithContext(ctx, r.db).Where("chain_status = ?", chainStatus).Order("submitted_at ASC").Limit(limit).Find(&certificates).Error; err != nil {
 		return nil, err
 	}
 	return certificates, nil
 }
 
 func (r *certificateRepository) Create(ctx context.Context, certificate *entity.Certificate) error {
-	return r.db.WithContext(ctx).Create(certificate).Error
+	return dbWithContext(ctx, r.db).Create(certificate).Error
 }
 
 func (r *certificateRepository) GetById(ctx context.Context, id int64) (*entity.Certificate, error) {
 	result := new(entity.Certificate)
-	if err := r.db.WithContext(ctx).Where("id = ?", id).First(result).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("id = ?", id).First(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *certificateRepository) GetByCertificateNumber(ctx context.Context, certificateNumber string) (*entity.Certificate, error) {
+	result := new(entity.Certificate)
+	if err := dbWithContext(ctx, r.db).Where("certificate_number = ?", certificateNumber).Preload("User").Preload("Donation.Hospital").First(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetDetailById mengambil sertifikat beserta pendonor dan rumah sakit tempat donor
+func (r *certificateRepository) GetDetailById(ctx context.Context, id int64) (*entity.Certificate, error) {
+	result := new(entity.Certificate)
+	if err := dbWithContext(ctx, r.db).Where("id = ?", id).Preload("User").Preload("Donation.Hospital").First(result).Error; err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -92,7 +122,7 @@ func (r *certificateRepository) GetAll(ctx context.Context, req dto.GetAllCertif
 	var total int64
 
 	// Hitung total item sebelum pagination
-	dataQuery := r.db.WithContext(ctx).Model(&entity.Certificate{}).Preload("User")
+	dataQuery := dbWithContext(ctx, r.db).Model(&entity.Certificate{}).Preload("User")
 	if req.UserId != "" {
 		dataQuery = dataQuery.Where("user_id = ?", req.UserId)
 	}
@@ -118,7 +148,7 @@ func (r *certificateRepository) GetByUser(ctx context.Context, userId int64, req
 	var total int64
 
 	// Hitung total item sebelum pagination
-	dataQuery := r.db.WithContext(ctx).Model(&entity.Certificate{}).Preload("User").Where("user_id = ?", userId)
+	dataQuery := dbWithContext(ctx, r.db).Model(&entity.Certificate{}).Preload("User").Where("user_id = ?", userId)
 	dataQuery, req = r.applyFilters(dataQuery, req)
 	if err := dataQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -140,7 +170,7 @@ func (r *certificateRepository) GetByDonationId(ctx context.Context, donationId
 	var total int64
 
 	// Hitung total item sebelum pagination
-	dataQuery := r.db.WithContext(ctx).Model(&entity.Certificate{}).Where("donation_id = ?", donationId)
+	dataQuery := dbWithContext(ctx, r.db).Model(&entity.Certificate{}).Where("donation_id = ?", donationId)
 	dataQuery, req = r.applyFilters(dataQuery, req)
 	if err := dataQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -158,9 +188,10 @@ func (r *certificateRepository) GetByDonationId(ctx context.Context, donationId
 }
 
 func (r *certificateRepository) Update(ctx context.Context, certificate *entity.Certificate) error {
-	return r.db.WithContext(ctx).Model(certificate).Updates(certificate).Error
+	// Select("*") agar tx_nonce dan raw_tx bisa dikosongkan saat sertifikat diantrekan ulang
+	return dbWithContext(ctx, r.db).Model(certificate).Select("*").Omit("created_at", clause.Associations).Updates(certificate).Error
 }
 
 func (r *certificateRepository) Delete(ctx context.Context, certificate *entity.Certificate) error {
-	return r.db.WithContext(ctx).Delete(certificate).Error
+	return dbWithContext(ctx, r.db).Delete(certificate).Error
 }
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ChainIndexRepository interface {
+	GetSyncState(ctx context.Context, name string) (*entity.ChainSyncState, error)
+	SaveSyncState(ctx context.Context, state *entity.ChainSyncState) error
+	SaveCertificateEvents(ctx context.Context, events []entity.ChainCertificateEvent) error
+	LinkCertificates(ctx context.Context) error
+	GetOnChainOnly(ctx context.Context) ([]dto.ChainReconciliationItem, error)
+	GetDbOnly(ctx context.Context, lastBlock int64, createdBefore time.Time) ([]dto.ChainReconciliationItem, error)
+	GetMismatched(ctx context.Context) ([]dto.ChainReconciliationItem, error)
+	GetDuplicates(ctx context.Context) ([]dto.ChainReconciliationItem, error)
+}
+
+// activeEvent mengecualikan event mint ke escrow yang sudah digantikan oleh klaim pendonor
+const activeEvent = "e.tx_hash NOT IN (SELECT previous_tx_hash FROM public.certificate_claims WHERE status = 'completed')"
+
+// recipientAddress adalah alamat pendonor yang seharusnya tercatat di kontrak
+const recipientAddress = "COALESCE(NULLIF(c.recipient_address, ''), u.wallet_address)"
+
+type chainIndexRepository struct {
+	db *gorm.DB
+}
+
+func NewChainIndexRepository(db *gorm.DB) ChainIndexRepository {
+	return &chainIndexRepository{db}
+}
+
+// GetSyncState mengembalikan nil tanpa error jika indexer belum pernah berjalan
+func (r *chainIndexRepository) GetSyncState(ctx context.Context, name string) (*entity.ChainSyncState, error) {
+	result := new(entity.ChainSyncState)
+	if err := dbWithContext(ctx, r.db).Where("name = ?", name).First(result).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *chainIndexRepository) SaveSyncState(ctx context.Context, state *entity.ChainSyncState) error {
+	return dbWithContext(ctx, r.db).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoUpdates: clause.AssignmentColumns([]string{"last_block", "updated_at"})}).
+		Create(state).Error
+}
+
+// SaveCertificateEvents menyimpan event, event yang sudah pernah disimpan (tx_hash dan log_index sama) diabaikan
+func (r *chainIndexRepository) SaveCertificateEvents(ctx context.Context, events []entity.ChainCertificateEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return dbWithContext(ctx, r.db).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "tx_hash"}, {Name: "log_index"}}, DoNothing: true}).
+		Create(&events).Error
+}
+
+// LinkCertificates menghubungkan event dengan sertifikat bernomor sama dan mengisi onchain_id
+// sertifikat yang belum punya, memakai event paling awal jika nomor yang sama di-mint berkali-kali.
+func (r *chainIndexRepository) LinkCertificates(ctx context.Context) error {
+	db := dbWithContext(ctx, r.db)
+	if err := db.Exec(`UPDATE public.chain_certificate_events e SET certificate_id = c.id
+		FROM public.certificates c
+		WHERE e.certificate_id IS NULL AND c.certificate_number = e.certificate_number`).Error; err != nil {
+		return err
+	}
+	return db.Exec(`UPDATE public.certificates c SET onchain_id = (
+			SELECT e.onchain_id FROM public.chain_certificate_events e
+			WHERE e.certificate_id = c.id AND ` + activeEvent + ` ORDER BY e.block_number, e.log_index LIMIT 1)
+		WHERE c.onchain_id IS NULL
+		AND EXISTS (SELECT 1 FROM public.chain_certificate_events e WHERE e.certificate_id = c.id AND ` + activeEvent + `)`).Error
+}
+
+func (r *chainIndexRepository) GetOnChainOnly(ctx context.Context) ([]dto.ChainReconciliationItem, error) {
+	result := make([]dto.ChainReconciliationItem, 0)
+	err := dbWithContext(ctx, r.db).Table("public.chain_certificate_events e").
+		Select("e.certificate_number, e.onchain_id, e.tx_hash, e.block_number, e.donor_address AS onchain_donor_address").
+		Where("e.certificate_id IS NULL").
+		Order("e.block_number, e.log_index").
+		Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetDbOnly mengambil sertifikat tanpa event SertifikatDibuat. Sertifikat yang dibuat setelah
+// createdBefore atau ditambang setelah lastBlock belum bisa dinilai sehingga dilewati.
+func (r *chainIndexRepository) GetDbOnly(ctx context.Context, lastBlock int64, createdBefore time.Time) ([]dto.ChainReconciliationItem, error) {
+	result := make([]dto.ChainReconciliationItem, 0)
+	err := dbWithContext(ctx, r.db).Table("public.certificates c").
+		Select("c.id AS certificate_id, c.certificate_number, c.onchain_id, c.digital_signature AS tx_hash, c.block_number, c.chain_status, "+recipientAddress+" AS db_donor_address").
+		Joins("LEFT JOIN public.users u ON u.id = c.user_id").
+		Where("NOT EXISTS (SELECT 1 FROM public.chain_certificate_events e WHERE e.certificate_id = c.id)").
+		Where("c.created_at < ?", createdBefore).
+		Where("c.block_number IS NULL OR c.block_number <= ?", lastBlock).
+		Order("c.created_at").
+		Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *chainIndexRepository) GetMismatched(ctx context.Context) ([]dto.ChainReconciliationItem, error) {
+	result := make([]dto.ChainReconciliationItem, 0)
+	err := dbWithContext(ctx, r.db).Table("public.chain_certificate_events e").
+		Select("c.id AS certificate_id, c.certificate_number, e.onchain_id, e.tx_hash, e.block_number, c.chain_status, e.donor_address AS onchain_donor_address, " + recipientAddress + " AS db_donor_address").
+		Joins("JOIN public.certificates c ON c.id = e.certificate_id").
+		Joins("JOIN public.users u ON u.id = c.user_id").
+		Where(activeEvent).
+		Where("LOWER(e.donor_address) <> LOWER(COALESCE(" + recipientAddress + ", ''))").
+		Order("e.block_number, e.log_index").
+		Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *chainIndexRepository) GetDuplicates(ctx context.Context) ([]dto.ChainReconciliationItem, error) {
+	result := make([]dto.ChainReconciliationItem, 0)
+	err := dbWithContext(ctx, r.db).Table("public.chain_certificate_events e").
+		Select("e.certificate_id, e.certificate_number, e.onchain_id, e.tx_hash, e.block_number, e.donor_address AS onchain_donor_address").
+		Where(activeEvent).
+		Where("e.certificate_number IN (SELECT certificate_number FROM public.chain_certificate_events e WHERE " + activeEvent + " GROUP BY certificate_number HAVING COUNT(*) > 1)").
+		Order("e.certificate_number, e.block_number, e.log_index").
+		Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -3,18 +3,31 @@ package repository
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type DonationsRepository interface {
 	Create(ctx context.Context, donation *entity.Donation) error
-	Update(ctx context.Context, orderId int64, donation *entity.Donation) error		
+	Update(ctx context.Context, orderId string, donation *entity.Donation) error		
 	GetById(ctx context.Context, id int64) (*entity.Donation, error)
+	// GetByOrderIdForUpdate mengunci baris donasi sampai transaksi selesai
+	GetByOrderIdForUpdate(ctx context.Context, orderId string) (*entity.Donation, error)
 	GetAllDonation(ctx context.Context, req dto.GetAllDonation) ([]entity.Donation, int64, error)
+	// GetPendingBefore mengambil donasi pending yang dibuat sebelum waktu tertentu, urut id
+	// setelah afterId
+	GetPendingBefore(ctx context.Context, createdBefore time.Time, afterId int64, limit int) ([]entity.Donation, error)
+	// GetBloodRequestProgress dan GetFundProgress menjumlahkan donasi yang sudah dibayar untuk target tersebut
+	GetBloodRequestProgress(ctx context.Context, bloodRequestId int64) (*entity.FundraisingProgress, error)
+	GetFundProgress(ctx context.Context, fundId int64) (*entity.FundraisingProgress, error)
+	// GetSuccessfulByUser mengambil donasi berstatus success atau partially_refunded milik
+	// pengguna yang dibayar pada rentang [from, to), urut waktu pembayaran
+	GetSuccessfulByUser(ctx context.Context, userId int64, from, to time.Time) ([]entity.Donation, error)
 }
 
 type donationsRepository struct {
@@ -30,6 +43,12 @@ func (r *donationsRepository) applyFilters(query *gorm.DB, req dto.GetAllDonatio
 	if req.OrderId != "" {
 		query = query.Where("order_id = ?", req.OrderId)
 	}
+	if req.BloodRequestId != 0 {
+		query = query.Where("blood_request_id = ?", req.BloodRequestId)
+	}
+	if req.FundId != 0 {
+		query = query.Where("fund_id = ?", req.FundId)
+	}
 	// Filter berdasarkan Status
 	if req.Status != "" {
 		query = query.Where("LOWER(status) = ?", req.Status)
@@ -66,12 +85,12 @@ func (r *donationsRepository) applyFilters(query *gorm.DB, req dto.GetAllDonatio
 }
 
 func (r *donationsRepository) Create(ctx context.Context, donation *entity.Donation) error {
-	return r.db.WithContext(ctx).Create(donation).Error
+	return dbWithContext(ctx, r.db).Create(donation).Error
 }
 
 func (r *donationsRepository) GetById(ctx context.Context, id int64) (*entity.Donation, error) {
 	result := new(entity.Donation)
-	if err := r.db.WithContext(ctx).Where("id = ?", id).Preload("User").First(result).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("id = ?", id).Preload("User").First(result).Error; err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -81,7 +100,7 @@ func (r *donationsRepository) GetAllDonation(ctx context.Context, req dto.GetAll
 	donations := make([]entity.Donation, 0)
 	var total int64
 
-	dataQuery := r.db.WithContext(ctx).Model(&entity.Donation{}).Preload("User")
+	dataQuery := dbWithContext(ctx, r.db).Model(&entity.Donation{}).Preload("User")
 	dataQuery, req = r.applyFilters(dataQuery, req)
 	if err := dataQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -98,6 +117,52 @@ func (r *donationsRepository) GetAllDonation(ctx context.Context, req dto.GetAll
 }
 
 
-func (r *donationsRepository) Update(ctx context.Context, orderId int64, donation *entity.Donation) error {
-	return r.db.WithContext(ctx).Where("order_id = ?", orderId).Model(donation).Updates(donation).Error
+func (r *donationsRepository) Update(ctx context.Context, orderId string, donation *entity.Donation) error {
+	return dbWithContext(ctx, r.db).Where("order_id = ?", orderId).Model(donation).Updates(donation).Error
+}
+
+func (r *donationsRepository) GetByOrderIdForUpdate(ctx context.Context, orderId string) (*entity.Donation, error) {
+	result := new(entity.Donation)
+	if err := dbWithContext(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_id = ?", orderId).First(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *donationsRepository) GetPendingBefore(ctx context.Context, createdBefore time.Time, afterId int64, limit int) ([]entity.Donation, error) {
+	donations := make([]entity.Donation, 0)
+	if err := dbWithContext(ctx, r.db).Where("status = ? AND created_at < ? AND id > ?", "pending", createdBefore, afterId).
+		Order("id ASC").Limit(limit).Find(&donations).Error; err != nil {
+		return nil, err
+	}
+	return donations, nil
+}
+
+func (r *donationsRepository) GetBloodRequestProgress(ctx context.Context, bloodRequestId int64) (*entity.FundraisingProgress, error) {
+	return r.getProgress(ctx, "blood_request_id", bloodRequestId)
+}
+
+func (r *donationsRepository) GetFundProgress(ctx context.Context, fundId int64) (*entity.FundraisingProgress, error) {
+	return r.getProgress(ctx, "fund_id", fundId)
+}
+
+// getProgress menghitung donasi berhasil, termasuk yang baru direfund sebagian
+func (r *donationsRepository) getProgress(ctx context.Context, column string, id int64) (*entity.FundraisingProgress, error) {
+	result := new(entity.FundraisingProgress)
+	if err := dbWithContext(ctx, r.db).Model(&entity.Donation{}).
+		Select("COALESCE(SUM(amount), 0) AS collected_amount, COUNT(DISTINCT user_id) AS donor_count").
+		Where(column+" = ? AND status IN ?", id, []string{"success", "partially_refunded"}).
+		Scan(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *donationsRepository) GetSuccessfulByUser(ctx context.Context, userId int64, from, to time.Time) ([]entity.Donation, error) {
+	donations := make([]entity.Donation, 0)
+	if err := dbWithContext(ctx, r.db).Where("user_id = ? AND status IN ? AND transaction_time >= ? AND transaction_time < ?", userId, []string{"success", "partially_refunded"}, from, to).
+		Order("transaction_time ASC, id ASC").Find(&donations).Error; err != nil {
+		return nil, err
+	}
+	return donations, nil
 }
@@ -70,12 +70,12 @@ func (r *donorRegistrationRepository) applyFilters(query *gorm.DB, req dto.GetAl
 }
 
 func (r *donorRegistrationRepository) Create(ctx context.Context, donorRegistration *entity.DonorRegistration) error {
-	return r.db.WithContext(ctx).Create(donorRegistration).Error
+	return dbWithContext(ctx, r.db).Create(donorRegistration).Error
 }
 
 func (r *donorRegistrationRepository) GetById(ctx context.Context, id int64) (*entity.DonorRegistration, error) {
 	result := new(entity.DonorRegistration)
-	if err := r.db.WithContext(ctx).Where("id = ?", id).First(result).Error; err != nil {
+	if err := scopeHospitalByRequest(ctx, dbWithContext(ctx, r.db), "request_id").Where("id = ?", id).First(result).Error; err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -86,7 +86,7 @@ func (r *donorRegistrationRepository) GetAll(ctx context.Context, req dto.GetAll
 	var total int64
 
 	// Hitung total item sebelum pagination
-	dataQuery := r.db.WithContext(ctx).Model(&entity.DonorRegistration{}).Preload("User").Preload("BloodRequest")
+	dataQuery := scopeHospitalByRequest(ctx, dbWithContext(ctx, r.db), "request_id").Model(&entity.DonorRegistration{}).Preload("User").Preload("BloodRequest")
 	dataQuery, req = r.applyFilters(dataQuery, req)
 	if err := dataQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -107,7 +107,7 @@ func (r *donorRegistrationRepository) GetAllByUserId(ctx context.Context, userId
 	var donorRegistration []entity.DonorRegistration
 	var total int64
 
-	dataQuery := r.db.WithContext(ctx).Model(&entity.DonorRegistration{}).Where("user_id = ?", userId).Preload("User")
+	dataQuery := scopeHospitalByRequest(ctx, dbWithContext(ctx, r.db), "request_id").Model(&entity.DonorRegistration{}).Where("user_id = ?", userId).Preload("User")
 	dataQuery, req = r.applyFilters(dataQuery, req)
 	if err := dataQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -126,7 +126,7 @@ func (r *donorRegistrationRepository) GetAllByUserId(ctx context.Context, userId
 
 func (r *donorRegistrationRepository) GetByRequestId(ctx context.Context, requestId int64, userId int64) (*entity.DonorRegistration, error) {
 	result := new(entity.DonorRegistration)
-	if err := r.db.WithContext(ctx).Where("request_id = ?", requestId).Where("user_id", userId).First(result).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("request_id = ?", requestId).Where("user_id", userId).First(result).Error; err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -137,7 +137,7 @@ func (r *donorRegistrationRepository) GetAllByScheduleId(ctx context.Context, sc
 	var donorRegistration []entity.DonorRegistration
 	var total int64
 
-	dataQuery := r.db.WithContext(ctx).Model(&entity.DonorRegistration{}).Where("schedule_id = ?", scheduleId).Preload("User")
+	dataQuery := scopeHospitalByRequest(ctx, dbWithContext(ctx, r.db), "request_id").Model(&entity.DonorRegistration{}).Where("schedule_id = ?", scheduleId).Preload("User")
 	dataQuery, req = r.applyFilters(dataQuery, req)
 	if err := dataQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -156,16 +156,16 @@ func (r *donorRegistrationRepository) GetAllByScheduleId(ctx context.Context, sc
 
 func (r *donorRegistrationRepository) GetByUserId(ctx context.Context, userId int64, req dto.GetAllDonorRegistrationRequest) ([]entity.DonorRegistration, error) {
 	var donorRegistration []entity.DonorRegistration
-	if err := r.db.WithContext(ctx).Where("user_id = ?", userId).Find(&donorRegistration).Error; err != nil {
+	if err := scopeHospitalByRequest(ctx, dbWithContext(ctx, r.db), "request_id").Where("user_id = ?", userId).Find(&donorRegistration).Error; err != nil {
 		return nil, err
 	}
 	return donorRegistration, nil
 }
 
 func (r *donorRegistrationRepository) Update(ctx context.Context, donorRegistration *entity.DonorRegistration) error {
-	return r.db.WithContext(ctx).Model(donorRegistration).Updates(donorRegistration).Error
+	return dbWithContext(ctx, r.db).Model(donorRegistration).Updates(donorRegistration).Error
 }
 
 func (r *donorRegistrationRepository) Delete(ctx context.Context, donorRegistration *entity.DonorRegistration) error {
-	return r.db.WithContext(ctx).Delete(donorRegistration).Error
+	return dbWithContext(ctx, r.db).Delete(donorRegistration).Error
 }
@@ -30,7 +30,7 @@ func NewDonorScheduleRepository(db *gorm.DB) DonorScheduleRepository {
 
 func (r *donorScheduleRepository) GetByRequestId(ctx context.Context, requestId int64) (*entity.DonorSchedule, error) {
 	result := new(entity.DonorSchedule)
-	if err := r.db.WithContext(ctx).Where("request_id = ?", requestId).First(result).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("request_id = ?", requestId).First(result).Error; err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -81,12 +81,12 @@ func (r *donorScheduleRepository) applyFilters(query *gorm.DB, req dto.GetAllDon
 }
 
 func (r *donorScheduleRepository) Create(ctx context.Context, donorSchedule *entity.DonorSchedule) error {
-	return r.db.WithContext(ctx).Create(donorSchedule).Error
+	return dbWithContext(ctx, r.db).Create(donorSchedule).Error
 }
 
 func (r *donorScheduleRepository) GetById(ctx context.Context, id int64) (*entity.DonorSchedule, error) {
 	result := new(entity.DonorSchedule)
-	if err := r.db.WithContext(ctx).Where("id = ?", id).First(result).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("id = ?", id).First(result).Error; err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -96,7 +96,7 @@ func (r *donorScheduleRepository) GetAll(ctx context.Context, UserId int64, req
 	var donorSchedule []entity.DonorSchedule
 	var total int64
 
-	dataQuery := r.db.WithContext(ctx).Model(&entity.DonorSchedule{}).Where("user_id = ?", UserId).Preload("Hospital").Preload("BloodRequest.Hospital")
+	dataQuery := dbWithContext(ctx, r.db).Model(&entity.DonorSchedule{}).Where("user_id = ?", UserId).Preload("Hospital").Preload("BloodRequest.Hospital")
 	dataQuery, req = r.applyFilters(dataQuery, req)
 	if err := dataQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -114,16 +114,16 @@ func (r *donorScheduleRepository) GetAll(ctx context.Context, UserId int64, req
 }
 
 func (r *donorScheduleRepository) Update(ctx context.Context, donorSchedule *entity.DonorSchedule) error {
-	return r.db.WithContext(ctx).Model(donorSchedule).Updates(donorSchedule).Error
+	return dbWithContext(ctx, r.db).Model(donorSchedule).Updates(donorSchedule).Error
 }
 
 func (r *donorScheduleRepository) Delete(ctx context.Context, donorSchedule *entity.DonorSchedule) error {
-	return r.db.WithContext(ctx).Delete(donorSchedule).Error
+	return dbWithContext(ctx, r.db).Delete(donorSchedule).Error
 }
 
 func (r *donorScheduleRepository) Validate(ctx context.Context, requestId int64, userId int64) (*entity.DonorSchedule, error) {
 	result := new(entity.DonorSchedule)
-	if err := r.db.WithContext(ctx).Where("request_id = ? AND user_id = ?", requestId, userId).First(result).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("request_id = ? AND user_id = ?", requestId, userId).First(result).Error; err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/rbac"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EmergencyAlertRepository interface {
+	FindEligibleDonors(ctx context.Context, filter dto.EmergencyDonorFilter) ([]dto.EmergencyDonor, error)
+	Create(ctx context.Context, alert *entity.EmergencyAlert) (bool, error)
+}
+
+type emergencyAlertRepository struct {
+	db *gorm.DB
+}
+
+func NewEmergencyAlertRepository(db *gorm.DB) EmergencyAlertRepository {
+	return &emergencyAlertRepository{db}
+}
+
+// FindEligibleDonors mencari calon pendonor dengan golongan darah cocok, health passport aktif,
+// tanpa donor selesai setelah LastDonationBefore, berada dalam radius rumah sakit, belum menerima notifikasi untuk
+// permintaan yang sama, dan belum mencapai batas notifikasi harian. Hasil diurutkan dari yang terdekat.
+// Pendonor dikenali dari health passport aktif, bukan dari role, sehingga pengguna dengan role dari
+// database seperti Hospital Staff tetap menerima notifikasi; hanya akun Administrator yang dilewati.
+func (r *emergencyAlertRepository) FindEligibleDonors(ctx context.Context, filter dto.EmergencyDonorFilter) ([]dto.EmergencyDonor, error) {
+	result := make([]dto.EmergencyDonor, 0)
+	distance := distanceSQL("users.latitude", "users.longitude")
+	now := time.Now()
+
+	err := dbWithContext(ctx, r.db).Model(&entity.User{}).
+		Select("users.id, users.name, users.email, users.gender, users.birth_date, users.blood_type, "+distance+" AS distance_km", distanceVars(filter.Lat, filter.Lng)...).
+		Where("users.role <> ? AND users.id <> ?", rbac.RoleAdministrator, filter.ExcludeUserId).
+		Where("UPPER(users.blood_type) IN ?", filter.BloodTypes).
+		Where("users.latitude IS NOT NULL AND users.longitude IS NOT NULL").
+		Where(distance+" <= ?", append(distanceVars(filter.Lat, filter.Lng), filter.RadiusKm)...).
+		Where("EXISTS (SELECT 1 FROM health_passports hp WHERE hp.user_id = users.id AND hp.status = ? AND hp.expiry_date > ?)", "active", now).
+		Where("NOT EXISTS (SELECT 1 FROM blood_donations bd WHERE bd.user_id = users.id AND bd.status = ? AND bd.donation_date > ?)", "completed", filter.LastDonationBefore).
+		Where("NOT EXISTS (SELECT 1 FROM emergency_alerts ea WHERE ea.user_id = users.id AND ea.request_id = ?)", filter.RequestId).
+		Where("(SELECT COUNT(*) FROM emergency_alerts ea WHERE ea.user_id = users.id AND ea.created_at > ?) < ?", filter.AlertedSince, filter.DailyLimit).
+		Order("distance_km asc").
+		Limit(filter.Limit).
+		Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Create menyimpan notifikasi darurat. Mengembalikan false jika pendonor sudah pernah
+// dinotifikasi untuk permintaan darah yang sama (dedup lewat unique constraint).
+func (r *emergencyAlertRepository) Create(ctx context.Context, alert *entity.EmergencyAlert) (bool, error) {
+	result := dbWithContext(ctx, r.db).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "request_id"}, {Name: "user_id"}}, DoNothing: true}).
+		Omit(clause.Associations).
+		Create(alert)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+
+	"gorm.io/gorm"
+)
+
+type FundRepository interface {
+	Create(ctx context.Context, fund *entity.Fund) error
+	Update(ctx context.Context, fund *entity.Fund) error
+	GetById(ctx context.Context, id int64) (*entity.Fund, error)
+	GetAll(ctx context.Context, req dto.GetAllFundRequest) ([]entity.Fund, int64, error)
+}
+
+type fundRepository struct {
+	db *gorm.DB
+}
+
+func NewFundRepository(db *gorm.DB) FundRepository {
+	return &fundRepository{db}
+}
+
+func (r *fundRepository) Create(ctx context.Context, fund *entity.Fund) error {
+	return dbWithContext(ctx, r.db).Create(fund).Error
+}
+
+// Update menyimpan seluruh kolom agar deadline bisa dikosongkan kembali
+func (r *fundRepository) Update(ctx context.Context, fund *entity.Fund) error {
+	return dbWithContext(ctx, r.db).Save(fund).Error
+}
+
+func (r *fundRepository) GetById(ctx context.Context, id int64) (*entity.Fund, error) {
+	result := new(entity.Fund)
+	if err := dbWithContext(ctx, r.db).Where("id = ?", id).First(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *fundRepository) applyFilters(query *gorm.DB, req dto.GetAllFundRequest) (*gorm.DB, dto.GetAllFundRequest) {
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.Search != "" {
+		query = query.Where("LOWER(title) LIKE ?", "%"+strings.ToLower(req.Search)+"%")
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	return query.Order("created_at DESC, id DESC"), req
+}
+
+func (r *fundRepository) GetAll(ctx context.Context, req dto.GetAllFundRequest) ([]entity.Fund, int64, error) {
+	funds := make([]entity.Fund, 0)
+	var total int64
+
+	dataQuery := dbWithContext(ctx, r.db).Model(&entity.Fund{})
+	dataQuery, req = r.applyFilters(dataQuery, req)
+	if err := dataQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	dataQuery = dataQuery.Limit(int(req.Limit)).Offset(int(offset))
+
+	if err := dataQuery.Find(&funds).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return funds, total, nil
+}
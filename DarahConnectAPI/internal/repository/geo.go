@@ -0,0 +1,19 @@
+package repository
+
+import "fmt"
+
+const earthRadiusKm = 6371.0
+
+// distanceSQL menghasilkan ekspresi SQL Haversine (dalam kilometer) antara kolom koordinat
+// dan sebuah titik. Placeholder diisi dengan distanceVars.
+func distanceSQL(latColumn, lngColumn string) string {
+	return fmt.Sprintf(
+		"(2 * %.1f * ASIN(SQRT(POWER(SIN(RADIANS(%s - ?) / 2), 2) + COS(RADIANS(?)) * COS(RADIANS(%s)) * POWER(SIN(RADIANS(%s - ?) / 2), 2))))",
+		earthRadiusKm, latColumn, latColumn, lngColumn,
+	)
+}
+
+// distanceVars mengembalikan nilai placeholder untuk ekspresi distanceSQL
+func distanceVars(lat, lng float64) []interface{} {
+	return []interface{}{lat, lat, lng}
+}
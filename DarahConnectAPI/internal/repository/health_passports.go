@@ -8,6 +8,7 @@ import (
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type HealthPassportRepository interface {
@@ -67,12 +68,15 @@ func (r *healthPassportRepository) applyFilters(query *gorm.DB, req dto.GetAllHe
 }
 
 func (r *healthPassportRepository) Create(ctx context.Context, healthPassport *entity.HealthPassport) error {
-	return r.db.WithContext(ctx).Create(healthPassport).Error
+	return dbWithContext(ctx, r.db).Create(healthPassport).Error
 }
 
 func (r *healthPassportRepository) GetById(ctx context.Context, id int64) (*entity.HealthPassport, error) {
 	result := new(entity.HealthPassport)
-	if err := r.db.WithContext(ctx).Where("id = ?", id).Preload("User").First(result).Error; err != nil {
+	// Riwayat skrining ditampilkan terbaru lebih dulu untuk peninjauan admin
+	if err := dbWithContext(ctx, r.db).Where("id = ?", id).Preload("User").
+		Preload("Screenings", func(db *gorm.DB) *gorm.DB { return db.Order("created_at desc") }).
+		First(result).Error; err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -82,7 +86,7 @@ func (r *healthPassportRepository) GetAll(ctx context.Context, req dto.GetAllHea
 	var healthPassports []entity.HealthPassport
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&entity.HealthPassport{}).Preload("User")
+	query := dbWithContext(ctx, r.db).Model(&entity.HealthPassport{}).Preload("User")
 	query, req = r.applyFilters(query, req)
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -102,16 +106,17 @@ func (r *healthPassportRepository) GetAll(ctx context.Context, req dto.GetAllHea
 
 func (r *healthPassportRepository) GetByUserId(ctx context.Context, userId int64) (*entity.HealthPassport, error) {
 	result := new(entity.HealthPassport)
-	if err := r.db.WithContext(ctx).Where("user_id = ?", userId).Preload("User").First(&result).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("user_id = ?", userId).Preload("User").First(&result).Error; err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
 func (r *healthPassportRepository) Update(ctx context.Context, healthPassport *entity.HealthPassport) error {
-	return r.db.WithContext(ctx).Model(healthPassport).Updates(healthPassport).Error
+	// Select("*") agar deferred_until bisa dikosongkan kembali
+	return dbWithContext(ctx, r.db).Model(healthPassport).Select("*").Omit("created_at", clause.Associations).Updates(healthPassport).Error
 }
 
 func (r *healthPassportRepository) Delete(ctx context.Context, healthPassport *entity.HealthPassport) error {
-	return r.db.WithContext(ctx).Delete(healthPassport).Error
+	return dbWithContext(ctx, r.db).Delete(healthPassport).Error
 }
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type HealthScreeningRepository interface {
+	Create(ctx context.Context, healthScreening *entity.HealthScreening) error
+	GetLatestByPassportId(ctx context.Context, healthPassportId int64) (*entity.HealthScreening, error)
+	Update(ctx context.Context, healthScreening *entity.HealthScreening) error
+}
+
+type healthScreeningRepository struct {
+	db *gorm.DB
+}
+
+func NewHealthScreeningRepository(db *gorm.DB) HealthScreeningRepository {
+	return &healthScreeningRepository{db}
+}
+
+func (r *healthScreeningRepository) Create(ctx context.Context, healthScreening *entity.HealthScreening) error {
+	return dbWithContext(ctx, r.db).Create(healthScreening).Error
+}
+
+func (r *healthScreeningRepository) GetLatestByPassportId(ctx context.Context, healthPassportId int64) (*entity.HealthScreening, error) {
+	result := new(entity.HealthScreening)
+	if err := dbWithContext(ctx, r.db).Where("health_passport_id = ?", healthPassportId).Order("created_at desc").First(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *healthScreeningRepository) Update(ctx context.Context, healthScreening *entity.HealthScreening) error {
+	return dbWithContext(ctx, r.db).Model(healthScreening).Updates(healthScreening).Error
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type HospitalRepository interface {
@@ -45,6 +46,11 @@ func (r *hospitalRepository) applyFilters(query *gorm.DB, req dto.GetAllHospital
 			"%"+search+"%", "%"+search+"%", "%"+search+"%", "%"+search+"%", "%"+search+"%", "%"+search+"%")
 	}
 
+	// Filter berdasarkan radius dari titik lokasi
+	if req.Lat != nil && req.Lng != nil && req.RadiusKm > 0 {
+		query = query.Where(distanceSQL("latitude", "longitude")+" <= ?", append(distanceVars(*req.Lat, *req.Lng), req.RadiusKm)...)
+	}
+
 	// Set default values jika tidak ada
 	if req.Page <= 0 {
 		req.Page = 1
@@ -65,18 +71,36 @@ func (r *hospitalRepository) applyFilters(query *gorm.DB, req dto.GetAllHospital
 		orderBy = req.Order
 	}
 
+	// Urutkan berdasarkan jarak terdekat jika lokasi diberikan dan tidak ada sorting lain
+	if req.Lat != nil && req.Lng != nil && req.Sort == "" {
+		query = query.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                distanceSQL("latitude", "longitude") + " ASC",
+			Vars:               distanceVars(*req.Lat, *req.Lng),
+			WithoutParentheses: true,
+		}})
+		return query, req
+	}
+
 	query = query.Order(sortBy + " " + orderBy)
 
 	return query, req
 }
 
+// selectDistance menambahkan kolom distance ke hasil query jika lokasi diberikan
+func (r *hospitalRepository) selectDistance(query *gorm.DB, req dto.GetAllHospitalRequest) *gorm.DB {
+	if req.Lat == nil || req.Lng == nil {
+		return query
+	}
+	return query.Select("hospitals.*, "+distanceSQL("latitude", "longitude")+" AS distance", distanceVars(*req.Lat, *req.Lng)...)
+}
+
 func (r *hospitalRepository) Create(ctx context.Context, hospital *entity.Hospital) error {
-	return r.db.WithContext(ctx).Create(&hospital).Error
+	return dbWithContext(ctx, r.db).Create(&hospital).Error
 }
 
 func (r *hospitalRepository) GetById(ctx context.Context, id int64) (*entity.Hospital, error) {
 	result := new(entity.Hospital)
-	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&result).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("id = ?", id).First(&result).Error; err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -87,7 +111,7 @@ func (r *hospitalRepository) GetAll(ctx context.Context, req dto.GetAllHospitalR
 	var total int64
 
 	// Hitung total item sebelum pagination
-	dataQuery := r.db.WithContext(ctx).Model(&entity.Hospital{})
+	dataQuery := dbWithContext(ctx, r.db).Model(&entity.Hospital{})
 	dataQuery, req = r.applyFilters(dataQuery, req)
 	if err := dataQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -95,7 +119,7 @@ func (r *hospitalRepository) GetAll(ctx context.Context, req dto.GetAllHospitalR
 
 	// Pagination
 	offset := (req.Page - 1) * req.Limit
-	dataQuery = dataQuery.Limit(int(req.Limit)).Offset(int(offset))
+	dataQuery = r.selectDistance(dataQuery, req).Limit(int(req.Limit)).Offset(int(offset))
 
 	if err := dataQuery.Find(&hospital).Error; err != nil {
 		return nil, 0, err
@@ -105,9 +129,9 @@ func (r *hospitalRepository) GetAll(ctx context.Context, req dto.GetAllHospitalR
 }
 
 func (r *hospitalRepository) Update(ctx context.Context, hospital *entity.Hospital) error {
-	return r.db.WithContext(ctx).Model(hospital).Updates(hospital).Error
+	return dbWithContext(ctx, r.db).Model(hospital).Updates(hospital).Error
 }
 
 func (r *hospitalRepository) Delete(ctx context.Context, hospital *entity.Hospital) error {
-	return r.db.WithContext(ctx).Delete(hospital).Error
+	return dbWithContext(ctx, r.db).Delete(hospital).Error
 }
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type HospitalStaffRepository interface {
+	GetHospitalIds(ctx context.Context, userId int64) ([]int64, error)
+	GetByHospitalId(ctx context.Context, hospitalId int64) ([]entity.HospitalStaff, error)
+	Create(ctx context.Context, staff *entity.HospitalStaff) error
+	Delete(ctx context.Context, hospitalId, userId int64) (bool, error)
+}
+
+type hospitalStaffRepository struct {
+	db *gorm.DB
+}
+
+func NewHospitalStaffRepository(db *gorm.DB) HospitalStaffRepository {
+	return &hospitalStaffRepository{db}
+}
+
+func (r *hospitalStaffRepository) GetHospitalIds(ctx context.Context, userId int64) ([]int64, error) {
+	hospitalIds := make([]int64, 0)
+	if err := dbWithContext(ctx, r.db).Model(&entity.HospitalStaff{}).
+		Where("user_id = ?", userId).
+		Pluck("hospital_id", &hospitalIds).Error; err != nil {
+		return nil, err
+	}
+	return hospitalIds, nil
+}
+
+func (r *hospitalStaffRepository) GetByHospitalId(ctx context.Context, hospitalId int64) ([]entity.HospitalStaff, error) {
+	var staff []entity.HospitalStaff
+	if err := dbWithContext(ctx, r.db).Where("hospital_id = ?", hospitalId).Preload("User").Order("created_at ASC").Find(&staff).Error; err != nil {
+		return nil, err
+	}
+	return staff, nil
+}
+
+// Create tidak mengubah apa pun jika pengguna sudah menjadi staf rumah sakit tersebut
+func (r *hospitalStaffRepository) Create(ctx context.Context, staff *entity.HospitalStaff) error {
+	return dbWithContext(ctx, r.db).Omit("User").Clauses(clause.OnConflict{DoNothing: true}).Create(staff).Error
+}
+
+func (r *hospitalStaffRepository) Delete(ctx context.Context, hospitalId, userId int64) (bool, error) {
+	result := dbWithContext(ctx, r.db).Where("hospital_id = ? AND user_id = ?", hospitalId, userId).Delete(&entity.HospitalStaff{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
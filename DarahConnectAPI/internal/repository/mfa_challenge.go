@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type MFAChallengeRepository interface {
+	Create(ctx context.Context, challenge *entity.MFAChallenge) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entity.MFAChallenge, error)
+	IncrementAttempts(ctx context.Context, challenge *entity.MFAChallenge) error
+	MarkUsed(ctx context.Context, challenge *entity.MFAChallenge) (bool, error)
+}
+
+type mfaChallengeRepository struct {
+	db *gorm.DB
+}
+
+func NewMFAChallengeRepository(db *gorm.DB) MFAChallengeRepository {
+	return &mfaChallengeRepository{db}
+}
+
+func (r *mfaChallengeRepository) Create(ctx context.Context, challenge *entity.MFAChallenge) error {
+	return dbWithContext(ctx, r.db).Create(challenge).Error
+}
+
+func (r *mfaChallengeRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.MFAChallenge, error) {
+	result := new(entity.MFAChallenge)
+	if err := dbWithContext(ctx, r.db).Where("token_hash = ?", tokenHash).First(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *mfaChallengeRepository) IncrementAttempts(ctx context.Context, challenge *entity.MFAChallenge) error {
+	if err := dbWithContext(ctx, r.db).Model(&entity.MFAChallenge{}).
+		Where("id = ?", challenge.Id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error; err != nil {
+		return err
+	}
+	challenge.Attempts++
+	return nil
+}
+
+// MarkUsed mengembalikan false jika token sudah ditukar oleh permintaan lain
+func (r *mfaChallengeRepository) MarkUsed(ctx context.Context, challenge *entity.MFAChallenge) (bool, error) {
+	now := time.Now()
+	result := dbWithContext(ctx, r.db).Model(&entity.MFAChallenge{}).
+		Where("id = ? AND used_at IS NULL", challenge.Id).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	challenge.UsedAt = &now
+	return true, nil
+}
@@ -30,7 +30,7 @@ func NewNotificationRepository(db *gorm.DB) NotificationRepository {
 }
 
 func (r *notificationRepository) Create(ctx context.Context, notification *entity.Notification) error {
-	return r.db.WithContext(ctx).Create(notification).Error
+	return dbWithContext(ctx, r.db).Create(notification).Error
 }
 
 // applyFilters menerapkan filter, sorting, dan pagination ke query GORM
@@ -96,7 +96,7 @@ func (r *notificationRepository) GetAll(ctx context.Context, req dto.GetAllNotif
 	var total int64
 
 	// Hitung total item sebelum pagination
-	dataQuery := r.db.WithContext(ctx).Model(&entity.Notification{}).Preload("User")
+	dataQuery := dbWithContext(ctx, r.db).Model(&entity.Notification{}).Preload("User")
 	dataQuery, req = r.applyFilters(dataQuery, req)
 	if err := dataQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -115,18 +115,18 @@ func (r *notificationRepository) GetAll(ctx context.Context, req dto.GetAllNotif
 
 func (r *notificationRepository) GetById(ctx context.Context, id int64) (*entity.Notification, error) {
 	result := new(entity.Notification)
-	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&result).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("id = ?", id).First(&result).Error; err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
 func (r *notificationRepository) Update(ctx context.Context, notification *entity.Notification) error {
-	return r.db.WithContext(ctx).Model(notification).Updates(notification).Error
+	return dbWithContext(ctx, r.db).Model(notification).Updates(notification).Error
 }
 
 func (r *notificationRepository) Delete(ctx context.Context, notification *entity.Notification) error {
-	return r.db.WithContext(ctx).Delete(notification).Error
+	return dbWithContext(ctx, r.db).Delete(notification).Error
 }
 
 // GetByUserId mengambil notifikasi berdasarkan ID pengguna dengan filter dan pagination
@@ -135,7 +135,7 @@ func (r *notificationRepository) GetByUserId(ctx context.Context, userId int64,
 	var total int64
 
 	// Hitung total item sebelum pagination
-	dataQuery := r.db.WithContext(ctx).Model(&entity.Notification{}).Where("user_id = ?", userId)
+	dataQuery := dbWithContext(ctx, r.db).Model(&entity.Notification{}).Where("user_id = ?", userId)
 	dataQuery, req = r.applyFilters(dataQuery, req)
 	if err := dataQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -154,7 +154,7 @@ func (r *notificationRepository) GetByUserId(ctx context.Context, userId int64,
 
 func (r *notificationRepository) GetUnreadCountByUserId(ctx context.Context, userId int64) (int64, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&entity.Notification{}).Where("user_id = ? AND is_read = false", userId).Count(&count).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&entity.Notification{}).Where("user_id = ? AND is_read = false", userId).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepository interface {
+	Create(ctx context.Context, message *entity.OutboxMessage) error
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]entity.OutboxMessage, error)
+	MarkDone(ctx context.Context, message *entity.OutboxMessage) error
+	MarkRetry(ctx context.Context, message *entity.OutboxMessage, lastError string, nextAttemptAt time.Time, failed bool) error
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db}
+}
+
+func (r *outboxRepository) Create(ctx context.Context, message *entity.OutboxMessage) error {
+	return dbWithContext(ctx, r.db).Create(message).Error
+}
+
+// ClaimDue mengunci pesan yang sudah jatuh tempo dan menandainya processing selama lease.
+// Pesan processing yang lease-nya habis (misalnya worker mati di tengah jalan) akan diambil lagi.
+func (r *outboxRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]entity.OutboxMessage, error) {
+	messages := make([]entity.OutboxMessage, 0)
+	err := dbWithContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND next_attempt_at <= ?", []string{"pending", "processing"}, now).
+			Order("next_attempt_at asc, id asc").
+			Limit(limit).
+			Find(&messages).Error; err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+
+		ids := make([]int64, 0, len(messages))
+		for i := range messages {
+			ids = append(ids, messages[i].Id)
+			messages[i].Status = "processing"
+			messages[i].Attempts++
+		}
+		return tx.Model(&entity.OutboxMessage{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":          "processing",
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": now.Add(lease),
+			"updated_at":      now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *outboxRepository) MarkDone(ctx context.Context, message *entity.OutboxMessage) error {
+	now := time.Now()
+	message.Status = "done"
+	message.ProcessedAt = &now
+	return dbWithContext(ctx, r.db).Model(message).Updates(map[string]interface{}{
+		"status":       message.Status,
+		"processed_at": now,
+		"last_error":   "",
+	}).Error
+}
+
+// MarkRetry menjadwalkan ulang pesan yang gagal, atau menandainya failed jika percobaan sudah habis
+func (r *outboxRepository) MarkRetry(ctx context.Context, message *entity.OutboxMessage, lastError string, nextAttemptAt time.Time, failed bool) error {
+	message.Status = "pending"
+	if failed {
+		message.Status = "failed"
+	}
+	message.LastError = lastError
+	message.NextAttemptAt = nextAttemptAt
+	return dbWithContext(ctx, r.db).Model(message).Updates(map[string]interface{}{
+		"status":          message.Status,
+		"last_error":      lastError,
+		"next_attempt_at": nextAttemptAt,
+	}).Error
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+
+	"gorm.io/gorm"
+)
+
+type PaymentDiscrepancyRepository interface {
+	Create(ctx context.Context, discrepancy *entity.PaymentDiscrepancy) error
+	// IsRecorded melaporkan apakah selisih yang sama sudah pernah dicatat untuk donasi tersebut
+	IsRecorded(ctx context.Context, donationId int64, action, gatewayStatus string) (bool, error)
+	GetAll(ctx context.Context, req dto.GetAllPaymentDiscrepancyRequest) ([]entity.PaymentDiscrepancy, int64, error)
+}
+
+type paymentDiscrepancyRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentDiscrepancyRepository(db *gorm.DB) PaymentDiscrepancyRepository {
+	return &paymentDiscrepancyRepository{db}
+}
+
+func (r *paymentDiscrepancyRepository) Create(ctx context.Context, discrepancy *entity.PaymentDiscrepancy) error {
+	return dbWithContext(ctx, r.db).Create(discrepancy).Error
+}
+
+func (r *paymentDiscrepancyRepository) IsRecorded(ctx context.Context, donationId int64, action, gatewayStatus string) (bool, error) {
+	var count int64
+	if err := dbWithContext(ctx, r.db).Model(&entity.PaymentDiscrepancy{}).
+		Where("donation_id = ? AND action = ? AND COALESCE(gateway_status, '') = ?", donationId, action, gatewayStatus).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *paymentDiscrepancyRepository) applyFilters(query *gorm.DB, req dto.GetAllPaymentDiscrepancyRequest) (*gorm.DB, dto.GetAllPaymentDiscrepancyRequest) {
+	if req.DonationId != 0 {
+		query = query.Where("donation_id = ?", req.DonationId)
+	}
+	if req.Action != "" {
+		query = query.Where("action = ?", req.Action)
+	}
+	if req.From != nil {
+		query = query.Where("created_at >= ?", *req.From)
+	}
+	if req.To != nil {
+		query = query.Where("created_at <= ?", *req.To)
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	return query.Order("created_at DESC, id DESC"), req
+}
+
+func (r *paymentDiscrepancyRepository) GetAll(ctx context.Context, req dto.GetAllPaymentDiscrepancyRequest) ([]entity.PaymentDiscrepancy, int64, error) {
+	discrepancies := make([]entity.PaymentDiscrepancy, 0)
+	var total int64
+
+	dataQuery := dbWithContext(ctx, r.db).Model(&entity.PaymentDiscrepancy{})
+	dataQuery, req = r.applyFilters(dataQuery, req)
+	if err := dataQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	dataQuery = dataQuery.Limit(int(req.Limit)).Offset(int(offset))
+
+	if err := dataQuery.Find(&discrepancies).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return discrepancies, total, nil
+}
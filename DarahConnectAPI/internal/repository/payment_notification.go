@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type PaymentNotificationRepository interface {
+	Create(ctx context.Context, notification *entity.PaymentNotification) error
+	MarkProcessed(ctx context.Context, notification *entity.PaymentNotification, result, message string) error
+	// IsApplied melaporkan apakah status tersebut sudah pernah diterapkan ke order
+	IsApplied(ctx context.Context, orderId, transactionStatus, fraudStatus string) (bool, error)
+}
+
+type paymentNotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentNotificationRepository(db *gorm.DB) PaymentNotificationRepository {
+	return &paymentNotificationRepository{db}
+}
+
+func (r *paymentNotificationRepository) Create(ctx context.Context, notification *entity.PaymentNotification) error {
+	return dbWithContext(ctx, r.db).Create(notification).Error
+}
+
+func (r *paymentNotificationRepository) MarkProcessed(ctx context.Context, notification *entity.PaymentNotification, result, message string) error {
+	now := time.Now()
+	notification.Result = result
+	notification.Message = message
+	notification.ProcessedAt = &now
+	return dbWithContext(ctx, r.db).Model(notification).Updates(map[string]interface{}{
+		"result":       result,
+		"message":      message,
+		"processed_at": now,
+	}).Error
+}
+
+func (r *paymentNotificationRepository) IsApplied(ctx context.Context, orderId, transactionStatus, fraudStatus string) (bool, error) {
+	var count int64
+	if err := dbWithContext(ctx, r.db).Model(&entity.PaymentNotification{}).
+		Where("order_id = ? AND transaction_status = ? AND fraud_status = ? AND result = ?", orderId, transactionStatus, fraudStatus, "applied").
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
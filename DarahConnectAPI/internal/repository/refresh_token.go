@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, refreshToken *entity.RefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	Rotate(ctx context.Context, refreshToken *entity.RefreshToken, replacedById int64) (bool, error)
+	GetByFamily(ctx context.Context, familyId string, createdAfter time.Time) ([]entity.RefreshToken, error)
+	RevokeFamily(ctx context.Context, familyId string) error
+	RevokeByUser(ctx context.Context, userId int64) error
+}
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db}
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, refreshToken *entity.RefreshToken) error {
+	return dbWithContext(ctx, r.db).Create(refreshToken).Error
+}
+
+func (r *refreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	result := new(entity.RefreshToken)
+	if err := dbWithContext(ctx, r.db).Where("token_hash = ?", tokenHash).First(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Rotate mencabut token dan mencatat penggantinya. Mengembalikan false jika token sudah
+// dicabut lebih dulu oleh permintaan lain.
+func (r *refreshTokenRepository) Rotate(ctx context.Context, refreshToken *entity.RefreshToken, replacedById int64) (bool, error) {
+	now := time.Now()
+	result := dbWithContext(ctx, r.db).Model(&entity.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", refreshToken.Id).
+		Updates(map[string]interface{}{"revoked_at": now, "replaced_by_id": replacedById})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *refreshTokenRepository) GetByFamily(ctx context.Context, familyId string, createdAfter time.Time) ([]entity.RefreshToken, error) {
+	var refreshTokens []entity.RefreshToken
+	if err := dbWithContext(ctx, r.db).Where("family_id = ? AND created_at > ?", familyId, createdAfter).Find(&refreshTokens).Error; err != nil {
+		return nil, err
+	}
+	return refreshTokens, nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyId string) error {
+	return dbWithContext(ctx, r.db).Model(&entity.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyId).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *refreshTokenRepository) RevokeByUser(ctx context.Context, userId int64) error {
+	return dbWithContext(ctx, r.db).Model(&entity.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Update("revoked_at", time.Now()).Error
+}
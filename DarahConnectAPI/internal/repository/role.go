@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type RoleRepository interface {
+	GetAll(ctx context.Context) ([]entity.Role, error)
+	GetById(ctx context.Context, id int64) (*entity.Role, error)
+	GetByName(ctx context.Context, name string) (*entity.Role, error)
+	Create(ctx context.Context, role *entity.Role) error
+	Update(ctx context.Context, role *entity.Role) error
+	Delete(ctx context.Context, role *entity.Role) error
+	ReplacePermissions(ctx context.Context, roleId int64, permissionIds []int64) error
+	GetPermissions(ctx context.Context) ([]entity.Permission, error)
+	GetPermissionsByName(ctx context.Context, names []string) ([]entity.Permission, error)
+	CountUsers(ctx context.Context, roleName string) (int64, error)
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db}
+}
+
+func (r *roleRepository) GetAll(ctx context.Context) ([]entity.Role, error) {
+	var roles []entity.Role
+	if err := dbWithContext(ctx, r.db).Preload("Permissions").Order("id ASC").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *roleRepository) GetById(ctx context.Context, id int64) (*entity.Role, error) {
+	result := new(entity.Role)
+	if err := dbWithContext(ctx, r.db).Where("id = ?", id).Preload("Permissions").First(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *roleRepository) GetByName(ctx context.Context, name string) (*entity.Role, error) {
+	result := new(entity.Role)
+	if err := dbWithContext(ctx, r.db).Where("name = ?", name).Preload("Permissions").First(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *roleRepository) Create(ctx context.Context, role *entity.Role) error {
+	return dbWithContext(ctx, r.db).Omit("Permissions").Create(role).Error
+}
+
+func (r *roleRepository) Update(ctx context.Context, role *entity.Role) error {
+	return dbWithContext(ctx, r.db).Omit("Permissions").Save(role).Error
+}
+
+func (r *roleRepository) Delete(ctx context.Context, role *entity.Role) error {
+	return dbWithContext(ctx, r.db).Select("Permissions").Delete(role).Error
+}
+
+func (r *roleRepository) ReplacePermissions(ctx context.Context, roleId int64, permissionIds []int64) error {
+	db := dbWithContext(ctx, r.db)
+	if err := db.Where("role_id = ?", roleId).Delete(&entity.RolePermission{}).Error; err != nil {
+		return err
+	}
+	if len(permissionIds) == 0 {
+		return nil
+	}
+
+	rolePermissions := make([]entity.RolePermission, 0, len(permissionIds))
+	for _, permissionId := range permissionIds {
+		rolePermissions = append(rolePermissions, entity.RolePermission{RoleId: roleId, PermissionId: permissionId})
+	}
+	return db.Create(&rolePermissions).Error
+}
+
+func (r *roleRepository) GetPermissions(ctx context.Context) ([]entity.Permission, error) {
+	var permissions []entity.Permission
+	if err := dbWithContext(ctx, r.db).Order("name ASC").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (r *roleRepository) GetPermissionsByName(ctx context.Context, names []string) ([]entity.Permission, error) {
+	var permissions []entity.Permission
+	if err := dbWithContext(ctx, r.db).Where("name IN ?", names).Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// CountUsers menghitung pengguna yang masih memakai role tersebut
+func (r *roleRepository) CountUsers(ctx context.Context, roleName string) (int64, error) {
+	var count int64
+	if err := dbWithContext(ctx, r.db).Model(&entity.User{}).Where("role = ?", roleName).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
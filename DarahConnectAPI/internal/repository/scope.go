@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/rbac"
+
+	"gorm.io/gorm"
+)
+
+// scopeHospital membatasi query ke rumah sakit staf yang sedang login, jika context dibatasi.
+// column adalah kolom hospital_id pada tabel yang di-query.
+func scopeHospital(ctx context.Context, query *gorm.DB, column string) *gorm.DB {
+	hospitalIds, ok := rbac.HospitalScope(ctx)
+	if !ok {
+		return query
+	}
+	if len(hospitalIds) == 0 {
+		return query.Where("1 = 0")
+	}
+	return query.Where(column+" IN ?", hospitalIds)
+}
+
+// scopeHospitalByRequest membatasi tabel yang terhubung ke rumah sakit lewat blood_requests
+func scopeHospitalByRequest(ctx context.Context, query *gorm.DB, column string) *gorm.DB {
+	hospitalIds, ok := rbac.HospitalScope(ctx)
+	if !ok {
+		return query
+	}
+	if len(hospitalIds) == 0 {
+		return query.Where("1 = 0")
+	}
+	return query.Where(column+" IN (SELECT id FROM public.blood_requests WHERE hospital_id IN ?)", hospitalIds)
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+// signingKeyLockId adalah kunci advisory lock Postgres agar hanya satu instance yang merotasi kunci
+const signingKeyLockId = 7316001
+
+type SigningKeyRepository interface {
+	Lock(ctx context.Context) error
+	GetUnexpired(ctx context.Context, now time.Time) ([]entity.SigningKey, error)
+	Create(ctx context.Context, key *entity.SigningKey) error
+	Update(ctx context.Context, key *entity.SigningKey) error
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
+
+type signingKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewSigningKeyRepository(db *gorm.DB) SigningKeyRepository {
+	return &signingKeyRepository{db}
+}
+
+// Lock harus dipanggil di dalam transaksi; lock dilepas saat transaksi selesai
+func (r *signingKeyRepository) Lock(ctx context.Context) error {
+	return dbWithContext(ctx, r.db).Exec("SELECT pg_advisory_xact_lock(?)", signingKeyLockId).Error
+}
+
+func (r *signingKeyRepository) GetUnexpired(ctx context.Context, now time.Time) ([]entity.SigningKey, error) {
+	var keys []entity.SigningKey
+	if err := dbWithContext(ctx, r.db).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Order("activates_at ASC").
+		Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *signingKeyRepository) Create(ctx context.Context, key *entity.SigningKey) error {
+	return dbWithContext(ctx, r.db).Create(key).Error
+}
+
+func (r *signingKeyRepository) Update(ctx context.Context, key *entity.SigningKey) error {
+	return dbWithContext(ctx, r.db).Save(key).Error
+}
+
+func (r *signingKeyRepository) DeleteExpired(ctx context.Context, now time.Time) error {
+	return dbWithContext(ctx, r.db).Where("expires_at <= ?", now).Delete(&entity.SigningKey{}).Error
+}
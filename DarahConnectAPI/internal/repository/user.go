@@ -2,19 +2,25 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
+// ErrWalletAddressTaken dikembalikan ketika alamat wallet sudah terhubung ke pengguna lain
+var ErrWalletAddressTaken = errors.New("alamat wallet sudah dipakai pengguna lain")
+
 type UserRepository interface {
 	GetAll(ctx context.Context, req dto.GetAllUserRequest) ([]entity.User, int64, error)
 	GetById(ctx context.Context, id int64) (*entity.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
+	GetByWalletAddress(ctx context.Context, walletAddress string) (*entity.User, error)
 	Create(ctx context.Context, user *entity.User) error
 	Update(ctx context.Context, user *entity.User) error
 	Delete(ctx context.Context, user *entity.User) error
@@ -34,9 +40,17 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 // applyFilters menerapkan filter, sorting, dan pagination ke query GORM
 func (r *userRepository) applyFilters(query *gorm.DB, req dto.GetAllUserRequest) (*gorm.DB, dto.GetAllUserRequest) {
 	// Filter berdasarkan BloodType
-	if req.BloodType != "" {
+	if len(req.BloodTypes) > 0 {
+		query = query.Where("UPPER(blood_type) IN ?", req.BloodTypes)
+	} else if req.BloodType != "" {
 		query = query.Where("LOWER(blood_type) = ?", req.BloodType)
 	}
+	if req.Role != "" {
+		query = query.Where("role = ?", req.Role)
+	}
+	if req.ExcludeRole != "" {
+		query = query.Where("role <> ?", req.ExcludeRole)
+	}
 	if req.Email != "" {
 		query = query.Where("LOWER(email) = ?", req.Email)
 	}
@@ -81,7 +95,7 @@ func (r *userRepository) GetAll(ctx context.Context, req dto.GetAllUserRequest)
 	var total int64
 
 	// Hitung total item sebelum pagination
-	dataQuery := r.db.WithContext(ctx).Model(&entity.User{})
+	dataQuery := dbWithContext(ctx, r.db).Model(&entity.User{})
 	dataQuery, req = r.applyFilters(dataQuery, req)
 	if err := dataQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -100,27 +114,43 @@ func (r *userRepository) GetAll(ctx context.Context, req dto.GetAllUserRequest)
 
 func (r *userRepository) GetById(ctx context.Context, id int64) (*entity.User, error) {
 	result := new(entity.User)
-	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&result).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("id = ?", id).First(&result).Error; err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
 func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
-	return r.db.WithContext(ctx).Create(&user).Error
+	return dbWithContext(ctx, r.db).Create(&user).Error
 }
 
+// Update menerjemahkan pelanggaran idx_users_wallet_address menjadi ErrWalletAddressTaken
+// karena dua permintaan link bisa lolos pengecekan GetByWalletAddress bersamaan.
 func (r *userRepository) Update(ctx context.Context, user *entity.User) error {
-	return r.db.WithContext(ctx).Model(&user).Updates(&user).Error
+	err := dbWithContext(ctx, r.db).Model(&user).Updates(&user).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_users_wallet_address" {
+		return ErrWalletAddressTaken
+	}
+	return err
 }
 
 func (r *userRepository) Delete(ctx context.Context, user *entity.User) error {
-	return r.db.WithContext(ctx).Delete(&user).Error
+	return dbWithContext(ctx, r.db).Delete(&user).Error
 }
 
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
 	result := new(entity.User)
-	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&result).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("email = ?", email).First(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetByWalletAddress mencari pengguna tanpa membedakan huruf besar kecil alamat wallet
+func (r *userRepository) GetByWalletAddress(ctx context.Context, walletAddress string) (*entity.User, error) {
+	result := new(entity.User)
+	if err := dbWithContext(ctx, r.db).Where("LOWER(wallet_address) = LOWER(?)", walletAddress).First(&result).Error; err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -128,7 +158,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.
 
 func (r *userRepository) GetByResetPasswordToken(ctx context.Context, token string) (*entity.User, error) {
 	result := new(entity.User)
-	if err := r.db.WithContext(ctx).Where("reset_password_token = ?", token).First(&result).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("reset_password_token = ?", token).First(&result).Error; err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -136,7 +166,7 @@ func (r *userRepository) GetByResetPasswordToken(ctx context.Context, token stri
 
 func (r *userRepository) GetByVerifyEmailToken(ctx context.Context, token string) (*entity.User, error) {
 	result := new(entity.User)
-	if err := r.db.WithContext(ctx).Where("verify_email_token = ?", token).First(&result).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("verify_email_token = ?", token).First(&result).Error; err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -144,7 +174,7 @@ func (r *userRepository) GetByVerifyEmailToken(ctx context.Context, token string
 
 func (r *userRepository) CountUser(ctx context.Context) (int64, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&entity.User{}).Where("role = ?", "User").Count(&count).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&entity.User{}).Where("role = ?", "User").Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
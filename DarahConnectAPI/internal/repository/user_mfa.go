@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type UserMFARepository interface {
+	GetByUserId(ctx context.Context, userId int64) (*entity.UserMFA, error)
+	Create(ctx context.Context, mfa *entity.UserMFA) error
+	Update(ctx context.Context, mfa *entity.UserMFA) error
+	Delete(ctx context.Context, userId int64) error
+	MarkStepUsed(ctx context.Context, userId int64, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userId int64, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userId int64, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userId int64) (int64, error)
+}
+
+type userMFARepository struct {
+	db *gorm.DB
+}
+
+func NewUserMFARepository(db *gorm.DB) UserMFARepository {
+	return &userMFARepository{db}
+}
+
+// GetByUserId mengembalikan nil tanpa error jika pengguna belum pernah mendaftarkan TOTP
+func (r *userMFARepository) GetByUserId(ctx context.Context, userId int64) (*entity.UserMFA, error) {
+	result := new(entity.UserMFA)
+	err := dbWithContext(ctx, r.db).Where("user_id = ?", userId).First(result).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *userMFARepository) Create(ctx context.Context, mfa *entity.UserMFA) error {
+	return dbWithContext(ctx, r.db).Create(mfa).Error
+}
+
+func (r *userMFARepository) Update(ctx context.Context, mfa *entity.UserMFA) error {
+	return dbWithContext(ctx, r.db).Save(mfa).Error
+}
+
+func (r *userMFARepository) Delete(ctx context.Context, userId int64) error {
+	db := dbWithContext(ctx, r.db)
+	if err := db.Where("user_id = ?", userId).Delete(&entity.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+	return db.Where("user_id = ?", userId).Delete(&entity.UserMFA{}).Error
+}
+
+// MarkStepUsed mencatat periode TOTP yang dipakai dan mengembalikan false jika periode
+// tersebut atau yang lebih baru sudah pernah dipakai
+func (r *userMFARepository) MarkStepUsed(ctx context.Context, userId int64, step int64) (bool, error) {
+	result := dbWithContext(ctx, r.db).Model(&entity.UserMFA{}).
+		Where("user_id = ? AND last_used_step < ?", userId, step).
+		Updates(map[string]interface{}{"last_used_step": step, "updated_at": time.Now()})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *userMFARepository) ReplaceRecoveryCodes(ctx context.Context, userId int64, codeHashes []string) error {
+	db := dbWithContext(ctx, r.db)
+	if err := db.Where("user_id = ?", userId).Delete(&entity.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	codes := make([]entity.MFARecoveryCode, 0, len(codeHashes))
+	for _, codeHash := range codeHashes {
+		codes = append(codes, entity.MFARecoveryCode{UserId: userId, CodeHash: codeHash, CreatedAt: now})
+	}
+	return db.Create(&codes).Error
+}
+
+// UseRecoveryCode menandai kode pemulihan terpakai dan mengembalikan false jika kode tidak
+// dikenal atau sudah dipakai
+func (r *userMFARepository) UseRecoveryCode(ctx context.Context, userId int64, codeHash string) (bool, error) {
+	result := dbWithContext(ctx, r.db).Model(&entity.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *userMFARepository) CountRecoveryCodes(ctx context.Context, userId int64) (int64, error) {
+	var count int64
+	if err := dbWithContext(ctx, r.db).Model(&entity.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userId).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
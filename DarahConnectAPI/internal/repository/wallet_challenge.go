@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type WalletChallengeRepository interface {
+	Create(ctx context.Context, challenge *entity.WalletChallenge) error
+	GetByNonce(ctx context.Context, nonce string) (*entity.WalletChallenge, error)
+	MarkUsed(ctx context.Context, challenge *entity.WalletChallenge) (bool, error)
+}
+
+type walletChallengeRepository struct {
+	db *gorm.DB
+}
+
+func NewWalletChallengeRepository(db *gorm.DB) WalletChallengeRepository {
+	return &walletChallengeRepository{db}
+}
+
+func (r *walletChallengeRepository) Create(ctx context.Context, challenge *entity.WalletChallenge) error {
+	return dbWithContext(ctx, r.db).Create(challenge).Error
+}
+
+func (r *walletChallengeRepository) GetByNonce(ctx context.Context, nonce string) (*entity.WalletChallenge, error) {
+	result := new(entity.WalletChallenge)
+	if err := dbWithContext(ctx, r.db).Where("nonce = ?", nonce).First(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// MarkUsed menandai tantangan sudah dipakai dan mengembalikan false jika tantangan sudah
+// dipakai oleh permintaan lain, sehingga tanda tangan yang sama tidak bisa diputar ulang.
+func (r *walletChallengeRepository) MarkUsed(ctx context.Context, challenge *entity.WalletChallenge) (bool, error) {
+	now := time.Now()
+	result := dbWithContext(ctx, r.db).Model(&entity.WalletChallenge{}).
+		Where("id = ? AND used_at IS NULL", challenge.Id).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	challenge.UsedAt = &now
+	return true, nil
+}
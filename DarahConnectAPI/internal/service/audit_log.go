@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/audit"
+)
+
+const auditVerifyBatchSize = 500
+
+type AuditLogService interface {
+	// Record menambahkan aksi ke audit log dengan metadata permintaan dari context. Jika ctx
+	// membawa transaksi, audit log ikut batal saat transaksi dibatalkan.
+	Record(ctx context.Context, entry audit.Entry) error
+	GetAll(ctx context.Context, req dto.GetAllAuditLogRequest) ([]entity.AuditLog, int64, error)
+	// Verify menghitung ulang rantai hash dari baris pertama
+	Verify(ctx context.Context) (*dto.AuditLogVerifyResponse, error)
+}
+
+type auditLogService struct {
+	auditLogRepository repository.AuditLogRepository
+	transactor         repository.Transactor
+}
+
+func NewAuditLogService(auditLogRepository repository.AuditLogRepository, transactor repository.Transactor) AuditLogService {
+	return &auditLogService{auditLogRepository, transactor}
+}
+
+func (s *auditLogService) Record(ctx context.Context, entry audit.Entry) error {
+	changes, err := audit.Diff(entry.Before, entry.After)
+	if err != nil {
+		return errors.New("Gagal mencatat audit log")
+	}
+	rawChanges, err := json.Marshal(changes)
+	if err != nil {
+		return errors.New("Gagal mencatat audit log")
+	}
+
+	record := audit.Record{
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityId:   entry.EntityId,
+		Changes:    rawChanges,
+		CreatedAt:  time.Now().UTC().Truncate(time.Microsecond),
+	}
+	metadata, ok := audit.MetadataFrom(ctx)
+	if ok {
+		record.ActorId = metadata.ActorId
+		record.ActorRole = metadata.ActorRole
+		record.IP = metadata.IP
+		record.RequestId = metadata.RequestId
+		record.Method = metadata.Method
+		record.Path = metadata.Path
+	}
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.auditLogRepository.LockChain(ctx); err != nil {
+			return err
+		}
+		prevHash, err := s.auditLogRepository.GetLastHash(ctx)
+		if err != nil {
+			return err
+		}
+		hash, err := audit.Hash(prevHash, record)
+		if err != nil {
+			return err
+		}
+		return s.auditLogRepository.Create(ctx, auditLogFromRecord(record, prevHash, hash))
+	})
+	if err != nil {
+		return errors.New("Gagal mencatat audit log")
+	}
+
+	if ok {
+		metadata.MarkRecorded()
+	}
+	return nil
+}
+
+func (s *auditLogService) GetAll(ctx context.Context, req dto.GetAllAuditLogRequest) ([]entity.AuditLog, int64, error) {
+	auditLogs, total, err := s.auditLogRepository.GetAll(ctx, req)
+	if err != nil {
+		return nil, 0, errors.New("Gagal mendapatkan audit log")
+	}
+	return auditLogs, total, nil
+}
+
+func (s *auditLogService) Verify(ctx context.Context) (*dto.AuditLogVerifyResponse, error) {
+	result := &dto.AuditLogVerifyResponse{Valid: true}
+	var lastId int64
+	for {
+		auditLogs, err := s.auditLogRepository.GetAfterId(ctx, lastId, auditVerifyBatchSize)
+		if err != nil {
+			return nil, errors.New("Gagal membaca audit log")
+		}
+
+		for _, auditLog := range auditLogs {
+			hash, err := audit.Hash(auditLog.PrevHash, recordFromAuditLog(auditLog))
+			if err != nil || auditLog.PrevHash != result.LastHash || hash != auditLog.Hash {
+				brokenAt := auditLog.Id
+				result.Valid = false
+				result.BrokenAt = &brokenAt
+				return result, nil
+			}
+			result.LastHash = auditLog.Hash
+			result.Checked++
+			lastId = auditLog.Id
+		}
+
+		if len(auditLogs) < auditVerifyBatchSize {
+			return result, nil
+		}
+	}
+}
+
+func auditLogFromRecord(record audit.Record, prevHash, hash string) *entity.AuditLog {
+	auditLog := &entity.AuditLog{
+		ActorRole:  record.ActorRole,
+		Action:     record.Action,
+		EntityType: record.EntityType,
+		Changes:    string(record.Changes),
+		IP:         record.IP,
+		RequestId:  record.RequestId,
+		Method:     record.Method,
+		Path:       record.Path,
+		PrevHash:   prevHash,
+		Hash:       hash,
+		CreatedAt:  record.CreatedAt,
+	}
+	if record.ActorId != 0 {
+		auditLog.ActorId = &record.ActorId
+	}
+	if record.EntityId != 0 {
+		auditLog.EntityId = &record.EntityId
+	}
+	return auditLog
+}
+
+func recordFromAuditLog(auditLog entity.AuditLog) audit.Record {
+	record := audit.Record{
+		ActorRole:  auditLog.ActorRole,
+		Action:     auditLog.Action,
+		EntityType: auditLog.EntityType,
+		Changes:    json.RawMessage(auditLog.Changes),
+		IP:         auditLog.IP,
+		RequestId:  auditLog.RequestId,
+		Method:     auditLog.Method,
+		Path:       auditLog.Path,
+		CreatedAt:  auditLog.CreatedAt,
+	}
+	if auditLog.ActorId != nil {
+		record.ActorId = *auditLog.ActorId
+	}
+	if auditLog.EntityId != nil {
+		record.EntityId = *auditLog.EntityId
+	}
+	return record
+}
@@ -0,0 +1,97 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogRecordAndVerify(t *testing.T) {
+	repo := &fakeAuditLogRepository{}
+	auditLogService := service.NewAuditLogService(repo, passthroughTransactor{})
+
+	metadata := &audit.Metadata{ActorId: 1, ActorRole: "Administrator", IP: "10.0.0.1", RequestId: "req-1", Method: "PUT", Path: "/api/v1/blood-request/7"}
+	ctx := audit.WithMetadata(context.Background(), metadata)
+
+	require.NoError(t, auditLogService.Record(ctx, audit.Entry{
+		Action:     "blood_request.status",
+		EntityType: "blood_request",
+		EntityId:   7,
+		Before:     entity.BloodRequest{Id: 7, Status: "pending"},
+		After:      entity.BloodRequest{Id: 7, Status: "verified"},
+	}))
+	require.NoError(t, auditLogService.Record(context.Background(), audit.Entry{Action: "user.delete", EntityType: "user", EntityId: 9, Before: entity.User{Id: 9, Password: "hash"}}))
+	assert.True(t, metadata.Recorded())
+
+	require.Len(t, repo.logs, 2)
+	first, second := repo.logs[0], repo.logs[1]
+	assert.Equal(t, int64(1), *first.ActorId)
+	assert.Equal(t, "req-1", first.RequestId)
+	assert.JSONEq(t, `{"status":{"before":"pending","after":"verified"}}`, first.Changes)
+	assert.Empty(t, first.PrevHash)
+	assert.Equal(t, first.Hash, second.PrevHash)
+	assert.Nil(t, second.ActorId)
+	assert.Contains(t, second.Changes, audit.Redacted)
+	assert.NotContains(t, second.Changes, `"hash"`)
+
+	result, err := auditLogService.Verify(context.Background())
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, int64(2), result.Checked)
+	assert.Equal(t, second.Hash, result.LastHash)
+
+	// Mengubah isi baris lama memutus rantai pada baris tersebut
+	repo.logs[0].Changes = `{"status":{"before":"pending","after":"rejected"}}`
+	result, err = auditLogService.Verify(context.Background())
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, first.Id, *result.BrokenAt)
+
+	// Menghapus baris pertama juga terdeteksi karena baris berikutnya merujuk hash yang hilang
+	repo.logs = repo.logs[1:]
+	result, err = auditLogService.Verify(context.Background())
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, second.Id, *result.BrokenAt)
+}
+
+type fakeAuditLogRepository struct {
+	logs []entity.AuditLog
+}
+
+func (r *fakeAuditLogRepository) LockChain(ctx context.Context) error {
+	return nil
+}
+
+func (r *fakeAuditLogRepository) GetLastHash(ctx context.Context) (string, error) {
+	if len(r.logs) == 0 {
+		return "", nil
+	}
+	return r.logs[len(r.logs)-1].Hash, nil
+}
+
+func (r *fakeAuditLogRepository) Create(ctx context.Context, auditLog *entity.AuditLog) error {
+	auditLog.Id = int64(len(r.logs) + 1)
+	r.logs = append(r.logs, *auditLog)
+	return nil
+}
+
+func (r *fakeAuditLogRepository) GetAll(ctx context.Context, req dto.GetAllAuditLogRequest) ([]entity.AuditLog, int64, error) {
+	return r.logs, int64(len(r.logs)), nil
+}
+
+func (r *fakeAuditLogRepository) GetAfterId(ctx context.Context, id int64, limit int) ([]entity.AuditLog, error) {
+	result := make([]entity.AuditLog, 0)
+	for _, auditLog := range r.logs {
+		if auditLog.Id > id && len(result) < limit {
+			result = append(result, auditLog)
+		}
+	}
+	return result, nil
+}
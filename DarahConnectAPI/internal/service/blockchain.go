@@ -7,51 +7,163 @@ import (
 	"fmt"
 	"log"
 	"math/big"
+	"time"
 
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/contracts" // Impor package kontrak hasil generate abigen
-	"github.com/mhusainh/DarahConnect/DarahConnectAPI/utils"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/event"
 )
 
 // BlockchainService mendefinisikan fungsi-fungsi untuk interaksi dengan blockchain.
 type BlockchainService interface {
-	// Mengembalikan: Transaction Hash (string), Error
-	CreateCertificate(donorAddress, donorName, donorAlamat string) (string, string, error)
+	// SignCertificate membuat dan menandatangani transaksi mintSertifikat tanpa mengirimnya
+	SignCertificate(ctx context.Context, req CertificateTx) (*SignedTx, error)
+	// BumpTransaction menandatangani ulang transaksi dengan nonce yang sama dan gas yang dinaikkan
+	BumpTransaction(ctx context.Context, raw []byte, bumpPercent int) (*SignedTx, error)
+	SendTransaction(ctx context.Context, raw []byte) error
+	// TransactionReceipt mengembalikan nil tanpa error jika transaksi belum ditambang
+	TransactionReceipt(ctx context.Context, txHash string) (*TxReceipt, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	// ConfirmedNonce adalah jumlah transaksi akun backend yang sudah masuk blok
+	ConfirmedNonce(ctx context.Context) (uint64, error)
+	// GetCertificate membaca daftarSertifikat, nil tanpa error jika id belum pernah di-mint
+	GetCertificate(ctx context.Context, onChainId int64) (*OnChainCertificate, error)
+	// IssuerAddress adalah alamat wallet backend yang menandatangani mintSertifikat
+	IssuerAddress() string
+	// FilterCertificateEvents membaca event SertifikatDibuat pada rentang blok [fromBlock, toBlock]
+	FilterCertificateEvents(ctx context.Context, fromBlock, toBlock uint64) ([]CertificateEvent, error)
+	// WatchCertificateEvents berlangganan event SertifikatDibuat baru. Mengembalikan error jika
+	// node tidak mendukung subscription (misalnya RPC lewat HTTP).
+	WatchCertificateEvents(ctx context.Context, sink chan<- CertificateEvent) (event.Subscription, error)
+}
+
+// EthClient adalah bagian dari ethclient yang dipakai BlockchainService. Dipenuhi oleh
+// *ethclient.Client maupun client dari simulated.Backend.
+type EthClient interface {
+	bind.ContractBackend
+	ethereum.ChainIDReader
+	ethereum.BlockNumberReader
+	ethereum.TransactionReader
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
+// CertificateTx adalah data sertifikat yang dicatat oleh mintSertifikat
+type CertificateTx struct {
+	CertificateNumber string
+	DonorAddress      string
+	DonorName         string
+	DonorAlamat       string
+}
+
+// SignedTx adalah transaksi bertanda tangan yang siap dikirim
+type SignedTx struct {
+	Hash      string
+	From      string // alamat penerbit yang menandatangani
+	Nonce     uint64
+	GasFeeCap *big.Int
+	GasTipCap *big.Int
+	Raw       []byte
+}
+
+type TxReceipt struct {
+	Success     bool
+	BlockNumber uint64
+	// CertificateId dan DonorAddress diambil dari event SertifikatDibuat, CertificateId nil
+	// jika transaksi tidak memancarkannya
+	CertificateId *int64
+	DonorAddress  string
+}
+
+// CertificateEvent adalah satu event SertifikatDibuat
+type CertificateEvent struct {
+	OnChainId         int64
+	DonorAddress      string
+	CertificateNumber string
+	BlockNumber       uint64
+	BlockHash         string
+	TxHash            string
+	LogIndex          uint
+}
+
+// OnChainCertificate adalah isi daftarSertifikat di kontrak
+type OnChainCertificate struct {
+	Id               int64
+	Pendonor         string
+	NamaPendonor     string
+	TanggalDonasi    time.Time
+	NomorSertifikat  string
+	AlamatPendonor   string
+	Penerbit         string
+	AdminVerifikator string
 }
 
 // Struct implementasi dari interface di atas.
 type blockchainService struct {
-	client           *ethclient.Client
+	client           EthClient
 	contractInstance *contracts.SertifikatDonasi
 	privateKey       *ecdsa.PrivateKey
+	fromAddress      common.Address
 	chainID          *big.Int
 }
 
+// Pilihan backend blockchain (BLOCKCHAIN_MODE)
+const (
+	BlockchainModeRPC       = "rpc"       // node Ethereum lewat SEPOLIA_RPC_URL
+	BlockchainModeSimulated = "simulated" // chain di memori untuk development dan pengujian
+	BlockchainModeDisabled  = "disabled"  // tanpa blockchain, mint sertifikat tetap antre
+)
+
 // NewBlockchainService adalah constructor yang membuat instance baru dari BlockchainService.
-// Ia menerima konfigurasi sebagai parameter (Dependency Injection).
-func NewBlockchainService(cfg configs.BlockchainConfig) (BlockchainService, error) {
-	// Ganti semua log.Fatalf dengan return nil, error
+// Backend dipilih dari cfg.Mode. ctx menentukan umur backend simulated.
+func NewBlockchainService(ctx context.Context, cfg configs.BlockchainConfig) (BlockchainService, error) {
+	switch cfg.Mode {
+	case BlockchainModeRPC, "":
+		return newRPCBlockchainService(ctx, cfg)
+	case BlockchainModeSimulated:
+		return newSimulatedBlockchainService(ctx, cfg)
+	case BlockchainModeDisabled:
+		return NewDisabledBlockchainService(), nil
+	default:
+		return nil, fmt.Errorf("BLOCKCHAIN_MODE tidak dikenal: %s", cfg.Mode)
+	}
+}
 
-	client, err := ethclient.Dial(cfg.RPCURL)
+func newRPCBlockchainService(ctx context.Context, cfg configs.BlockchainConfig) (BlockchainService, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	client, err := ethclient.DialContext(dialCtx, cfg.RPCURL)
 	if err != nil {
 		// return error, bukan mematikan program
 		return nil, fmt.Errorf("gagal terhubung ke Ethereum client: %w", err)
 	}
 
-	contractAddress := common.HexToAddress(cfg.ContractAddress)
-	instance, err := contracts.NewSertifikatDonasi(contractAddress, client)
+	privateKey, err := crypto.HexToECDSA(cfg.PrivateKey)
 	if err != nil {
-		return nil, fmt.Errorf("gagal membuat instance kontrak: %w", err)
+		return nil, fmt.Errorf("gagal memuat private key: %w", err)
 	}
 
-	privateKey, err := crypto.HexToECDSA(cfg.PrivateKey)
+	service, err := NewBlockchainServiceFromClient(client, common.HexToAddress(cfg.ContractAddress), privateKey)
 	if err != nil {
-		return nil, fmt.Errorf("gagal memuat private key: %w", err)
+		return nil, err
+	}
+
+	fmt.Println("✅ Blockchain service successfully initialized.")
+	return service, nil
+}
+
+// NewBlockchainServiceFromClient membuat BlockchainService dari client yang sudah terhubung
+func NewBlockchainServiceFromClient(client EthClient, contractAddress common.Address, privateKey *ecdsa.PrivateKey) (BlockchainService, error) {
+	instance, err := contracts.NewSertifikatDonasi(contractAddress, client)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat instance kontrak: %w", err)
 	}
 
 	chainID, err := client.ChainID(context.Background())
@@ -59,48 +171,250 @@ func NewBlockchainService(cfg configs.BlockchainConfig) (BlockchainService, erro
 		return nil, fmt.Errorf("gagal mendapatkan ChainID: %w", err)
 	}
 
-	fmt.Println("✅ Blockchain service successfully initialized.")
-
-	// Kembalikan service dan error yang nil (tidak ada error)
 	return &blockchainService{
 		client,
 		instance,
 		privateKey,
+		crypto.PubkeyToAddress(privateKey.PublicKey),
 		chainID,
 	}, nil
 }
 
-// CreateCertificate memanggil fungsi mintSertifikat di smart contract.
-func (s *blockchainService) CreateCertificate(donorAddress, donorName, donorAlamat string) (string, string, error) {
+// SignCertificate menyiapkan transaksi mintSertifikat. Nonce dan gas diisi otomatis dari node.
+// Transaksi sengaja tidak langsung dikirim agar hash dan nonce bisa disimpan lebih dulu.
+func (s *blockchainService) SignCertificate(ctx context.Context, req CertificateTx) (*SignedTx, error) {
 	// Buat "transactor" (penanda tangan transaksi) dari private key backend
 	auth, err := bind.NewKeyedTransactorWithChainID(s.privateKey, s.chainID)
 	if err != nil {
-		return "", "", errors.New("gagal membuat transactor")
+		return nil, errors.New("gagal membuat transactor")
+	}
+	auth.Context = ctx
+	auth.NoSend = true
+
+	// Konversi tipe data Go ke tipe data yang dimengerti Solidity
+	pendonorAddress := common.HexToAddress(req.DonorAddress)
+	nomorSertifikat, success := new(big.Int).SetString(req.CertificateNumber, 10)
+	if !success {
+		return nil, errors.New("failed to convert certificate number to big.Int: " + req.CertificateNumber)
 	}
 
-	certificateNumber, err := utils.GenerateUniqueCertificateNumber()
+	// Panggil fungsi dari smart contract (dari file hasil generate abigen)
+	tx, err := s.contractInstance.MintSertifikat(auth, pendonorAddress, req.DonorName, nomorSertifikat, req.DonorAlamat)
 	if err != nil {
-		return "", "", errors.New("Gagal membuat nomor sertifikat")
+		return nil, errors.New("gagal memanggil fungsi MintSertifikat: " + err.Error())
 	}
+	return newSignedTx(tx)
+}
 
-	// Konversi tipe data Go ke tipe data yang dimengerti Solidity
-	pendonorAddress := common.HexToAddress(donorAddress)
-	nomorSertifikat, success := new(big.Int).SetString(certificateNumber, 10)
-	if !success {
-		return "", "", errors.New("failed to convert certificate number to big.Int: " + certificateNumber)
+func (s *blockchainService) BumpTransaction(ctx context.Context, raw []byte, bumpPercent int) (*SignedTx, error) {
+	old := new(types.Transaction)
+	if err := old.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("gagal membaca transaksi: %w", err)
+	}
+
+	suggestedTip, err := s.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan gas tip: %w", err)
+	}
+	head, err := s.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan header blok: %w", err)
 	}
 
-	log.Println("Mengirim transaksi MintSertifikat ke blockchain...")
+	var replacement types.TxData
+	if head.BaseFee != nil {
+		// Node menolak pengganti kecuali fee cap dan tip naik minimal 10%, jadi naikkan
+		// dari nilai lama dan pastikan tidak di bawah harga pasar saat ini.
+		tip := maxBig(bumpFee(old.GasTipCap(), bumpPercent), suggestedTip)
+		feeCap := new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+		replacement = &types.DynamicFeeTx{
+			ChainID:   s.chainID,
+			Nonce:     old.Nonce(),
+			GasTipCap: tip,
+			GasFeeCap: maxBig(bumpFee(old.GasFeeCap(), bumpPercent), feeCap),
+			Gas:       old.Gas(),
+			To:        old.To(),
+			Value:     old.Value(),
+			Data:      old.Data(),
+		}
+	} else {
+		gasPrice, err := s.client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("gagal mendapatkan gas price: %w", err)
+		}
+		replacement = &types.LegacyTx{
+			Nonce:    old.Nonce(),
+			GasPrice: maxBig(bumpFee(old.GasPrice(), bumpPercent), gasPrice),
+			Gas:      old.Gas(),
+			To:       old.To(),
+			Value:    old.Value(),
+			Data:     old.Data(),
+		}
+	}
 
-	// Panggil fungsi dari smart contract (dari file hasil generate abigen)
-	tx, err := s.contractInstance.MintSertifikat(auth, pendonorAddress, donorName, nomorSertifikat, donorAlamat)
+	tx, err := types.SignNewTx(s.privateKey, types.LatestSignerForChainID(s.chainID), replacement)
 	if err != nil {
-		return "", "", errors.New("gagal memanggil fungsi MintSertifikat: " + err.Error())
+		return nil, fmt.Errorf("gagal menandatangani transaksi: %w", err)
 	}
+	return newSignedTx(tx)
+}
 
-	// Ambil Transaction Hash dari objek transaksi!
-	txHash := tx.Hash().Hex()
-	log.Printf("Transaksi berhasil dikirim! Tx Hash: %s", txHash)
-	// Kembalikan hash tersebut untuk disimpan ke database
-	return txHash, certificateNumber, nil
+func (s *blockchainService) SendTransaction(ctx context.Context, raw []byte) error {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return fmt.Errorf("gagal membaca transaksi: %w", err)
+	}
+
+	log.Printf("Mengirim transaksi %s (nonce %d) ke blockchain...", tx.Hash().Hex(), tx.Nonce())
+	if err := s.client.SendTransaction(ctx, tx); err != nil {
+		return fmt.Errorf("gagal mengirim transaksi %s: %w", tx.Hash().Hex(), err)
+	}
+	return nil
+}
+
+func (s *blockchainService) TransactionReceipt(ctx context.Context, txHash string) (*TxReceipt, error) {
+	receipt, err := s.client.TransactionReceipt(ctx, common.HexToHash(txHash))
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan receipt %s: %w", txHash, err)
+	}
+
+	result := &TxReceipt{
+		Success:     receipt.Status == types.ReceiptStatusSuccessful,
+		BlockNumber: receipt.BlockNumber.Uint64(),
+	}
+	for _, l := range receipt.Logs {
+		event, err := s.contractInstance.ParseSertifikatDibuat(*l)
+		if err != nil {
+			continue
+		}
+		id := event.Id.Int64()
+		result.CertificateId = &id
+		result.DonorAddress = event.Pendonor.Hex()
+		break
+	}
+	return result, nil
+}
+
+func (s *blockchainService) GetCertificate(ctx context.Context, onChainId int64) (*OnChainCertificate, error) {
+	sertifikat, err := s.contractInstance.DaftarSertifikat(&bind.CallOpts{Context: ctx}, big.NewInt(onChainId))
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca daftarSertifikat: %w", err)
+	}
+	// Mapping Solidity mengembalikan nilai kosong untuk id yang tidak ada
+	if sertifikat.Id.Sign() == 0 && sertifikat.NomorSertifikat.Sign() == 0 {
+		return nil, nil
+	}
+	return &OnChainCertificate{
+		Id:               sertifikat.Id.Int64(),
+		Pendonor:         sertifikat.Pendonor.Hex(),
+		NamaPendonor:     sertifikat.NamaPendonor,
+		TanggalDonasi:    time.Unix(sertifikat.TanggalDonasi.Int64(), 0),
+		NomorSertifikat:  sertifikat.NomorSertifikat.String(),
+		AlamatPendonor:   sertifikat.AlamatPendonor,
+		Penerbit:         sertifikat.Penerbit,
+		AdminVerifikator: sertifikat.AdminVerifikator.Hex(),
+	}, nil
+}
+
+func (s *blockchainService) IssuerAddress() string {
+	return s.fromAddress.Hex()
+}
+
+func (s *blockchainService) BlockNumber(ctx context.Context) (uint64, error) {
+	return s.client.BlockNumber(ctx)
+}
+
+func (s *blockchainService) ConfirmedNonce(ctx context.Context) (uint64, error) {
+	return s.client.NonceAt(ctx, s.fromAddress, nil)
+}
+
+func (s *blockchainService) FilterCertificateEvents(ctx context.Context, fromBlock, toBlock uint64) ([]CertificateEvent, error) {
+	iterator, err := s.contractInstance.FilterSertifikatDibuat(&bind.FilterOpts{Start: fromBlock, End: &toBlock, Context: ctx}, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca event SertifikatDibuat blok %d-%d: %w", fromBlock, toBlock, err)
+	}
+	defer iterator.Close()
+
+	var events []CertificateEvent
+	for iterator.Next() {
+		events = append(events, newCertificateEvent(iterator.Event))
+	}
+	if err := iterator.Error(); err != nil {
+		return nil, fmt.Errorf("gagal membaca event SertifikatDibuat blok %d-%d: %w", fromBlock, toBlock, err)
+	}
+	return events, nil
+}
+
+func (s *blockchainService) WatchCertificateEvents(ctx context.Context, sink chan<- CertificateEvent) (event.Subscription, error) {
+	logs := make(chan *contracts.SertifikatDonasiSertifikatDibuat)
+	sub, err := s.contractInstance.WatchSertifikatDibuat(&bind.WatchOpts{Context: ctx}, logs, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case l := <-logs:
+				select {
+				case sink <- newCertificateEvent(l):
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+func newCertificateEvent(e *contracts.SertifikatDonasiSertifikatDibuat) CertificateEvent {
+	return CertificateEvent{
+		OnChainId:         e.Id.Int64(),
+		DonorAddress:      e.Pendonor.Hex(),
+		CertificateNumber: e.NomorSertifikat.String(),
+		BlockNumber:       e.Raw.BlockNumber,
+		BlockHash:         e.Raw.BlockHash.Hex(),
+		TxHash:            e.Raw.TxHash.Hex(),
+		LogIndex:          e.Raw.Index,
+	}
+}
+
+func newSignedTx(tx *types.Transaction) (*SignedTx, error) {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyandikan transaksi: %w", err)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca penanda tangan transaksi: %w", err)
+	}
+	return &SignedTx{
+		Hash:      tx.Hash().Hex(),
+		From:      from.Hex(),
+		Nonce:     tx.Nonce(),
+		GasFeeCap: tx.GasFeeCap(),
+		GasTipCap: tx.GasTipCap(),
+		Raw:       raw,
+	}, nil
+}
+
+// bumpFee menaikkan fee sebesar percent persen, dibulatkan ke atas
+func bumpFee(fee *big.Int, percent int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(int64(100+percent)))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
 }
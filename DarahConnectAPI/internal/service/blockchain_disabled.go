@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/event"
+)
+
+var ErrBlockchainDisabled = errors.New("blockchain tidak aktif")
+
+// disabledBlockchainService dipakai saat BLOCKCHAIN_MODE=disabled atau node tidak bisa dihubungi.
+// Semua operasi mengembalikan ErrBlockchainDisabled sehingga sertifikat tetap berstatus queued.
+type disabledBlockchainService struct{}
+
+func NewDisabledBlockchainService() BlockchainService {
+	return disabledBlockchainService{}
+}
+
+func (disabledBlockchainService) SignCertificate(ctx context.Context, req CertificateTx) (*SignedTx, error) {
+	return nil, ErrBlockchainDisabled
+}
+
+func (disabledBlockchainService) BumpTransaction(ctx context.Context, raw []byte, bumpPercent int) (*SignedTx, error) {
+	return nil, ErrBlockchainDisabled
+}
+
+func (disabledBlockchainService) SendTransaction(ctx context.Context, raw []byte) error {
+	return ErrBlockchainDisabled
+}
+
+func (disabledBlockchainService) TransactionReceipt(ctx context.Context, txHash string) (*TxReceipt, error) {
+	return nil, ErrBlockchainDisabled
+}
+
+func (disabledBlockchainService) BlockNumber(ctx context.Context) (uint64, error) {
+	return 0, ErrBlockchainDisabled
+}
+
+func (disabledBlockchainService) ConfirmedNonce(ctx context.Context) (uint64, error) {
+	return 0, ErrBlockchainDisabled
+}
+
+func (disabledBlockchainService) GetCertificate(ctx context.Context, onChainId int64) (*OnChainCertificate, error) {
+	return nil, ErrBlockchainDisabled
+}
+
+func (disabledBlockchainService) IssuerAddress() string {
+	return ""
+}
+
+func (disabledBlockchainService) FilterCertificateEvents(ctx context.Context, fromBlock, toBlock uint64) ([]CertificateEvent, error) {
+	return nil, ErrBlockchainDisabled
+}
+
+func (disabledBlockchainService) WatchCertificateEvents(ctx context.Context, sink chan<- CertificateEvent) (event.Subscription, error) {
+	return nil, ErrBlockchainDisabled
+}
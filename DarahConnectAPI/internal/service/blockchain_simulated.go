@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/contracts"
+)
+
+// newSimulatedBlockchainService menjalankan chain Ethereum di memori dengan kontrak SertifikatDonasi
+// yang langsung di-deploy. Blok baru dibuat setiap cfg.SimulatedBlockTime sampai ctx dibatalkan.
+// Data chain hilang saat aplikasi berhenti.
+func newSimulatedBlockchainService(ctx context.Context, cfg configs.BlockchainConfig) (BlockchainService, error) {
+	privateKey, err := simulatedPrivateKey(cfg.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		crypto.PubkeyToAddress(privateKey.PublicKey): {Balance: balance},
+	})
+	client := backend.Client()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		backend.Close()
+		return nil, fmt.Errorf("gagal mendapatkan ChainID: %w", err)
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+	if err != nil {
+		backend.Close()
+		return nil, fmt.Errorf("gagal membuat transactor: %w", err)
+	}
+	contractAddress, _, _, err := contracts.DeploySertifikatDonasi(auth, client)
+	if err != nil {
+		backend.Close()
+		return nil, fmt.Errorf("gagal deploy kontrak SertifikatDonasi: %w", err)
+	}
+	backend.Commit()
+
+	service, err := NewBlockchainServiceFromClient(client, contractAddress, privateKey)
+	if err != nil {
+		backend.Close()
+		return nil, err
+	}
+
+	blockTime := cfg.SimulatedBlockTime
+	if blockTime <= 0 {
+		blockTime = 2 * time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(blockTime)
+		defer ticker.Stop()
+		defer backend.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				backend.Commit()
+			}
+		}
+	}()
+
+	log.Printf("Blockchain simulated aktif, kontrak SertifikatDonasi di %s", contractAddress.Hex())
+	return service, nil
+}
+
+// simulatedPrivateKey memakai PRIVATE_KEY jika diisi, selain itu membuat key baru
+func simulatedPrivateKey(hexKey string) (*ecdsa.PrivateKey, error) {
+	if hexKey == "" {
+		return crypto.GenerateKey()
+	}
+	privateKey, err := crypto.HexToECDSA(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memuat private key: %w", err)
+	}
+	return privateKey, nil
+}
@@ -0,0 +1,277 @@
+package service_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/contracts"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type simulatedChain struct {
+	backend    *simulated.Backend
+	blockchain service.BlockchainService
+	key        *ecdsa.PrivateKey
+}
+
+func newSimulatedChain(t *testing.T) *simulatedChain {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		from: {Balance: new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))},
+	})
+	t.Cleanup(func() { backend.Close() })
+
+	chainID, err := backend.Client().ChainID(context.Background())
+	require.NoError(t, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	require.NoError(t, err)
+
+	address, deployTx, _, err := contracts.DeploySertifikatDonasi(auth, backend.Client())
+	require.NoError(t, err)
+	backend.Commit()
+	// The simulated backend indexes transactions asynchronously; wait until it
+	// has caught up so receipt lookups in the tests never see a half-built index.
+	require.Eventually(t, func() bool {
+		_, err := backend.Client().TransactionReceipt(context.Background(), deployTx.Hash())
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	blockchain, err := service.NewBlockchainServiceFromClient(backend.Client(), address, key)
+	require.NoError(t, err)
+	return &simulatedChain{backend, blockchain, key}
+}
+
+func (c *simulatedChain) mint(t *testing.T, number, donor string) *service.SignedTx {
+	ctx := context.Background()
+	signed, err := c.blockchain.SignCertificate(ctx, service.CertificateTx{
+		CertificateNumber: number,
+		DonorAddress:      donor,
+		DonorName:         "Budi",
+		DonorAlamat:       "Jl. Merdeka 1, Semarang, Jawa Tengah",
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.blockchain.SendTransaction(ctx, signed.Raw))
+	c.backend.Commit()
+	return signed
+}
+
+func TestBlockchainServiceMintAndRead(t *testing.T) {
+	ctx := context.Background()
+	chain := newSimulatedChain(t)
+	donor := common.HexToAddress("0x00000000000000000000000000000000000000aa").Hex()
+
+	signed := chain.mint(t, "20241018001", donor)
+
+	receipt, err := chain.blockchain.TransactionReceipt(ctx, signed.Hash)
+	require.NoError(t, err)
+	require.NotNil(t, receipt)
+	assert.True(t, receipt.Success)
+	require.NotNil(t, receipt.CertificateId)
+	assert.Equal(t, int64(1), *receipt.CertificateId)
+
+	certificate, err := chain.blockchain.GetCertificate(ctx, *receipt.CertificateId)
+	require.NoError(t, err)
+	require.NotNil(t, certificate)
+	assert.Equal(t, "20241018001", certificate.NomorSertifikat)
+	assert.Equal(t, donor, certificate.Pendonor)
+	assert.Equal(t, "Budi", certificate.NamaPendonor)
+	assert.Equal(t, "DarahConnect", certificate.Penerbit)
+	assert.Equal(t, chain.blockchain.IssuerAddress(), certificate.AdminVerifikator)
+
+	missing, err := chain.blockchain.GetCertificate(ctx, 99)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	unknown, err := chain.blockchain.TransactionReceipt(ctx, common.Hash{}.Hex())
+	require.NoError(t, err)
+	assert.Nil(t, unknown)
+}
+
+func TestBlockchainServiceBumpTransaction(t *testing.T) {
+	ctx := context.Background()
+	chain := newSimulatedChain(t)
+
+	signed, err := chain.blockchain.SignCertificate(ctx, service.CertificateTx{
+		CertificateNumber: "20241018002",
+		DonorAddress:      common.HexToAddress("0x00000000000000000000000000000000000000bb").Hex(),
+		DonorName:         "Siti",
+		DonorAlamat:       "Jl. Pemuda 2, Semarang, Jawa Tengah",
+	})
+	require.NoError(t, err)
+
+	bumped, err := chain.blockchain.BumpTransaction(ctx, signed.Raw, 20)
+	require.NoError(t, err)
+	assert.Equal(t, signed.Nonce, bumped.Nonce)
+	assert.NotEqual(t, signed.Hash, bumped.Hash)
+	assert.True(t, bumped.GasTipCap.Cmp(signed.GasTipCap) > 0)
+	assert.True(t, bumped.GasFeeCap.Cmp(signed.GasFeeCap) > 0)
+
+	require.NoError(t, chain.blockchain.SendTransaction(ctx, bumped.Raw))
+	chain.backend.Commit()
+
+	receipt, err := chain.blockchain.TransactionReceipt(ctx, bumped.Hash)
+	require.NoError(t, err)
+	require.NotNil(t, receipt)
+	assert.True(t, receipt.Success)
+
+	nonce, err := chain.blockchain.ConfirmedNonce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, signed.Nonce+1, nonce)
+}
+
+func TestNewBlockchainServiceSimulated(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blockchain, err := service.NewBlockchainService(ctx, configs.BlockchainConfig{
+		Mode:               service.BlockchainModeSimulated,
+		SimulatedBlockTime: 50 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, blockchain.IssuerAddress())
+
+	signed, err := blockchain.SignCertificate(ctx, service.CertificateTx{
+		CertificateNumber: "20241018001",
+		DonorAddress:      common.HexToAddress("0x00000000000000000000000000000000000000aa").Hex(),
+		DonorName:         "Budi",
+		DonorAlamat:       "Jl. Merdeka 1, Semarang, Jawa Tengah",
+	})
+	require.NoError(t, err)
+	require.NoError(t, blockchain.SendTransaction(ctx, signed.Raw))
+
+	var receipt *service.TxReceipt
+	require.Eventually(t, func() bool {
+		receipt, err = blockchain.TransactionReceipt(ctx, signed.Hash)
+		return err == nil && receipt != nil
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.True(t, receipt.Success)
+	require.NotNil(t, receipt.CertificateId)
+
+	certificate, err := blockchain.GetCertificate(ctx, *receipt.CertificateId)
+	require.NoError(t, err)
+	require.NotNil(t, certificate)
+	assert.Equal(t, "20241018001", certificate.NomorSertifikat)
+}
+
+func TestNewBlockchainServiceDisabled(t *testing.T) {
+	ctx := context.Background()
+
+	blockchain, err := service.NewBlockchainService(ctx, configs.BlockchainConfig{Mode: service.BlockchainModeDisabled})
+	require.NoError(t, err)
+
+	_, err = blockchain.SignCertificate(ctx, service.CertificateTx{CertificateNumber: "20241018001"})
+	assert.ErrorIs(t, err, service.ErrBlockchainDisabled)
+	_, err = blockchain.GetCertificate(ctx, 1)
+	assert.ErrorIs(t, err, service.ErrBlockchainDisabled)
+	assert.Empty(t, blockchain.IssuerAddress())
+
+	_, err = service.NewBlockchainService(ctx, configs.BlockchainConfig{Mode: "ganache"})
+	assert.Error(t, err)
+}
+
+func TestCertificateIndexerSync(t *testing.T) {
+	ctx := context.Background()
+	chain := newSimulatedChain(t)
+	donor := common.HexToAddress("0x00000000000000000000000000000000000000cc").Hex()
+
+	chain.mint(t, "20241018003", donor)
+	chain.mint(t, "20241018004", donor)
+
+	repo := newFakeChainIndexRepository()
+	cfg := &configs.BlockchainConfig{Confirmations: 2, IndexerBlockRange: 1, ReconcileGracePeriod: time.Hour}
+	indexer := service.NewCertificateIndexerService(repo, passthroughTransactor{}, chain.blockchain, cfg)
+
+	// Mint kedua baru punya satu konfirmasi sehingga belum diindeks
+	require.NoError(t, indexer.Sync(ctx))
+	require.Len(t, repo.events, 1)
+	assert.Equal(t, "20241018003", repo.events[0].CertificateNumber)
+	assert.Equal(t, donor, repo.events[0].DonorAddress)
+
+	chain.backend.Commit()
+	require.NoError(t, indexer.Sync(ctx))
+	require.Len(t, repo.events, 2)
+	assert.Equal(t, "20241018004", repo.events[1].CertificateNumber)
+	assert.Equal(t, int64(2), repo.events[1].OnChainId)
+
+	head, err := chain.blockchain.BlockNumber(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(head-1), repo.state.LastBlock)
+
+	// Sync berikutnya melanjutkan dari blok terakhir tanpa memproses ulang event lama
+	require.NoError(t, indexer.Sync(ctx))
+	assert.Len(t, repo.events, 2)
+}
+
+type passthroughTransactor struct{}
+
+func (passthroughTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type fakeChainIndexRepository struct {
+	state  *entity.ChainSyncState
+	events []entity.ChainCertificateEvent
+	seen   map[string]bool
+}
+
+func newFakeChainIndexRepository() *fakeChainIndexRepository {
+	return &fakeChainIndexRepository{seen: map[string]bool{}}
+}
+
+func (r *fakeChainIndexRepository) GetSyncState(ctx context.Context, name string) (*entity.ChainSyncState, error) {
+	return r.state, nil
+}
+
+func (r *fakeChainIndexRepository) SaveSyncState(ctx context.Context, state *entity.ChainSyncState) error {
+	r.state = state
+	return nil
+}
+
+func (r *fakeChainIndexRepository) SaveCertificateEvents(ctx context.Context, events []entity.ChainCertificateEvent) error {
+	for _, e := range events {
+		key := fmt.Sprintf("%s/%d", e.TxHash, e.LogIndex)
+		if r.seen[key] {
+			continue
+		}
+		r.seen[key] = true
+		r.events = append(r.events, e)
+	}
+	return nil
+}
+
+func (r *fakeChainIndexRepository) LinkCertificates(ctx context.Context) error {
+	return nil
+}
+
+func (r *fakeChainIndexRepository) GetOnChainOnly(ctx context.Context) ([]dto.ChainReconciliationItem, error) {
+	return nil, nil
+}
+
+func (r *fakeChainIndexRepository) GetDbOnly(ctx context.Context, lastBlock int64, createdBefore time.Time) ([]dto.ChainReconciliationItem, error) {
+	return nil, nil
+}
+
+func (r *fakeChainIndexRepository) GetMismatched(ctx context.Context) ([]dto.ChainReconciliationItem, error) {
+	return nil, nil
+}
+
+func (r *fakeChainIndexRepository) GetDuplicates(ctx context.Context) ([]dto.ChainReconciliationItem, error) {
+	return nil, nil
+}
@@ -7,30 +7,50 @@ import (
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/audit"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/cloudinary"
 )
 
+// ErrBloodDonationNotPending menandai donasi darah yang statusnya sudah tidak bisa diubah
+var ErrBloodDonationNotPending = errors.New("Donasi darah tidak bisa diubah")
+
 type BloodDonationService interface {
 	Create(ctx context.Context, req dto.BloodDonationCreateRequest) error
 	GetAll(ctx context.Context, req dto.GetAllBloodDonationRequest) ([]entity.BloodDonation, int64, error)
 	GetByUserId(ctx context.Context, userId int64, req dto.GetAllBloodDonationRequest) ([]entity.BloodDonation, int64, error)
 	GetById(ctx context.Context, id int64) (*entity.BloodDonation, error)
 	Update(ctx context.Context, req dto.BloodDonationUpdateRequest, bloodDonation *entity.BloodDonation) (*entity.BloodDonation,error)
+	UpdateStatus(ctx context.Context, req dto.BloodDonationUpdateRequest) error
 	Delete(ctx context.Context, id int64) error
 }
 
 type bloodDonationService struct {
 	bloodDonationRepository repository.BloodDonationRepository
 	cloudinaryService       cloudinary.Service
+	certificateService      CertificateService
+	inventoryService        InventoryService
+	outboxService           OutboxService
+	transactor              repository.Transactor
+	auditLogService         AuditLogService
 }
 
 func NewBloodDonationService(
 	bloodDonationRepository repository.BloodDonationRepository,
 	cloudinaryService cloudinary.Service,
+	certificateService CertificateService,
+	inventoryService InventoryService,
+	outboxService OutboxService,
+	transactor repository.Transactor,
+	auditLogService AuditLogService,
 ) BloodDonationService {
 	return &bloodDonationService{
 		bloodDonationRepository,
 		cloudinaryService,
+		certificateService,
+		inventoryService,
+		outboxService,
+		transactor,
+		auditLogService,
 	}
 }
 
@@ -92,6 +112,7 @@ func (s *bloodDonationService) GetById(ctx context.Context, id int64) (*entity.B
 }
 
 func (s *bloodDonationService) Update(ctx context.Context, req dto.BloodDonationUpdateRequest, bloodDonation *entity.BloodDonation) (*entity.BloodDonation, error) {
+    before := *bloodDonation
     if !req.DonationDate.IsZero() {
         bloodDonation.DonationDate = req.DonationDate
     }
@@ -119,14 +140,24 @@ func (s *bloodDonationService) Update(ctx context.Context, req dto.BloodDonation
         bloodDonation.PublicId = publicId
     }
 
-    if err := s.bloodDonationRepository.Update(ctx, bloodDonation); err != nil {
+    action := "blood_donation.update"
+    if before.Status != bloodDonation.Status {
+        action = "blood_donation.status"
+    }
+    err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+        if err := s.bloodDonationRepository.Update(ctx, bloodDonation); err != nil {
+            return errors.New("Gagal mengupdate donasi darah")
+        }
+        return s.auditLogService.Record(ctx, audit.Entry{Action: action, EntityType: "blood_donation", EntityId: bloodDonation.Id, Before: before, After: bloodDonation})
+    })
+    if err != nil {
         // Jika database update gagal dan ada gambar baru yang diunggah, hapus gambar baru
         if req.Image != nil {
             if err := s.cloudinaryService.DeleteFile(newPublicId); err != nil {
                 return nil, errors.New("Gagal menghapus gambar baru")
             }
         }
-        return nil, errors.New("Gagal mengupdate donasi darah")
+        return nil, err
     }
     
     // Jika berhasil dan ada gambar lama, hapus gambar lama
@@ -139,15 +170,60 @@ func (s *bloodDonationService) Update(ctx context.Context, req dto.BloodDonation
     return bloodDonation, nil
 }
 
+// UpdateStatus mengubah status donasi darah yang masih pending. Baris donasi dikunci selama
+// transaksi sehingga dua permintaan "completed" yang bersamaan tidak menerbitkan sertifikat
+// atau menambah stok dua kali. Minting blockchain, notifikasi, dan email dikirim oleh outbox
+// dispatcher setelah commit.
+func (s *bloodDonationService) UpdateStatus(ctx context.Context, req dto.BloodDonationUpdateRequest) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		bloodDonation, err := s.bloodDonationRepository.GetByIdForUpdate(ctx, req.Id)
+		if err != nil {
+			return errors.New("Gagal mengambil donasi darah")
+		}
+		if bloodDonation.Status != "pending" {
+			return ErrBloodDonationNotPending
+		}
+
+		if _, err := s.Update(ctx, req, bloodDonation); err != nil {
+			return errors.New("Gagal memperbarui status donasi darah: " + err.Error())
+		}
+
+		notif := NotificationCreatePayload{
+			UserId:           bloodDonation.UserId,
+			Title:            "Status Donasi Darah",
+			Message:          "Status donasi darah anda telah " + req.Status,
+			NotificationType: "information",
+		}
+		if req.Status == "completed" {
+			certificate, err := s.certificateService.Create(ctx, bloodDonation)
+			if err != nil {
+				return errors.New("Gagal membuat sertifikat: " + err.Error())
+			}
+			notif.Message += " dengan nomor sertifikat " + certificate.CertificateNumber + ". Sertifikat sedang diterbitkan di blockchain"
+
+			// Donasi yang selesai menambah satu kantong darah ke stok rumah sakit
+			if _, err := s.inventoryService.AddFromDonation(ctx, bloodDonation); err != nil {
+				return err
+			}
+		}
+
+		if err := s.outboxService.Notify(ctx, notif); err != nil {
+			return errors.New("Gagal membuat notifikasi: " + err.Error())
+		}
+		return nil
+	})
+}
+
 func (s *bloodDonationService) Delete(ctx context.Context, id int64) error {
 	bloodDonation, err := s.bloodDonationRepository.GetById(ctx, id)
 	if err != nil {
 		return errors.New("Gagal mengambil donasi darah")
 	}
 
-	if err := s.bloodDonationRepository.Delete(ctx, bloodDonation); err != nil {
-		return errors.New("Gagal menghapus donasi darah")
-	}
-
-	return nil
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.bloodDonationRepository.Delete(ctx, bloodDonation); err != nil {
+			return errors.New("Gagal menghapus donasi darah")
+		}
+		return s.auditLogService.Record(ctx, audit.Entry{Action: "blood_donation.delete", EntityType: "blood_donation", EntityId: bloodDonation.Id, Before: bloodDonation})
+	})
 }
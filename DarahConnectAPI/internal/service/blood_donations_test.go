@@ -0,0 +1,52 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/cloudinary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBloodDonationUpdateStatusCompletesOnce(t *testing.T) {
+	donations := &lockingBloodDonationRepository{donation: entity.BloodDonation{Id: 4, UserId: 7, HospitalId: 2, BloodType: "B+", Status: "pending"}}
+	certificates := &fakeCertificateRepository{certificates: map[int64]*entity.Certificate{}}
+	bags := &fakeBloodBagRepository{}
+	outbox := &fakeOutboxService{}
+	certificateService := service.NewCertificateService(certificates, &fakeCertificateClaimRepository{}, &fakeUserRepository{}, donations, passthroughTransactor{}, outbox, nil, nil, nil)
+	auditLogService := service.NewAuditLogService(&fakeAuditLogRepository{}, passthroughTransactor{})
+	bloodDonationService := service.NewBloodDonationService(donations, cloudinary.Service{}, certificateService, service.NewInventoryService(bags), outbox, passthroughTransactor{}, auditLogService)
+
+	req := dto.BloodDonationUpdateRequest{Id: 4, Status: "completed"}
+	require.NoError(t, bloodDonationService.UpdateStatus(context.Background(), req))
+	assert.Equal(t, "completed", donations.donation.Status)
+	assert.Len(t, certificates.certificates, 1)
+	assert.Len(t, bags.bags, 1)
+
+	// Permintaan kedua membaca ulang status di bawah kunci baris dan ditolak
+	err := bloodDonationService.UpdateStatus(context.Background(), req)
+	assert.ErrorIs(t, err, service.ErrBloodDonationNotPending)
+	assert.Len(t, certificates.certificates, 1)
+	assert.Len(t, bags.bags, 1)
+	assert.Equal(t, []string{service.OutboxCertificateMint, service.OutboxNotificationCreate}, outbox.events)
+}
+
+type lockingBloodDonationRepository struct {
+	repository.BloodDonationRepository
+	donation entity.BloodDonation
+}
+
+func (r *lockingBloodDonationRepository) GetByIdForUpdate(ctx context.Context, id int64) (*entity.BloodDonation, error) {
+	bloodDonation := r.donation
+	return &bloodDonation, nil
+}
+
+func (r *lockingBloodDonationRepository) Update(ctx context.Context, bloodDonation *entity.BloodDonation) error {
+	r.donation = *bloodDonation
+	return nil
+}
@@ -3,11 +3,16 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/audit"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/cloudinary"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/compatibility"
 )
 
 type BloodRequestService interface {
@@ -15,24 +20,39 @@ type BloodRequestService interface {
 	CreateBloodRequest(ctx context.Context, req dto.BloodRequestCreateRequest) error
 	CreateCampaign(ctx context.Context, req dto.CampaignCreateRequest) error
 	GetAllBloodRequest(ctx context.Context, req dto.GetAllBloodRequestRequest) ([]entity.BloodRequest, int64, error)
+	GetCompatibleBloodRequest(ctx context.Context, donorBloodType string, req dto.GetAllBloodRequestRequest) ([]entity.BloodRequest, int64, error)
 	GetAllBloodRequestByUser(ctx context.Context, userId int64, req dto.GetAllBloodRequestRequest) ([]entity.BloodRequest, int64, error)
 	GetAllAdminBloodRequest(ctx context.Context, req dto.GetAllBloodRequestRequest) ([]entity.BloodRequest, int64, error)
 	GetAllCampaign(ctx context.Context, req dto.GetAllBloodRequestRequest) ([]entity.BloodRequest, int64, error)
 	GetById(ctx context.Context, id int64) (*entity.BloodRequest, error)
 	UpdateCampaign(ctx context.Context, req dto.CampaignUpdateRequest, bloodRequest *entity.BloodRequest) error
 	UpdateBloodRequest(ctx context.Context, req dto.BloodRequestUpdateRequest, bloodRequest *entity.BloodRequest) error
+	// UpdateStatus mengubah status permintaan darah. Permintaan darah yang dipenuhi memakai kantong
+	// darah dari stok rumah sakit, dan permintaan darurat yang baru diverifikasi mengantrekan alert
+	// ke pendonor terdekat, dalam transaksi yang sama dengan perubahan statusnya.
+	UpdateStatus(ctx context.Context, req dto.BloodRequestUpdateRequest, bloodRequest *entity.BloodRequest) error
 	Delete(ctx context.Context, id int64) error
 }
 
 type bloodRequestService struct {
 	bloodRequestRepository repository.BloodRequestRepository
+	donationsRepository    repository.DonationsRepository
 	cloudinaryService     cloudinary.Service
+	inventoryService       InventoryService
+	emergencyAlertService  EmergencyAlertService
+	transactor             repository.Transactor
+	auditLogService        AuditLogService
 }
 
-func NewBloodRequestService(bloodRequestRepository repository.BloodRequestRepository, cloudinaryService cloudinary.Service) BloodRequestService {
+func NewBloodRequestService(bloodRequestRepository repository.BloodRequestRepository, donationsRepository repository.DonationsRepository, cloudinaryService cloudinary.Service, inventoryService InventoryService, emergencyAlertService EmergencyAlertService, transactor repository.Transactor, auditLogService AuditLogService) BloodRequestService {
 	return &bloodRequestService{
 		bloodRequestRepository,
+		donationsRepository,
 		cloudinaryService,
+		inventoryService,
+		emergencyAlertService,
+		transactor,
+		auditLogService,
 	}
 }
 
@@ -83,6 +103,8 @@ func (s *bloodRequestService) CreateCampaign(ctx context.Context, req dto.Campai
 	bloodRequest.EventDate = req.EventDate
 	bloodRequest.EventType = "campaign"
 	bloodRequest.Status = "verified"
+	bloodRequest.GoalAmount = req.GoalAmount
+	bloodRequest.DonationDeadline = req.DonationDeadline
 
 	if req.Image != nil {
 		UrlFile, publicId, err := s.cloudinaryService.UploadFile(req.Image, "BloodRequests")
@@ -104,7 +126,35 @@ func (s *bloodRequestService) CreateCampaign(ctx context.Context, req dto.Campai
 	return nil
 }
 
+// GetAllBloodRequest menampilkan permintaan darah publik. Parameter blood_type dibaca sebagai
+// golongan darah pendonor, sehingga yang tampil adalah permintaan yang bisa dibantu olehnya.
 func (s *bloodRequestService) GetAllBloodRequest(ctx context.Context, req dto.GetAllBloodRequestRequest) ([]entity.BloodRequest, int64, error) {
+	if req.BloodType != "" {
+		donorBloodType, err := compatibility.Normalize(req.BloodType)
+		if err != nil {
+			return nil, 0, errors.New("Golongan darah tidak valid")
+		}
+		req.BloodTypes = compatibility.RecipientsFor(donorBloodType, compatibility.RedCells)
+		req.BloodType = ""
+	}
+
+	bloodRequests, total, err := s.bloodRequestRepository.GetAllBloodRequest(ctx, req)
+	if err != nil {
+		return nil, 0, errors.New("Gagal mendapatkan permintaan darah")
+	}
+
+	return bloodRequests, total, nil
+}
+
+// GetCompatibleBloodRequest menampilkan permintaan darah yang dapat dibantu oleh pendonor dengan golongan darah tertentu
+func (s *bloodRequestService) GetCompatibleBloodRequest(ctx context.Context, donorBloodType string, req dto.GetAllBloodRequestRequest) ([]entity.BloodRequest, int64, error) {
+	bloodType, err := compatibility.Normalize(donorBloodType)
+	if err != nil {
+		return nil, 0, errors.New("Golongan darah pengguna belum diisi atau tidak valid")
+	}
+	req.BloodType = ""
+	req.BloodTypes = compatibility.RecipientsFor(bloodType, compatibility.RedCells)
+
 	bloodRequests, total, err := s.bloodRequestRepository.GetAllBloodRequest(ctx, req)
 	if err != nil {
 		return nil, 0, errors.New("Gagal mendapatkan permintaan darah")
@@ -146,10 +196,30 @@ func (s *bloodRequestService) GetById(ctx context.Context, id int64) (*entity.Bl
 		return nil, errors.New("Permintaan darah tidak ditemukan")
 	}
 
+	// Perolehan donasi uang hanya ditampilkan untuk campaign yang menggalang dana
+	if bloodRequest.GoalAmount > 0 {
+		progress, err := s.donationsRepository.GetBloodRequestProgress(ctx, bloodRequest.Id)
+		if err != nil {
+			return nil, errors.New("Gagal menghitung perolehan donasi")
+		}
+		bloodRequest.Fundraising = fundraisingProgress(progress, bloodRequest.GoalAmount, bloodRequest.DonationDeadline, bloodRequestAcceptsDonation(bloodRequest.Status), time.Now())
+	}
+
 	return bloodRequest, nil
 }
 
+// bloodRequestAcceptsDonation melaporkan apakah status permintaan darah masih menerima donasi uang
+func bloodRequestAcceptsDonation(status string) bool {
+	switch strings.ToLower(status) {
+	case "cancelled", "expired", "fulfilled", "rejected":
+		return false
+	default:
+		return true
+	}
+}
+
 func (s *bloodRequestService) UpdateBloodRequest(ctx context.Context, req dto.BloodRequestUpdateRequest, bloodRequest *entity.BloodRequest) error {
+	before := *bloodRequest
 	if req.EventName != "" {
 		bloodRequest.EventName = req.EventName
 	}
@@ -190,22 +260,52 @@ func (s *bloodRequestService) UpdateBloodRequest(ctx context.Context, req dto.Bl
 		bloodRequest.PublicId = publicId
 	}
 
-	if err := s.bloodRequestRepository.Update(ctx, bloodRequest); err != nil {
+	action := "blood_request.update"
+	if before.Status != bloodRequest.Status {
+		action = "blood_request.status"
+	}
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.bloodRequestRepository.Update(ctx, bloodRequest); err != nil {
+			return errors.New("Gagal mengupdate permintaan darah")
+		}
+		return s.auditLogService.Record(ctx, audit.Entry{Action: action, EntityType: "blood_request", EntityId: bloodRequest.Id, Before: before, After: bloodRequest})
+	})
+	if err != nil {
 		// Jika gagal update database dan ada gambar baru yang diupload, hapus gambar baru
 		if newPublicId != "" {
 			_ = s.cloudinaryService.DeleteFile(newPublicId)
 		}
-		return errors.New("Gagal mengupdate permintaan darah")
+		return err
 	}
 
 	// Jika berhasil update database dan ada gambar baru, hapus gambar lama
 	if oldPublicId != "" && newPublicId != "" {
 		_ = s.cloudinaryService.DeleteFile(oldPublicId)
 	}
-
 	return nil
 }
 
+func (s *bloodRequestService) UpdateStatus(ctx context.Context, req dto.BloodRequestUpdateRequest, bloodRequest *entity.BloodRequest) error {
+	consume := req.Status == "fulfilled" && bloodRequest.Status != "fulfilled" && bloodRequest.EventType == "blood_request"
+	alert := req.Status == "verified" && bloodRequest.Status != "verified" && bloodRequest.EventType == "blood_request" && s.emergencyAlertService.IsUrgent(bloodRequest)
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if consume {
+			if _, err := s.inventoryService.ConsumeForRequest(ctx, bloodRequest); err != nil {
+				return fmt.Errorf("%w: %v", ErrBloodStockUnavailable, err)
+			}
+		}
+		if err := s.UpdateBloodRequest(ctx, req, bloodRequest); err != nil {
+			return err
+		}
+		if alert {
+			if _, err := s.emergencyAlertService.Dispatch(ctx, bloodRequest); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (s *bloodRequestService) UpdateCampaign(ctx context.Context, req dto.CampaignUpdateRequest, bloodRequest *entity.BloodRequest) error {
 	if req.EventName != "" {
 		bloodRequest.EventName = req.EventName
@@ -221,6 +321,12 @@ func (s *bloodRequestService) UpdateCampaign(ctx context.Context, req dto.Campai
 	}
 	bloodRequest.SlotsAvailable = req.SlotsAvailable
 	bloodRequest.SlotsBooked = req.SlotsBooked
+	if req.GoalAmount != nil {
+		bloodRequest.GoalAmount = *req.GoalAmount
+	}
+	if req.DonationDeadline != nil {
+		bloodRequest.DonationDeadline = req.DonationDeadline
+	}
 
 	// Simpan publicId lama sebelum mengubahnya
 	oldPublicId := bloodRequest.PublicId
@@ -262,8 +368,14 @@ func (s *bloodRequestService) Delete(ctx context.Context, id int64) error {
 	// Simpan publicId untuk dihapus setelah data dihapus dari database
 	publicId := bloodRequest.PublicId
 
-	if err := s.bloodRequestRepository.Delete(ctx, bloodRequest); err != nil {
-		return errors.New("Gagal menghapus permintaan darah")
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.bloodRequestRepository.Delete(ctx, bloodRequest); err != nil {
+			return errors.New("Gagal menghapus permintaan darah")
+		}
+		return s.auditLogService.Record(ctx, audit.Entry{Action: "blood_request.delete", EntityType: "blood_request", EntityId: bloodRequest.Id, Before: bloodRequest})
+	})
+	if err != nil {
+		return err
 	}
 
 	// Hapus gambar dari cloudinary jika ada
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+)
+
+// certificateIndexerName adalah nama indexer di tabel chain_sync_states
+const certificateIndexerName = "certificate_events"
+
+type CertificateIndexerService interface {
+	// Sync memproses event SertifikatDibuat dari blok terakhir yang tersimpan sampai blok
+	// yang sudah cukup konfirmasi
+	Sync(ctx context.Context) error
+	// Watch mengirim sinyal ke trigger setiap ada event baru agar Sync bisa segera dijalankan
+	Watch(ctx context.Context, trigger chan<- struct{}) (event.Subscription, error)
+	Report(ctx context.Context) (*dto.ChainReconciliationReport, error)
+}
+
+type certificateIndexerService struct {
+	chainIndexRepository repository.ChainIndexRepository
+	transactor           repository.Transactor
+	blockchain           BlockchainService
+	cfg                  *configs.BlockchainConfig
+}
+
+func NewCertificateIndexerService(
+	chainIndexRepository repository.ChainIndexRepository,
+	transactor repository.Transactor,
+	blockchain BlockchainService,
+	cfg *configs.BlockchainConfig,
+) CertificateIndexerService {
+	return &certificateIndexerService{chainIndexRepository, transactor, blockchain, cfg}
+}
+
+func (s *certificateIndexerService) Sync(ctx context.Context) error {
+	head, err := s.blockchain.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal mendapatkan nomor blok: %w", err)
+	}
+	// Hanya blok dengan jumlah konfirmasi yang cukup yang diindeks agar aman dari reorg
+	if s.cfg.Confirmations > 1 {
+		if head < uint64(s.cfg.Confirmations-1) {
+			return nil
+		}
+		head -= uint64(s.cfg.Confirmations - 1)
+	}
+
+	from, err := s.nextBlock(ctx)
+	if err != nil {
+		return err
+	}
+
+	blockRange := s.cfg.IndexerBlockRange
+	if blockRange == 0 {
+		blockRange = 1
+	}
+	for from <= head {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		to := min(from+blockRange-1, head)
+
+		events, err := s.blockchain.FilterCertificateEvents(ctx, from, to)
+		if err != nil {
+			return err
+		}
+
+		// Event dan posisi blok disimpan bersamaan supaya tidak ada rentang yang terlewat
+		err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			if len(events) > 0 {
+				if err := s.chainIndexRepository.SaveCertificateEvents(ctx, newChainCertificateEvents(events)); err != nil {
+					return err
+				}
+				if err := s.chainIndexRepository.LinkCertificates(ctx); err != nil {
+					return err
+				}
+			}
+			return s.chainIndexRepository.SaveSyncState(ctx, &entity.ChainSyncState{
+				Name:      certificateIndexerName,
+				LastBlock: int64(to),
+				UpdatedAt: time.Now(),
+			})
+		})
+		if err != nil {
+			return fmt.Errorf("gagal menyimpan event blok %d-%d: %w", from, to, err)
+		}
+		if len(events) > 0 {
+			log.Printf("Certificate indexer: %d event SertifikatDibuat diindeks dari blok %d-%d", len(events), from, to)
+		}
+		from = to + 1
+	}
+	return nil
+}
+
+// nextBlock adalah blok pertama yang belum diproses, dimulai dari blok deploy kontrak
+func (s *certificateIndexerService) nextBlock(ctx context.Context) (uint64, error) {
+	state, err := s.chainIndexRepository.GetSyncState(ctx, certificateIndexerName)
+	if err != nil {
+		return 0, fmt.Errorf("gagal mendapatkan posisi indexer: %w", err)
+	}
+	if state == nil || uint64(state.LastBlock) < s.cfg.DeployBlock {
+		return s.cfg.DeployBlock, nil
+	}
+	return uint64(state.LastBlock) + 1, nil
+}
+
+func (s *certificateIndexerService) Watch(ctx context.Context, trigger chan<- struct{}) (event.Subscription, error) {
+	events := make(chan CertificateEvent)
+	sub, err := s.blockchain.WatchCertificateEvents(ctx, events)
+	if err != nil {
+		return nil, err
+	}
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case <-events:
+				select {
+				case trigger <- struct{}{}:
+				default:
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// Report menyusun laporan rekonsiliasi antara event yang sudah diindeks dan tabel certificates
+func (s *certificateIndexerService) Report(ctx context.Context) (*dto.ChainReconciliationReport, error) {
+	state, err := s.chainIndexRepository.GetSyncState(ctx, certificateIndexerName)
+	if err != nil {
+		return nil, errors.New("Gagal mendapatkan posisi indexer")
+	}
+	report := &dto.ChainReconciliationReport{LastIndexedBlock: int64(s.cfg.DeployBlock) - 1}
+	if state != nil {
+		report.LastIndexedBlock = state.LastBlock
+	}
+
+	// Laporan tetap ditampilkan walaupun node blockchain tidak bisa dihubungi
+	if head, err := s.blockchain.BlockNumber(ctx); err != nil {
+		log.Printf("Gagal mendapatkan nomor blok untuk laporan rekonsiliasi: %v", err)
+	} else {
+		report.HeadBlock = head
+	}
+
+	if report.OnChainOnly, err = s.chainIndexRepository.GetOnChainOnly(ctx); err != nil {
+		return nil, errors.New("Gagal mendapatkan event tanpa sertifikat")
+	}
+	if report.DbOnly, err = s.chainIndexRepository.GetDbOnly(ctx, report.LastIndexedBlock, time.Now().Add(-s.cfg.ReconcileGracePeriod)); err != nil {
+		return nil, errors.New("Gagal mendapatkan sertifikat tanpa event")
+	}
+	if report.Mismatched, err = s.chainIndexRepository.GetMismatched(ctx); err != nil {
+		return nil, errors.New("Gagal mendapatkan sertifikat yang tidak sesuai")
+	}
+	if report.Duplicates, err = s.chainIndexRepository.GetDuplicates(ctx); err != nil {
+		return nil, errors.New("Gagal mendapatkan sertifikat ganda")
+	}
+	return report, nil
+}
+
+func newChainCertificateEvents(events []CertificateEvent) []entity.ChainCertificateEvent {
+	rows := make([]entity.ChainCertificateEvent, 0, len(events))
+	for _, e := range events {
+		rows = append(rows, entity.ChainCertificateEvent{
+			OnChainId:         e.OnChainId,
+			DonorAddress:      e.DonorAddress,
+			CertificateNumber: e.CertificateNumber,
+			BlockNumber:       int64(e.BlockNumber),
+			BlockHash:         e.BlockHash,
+			TxHash:            e.TxHash,
+			LogIndex:          int(e.LogIndex),
+		})
+	}
+	return rows
+}
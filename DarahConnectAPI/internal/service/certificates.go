@@ -1,16 +1,37 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/certificatepdf"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/timezone"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/utils"
 )
 
+var ErrCertificateNotFound = errors.New("Sertifikat tidak ditemukan")
+
 type CertificateService interface {
-	Create(ctx context.Context, bloodDonation *entity.BloodDonation, certificateNumber, digitalSignature string) (*entity.Certificate, error)
+	Create(ctx context.Context, bloodDonation *entity.BloodDonation) (*entity.Certificate, error)
+	Mint(ctx context.Context, certificateId int64) error
+	RefreshChainStatuses(ctx context.Context) error
+	RequeueMints(ctx context.Context) (int, error)
+	Claim(ctx context.Context, userId, certificateId int64) (*entity.CertificateClaim, error)
+	GetClaims(ctx context.Context, userId, certificateId int64) ([]entity.CertificateClaim, error)
+	Verify(ctx context.Context, certificateNumber string) (*dto.CertificateVerificationResponse, error)
+	RenderPDF(ctx context.Context, certificateId int64) ([]byte, error)
 	GetAll(ctx context.Context, req dto.GetAllCertificateRequest) ([]entity.Certificate, int64, error)
 	GetById(ctx context.Context, id int64) (*entity.Certificate, error)
 	GetByUser(ctx context.Context, userId int64, req dto.GetAllCertificateRequest) ([]entity.Certificate, int64, error)
@@ -18,30 +39,678 @@ type CertificateService interface {
 }
 
 type certificateService struct {
-	certificateRepository repository.CertificateRepository
+	certificateRepository      repository.CertificateRepository
+	certificateClaimRepository repository.CertificateClaimRepository
+	userRepository             repository.UserRepository
+	bloodDonationRepository    repository.BloodDonationRepository
+	transactor                 repository.Transactor
+	outboxService              OutboxService
+	blockchain                 BlockchainService
+	cfg                        *configs.BlockchainConfig
+	certificateCfg             *configs.CertificateConfig
 }
 
 func NewCertificateService(
 	certificateRepository repository.CertificateRepository,
+	certificateClaimRepository repository.CertificateClaimRepository,
+	userRepository repository.UserRepository,
+	bloodDonationRepository repository.BloodDonationRepository,
+	transactor repository.Transactor,
+	outboxService OutboxService,
+	blockchain BlockchainService,
+	cfg *configs.BlockchainConfig,
+	certificateCfg *configs.CertificateConfig,
 ) CertificateService {
 	return &certificateService{
 		certificateRepository,
+		certificateClaimRepository,
+		userRepository,
+		bloodDonationRepository,
+		transactor,
+		outboxService,
+		blockchain,
+		cfg,
+		certificateCfg,
 	}
 }
 
-func (s *certificateService) Create(ctx context.Context, bloodDonation *entity.BloodDonation, certificateNumber, digitalSignature string) (*entity.Certificate, error) {
+// Create menyimpan sertifikat dengan nomor baru dan mengantrekan minting ke blockchain.
+// Digital signature (tx hash) diisi oleh dispatcher outbox saat transaksi dikirim.
+func (s *certificateService) Create(ctx context.Context, bloodDonation *entity.BloodDonation) (*entity.Certificate, error) {
+	certificateNumber, err := utils.GenerateUniqueCertificateNumber()
+	if err != nil {
+		return nil, errors.New("Gagal membuat nomor sertifikat")
+	}
+
 	certificate := &entity.Certificate{
 		DonationId:        bloodDonation.Id,
 		UserId:            bloodDonation.UserId,
 		CertificateNumber: certificateNumber,
-		DigitalSignature:  digitalSignature,
+		ChainStatus:       entity.ChainStatusQueued,
 	}
 	if err := s.certificateRepository.Create(ctx, certificate); err != nil {
 		return nil, errors.New("Gagal membuat sertifikat" + err.Error())
 	}
+
+	if err := s.outboxService.Enqueue(ctx, OutboxCertificateMint, "certificate", certificate.Id, CertificateMintPayload{CertificateId: certificate.Id}); err != nil {
+		return nil, err
+	}
 	return certificate, nil
 }
 
+// Mint menandatangani transaksi mintSertifikat untuk sertifikat yang masih antre, menyimpan
+// hash dan nonce-nya, lalu mengirimnya. Status akhir ditentukan oleh RefreshChainStatuses.
+func (s *certificateService) Mint(ctx context.Context, certificateId int64) error {
+	certificate, err := s.certificateRepository.GetById(ctx, certificateId)
+	if err != nil {
+		return errors.New("Sertifikat tidak ditemukan")
+	}
+	if certificate.ChainStatus != entity.ChainStatusQueued {
+		return nil
+	}
+
+	// Transaksi yang sudah ditandatangani tetapi gagal dikirim dikirim ulang dengan nonce yang
+	// sama supaya sertifikat tidak di-mint dua kali
+	if certificate.RawTx != "" && certificate.TxNonce != nil {
+		return s.resend(ctx, certificate)
+	}
+
+	user, err := s.userRepository.GetById(ctx, certificate.UserId)
+	if err != nil {
+		return errors.New("Pengguna tidak ditemukan")
+	}
+	bloodDonation, err := s.bloodDonationRepository.GetById(ctx, certificate.DonationId)
+	if err != nil {
+		return errors.New("Donasi darah tidak ditemukan")
+	}
+
+	// Pendonor tanpa wallet menerima sertifikat di alamat escrow dan bisa mengklaimnya nanti
+	recipient := user.WalletAddress
+	if !common.IsHexAddress(recipient) {
+		recipient = s.escrowAddress()
+	}
+	claim, err := s.certificateClaimRepository.GetPendingByCertificateId(ctx, certificate.Id)
+	if err != nil {
+		return fmt.Errorf("gagal mendapatkan klaim sertifikat %d: %w", certificate.Id, err)
+	}
+	if claim != nil {
+		recipient = claim.ToAddress
+	}
+
+	signed, err := s.blockchain.SignCertificate(ctx, CertificateTx{
+		CertificateNumber: certificate.CertificateNumber,
+		DonorAddress:      recipient,
+		DonorName:         user.Name,
+		DonorAlamat:       bloodDonation.Hospital.Address + ", " + bloodDonation.Hospital.City + ", " + bloodDonation.Hospital.Province,
+	})
+	if errors.Is(err, ErrBlockchainDisabled) {
+		// Sertifikat tetap queued dan diantrekan ulang oleh RequeueMints saat blockchain aktif
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Simpan sebelum dikirim supaya retry outbox tidak menghasilkan transaksi ganda
+	applySignedTx(certificate, signed)
+	if err := s.certificateRepository.Update(ctx, certificate); err != nil {
+		return fmt.Errorf("gagal menyimpan transaksi sertifikat %d: %w", certificate.Id, err)
+	}
+
+	return s.send(ctx, certificate, signed.Raw)
+}
+
+// send mengirim transaksi mint. Jika gagal, sertifikat dikembalikan ke queued dengan transaksi
+// yang sama dan error dikembalikan supaya outbox mencoba lagi.
+func (s *certificateService) send(ctx context.Context, certificate *entity.Certificate, raw []byte) error {
+	err := s.blockchain.SendTransaction(ctx, raw)
+	if err == nil || isAlreadyKnown(err) {
+		return nil
+	}
+
+	log.Printf("Sertifikat %d: %v", certificate.Id, err)
+	certificate.ChainStatus = entity.ChainStatusQueued
+	certificate.ChainError = err.Error()
+	if updateErr := s.certificateRepository.Update(ctx, certificate); updateErr != nil {
+		return fmt.Errorf("gagal mengantrekan ulang sertifikat %d: %w", certificate.Id, updateErr)
+	}
+	return err
+}
+
+// resend mengirim ulang transaksi sertifikat yang sebelumnya gagal dikirim. Jika nonce-nya sudah
+// terpakai, sertifikat diserahkan ke RefreshChainStatuses yang memeriksa receipt-nya dan
+// mengantrekan ulang mint jika nonce tersebut dipakai transaksi lain.
+func (s *certificateService) resend(ctx context.Context, certificate *entity.Certificate) error {
+	raw, err := hexutil.Decode(certificate.RawTx)
+	if err != nil {
+		return fmt.Errorf("raw transaksi sertifikat %d tidak valid: %w", certificate.Id, err)
+	}
+	confirmedNonce, err := s.blockchain.ConfirmedNonce(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal mendapatkan nonce: %w", err)
+	}
+
+	now := time.Now()
+	certificate.ChainStatus = entity.ChainStatusSubmitted
+	certificate.SubmittedAt = &now
+	certificate.ChainError = ""
+	if err := s.certificateRepository.Update(ctx, certificate); err != nil {
+		return fmt.Errorf("gagal menyimpan transaksi sertifikat %d: %w", certificate.Id, err)
+	}
+	if confirmedNonce > uint64(*certificate.TxNonce) {
+		return nil
+	}
+	return s.send(ctx, certificate, raw)
+}
+
+// isAlreadyKnown menandai transaksi yang sudah ada di mempool node, misalnya karena pengiriman
+// sebelumnya sampai tetapi responsnya hilang
+func isAlreadyKnown(err error) bool {
+	return strings.Contains(err.Error(), "already known")
+}
+
+// RefreshChainStatuses memeriksa receipt sertifikat yang sudah dikirim. Sertifikat dikonfirmasi
+// setelah cukup blok, ditandai gagal jika reverted, dan dikirim ulang dengan gas lebih tinggi
+// jika terlalu lama tidak ditambang.
+func (s *certificateService) RefreshChainStatuses(ctx context.Context) error {
+	certificates, err := s.certificateRepository.GetByChainStatus(ctx, entity.ChainStatusSubmitted, refreshChainStatusBatch)
+	if err != nil {
+		return errors.New("Gagal mendapatkan sertifikat yang menunggu konfirmasi")
+	}
+	if len(certificates) == 0 {
+		return nil
+	}
+
+	// Nonce diambil sebelum receipt: transaksi yang nonce-nya sudah terpakai pasti sudah
+	// punya receipt saat dicek setelahnya.
+	confirmedNonce, err := s.blockchain.ConfirmedNonce(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal mendapatkan nonce: %w", err)
+	}
+	head, err := s.blockchain.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal mendapatkan nomor blok: %w", err)
+	}
+
+	for i := range certificates {
+		if err := s.refreshChainStatus(ctx, &certificates[i], int64(head), confirmedNonce); err != nil {
+			log.Printf("Gagal memperbarui status blockchain sertifikat %d: %v", certificates[i].Id, err)
+		}
+	}
+	return nil
+}
+
+const refreshChainStatusBatch = 50
+
+// RequeueMints mengantrekan ulang mint untuk sertifikat yang masih queued, misalnya yang dibuat
+// saat blockchain tidak aktif. Mint idempoten sehingga pesan ganda tidak menimbulkan transaksi ganda.
+func (s *certificateService) RequeueMints(ctx context.Context) (int, error) {
+	certificates, err := s.certificateRepository.GetByChainStatus(ctx, entity.ChainStatusQueued, requeueMintBatch)
+	if err != nil {
+		return 0, errors.New("Gagal mendapatkan sertifikat yang belum di-mint")
+	}
+
+	for _, certificate := range certificates {
+		if err := s.outboxService.Enqueue(ctx, OutboxCertificateMint, "certificate", certificate.Id, CertificateMintPayload{CertificateId: certificate.Id}); err != nil {
+			return 0, err
+		}
+	}
+	return len(certificates), nil
+}
+
+const requeueMintBatch = 500
+
+func (s *certificateService) refreshChainStatus(ctx context.Context, certificate *entity.Certificate, head int64, confirmedNonce uint64) error {
+	// Transaksi pengganti dan yang digantikan memakai nonce yang sama, mana pun bisa ditambang
+	hashes := append([]string{certificate.DigitalSignature}, certificate.ReplacedTxHashes...)
+	for _, hash := range hashes {
+		if hash == "" {
+			continue
+		}
+		receipt, err := s.blockchain.TransactionReceipt(ctx, hash)
+		if err != nil {
+			return err
+		}
+		if receipt != nil {
+			return s.applyReceipt(ctx, certificate, hash, receipt, head)
+		}
+	}
+
+	stuck := certificate.SubmittedAt == nil || time.Since(*certificate.SubmittedAt) >= s.cfg.StuckAfter
+
+	switch {
+	case certificate.TxNonce == nil || certificate.RawTx == "":
+		// Sertifikat lama tanpa transaksi tersimpan tidak bisa dikirim ulang
+		if !stuck {
+			return nil
+		}
+		return s.markFailed(ctx, certificate, "Transaksi tidak ditemukan di blockchain")
+	case confirmedNonce > uint64(*certificate.TxNonce):
+		return s.requeue(ctx, certificate)
+	case !stuck:
+		return nil
+	case certificate.SubmitAttempts > s.cfg.MaxResubmits:
+		return s.markFailed(ctx, certificate, fmt.Sprintf("Transaksi tidak ditambang setelah %d kali pengiriman", certificate.SubmitAttempts))
+	}
+
+	raw, err := hexutil.Decode(certificate.RawTx)
+	if err != nil {
+		return fmt.Errorf("raw transaksi tidak valid: %w", err)
+	}
+	signed, err := s.blockchain.BumpTransaction(ctx, raw, s.cfg.GasBumpPercent)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Sertifikat %d: mengganti transaksi %s dengan %s (nonce %d)", certificate.Id, certificate.DigitalSignature, signed.Hash, signed.Nonce)
+	certificate.ReplacedTxHashes = append(certificate.ReplacedTxHashes, certificate.DigitalSignature)
+	applySignedTx(certificate, signed)
+	if err := s.certificateRepository.Update(ctx, certificate); err != nil {
+		return err
+	}
+	return s.blockchain.SendTransaction(ctx, signed.Raw)
+}
+
+func (s *certificateService) applyReceipt(ctx context.Context, certificate *entity.Certificate, hash string, receipt *TxReceipt, head int64) error {
+	blockNumber := int64(receipt.BlockNumber)
+	certificate.DigitalSignature = hash
+	certificate.BlockNumber = &blockNumber
+	if receipt.CertificateId != nil {
+		certificate.OnChainId = receipt.CertificateId
+		certificate.RecipientAddress = receipt.DonorAddress
+		certificate.Custodial = strings.EqualFold(receipt.DonorAddress, s.escrowAddress())
+	}
+
+	if !receipt.Success {
+		return s.markFailed(ctx, certificate, fmt.Sprintf("Transaksi %s reverted di blok %d", hash, blockNumber))
+	}
+
+	certificate.Confirmations = 0
+	if head >= blockNumber {
+		certificate.Confirmations = head - blockNumber + 1
+	}
+	if certificate.Confirmations < s.cfg.Confirmations {
+		return s.certificateRepository.Update(ctx, certificate)
+	}
+
+	now := time.Now()
+	certificate.ChainStatus = entity.ChainStatusConfirmed
+	certificate.ConfirmedAt = &now
+	certificate.ChainError = ""
+	certificate.RawTx = ""
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.certificateRepository.Update(ctx, certificate); err != nil {
+			return err
+		}
+		if err := s.finishClaim(ctx, certificate); err != nil {
+			return err
+		}
+		return s.notifyIssued(ctx, certificate)
+	})
+}
+
+// requeue dipakai jika nonce transaksi sudah terpakai oleh transaksi lain, sehingga
+// sertifikat harus di-mint ulang dengan nonce baru.
+func (s *certificateService) requeue(ctx context.Context, certificate *entity.Certificate) error {
+	log.Printf("Sertifikat %d: nonce %d sudah terpakai, mint diantrekan ulang", certificate.Id, *certificate.TxNonce)
+	certificate.ReplacedTxHashes = append(certificate.ReplacedTxHashes, certificate.DigitalSignature)
+	certificate.DigitalSignature = ""
+	certificate.ChainStatus = entity.ChainStatusQueued
+	certificate.TxNonce = nil
+	certificate.RawTx = ""
+	certificate.GasFeeCap = ""
+	certificate.GasTipCap = ""
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.certificateRepository.Update(ctx, certificate); err != nil {
+			return err
+		}
+		return s.outboxService.Enqueue(ctx, OutboxCertificateMint, "certificate", certificate.Id, CertificateMintPayload{CertificateId: certificate.Id})
+	})
+}
+
+func (s *certificateService) markFailed(ctx context.Context, certificate *entity.Certificate, reason string) error {
+	claim, err := s.certificateClaimRepository.GetPendingByCertificateId(ctx, certificate.Id)
+	if err != nil {
+		return fmt.Errorf("gagal mendapatkan klaim sertifikat %d: %w", certificate.Id, err)
+	}
+	if claim != nil {
+		return s.failClaim(ctx, certificate, claim, reason)
+	}
+
+	log.Printf("Sertifikat %d gagal diterbitkan: %s", certificate.Id, reason)
+	certificate.ChainStatus = entity.ChainStatusFailed
+	certificate.ChainError = reason
+	return s.certificateRepository.Update(ctx, certificate)
+}
+
+// failClaim menandai klaim gagal dan mengembalikan sertifikat ke catatan escrow yang masih
+// berlaku di blockchain, sehingga pendonor bisa mengklaim ulang
+func (s *certificateService) failClaim(ctx context.Context, certificate *entity.Certificate, claim *entity.CertificateClaim, reason string) error {
+	log.Printf("Klaim sertifikat %d gagal: %s", certificate.Id, reason)
+	now := time.Now()
+	claim.Status = entity.ClaimStatusFailed
+	claim.Error = reason
+	claim.TxHash = certificate.DigitalSignature
+	claim.CompletedAt = &now
+
+	certificate.ChainStatus = entity.ChainStatusConfirmed
+	certificate.DigitalSignature = claim.PreviousTxHash
+	certificate.OnChainId = claim.PreviousOnChainId
+	certificate.RecipientAddress = claim.FromAddress
+	certificate.Custodial = true
+	certificate.RawTx = ""
+	certificate.ChainError = ""
+	state := claim.PreviousState
+	if state == nil {
+		state = &entity.CertificateEscrowState{}
+	}
+	certificate.IssuerAddress = state.IssuerAddress
+	certificate.TxNonce = state.TxNonce
+	certificate.GasFeeCap = state.GasFeeCap
+	certificate.GasTipCap = state.GasTipCap
+	certificate.ReplacedTxHashes = state.ReplacedTxHashes
+	certificate.SubmitAttempts = state.SubmitAttempts
+	certificate.BlockNumber = state.BlockNumber
+	certificate.Confirmations = state.Confirmations
+	certificate.SubmittedAt = state.SubmittedAt
+	certificate.ConfirmedAt = state.ConfirmedAt
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.certificateRepository.Update(ctx, certificate); err != nil {
+			return err
+		}
+		return s.certificateClaimRepository.Update(ctx, claim)
+	})
+}
+
+// escrowAddress adalah penerima sertifikat untuk pendonor yang belum menghubungkan wallet
+func (s *certificateService) escrowAddress() string {
+	if s.cfg.EscrowAddress != "" {
+		return s.cfg.EscrowAddress
+	}
+	return s.blockchain.IssuerAddress()
+}
+
+// Claim memindahkan sertifikat dari alamat escrow ke wallet pendonor. Kontrak tidak mendukung
+// transfer sehingga sertifikat di-mint ulang atas nama wallet tersebut. Gas dibayar oleh
+// penerbit sehingga pendonor tidak perlu memiliki ETH.
+func (s *certificateService) Claim(ctx context.Context, userId, certificateId int64) (*entity.CertificateClaim, error) {
+	certificate, err := s.certificateRepository.GetById(ctx, certificateId)
+	if err != nil || certificate.UserId != userId {
+		return nil, ErrCertificateNotFound
+	}
+	if !certificate.Custodial {
+		return nil, errors.New("Sertifikat sudah tercatat atas nama wallet anda")
+	}
+	if certificate.ChainStatus != entity.ChainStatusConfirmed {
+		return nil, errors.New("Sertifikat masih diproses di blockchain, coba lagi nanti")
+	}
+
+	user, err := s.userRepository.GetById(ctx, userId)
+	if err != nil {
+		return nil, errors.New("Pengguna tidak ditemukan")
+	}
+	if !common.IsHexAddress(user.WalletAddress) {
+		return nil, errors.New("Hubungkan alamat wallet terlebih dahulu")
+	}
+
+	claim := &entity.CertificateClaim{
+		CertificateId:     certificate.Id,
+		UserId:            userId,
+		FromAddress:       certificate.RecipientAddress,
+		ToAddress:         common.HexToAddress(user.WalletAddress).Hex(),
+		PreviousOnChainId: certificate.OnChainId,
+		PreviousTxHash:    certificate.DigitalSignature,
+		PreviousState: &entity.CertificateEscrowState{
+			IssuerAddress:    certificate.IssuerAddress,
+			TxNonce:          certificate.TxNonce,
+			GasFeeCap:        certificate.GasFeeCap,
+			GasTipCap:        certificate.GasTipCap,
+			ReplacedTxHashes: certificate.ReplacedTxHashes,
+			SubmitAttempts:   certificate.SubmitAttempts,
+			BlockNumber:      certificate.BlockNumber,
+			Confirmations:    certificate.Confirmations,
+			SubmittedAt:      certificate.SubmittedAt,
+			ConfirmedAt:      certificate.ConfirmedAt,
+		},
+		Status: entity.ClaimStatusPending,
+	}
+
+	// onchain_id tetap menunjuk catatan escrow sampai mint ulang dikonfirmasi
+	certificate.ChainStatus = entity.ChainStatusQueued
+	certificate.DigitalSignature = ""
+	certificate.TxNonce = nil
+	certificate.GasFeeCap = ""
+	certificate.GasTipCap = ""
+	certificate.RawTx = ""
+	certificate.ReplacedTxHashes = nil
+	certificate.SubmitAttempts = 0
+	certificate.BlockNumber = nil
+	certificate.Confirmations = 0
+	certificate.ChainError = ""
+	certificate.SubmittedAt = nil
+	certificate.ConfirmedAt = nil
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.certificateClaimRepository.Create(ctx, claim); err != nil {
+			return errors.New("Gagal menyimpan klaim sertifikat")
+		}
+		if err := s.certificateRepository.Update(ctx, certificate); err != nil {
+			return errors.New("Gagal memperbarui sertifikat")
+		}
+		return s.outboxService.Enqueue(ctx, OutboxCertificateMint, "certificate", certificate.Id, CertificateMintPayload{CertificateId: certificate.Id})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claim, nil
+}
+
+func (s *certificateService) GetClaims(ctx context.Context, userId, certificateId int64) ([]entity.CertificateClaim, error) {
+	certificate, err := s.certificateRepository.GetById(ctx, certificateId)
+	if err != nil || certificate.UserId != userId {
+		return nil, ErrCertificateNotFound
+	}
+
+	claims, err := s.certificateClaimRepository.GetByCertificateId(ctx, certificate.Id)
+	if err != nil {
+		return nil, errors.New("Gagal mendapatkan riwayat klaim sertifikat")
+	}
+	return claims, nil
+}
+
+// finishClaim menyelesaikan klaim yang sedang berjalan setelah mint ulang dikonfirmasi
+func (s *certificateService) finishClaim(ctx context.Context, certificate *entity.Certificate) error {
+	claim, err := s.certificateClaimRepository.GetPendingByCertificateId(ctx, certificate.Id)
+	if err != nil || claim == nil {
+		return err
+	}
+
+	now := time.Now()
+	claim.TxHash = certificate.DigitalSignature
+	claim.CompletedAt = &now
+	claim.Status = entity.ClaimStatusCompleted
+	claim.OnChainId = certificate.OnChainId
+	return s.certificateClaimRepository.Update(ctx, claim)
+}
+
+// notifyIssued mengantrekan notifikasi dan email bahwa sertifikat sudah tercatat di blockchain
+func (s *certificateService) notifyIssued(ctx context.Context, certificate *entity.Certificate) error {
+	user, err := s.userRepository.GetById(ctx, certificate.UserId)
+	if err != nil {
+		return errors.New("Pengguna tidak ditemukan")
+	}
+	bloodDonation, err := s.bloodDonationRepository.GetById(ctx, certificate.DonationId)
+	if err != nil {
+		return errors.New("Donasi darah tidak ditemukan")
+	}
+
+	message := "Sertifikat donor darah anda dengan nomor " + certificate.CertificateNumber + " telah diterbitkan dengan digital signature (transaction hash) " + certificate.DigitalSignature
+	if certificate.Custodial {
+		message += ". Sertifikat disimpan di alamat escrow DarahConnect, hubungkan wallet anda untuk mengklaimnya."
+	}
+	if err := s.outboxService.Notify(ctx, NotificationCreatePayload{
+		UserId:           user.Id,
+		Title:            "Sertifikat Donor Darah",
+		Message:          message,
+		NotificationType: "information",
+	}); err != nil {
+		return err
+	}
+
+	return s.outboxService.SendEmail(ctx, EmailSendPayload{
+		To:       user.Email,
+		Subject:  "Sertifikat Donor Darah - Darah Connect",
+		Template: "certificate-issued.html",
+		Data: map[string]interface{}{
+			"Name":              user.Name,
+			"CertificateNumber": certificate.CertificateNumber,
+			"TxHash":            certificate.DigitalSignature,
+			"HospitalName":      bloodDonation.Hospital.Name,
+			"DonationDate":      bloodDonation.DonationDate.Format("02-01-2006"),
+		},
+	})
+}
+
+// Verify mencocokkan sertifikat di database dengan catatan daftarSertifikat di kontrak,
+// sehingga pihak luar bisa memeriksa keaslian sertifikat tanpa harus mempercayai database.
+func (s *certificateService) Verify(ctx context.Context, certificateNumber string) (*dto.CertificateVerificationResponse, error) {
+	certificate, err := s.certificateRepository.GetByCertificateNumber(ctx, certificateNumber)
+	if err != nil {
+		return nil, ErrCertificateNotFound
+	}
+
+	donationDate := certificate.Donation.DonationDate
+	hospital := certificate.Donation.Hospital
+	// Sertifikat lama yang di-mint sebelum penerbit disimpan memakai kunci backend yang aktif
+	issuer := certificate.IssuerAddress
+	if issuer == "" {
+		issuer = s.blockchain.IssuerAddress()
+	}
+	result := &dto.CertificateVerificationResponse{
+		CertificateNumber: certificate.CertificateNumber,
+		ChainStatus:       certificate.ChainStatus,
+		TxHash:            certificate.DigitalSignature,
+		OnChainId:         certificate.OnChainId,
+		Database: dto.CertificateRecord{
+			CertificateNumber: certificate.CertificateNumber,
+			DonorAddress:      certificate.RecipientAddress,
+			DonorName:         certificate.User.Name,
+			DonorAlamat:       hospital.Address + ", " + hospital.City + ", " + hospital.Province,
+			Issuer:            issuer,
+			DonationDate:      &donationDate,
+		},
+		Mismatches: []string{},
+	}
+
+	// Sertifikat yang dikonfirmasi sebelum onchain_id disimpan dicari lewat receipt-nya. Verifikasi
+	// publik tidak menulis ke database; onchain_id disimpan oleh certificate indexer.
+	if certificate.OnChainId == nil && certificate.DigitalSignature != "" {
+		receipt, err := s.blockchain.TransactionReceipt(ctx, certificate.DigitalSignature)
+		if err != nil {
+			return nil, err
+		}
+		if receipt != nil && receipt.Success && receipt.CertificateId != nil {
+			result.OnChainId = receipt.CertificateId
+		}
+	}
+	if result.Database.DonorAddress == "" {
+		result.Database.DonorAddress = certificate.User.WalletAddress
+	}
+	if result.OnChainId == nil {
+		result.Message = "Sertifikat belum tercatat di blockchain"
+		return result, nil
+	}
+
+	onChain, err := s.blockchain.GetCertificate(ctx, *result.OnChainId)
+	if err != nil {
+		return nil, err
+	}
+	if onChain == nil {
+		result.Message = "Sertifikat tidak ditemukan di blockchain"
+		return result, nil
+	}
+
+	result.OnChain = &dto.CertificateRecord{
+		CertificateNumber: onChain.NomorSertifikat,
+		DonorAddress:      onChain.Pendonor,
+		DonorName:         onChain.NamaPendonor,
+		DonorAlamat:       onChain.AlamatPendonor,
+		Issuer:            onChain.AdminVerifikator,
+		Penerbit:          onChain.Penerbit,
+		DonationDate:      &onChain.TanggalDonasi,
+	}
+
+	db, chain := result.Database, result.OnChain
+	checks := []struct {
+		field string
+		match bool
+	}{
+		{"certificate_number", db.CertificateNumber == chain.CertificateNumber},
+		{"donor_address", strings.EqualFold(db.DonorAddress, chain.DonorAddress)},
+		{"donor_name", db.DonorName == chain.DonorName},
+		{"donor_alamat", db.DonorAlamat == chain.DonorAlamat},
+		{"issuer", strings.EqualFold(db.Issuer, chain.Issuer)},
+	}
+	for _, check := range checks {
+		if !check.match {
+			result.Mismatches = append(result.Mismatches, check.field)
+		}
+	}
+
+	result.Match = len(result.Mismatches) == 0
+	if result.Match {
+		result.Message = "Sertifikat valid dan sesuai dengan catatan di blockchain"
+	} else {
+		result.Message = "Data sertifikat tidak sesuai dengan catatan di blockchain"
+	}
+	return result, nil
+}
+
+// RenderPDF membuat PDF sertifikat dengan QR code menuju verifikasi publik. Transaction hash
+// hanya dicetak setelah sertifikat dikonfirmasi di blockchain.
+func (s *certificateService) RenderPDF(ctx context.Context, certificateId int64) ([]byte, error) {
+	certificate, err := s.certificateRepository.GetDetailById(ctx, certificateId)
+	if err != nil {
+		return nil, ErrCertificateNotFound
+	}
+
+	data := certificatepdf.Data{
+		CertificateNumber: certificate.CertificateNumber,
+		DonorName:         certificate.User.Name,
+		BloodType:         certificate.User.BloodType,
+		HospitalName:      certificate.Donation.Hospital.Name,
+		HospitalAddress:   certificate.Donation.Hospital.Address + ", " + certificate.Donation.Hospital.City + ", " + certificate.Donation.Hospital.Province,
+		DonationDate:      certificate.Donation.DonationDate.In(timezone.JakartaLocation),
+		VerifyURL:         strings.TrimRight(s.certificateCfg.VerifyBaseURL, "/") + "/" + url.PathEscape(certificate.CertificateNumber),
+	}
+	if certificate.ChainStatus == entity.ChainStatusConfirmed {
+		data.TxHash = certificate.DigitalSignature
+	}
+
+	var buf bytes.Buffer
+	if err := certificatepdf.Render(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func applySignedTx(certificate *entity.Certificate, signed *SignedTx) {
+	now := time.Now()
+	nonce := int64(signed.Nonce)
+	certificate.ChainStatus = entity.ChainStatusSubmitted
+	certificate.DigitalSignature = signed.Hash
+	certificate.IssuerAddress = signed.From
+	certificate.TxNonce = &nonce
+	certificate.GasFeeCap = signed.GasFeeCap.String()
+	certificate.GasTipCap = signed.GasTipCap.String()
+	certificate.RawTx = hexutil.Encode(signed.Raw)
+	certificate.SubmitAttempts++
+	certificate.SubmittedAt = &now
+	certificate.ChainError = ""
+}
+
 func (s *certificateService) GetAll(ctx context.Context, req dto.GetAllCertificateRequest) ([]entity.Certificate, int64, error) {
 	certificates, total, err := s.certificateRepository.GetAll(ctx, req)
 	if err != nil {
@@ -0,0 +1,544 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertificateEscrowMintAndClaim(t *testing.T) {
+	ctx := context.Background()
+	chain := newSimulatedChain(t)
+
+	certificates := &fakeCertificateRepository{certificates: map[int64]*entity.Certificate{}}
+	claims := &fakeCertificateClaimRepository{}
+	users := &fakeUserRepository{user: &entity.User{Id: 7, Name: "Budi", Email: "budi@example.com"}}
+	donations := &fakeBloodDonationRepository{donation: &entity.BloodDonation{Id: 3, UserId: 7, Hospital: entity.Hospital{
+		Address: "Jl. Merdeka 1", City: "Semarang", Province: "Jawa Tengah",
+	}}}
+	outbox := &fakeOutboxService{}
+
+	certificateService := service.NewCertificateService(certificates, claims, users, donations, passthroughTransactor{}, outbox, chain.blockchain, &configs.BlockchainConfig{
+		Confirmations: 1,
+		StuckAfter:    time.Hour,
+	}, &configs.CertificateConfig{})
+
+	mintAndConfirm := func(certificateId int64) *entity.Certificate {
+		require.NoError(t, certificateService.Mint(ctx, certificateId))
+		chain.backend.Commit()
+		require.NoError(t, certificateService.RefreshChainStatuses(ctx))
+		certificate := certificates.certificates[certificateId]
+		require.Equal(t, entity.ChainStatusConfirmed, certificate.ChainStatus, certificate.ChainError)
+		return certificate
+	}
+
+	// Pendonor tanpa wallet menerima sertifikat di alamat escrow (wallet penerbit)
+	created, err := certificateService.Create(ctx, donations.donation)
+	require.NoError(t, err)
+	certificate := mintAndConfirm(created.Id)
+	assert.True(t, certificate.Custodial)
+	assert.Equal(t, chain.blockchain.IssuerAddress(), certificate.RecipientAddress)
+	require.NotNil(t, certificate.OnChainId)
+	escrowId, escrowTx := *certificate.OnChainId, certificate.DigitalSignature
+
+	_, err = certificateService.Claim(ctx, 7, certificate.Id)
+	assert.EqualError(t, err, "Hubungkan alamat wallet terlebih dahulu")
+	_, err = certificateService.Claim(ctx, 8, certificate.Id)
+	assert.ErrorIs(t, err, service.ErrCertificateNotFound)
+
+	wallet := common.HexToAddress("0x00000000000000000000000000000000000000aa").Hex()
+	users.user.WalletAddress = wallet
+	claim, err := certificateService.Claim(ctx, 7, certificate.Id)
+	require.NoError(t, err)
+	assert.Equal(t, entity.ClaimStatusPending, claim.Status)
+	assert.Equal(t, entity.ChainStatusQueued, certificate.ChainStatus)
+	assert.Equal(t, escrowId, *certificate.OnChainId, "catatan escrow tetap dipakai sampai mint ulang dikonfirmasi")
+
+	certificate = mintAndConfirm(certificate.Id)
+	assert.False(t, certificate.Custodial)
+	assert.Equal(t, wallet, certificate.RecipientAddress)
+	assert.NotEqual(t, escrowId, *certificate.OnChainId)
+
+	require.Len(t, claims.claims, 1)
+	claim = claims.claims[0]
+	assert.Equal(t, entity.ClaimStatusCompleted, claim.Status)
+	assert.Equal(t, chain.blockchain.IssuerAddress(), claim.FromAddress)
+	assert.Equal(t, wallet, claim.ToAddress)
+	assert.Equal(t, escrowId, *claim.PreviousOnChainId)
+	assert.Equal(t, escrowTx, claim.PreviousTxHash)
+	assert.Equal(t, certificate.OnChainId, claim.OnChainId)
+	assert.Equal(t, certificate.DigitalSignature, claim.TxHash)
+
+	onChain, err := chain.blockchain.GetCertificate(ctx, *certificate.OnChainId)
+	require.NoError(t, err)
+	assert.Equal(t, wallet, onChain.Pendonor)
+
+	_, err = certificateService.Claim(ctx, 7, certificate.Id)
+	assert.EqualError(t, err, "Sertifikat sudah tercatat atas nama wallet anda")
+}
+
+func TestCertificateClaimFailureRestoresEscrow(t *testing.T) {
+	ctx := context.Background()
+	chain := newSimulatedChain(t)
+
+	certificates := &fakeCertificateRepository{certificates: map[int64]*entity.Certificate{}}
+	claims := &fakeCertificateClaimRepository{}
+	users := &fakeUserRepository{user: &entity.User{Id: 7, Name: "Budi", Email: "budi@example.com"}}
+	donations := &fakeBloodDonationRepository{donation: &entity.BloodDonation{Id: 3, UserId: 7, Hospital: entity.Hospital{
+		Address: "Jl. Merdeka 1", City: "Semarang", Province: "Jawa Tengah",
+	}}}
+	cfg := &configs.BlockchainConfig{Confirmations: 1, StuckAfter: time.Hour}
+	certificateService := service.NewCertificateService(certificates, claims, users, donations, passthroughTransactor{}, &fakeOutboxService{}, chain.blockchain, cfg, &configs.CertificateConfig{})
+
+	created, err := certificateService.Create(ctx, donations.donation)
+	require.NoError(t, err)
+	require.NoError(t, certificateService.Mint(ctx, created.Id))
+	chain.backend.Commit()
+	require.NoError(t, certificateService.RefreshChainStatuses(ctx))
+	escrow := *certificates.certificates[created.Id]
+	require.Equal(t, entity.ChainStatusConfirmed, escrow.ChainStatus, escrow.ChainError)
+	require.True(t, escrow.Custodial)
+
+	users.user.WalletAddress = common.HexToAddress("0x00000000000000000000000000000000000000aa").Hex()
+	_, err = certificateService.Claim(ctx, 7, created.Id)
+	require.NoError(t, err)
+	require.NoError(t, certificateService.Mint(ctx, created.Id))
+
+	// Mint ulang tidak pernah ditambang dan batas pengiriman ulang habis
+	cfg.StuckAfter = 0
+	cfg.MaxResubmits = 0
+	remintTx := certificates.certificates[created.Id].DigitalSignature
+	require.NoError(t, certificateService.RefreshChainStatuses(ctx))
+
+	require.Len(t, claims.claims, 1)
+	claim := claims.claims[0]
+	assert.Equal(t, entity.ClaimStatusFailed, claim.Status)
+	assert.NotEmpty(t, claim.Error)
+	assert.Equal(t, remintTx, claim.TxHash)
+
+	certificate := certificates.certificates[created.Id]
+	assert.Equal(t, entity.ChainStatusConfirmed, certificate.ChainStatus)
+	assert.True(t, certificate.Custodial)
+	assert.Equal(t, escrow.DigitalSignature, certificate.DigitalSignature)
+	assert.Equal(t, *escrow.OnChainId, *certificate.OnChainId)
+	assert.Equal(t, escrow.RecipientAddress, certificate.RecipientAddress)
+	assert.Equal(t, escrow.IssuerAddress, certificate.IssuerAddress)
+	assert.Equal(t, *escrow.TxNonce, *certificate.TxNonce)
+	assert.Equal(t, *escrow.BlockNumber, *certificate.BlockNumber)
+	assert.Equal(t, escrow.Confirmations, certificate.Confirmations)
+	assert.Empty(t, certificate.ChainError)
+
+	// Pendonor bisa mengklaim ulang
+	claim, err = certificateService.Claim(ctx, 7, created.Id)
+	require.NoError(t, err)
+	assert.Equal(t, entity.ClaimStatusPending, claim.Status)
+	assert.Equal(t, escrow.DigitalSignature, claim.PreviousTxHash)
+}
+
+func TestCertificateVerify(t *testing.T) {
+	ctx := context.Background()
+	chain := newSimulatedChain(t)
+
+	certificates := &fakeCertificateRepository{certificates: map[int64]*entity.Certificate{}}
+	users := &fakeUserRepository{user: &entity.User{Id: 7, Name: "Budi", Email: "budi@example.com"}}
+	donations := &fakeBloodDonationRepository{donation: &entity.BloodDonation{Id: 3, UserId: 7, Hospital: entity.Hospital{
+		Address: "Jl. Merdeka 1", City: "Semarang", Province: "Jawa Tengah",
+	}}}
+	cfg := &configs.BlockchainConfig{Confirmations: 1, StuckAfter: time.Hour}
+	certificateService := service.NewCertificateService(certificates, &fakeCertificateClaimRepository{}, users, donations, passthroughTransactor{}, &fakeOutboxService{}, chain.blockchain, cfg, &configs.CertificateConfig{})
+
+	created, err := certificateService.Create(ctx, donations.donation)
+	require.NoError(t, err)
+	require.NoError(t, certificateService.Mint(ctx, created.Id))
+	chain.backend.Commit()
+	require.NoError(t, certificateService.RefreshChainStatuses(ctx))
+	certificate := certificates.certificates[created.Id]
+	require.Equal(t, entity.ChainStatusConfirmed, certificate.ChainStatus, certificate.ChainError)
+	assert.Equal(t, chain.blockchain.IssuerAddress(), certificate.IssuerAddress)
+	certificate.User = *users.user
+	certificate.Donation = *donations.donation
+
+	verify := func(t *testing.T, certificateService service.CertificateService) *dto.CertificateVerificationResponse {
+		updates := certificates.updates
+		result, err := certificateService.Verify(ctx, certificate.CertificateNumber)
+		require.NoError(t, err)
+		assert.Equal(t, updates, certificates.updates, "verifikasi publik tidak boleh menulis ke database")
+		return result
+	}
+
+	t.Run("match", func(t *testing.T) {
+		result := verify(t, certificateService)
+		assert.True(t, result.Match, result.Mismatches)
+		assert.Equal(t, certificate.OnChainId, result.OnChainId)
+		assert.Equal(t, certificate.IssuerAddress, result.OnChain.Issuer)
+	})
+
+	t.Run("issuer key rotated", func(t *testing.T) {
+		rotated := &rotatedIssuerBlockchain{chain.blockchain, common.HexToAddress("0x00000000000000000000000000000000000000bb").Hex()}
+		rotatedService := service.NewCertificateService(certificates, &fakeCertificateClaimRepository{}, users, donations, passthroughTransactor{}, &fakeOutboxService{}, rotated, cfg, &configs.CertificateConfig{})
+
+		result := verify(t, rotatedService)
+		assert.True(t, result.Match, result.Mismatches)
+		assert.Equal(t, chain.blockchain.IssuerAddress(), result.Database.Issuer)
+	})
+
+	t.Run("onchain_id not stored yet", func(t *testing.T) {
+		onChainId := certificate.OnChainId
+		certificate.OnChainId = nil
+		defer func() { certificate.OnChainId = onChainId }()
+
+		result := verify(t, certificateService)
+		assert.True(t, result.Match, result.Mismatches)
+		assert.Equal(t, *onChainId, *result.OnChainId)
+		assert.Nil(t, certificate.OnChainId)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		certificate.User.Name = "Bukan Budi"
+		defer func() { certificate.User.Name = users.user.Name }()
+
+		result := verify(t, certificateService)
+		assert.False(t, result.Match)
+		assert.Equal(t, []string{"donor_name"}, result.Mismatches)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := certificateService.Verify(ctx, "CERT-TIDAK-ADA")
+		assert.ErrorIs(t, err, service.ErrCertificateNotFound)
+	})
+}
+
+func TestCertificateMintSendFailure(t *testing.T) {
+	ctx := context.Background()
+	chain := newSimulatedChain(t)
+	blockchain := &flakyBlockchain{BlockchainService: chain.blockchain, failSends: 1}
+	certificateService, certificates, _ := newMintTestService(t, blockchain, &configs.BlockchainConfig{Confirmations: 1, StuckAfter: time.Hour})
+
+	created, err := certificateService.Create(ctx, &entity.BloodDonation{Id: 3, UserId: 7})
+	require.NoError(t, err)
+
+	// Pengiriman pertama gagal: sertifikat kembali antre dan outbox mencoba lagi
+	assert.Error(t, certificateService.Mint(ctx, created.Id))
+	certificate := certificates.certificates[created.Id]
+	assert.Equal(t, entity.ChainStatusQueued, certificate.ChainStatus)
+	assert.NotEmpty(t, certificate.ChainError)
+	signedTx := certificate.DigitalSignature
+	require.NotEmpty(t, signedTx)
+
+	// Percobaan berikutnya mengirim transaksi yang sama, bukan mint baru
+	require.NoError(t, certificateService.Mint(ctx, created.Id))
+	certificate = certificates.certificates[created.Id]
+	assert.Equal(t, entity.ChainStatusSubmitted, certificate.ChainStatus)
+	assert.Equal(t, signedTx, certificate.DigitalSignature)
+	assert.Empty(t, certificate.ChainError)
+
+	chain.backend.Commit()
+	require.NoError(t, certificateService.RefreshChainStatuses(ctx))
+	certificate = certificates.certificates[created.Id]
+	assert.Equal(t, entity.ChainStatusConfirmed, certificate.ChainStatus, certificate.ChainError)
+	assert.Equal(t, signedTx, certificate.DigitalSignature)
+}
+
+func TestCertificateReceiptPollingWaitsForConfirmations(t *testing.T) {
+	ctx := context.Background()
+	chain := newSimulatedChain(t)
+	certificateService, certificates, outbox := newMintTestService(t, chain.blockchain, &configs.BlockchainConfig{Confirmations: 2, StuckAfter: time.Hour})
+
+	created, err := certificateService.Create(ctx, &entity.BloodDonation{Id: 3, UserId: 7})
+	require.NoError(t, err)
+	require.NoError(t, certificateService.Mint(ctx, created.Id))
+
+	// Belum ditambang
+	require.NoError(t, certificateService.RefreshChainStatuses(ctx))
+	certificate := certificates.certificates[created.Id]
+	assert.Equal(t, entity.ChainStatusSubmitted, certificate.ChainStatus)
+	assert.Nil(t, certificate.BlockNumber)
+
+	chain.backend.Commit()
+	require.NoError(t, certificateService.RefreshChainStatuses(ctx))
+	certificate = certificates.certificates[created.Id]
+	assert.Equal(t, entity.ChainStatusSubmitted, certificate.ChainStatus)
+	require.NotNil(t, certificate.BlockNumber)
+	assert.Equal(t, int64(1), certificate.Confirmations)
+	require.NotNil(t, certificate.OnChainId)
+	assert.Empty(t, outbox.emails)
+
+	chain.backend.Commit()
+	require.NoError(t, certificateService.RefreshChainStatuses(ctx))
+	certificate = certificates.certificates[created.Id]
+	assert.Equal(t, entity.ChainStatusConfirmed, certificate.ChainStatus)
+	assert.Equal(t, int64(2), certificate.Confirmations)
+	assert.NotNil(t, certificate.ConfirmedAt)
+	assert.Empty(t, certificate.RawTx)
+	assert.Len(t, outbox.emails, 1)
+}
+
+func TestCertificateGasBump(t *testing.T) {
+	ctx := context.Background()
+	chain := newSimulatedChain(t)
+	blockchain := &flakyBlockchain{BlockchainService: chain.blockchain, dropSends: 1}
+	certificateService, certificates, _ := newMintTestService(t, blockchain, &configs.BlockchainConfig{
+		Confirmations:  1,
+		StuckAfter:     0,
+		GasBumpPercent: 20,
+		MaxResubmits:   3,
+	})
+
+	created, err := certificateService.Create(ctx, &entity.BloodDonation{Id: 3, UserId: 7})
+	require.NoError(t, err)
+	require.NoError(t, certificateService.Mint(ctx, created.Id))
+	stuck := *certificates.certificates[created.Id]
+
+	// Transaksi tidak pernah sampai ke node: dikirim ulang dengan nonce sama dan gas lebih tinggi
+	require.NoError(t, certificateService.RefreshChainStatuses(ctx))
+	certificate := certificates.certificates[created.Id]
+	assert.Equal(t, entity.ChainStatusSubmitted, certificate.ChainStatus)
+	assert.NotEqual(t, stuck.DigitalSignature, certificate.DigitalSignature)
+	assert.Equal(t, []string{stuck.DigitalSignature}, certificate.ReplacedTxHashes)
+	assert.Equal(t, *stuck.TxNonce, *certificate.TxNonce)
+	assert.Equal(t, 2, certificate.SubmitAttempts)
+	assert.Greater(t, bigString(t, certificate.GasFeeCap).Cmp(bigString(t, stuck.GasFeeCap)), 0)
+	assert.Greater(t, bigString(t, certificate.GasTipCap).Cmp(bigString(t, stuck.GasTipCap)), 0)
+
+	chain.backend.Commit()
+	require.NoError(t, certificateService.RefreshChainStatuses(ctx))
+	confirmed := certificates.certificates[created.Id]
+	assert.Equal(t, entity.ChainStatusConfirmed, confirmed.ChainStatus, confirmed.ChainError)
+	assert.Equal(t, certificate.DigitalSignature, confirmed.DigitalSignature)
+}
+
+func TestCertificateGasBumpGivesUp(t *testing.T) {
+	ctx := context.Background()
+	chain := newSimulatedChain(t)
+	blockchain := &flakyBlockchain{BlockchainService: chain.blockchain, dropSends: 2}
+	certificateService, certificates, _ := newMintTestService(t, blockchain, &configs.BlockchainConfig{
+		Confirmations:  1,
+		StuckAfter:     0,
+		GasBumpPercent: 20,
+		MaxResubmits:   1,
+	})
+
+	created, err := certificateService.Create(ctx, &entity.BloodDonation{Id: 3, UserId: 7})
+	require.NoError(t, err)
+	require.NoError(t, certificateService.Mint(ctx, created.Id))
+	require.NoError(t, certificateService.RefreshChainStatuses(ctx))
+	assert.Equal(t, entity.ChainStatusSubmitted, certificates.certificates[created.Id].ChainStatus)
+
+	require.NoError(t, certificateService.RefreshChainStatuses(ctx))
+	certificate := certificates.certificates[created.Id]
+	assert.Equal(t, entity.ChainStatusFailed, certificate.ChainStatus)
+	assert.Contains(t, certificate.ChainError, "2 kali pengiriman")
+}
+
+func TestCertificateMintNonceTaken(t *testing.T) {
+	ctx := context.Background()
+	chain := newSimulatedChain(t)
+	blockchain := &flakyBlockchain{BlockchainService: chain.blockchain, failSends: 1}
+	certificateService, certificates, outbox := newMintTestService(t, blockchain, &configs.BlockchainConfig{Confirmations: 1, StuckAfter: time.Hour})
+
+	created, err := certificateService.Create(ctx, &entity.BloodDonation{Id: 3, UserId: 7})
+	require.NoError(t, err)
+	assert.Error(t, certificateService.Mint(ctx, created.Id))
+	failedTx := certificates.certificates[created.Id].DigitalSignature
+
+	// Transaksi lain memakai nonce yang sama sebelum pengiriman ulang
+	chain.mint(t, "999999", chain.blockchain.IssuerAddress())
+
+	// Transaksi lama tidak dikirim ulang, poller mengantrekan ulang mint dengan nonce baru
+	require.NoError(t, certificateService.Mint(ctx, created.Id))
+	require.NoError(t, certificateService.RefreshChainStatuses(ctx))
+	certificate := certificates.certificates[created.Id]
+	assert.Equal(t, entity.ChainStatusQueued, certificate.ChainStatus)
+	assert.Empty(t, certificate.RawTx)
+	assert.Contains(t, certificate.ReplacedTxHashes, failedTx)
+	assert.Contains(t, outbox.events, service.OutboxCertificateMint)
+
+	require.NoError(t, certificateService.Mint(ctx, created.Id))
+	chain.backend.Commit()
+	require.NoError(t, certificateService.RefreshChainStatuses(ctx))
+	certificate = certificates.certificates[created.Id]
+	assert.Equal(t, entity.ChainStatusConfirmed, certificate.ChainStatus, certificate.ChainError)
+	assert.NotEqual(t, failedTx, certificate.DigitalSignature)
+}
+
+func newMintTestService(t *testing.T, blockchain service.BlockchainService, cfg *configs.BlockchainConfig) (service.CertificateService, *fakeCertificateRepository, *fakeOutboxService) {
+	certificates := &fakeCertificateRepository{certificates: map[int64]*entity.Certificate{}}
+	users := &fakeUserRepository{user: &entity.User{Id: 7, Name: "Budi", Email: "budi@example.com"}}
+	donations := &fakeBloodDonationRepository{donation: &entity.BloodDonation{Id: 3, UserId: 7, Hospital: entity.Hospital{
+		Address: "Jl. Merdeka 1", City: "Semarang", Province: "Jawa Tengah",
+	}}}
+	outbox := &fakeOutboxService{}
+	certificateService := service.NewCertificateService(certificates, &fakeCertificateClaimRepository{}, users, donations, passthroughTransactor{}, outbox, blockchain, cfg, &configs.CertificateConfig{})
+	return certificateService, certificates, outbox
+}
+
+func bigString(t *testing.T, value string) *big.Int {
+	result, ok := new(big.Int).SetString(value, 10)
+	require.True(t, ok, value)
+	return result
+}
+
+// flakyBlockchain meneruskan ke chain simulasi, tetapi bisa menggagalkan pengiriman atau
+// membuangnya seolah transaksi hilang dari mempool
+type flakyBlockchain struct {
+	service.BlockchainService
+	failSends int
+	dropSends int
+}
+
+func (b *flakyBlockchain) SendTransaction(ctx context.Context, raw []byte) error {
+	if b.failSends > 0 {
+		b.failSends--
+		return errors.New("connection refused")
+	}
+	if b.dropSends > 0 {
+		b.dropSends--
+		return nil
+	}
+	return b.BlockchainService.SendTransaction(ctx, raw)
+}
+
+// rotatedIssuerBlockchain meniru backend yang kunci penerbitnya sudah diganti
+type rotatedIssuerBlockchain struct {
+	service.BlockchainService
+	issuer string
+}
+
+func (b *rotatedIssuerBlockchain) IssuerAddress() string {
+	return b.issuer
+}
+
+type fakeCertificateRepository struct {
+	repository.CertificateRepository
+	certificates map[int64]*entity.Certificate
+	updates      int
+}
+
+func (r *fakeCertificateRepository) Create(ctx context.Context, certificate *entity.Certificate) error {
+	certificate.Id = int64(len(r.certificates) + 1)
+	r.certificates[certificate.Id] = certificate
+	return nil
+}
+
+func (r *fakeCertificateRepository) GetById(ctx context.Context, id int64) (*entity.Certificate, error) {
+	certificate, ok := r.certificates[id]
+	if !ok {
+		return nil, service.ErrCertificateNotFound
+	}
+	return certificate, nil
+}
+
+func (r *fakeCertificateRepository) GetByChainStatus(ctx context.Context, chainStatus string, limit int) ([]entity.Certificate, error) {
+	var result []entity.Certificate
+	for _, certificate := range r.certificates {
+		if certificate.ChainStatus == chainStatus {
+			result = append(result, *certificate)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeCertificateRepository) GetByCertificateNumber(ctx context.Context, certificateNumber string) (*entity.Certificate, error) {
+	for _, certificate := range r.certificates {
+		if certificate.CertificateNumber == certificateNumber {
+			return certificate, nil
+		}
+	}
+	return nil, service.ErrCertificateNotFound
+}
+
+func (r *fakeCertificateRepository) Update(ctx context.Context, certificate *entity.Certificate) error {
+	r.updates++
+	stored := *certificate
+	r.certificates[certificate.Id] = &stored
+	return nil
+}
+
+type fakeCertificateClaimRepository struct {
+	claims []*entity.CertificateClaim
+}
+
+func (r *fakeCertificateClaimRepository) Create(ctx context.Context, claim *entity.CertificateClaim) error {
+	claim.Id = int64(len(r.claims) + 1)
+	r.claims = append(r.claims, claim)
+	return nil
+}
+
+func (r *fakeCertificateClaimRepository) GetPendingByCertificateId(ctx context.Context, certificateId int64) (*entity.CertificateClaim, error) {
+	for _, claim := range r.claims {
+		if claim.CertificateId == certificateId && claim.Status == entity.ClaimStatusPending {
+			return claim, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeCertificateClaimRepository) GetByCertificateId(ctx context.Context, certificateId int64) ([]entity.CertificateClaim, error) {
+	var result []entity.CertificateClaim
+	for _, claim := range r.claims {
+		if claim.CertificateId == certificateId {
+			result = append(result, *claim)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeCertificateClaimRepository) Update(ctx context.Context, claim *entity.CertificateClaim) error {
+	return nil
+}
+
+type fakeUserRepository struct {
+	repository.UserRepository
+	user *entity.User
+}
+
+func (r *fakeUserRepository) GetById(ctx context.Context, id int64) (*entity.User, error) {
+	if id != r.user.Id {
+		return nil, assert.AnError
+	}
+	user := *r.user
+	return &user, nil
+}
+
+type fakeBloodDonationRepository struct {
+	repository.BloodDonationRepository
+	donation *entity.BloodDonation
+}
+
+func (r *fakeBloodDonationRepository) GetById(ctx context.Context, id int64) (*entity.BloodDonation, error) {
+	return r.donation, nil
+}
+
+func (r *fakeBloodDonationRepository) GetCompletedByUserIds(ctx context.Context, userIds []int64, since time.Time) ([]entity.BloodDonation, error) {
+	return nil, nil
+}
+
+type fakeOutboxService struct {
+	events []string
+	emails []service.EmailSendPayload
+}
+
+func (s *fakeOutboxService) Enqueue(ctx context.Context, eventType, aggregateType string, aggregateId int64, payload interface{}) error {
+	s.events = append(s.events, eventType)
+	return nil
+}
+
+func (s *fakeOutboxService) Notify(ctx context.Context, payload service.NotificationCreatePayload) error {
+	return s.Enqueue(ctx, service.OutboxNotificationCreate, "user", payload.UserId, payload)
+}
+
+func (s *fakeOutboxService) SendEmail(ctx context.Context, payload service.EmailSendPayload) error {
+	s.emails = append(s.emails, payload)
+	return s.Enqueue(ctx, service.OutboxEmailSend, "email", 0, payload)
+}
@@ -1,25 +1,103 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
 
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/donationstatement"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/payment"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/timezone"
+)
+
+// Hasil pemrosesan notifikasi gateway pembayaran
+const (
+	NotificationApplied   = "applied"
+	NotificationDuplicate = "duplicate"
+	NotificationIgnored   = "ignored"
+	NotificationRejected  = "rejected"
+	NotificationFailed    = "failed"
+
+	// DiscrepancyExpired menandai donasi yang dikedaluwarsakan reconciler
+	DiscrepancyExpired = "expired"
+)
+
+var (
+	ErrNotificationPayload    = payment.ErrInvalidPayload
+	ErrNotificationSignature  = payment.ErrInvalidSignature
+	ErrDonationNotRefundable  = errors.New("donasi belum dibayar atau sudah direfund penuh")
+	ErrDonationTargetNotFound = errors.New("campaign atau dana tujuan donasi tidak ditemukan")
+	ErrDonationTargetClosed   = errors.New("campaign atau dana tujuan donasi sudah tidak menerima donasi")
 )
 
 type DonationsService interface {
 	GetAllDonation(ctx context.Context, req dto.GetAllDonation) ([]entity.Donation, int64, error)
 	GetById(ctx context.Context, id int64)(*entity.Donation, error)
+	// CreateTransaction mencatat donasi pending dengan order id baru, membuat pembayaran di
+	// gateway, lalu mengembalikan URL halaman pembayaran
+	CreateTransaction(ctx context.Context, req dto.PaymentRequest) (string, error)
+	// HandleNotification menyimpan notifikasi gateway mentah, memverifikasi signature-nya, lalu
+	// menerapkan perubahan status donasi satu kali untuk setiap pasangan order dan status
+	HandleNotification(ctx context.Context, payload []byte) (string, error)
+	// Refund meminta refund ke gateway; status donasi berubah saat notifikasi refund diterima
+	Refund(ctx context.Context, req dto.DonationRefundRequest) error
+	// Reconcile mencocokkan donasi pending yang webhook-nya belum diterima dengan status
+	// transaksi di gateway dan mengembalikan jumlah selisih yang ditemukan
+	Reconcile(ctx context.Context) (int, error)
+	GetDiscrepancies(ctx context.Context, req dto.GetAllPaymentDiscrepancyRequest) ([]entity.PaymentDiscrepancy, int64, error)
+	// Statement merangkum donasi berhasil milik pengguna pada satu tahun kalender
+	Statement(ctx context.Context, userId int64, year int) (*donationstatement.Data, error)
+	// RenderStatement membuat laporan tahunan dalam format pdf (bawaan) atau csv
+	RenderStatement(ctx context.Context, req dto.DonationStatementRequest) ([]byte, error)
 }
 
 type donationService struct {
-	DonationsRepository repository.DonationsRepository
+	DonationsRepository           repository.DonationsRepository
+	paymentNotificationRepository repository.PaymentNotificationRepository
+	paymentDiscrepancyRepository  repository.PaymentDiscrepancyRepository
+	bloodRequestRepository        repository.BloodRequestRepository
+	fundRepository                repository.FundRepository
+	userRepository                repository.UserRepository
+	transactor                    repository.Transactor
+	outboxService                 OutboxService
+	gateway                       payment.PaymentGateway
+	cfg                           *configs.PaymentConfig
 }
 
-func NewDonationService(donationsRepository repository.DonationsRepository) DonationsService {
+func NewDonationService(
+	donationsRepository repository.DonationsRepository,
+	paymentNotificationRepository repository.PaymentNotificationRepository,
+	paymentDiscrepancyRepository repository.PaymentDiscrepancyRepository,
+	bloodRequestRepository repository.BloodRequestRepository,
+	fundRepository repository.FundRepository,
+	userRepository repository.UserRepository,
+	transactor repository.Transactor,
+	outboxService OutboxService,
+	gateway payment.PaymentGateway,
+	cfg *configs.PaymentConfig,
+) DonationsService {
 	return &donationService{
-		DonationsRepository: donationsRepository,
+		DonationsRepository:           donationsRepository,
+		paymentNotificationRepository: paymentNotificationRepository,
+		paymentDiscrepancyRepository:  paymentDiscrepancyRepository,
+		bloodRequestRepository:        bloodRequestRepository,
+		fundRepository:                fundRepository,
+		userRepository:                userRepository,
+		transactor:                    transactor,
+		outboxService:                 outboxService,
+		gateway:                       gateway,
+		cfg:                           cfg,
 	}
 }
 
@@ -37,4 +115,515 @@ func (s *donationService) GetById(ctx context.Context, id int64)(*entity.Donatio
 		return nil, err
 	}
 	return donation, nil
-}
\ No newline at end of file
+}
+
+func (s *donationService) CreateTransaction(ctx context.Context, req dto.PaymentRequest) (string, error) {
+	if err := s.checkTarget(ctx, req); err != nil {
+		return "", err
+	}
+
+	orderId, err := newOrderId(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	// Donasi dicatat sebelum charge dibuat sehingga order id dijamin unik oleh database
+	donation := &entity.Donation{
+		UserId:         req.UserId,
+		Amount:         req.Amount,
+		OrderId:        orderId,
+		Status:         payment.StatusPending,
+		Gateway:        s.gateway.Name(),
+		BloodRequestId: req.BloodRequestId,
+		FundId:         req.FundId,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	if err := s.DonationsRepository.Create(ctx, donation); err != nil {
+		return "", errors.New("gagal memproses donasi")
+	}
+
+	charge, err := s.gateway.CreateCharge(ctx, payment.Charge{
+		OrderId:       orderId,
+		Amount:        req.Amount,
+		CustomerName:  req.Fullname,
+		CustomerEmail: req.Email,
+	})
+	if err != nil {
+		donation.Status = payment.StatusFailed
+		donation.UpdatedAt = time.Now()
+		if updateErr := s.DonationsRepository.Update(ctx, orderId, donation); updateErr != nil {
+			log.Printf("Gagal menandai donasi %s gagal: %v", orderId, updateErr)
+		}
+		return "", err
+	}
+	return charge.RedirectURL, nil
+}
+
+// checkTarget memastikan campaign atau dana tujuan donasi masih menggalang dana
+func (s *donationService) checkTarget(ctx context.Context, req dto.PaymentRequest) error {
+	now := time.Now()
+	switch {
+	case req.BloodRequestId != nil && req.FundId != nil:
+		return errors.New("donasi hanya dapat ditujukan ke satu campaign atau dana")
+	case req.BloodRequestId != nil:
+		bloodRequest, err := s.bloodRequestRepository.GetById(ctx, *req.BloodRequestId)
+		if err != nil {
+			return ErrDonationTargetNotFound
+		}
+		if !fundraisingOpen(bloodRequest.GoalAmount, bloodRequest.DonationDeadline, bloodRequestAcceptsDonation(bloodRequest.Status), now) {
+			return ErrDonationTargetClosed
+		}
+	case req.FundId != nil:
+		fund, err := s.fundRepository.GetById(ctx, *req.FundId)
+		if err != nil {
+			return ErrDonationTargetNotFound
+		}
+		if !fundraisingOpen(fund.GoalAmount, fund.Deadline, fund.Status == FundActive, now) {
+			return ErrDonationTargetClosed
+		}
+	}
+	return nil
+}
+
+func (s *donationService) HandleNotification(ctx context.Context, payload []byte) (string, error) {
+	notification, err := s.gateway.ParseNotification(ctx, payload)
+	if err != nil && !errors.Is(err, payment.ErrInvalidSignature) {
+		return "", err
+	}
+
+	// Notifikasi disimpan sebelum diproses agar percobaan pemalsuan juga tercatat
+	record := &entity.PaymentNotification{
+		Gateway:           s.gateway.Name(),
+		OrderId:           notification.OrderId,
+		TransactionId:     notification.TransactionId,
+		TransactionStatus: notification.TransactionStatus,
+		FraudStatus:       notification.FraudStatus,
+		StatusCode:        notification.StatusCode,
+		GrossAmount:       notification.GrossAmount,
+		SignatureValid:    notification.SignatureValid,
+		Payload:           string(payload),
+		Result:            "received",
+		CreatedAt:         time.Now(),
+	}
+	if err := s.paymentNotificationRepository.Create(ctx, record); err != nil {
+		return "", errors.New("gagal menyimpan notifikasi pembayaran")
+	}
+
+	if !record.SignatureValid {
+		s.markProcessed(ctx, record, NotificationRejected, ErrNotificationSignature.Error())
+		return NotificationRejected, ErrNotificationSignature
+	}
+
+	result, _, err := s.process(ctx, record, notification)
+	return result, err
+}
+
+// process menerapkan notifikasi yang sudah tersimpan lalu mencatat hasilnya
+func (s *donationService) process(ctx context.Context, record *entity.PaymentNotification, notification *payment.Notification) (string, string, error) {
+	var result, message string
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, message, err = s.applyNotification(ctx, notification)
+		if err != nil {
+			return err
+		}
+		return s.paymentNotificationRepository.MarkProcessed(ctx, record, result, message)
+	})
+	if err != nil {
+		// Gateway mengirim ulang notifikasi selama respons bukan 2xx
+		s.markProcessed(ctx, record, NotificationFailed, err.Error())
+		return NotificationFailed, err.Error(), errors.New("gagal memproses donasi")
+	}
+	return result, message, nil
+}
+
+// applyNotification mengunci donasi lalu menerapkan status baru jika status tersebut belum
+// pernah diterapkan dan perpindahannya diizinkan
+func (s *donationService) applyNotification(ctx context.Context, notification *payment.Notification) (string, string, error) {
+	orderId := notification.OrderId
+	donation, err := s.DonationsRepository.GetByOrderIdForUpdate(ctx, orderId)
+	if err != nil {
+		return NotificationRejected, "donasi dengan order tersebut tidak ditemukan", nil
+	}
+
+	if !payment.GrossAmountEquals(notification.GrossAmount, donation.Amount) {
+		return NotificationRejected, fmt.Sprintf("nominal %s tidak sesuai dengan donasi %d", notification.GrossAmount, donation.Amount), nil
+	}
+
+	// Refund sebagian berikutnya memakai transaction_status yang sama; yang berubah hanya total refund
+	if notification.Status == payment.StatusPartiallyRefunded && donation.Status == payment.StatusPartiallyRefunded &&
+		notification.RefundedAmount > donation.RefundedAmount {
+		donation.RefundedAmount = notification.RefundedAmount
+		donation.UpdatedAt = time.Now()
+		if err := s.DonationsRepository.Update(ctx, orderId, donation); err != nil {
+			return "", "", err
+		}
+		return NotificationApplied, fmt.Sprintf("total refund donasi menjadi %d", donation.RefundedAmount), nil
+	}
+
+	applied, err := s.paymentNotificationRepository.IsApplied(ctx, notification.OrderId, notification.TransactionStatus, notification.FraudStatus)
+	if err != nil {
+		return "", "", err
+	}
+	if applied {
+		return NotificationDuplicate, "status " + notification.TransactionStatus + " sudah diproses", nil
+	}
+
+	status := notification.Status
+	if status == "" {
+		return NotificationIgnored, "status transaksi " + notification.TransactionStatus + " tidak dikenal", nil
+	}
+	if status == donation.Status {
+		return NotificationApplied, "status donasi tetap " + status, nil
+	}
+	if !payment.CanTransition(donation.Status, status) {
+		return NotificationIgnored, fmt.Sprintf("status donasi %s tidak dapat berubah menjadi %s", donation.Status, status), nil
+	}
+
+	donation.Status = status
+	donation.UpdatedAt = time.Now()
+	switch status {
+	case payment.StatusRefunded:
+		donation.RefundedAmount = donation.Amount
+	case payment.StatusPartiallyRefunded:
+		donation.RefundedAmount = notification.RefundedAmount
+	}
+	if status == payment.StatusSuccess {
+		donation.TransactionTime = notification.TransactionTime
+		if donation.TransactionTime.IsZero() {
+			donation.TransactionTime = time.Now()
+		}
+	}
+	if err := s.DonationsRepository.Update(ctx, orderId, donation); err != nil {
+		return "", "", err
+	}
+	if status == payment.StatusSuccess {
+		// Kwitansi diantrekan di transaksi yang sama sehingga hanya terkirim sekali per donasi
+		if err := s.sendReceipt(ctx, donation); err != nil {
+			return "", "", err
+		}
+	}
+	return NotificationApplied, "status donasi menjadi " + status, nil
+}
+
+// sendReceipt mengantrekan email kwitansi untuk donasi yang baru dibayar
+func (s *donationService) sendReceipt(ctx context.Context, donation *entity.Donation) error {
+	user, err := s.userRepository.GetById(ctx, donation.UserId)
+	if err != nil {
+		return errors.New("Pengguna tidak ditemukan")
+	}
+	// Campaign yang sudah dihapus tidak boleh menggagalkan pembayaran
+	target, _ := s.targetName(ctx, donation)
+	if target == "" {
+		target = "Donasi umum DarahConnect"
+	}
+
+	return s.outboxService.SendEmail(ctx, EmailSendPayload{
+		To:       user.Email,
+		Subject:  "Kwitansi Donasi - Darah Connect",
+		Template: "donation-receipt.html",
+		Data: map[string]interface{}{
+			"Name":            user.Name,
+			"OrderId":         donation.OrderId,
+			"Amount":          donationstatement.FormatRupiah(donation.Amount),
+			"Target":          target,
+			"Gateway":         donation.Gateway,
+			"TransactionTime": donation.TransactionTime.In(jakartaLocation()).Format("02-01-2006 15:04") + " WIB",
+		},
+	})
+}
+
+// targetName mengembalikan judul campaign atau dana tujuan donasi, kosong untuk donasi umum
+func (s *donationService) targetName(ctx context.Context, donation *entity.Donation) (string, error) {
+	switch {
+	case donation.BloodRequestId != nil:
+		bloodRequest, err := s.bloodRequestRepository.GetById(ctx, *donation.BloodRequestId)
+		if err != nil {
+			return "", ErrDonationTargetNotFound
+		}
+		return bloodRequest.EventName, nil
+	case donation.FundId != nil:
+		fund, err := s.fundRepository.GetById(ctx, *donation.FundId)
+		if err != nil {
+			return "", ErrDonationTargetNotFound
+		}
+		return fund.Title, nil
+	}
+	return "", nil
+}
+
+func (s *donationService) Refund(ctx context.Context, req dto.DonationRefundRequest) error {
+	donation, err := s.DonationsRepository.GetById(ctx, req.Id)
+	if err != nil {
+		return errors.New("donasi tidak ditemukan")
+	}
+	if donation.Status != payment.StatusSuccess && donation.Status != payment.StatusPartiallyRefunded {
+		return ErrDonationNotRefundable
+	}
+	if donation.Gateway != "" && donation.Gateway != s.gateway.Name() {
+		return fmt.Errorf("donasi diproses gateway %s yang sedang tidak aktif", donation.Gateway)
+	}
+	if req.Amount > donation.Amount {
+		return errors.New("nominal refund melebihi nominal donasi")
+	}
+
+	if err := s.gateway.Refund(ctx, donation.OrderId, req.Amount, req.Reason); err != nil {
+		return fmt.Errorf("gagal meminta refund: %w", err)
+	}
+	return nil
+}
+
+func (s *donationService) Reconcile(ctx context.Context) (int, error) {
+	now := time.Now()
+	batchSize := s.cfg.ReconcileBatchSize
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+
+	found := 0
+	var afterId int64
+	for {
+		donations, err := s.DonationsRepository.GetPendingBefore(ctx, now.Add(-s.cfg.ReconcileAfter), afterId, batchSize)
+		if err != nil {
+			return found, err
+		}
+
+		for i := range donations {
+			afterId = donations[i].Id
+			discrepancy, err := s.reconcileDonation(ctx, &donations[i], now)
+			if err != nil {
+				if ctx.Err() != nil {
+					return found, ctx.Err()
+				}
+				log.Printf("Gagal merekonsiliasi donasi %d: %v", donations[i].Id, err)
+				continue
+			}
+			if discrepancy == nil {
+				continue
+			}
+			// Selisih yang sama tidak dicatat ulang setiap kali reconciler berjalan
+			recorded, err := s.paymentDiscrepancyRepository.IsRecorded(ctx, discrepancy.DonationId, discrepancy.Action, discrepancy.GatewayStatus)
+			if err != nil {
+				return found, err
+			}
+			if recorded {
+				continue
+			}
+			if err := s.paymentDiscrepancyRepository.Create(ctx, discrepancy); err != nil {
+				return found, err
+			}
+			found++
+		}
+
+		if len(donations) < batchSize {
+			return found, nil
+		}
+	}
+}
+
+// reconcileDonation menanyakan status satu donasi ke gateway. Hasilnya nil jika status donasi
+// masih sesuai dengan gateway.
+func (s *donationService) reconcileDonation(ctx context.Context, donation *entity.Donation, now time.Time) (*entity.PaymentDiscrepancy, error) {
+	if donation.Gateway != s.gateway.Name() {
+		return nil, nil
+	}
+
+	discrepancy := &entity.PaymentDiscrepancy{
+		DonationId:  donation.Id,
+		OrderId:     donation.OrderId,
+		Gateway:     donation.Gateway,
+		LocalStatus: donation.Status,
+		LocalAmount: donation.Amount,
+		CreatedAt:   now,
+	}
+	expired := now.Sub(donation.CreatedAt) >= s.cfg.ExpireAfter
+
+	notification, err := s.gateway.QueryStatus(ctx, discrepancy.OrderId)
+	if errors.Is(err, payment.ErrOrderNotFound) {
+		// Snap Midtrans baru membuat transaksi setelah pendonor memilih metode pembayaran
+		if !expired {
+			return nil, nil
+		}
+		discrepancy.Message = "order tidak ditemukan di gateway setelah batas waktu pembayaran"
+		return s.expire(ctx, donation, discrepancy)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	discrepancy.GatewayStatus = notification.TransactionStatus
+	discrepancy.GatewayAmount = notification.GrossAmount
+	if notification.Status == payment.StatusPending {
+		if !expired {
+			return nil, nil
+		}
+		discrepancy.Message = "transaksi masih pending di gateway setelah batas waktu pembayaran"
+		return s.expire(ctx, donation, discrepancy)
+	}
+
+	// Status dari gateway diproses seperti webhook agar idempotensinya sama
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return nil, err
+	}
+	record := &entity.PaymentNotification{
+		Gateway:           s.gateway.Name(),
+		OrderId:           notification.OrderId,
+		TransactionId:     notification.TransactionId,
+		TransactionStatus: notification.TransactionStatus,
+		FraudStatus:       notification.FraudStatus,
+		StatusCode:        notification.StatusCode,
+		GrossAmount:       notification.GrossAmount,
+		SignatureValid:    notification.SignatureValid,
+		Payload:           string(payload),
+		Result:            "received",
+		CreatedAt:         time.Now(),
+	}
+	if err := s.paymentNotificationRepository.Create(ctx, record); err != nil {
+		return nil, err
+	}
+	result, message, err := s.process(ctx, record, notification)
+	if err != nil {
+		return nil, err
+	}
+	if result == NotificationDuplicate {
+		// Webhook datang lebih dulu saat status sedang ditanyakan
+		return nil, nil
+	}
+	discrepancy.Action = result
+	discrepancy.Message = message
+	if result != NotificationApplied && expired {
+		// Donasi yang tetap tidak bisa diselesaikan sampai batas waktu pembayaran berakhir
+		// dikedaluwarsakan agar tidak ditanyakan ke gateway selamanya
+		discrepancy.Message = message + "; batas waktu pembayaran sudah lewat"
+		return s.expire(ctx, donation, discrepancy)
+	}
+	return discrepancy, nil
+}
+
+// expire mengubah donasi yang melewati batas waktu pembayaran menjadi expired
+func (s *donationService) expire(ctx context.Context, donation *entity.Donation, discrepancy *entity.PaymentDiscrepancy) (*entity.PaymentDiscrepancy, error) {
+	changed := false
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		locked, err := s.DonationsRepository.GetByOrderIdForUpdate(ctx, donation.OrderId)
+		if err != nil {
+			return err
+		}
+		if locked.Status != payment.StatusPending {
+			return nil
+		}
+		locked.Status = payment.StatusExpired
+		locked.UpdatedAt = time.Now()
+		changed = true
+		return s.DonationsRepository.Update(ctx, locked.OrderId, locked)
+	})
+	if err != nil || !changed {
+		return nil, err
+	}
+	discrepancy.Action = DiscrepancyExpired
+	return discrepancy, nil
+}
+
+func (s *donationService) GetDiscrepancies(ctx context.Context, req dto.GetAllPaymentDiscrepancyRequest) ([]entity.PaymentDiscrepancy, int64, error) {
+	return s.paymentDiscrepancyRepository.GetAll(ctx, req)
+}
+
+// Statement menghitung donasi yang sudah dibayar. Donasi yang direfund sebagian dihitung
+// dengan nilai bersih setelah dikurangi nominal refund yang dilaporkan gateway.
+func (s *donationService) Statement(ctx context.Context, userId int64, year int) (*donationstatement.Data, error) {
+	user, err := s.userRepository.GetById(ctx, userId)
+	if err != nil {
+		return nil, errors.New("Pengguna tidak ditemukan")
+	}
+
+	// Tahun kalender mengikuti WIB
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, jakartaLocation())
+	donations, err := s.DonationsRepository.GetSuccessfulByUser(ctx, userId, from, from.AddDate(1, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	data := &donationstatement.Data{
+		Year:        year,
+		DonorName:   user.Name,
+		DonorEmail:  user.Email,
+		Donations:   make([]donationstatement.Row, 0, len(donations)),
+		GeneratedAt: time.Now(),
+	}
+	targets := make(map[string]string)
+	for i := range donations {
+		donation := &donations[i]
+		key := targetKey(donation)
+		target, ok := targets[key]
+		if !ok {
+			target, err = s.targetName(ctx, donation)
+			if err != nil {
+				target = "-"
+			}
+			targets[key] = target
+		}
+		data.Donations = append(data.Donations, donationstatement.Row{
+			Date:     donation.TransactionTime.In(from.Location()),
+			OrderId:  donation.OrderId,
+			Target:   target,
+			Gateway:  donation.Gateway,
+			Amount:   donation.Amount,
+			Refunded: donation.RefundedAmount,
+		})
+		data.TotalAmount += donation.Amount - donation.RefundedAmount
+	}
+	return data, nil
+}
+
+func (s *donationService) RenderStatement(ctx context.Context, req dto.DonationStatementRequest) ([]byte, error) {
+	data, err := s.Statement(ctx, req.UserId, req.Year)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if req.Format == "csv" {
+		err = donationstatement.RenderCSV(&buf, *data)
+	} else {
+		err = donationstatement.RenderPDF(&buf, *data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// jakartaLocation jatuh ke zona waktu lokal jika timezone belum diinisialisasi
+func jakartaLocation() *time.Location {
+	if timezone.JakartaLocation == nil {
+		return time.Local
+	}
+	return timezone.JakartaLocation
+}
+
+func targetKey(donation *entity.Donation) string {
+	switch {
+	case donation.BloodRequestId != nil:
+		return fmt.Sprintf("blood_request:%d", *donation.BloodRequestId)
+	case donation.FundId != nil:
+		return fmt.Sprintf("fund:%d", *donation.FundId)
+	}
+	return ""
+}
+
+func (s *donationService) markProcessed(ctx context.Context, record *entity.PaymentNotification, result, message string) {
+	if err := s.paymentNotificationRepository.MarkProcessed(ctx, record, result, message); err != nil {
+		log.Printf("Gagal memperbarui notifikasi pembayaran %d: %v", record.Id, err)
+	}
+}
+
+// newOrderId membuat order id gateway berformat DC-<tanggal>-<16 hex acak>. Bagian acak 64 bit
+// membuat tabrakan praktis mustahil, dan unique index order_id menolak sisanya.
+func newOrderId(now time.Time) (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.New("gagal membuat order id")
+	}
+	return "DC-" + now.Format("20060102") + "-" + strings.ToUpper(hex.EncodeToString(buf)), nil
+}
@@ -0,0 +1,479 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/midtrans"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/payment"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/payment/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testServerKey = "SB-Mid-server-test"
+
+func TestDonationHandleNotification(t *testing.T) {
+	ctx := context.Background()
+	donations := &fakeDonationsRepository{donation: &entity.Donation{Id: 1, UserId: 7, OrderId: "ORDER-7-20241018100000", Amount: 50000, Status: payment.StatusPending}}
+	notifications := &fakePaymentNotificationRepository{}
+	outbox := &fakeOutboxService{}
+	donationService := service.NewDonationService(donations, notifications, &fakePaymentDiscrepancyRepository{}, nil, &fakeFundRepository{}, donationUsers(), passthroughTransactor{}, outbox, newMidtransGateway(t), &configs.PaymentConfig{})
+
+	settlement := signedNotification("settlement", "", "50000.00")
+	result, err := donationService.HandleNotification(ctx, settlement)
+	require.NoError(t, err)
+	assert.Equal(t, service.NotificationApplied, result)
+	assert.Equal(t, payment.StatusSuccess, donations.donation.Status)
+	assert.Equal(t, 2024, donations.donation.TransactionTime.Year())
+	require.Len(t, outbox.emails, 1)
+	assert.Equal(t, "budi@example.com", outbox.emails[0].To)
+	assert.Equal(t, "donation-receipt.html", outbox.emails[0].Template)
+	assert.Equal(t, "Rp50.000", outbox.emails[0].Data["Amount"])
+
+	// Midtrans mengirim ulang notification yang sama
+	result, err = donationService.HandleNotification(ctx, settlement)
+	require.NoError(t, err)
+	assert.Equal(t, service.NotificationDuplicate, result)
+	assert.Equal(t, 1, donations.updates)
+	assert.Len(t, outbox.emails, 1)
+
+	// Donasi yang sudah berhasil tidak bisa kedaluwarsa
+	result, err = donationService.HandleNotification(ctx, signedNotification("expire", "", "50000.00"))
+	require.NoError(t, err)
+	assert.Equal(t, service.NotificationIgnored, result)
+	assert.Equal(t, payment.StatusSuccess, donations.donation.Status)
+
+	result, err = donationService.HandleNotification(ctx, signedNotification("refund", "", "50000.00"))
+	require.NoError(t, err)
+	assert.Equal(t, service.NotificationApplied, result)
+	assert.Equal(t, payment.StatusRefunded, donations.donation.Status)
+
+	// Semua notification tersimpan beserta hasilnya
+	require.Len(t, notifications.notifications, 4)
+	assert.Equal(t, []string{service.NotificationApplied, service.NotificationDuplicate, service.NotificationIgnored, service.NotificationApplied}, notifications.results())
+}
+
+func TestDonationHandleNotificationPartialRefund(t *testing.T) {
+	ctx := context.Background()
+	donations := &fakeDonationsRepository{donation: &entity.Donation{Id: 1, UserId: 7, OrderId: "ORDER-7-20241018100000", Amount: 50000, Status: payment.StatusSuccess}}
+	notifications := &fakePaymentNotificationRepository{}
+	donationService := service.NewDonationService(donations, notifications, &fakePaymentDiscrepancyRepository{}, nil, &fakeFundRepository{}, donationUsers(), passthroughTransactor{}, &fakeOutboxService{}, newMidtransGateway(t), &configs.PaymentConfig{})
+
+	result, err := donationService.HandleNotification(ctx, signedRefundNotification("partial_refund", "50000.00", "20000.00"))
+	require.NoError(t, err)
+	assert.Equal(t, service.NotificationApplied, result)
+	assert.Equal(t, payment.StatusPartiallyRefunded, donations.donation.Status)
+	assert.Equal(t, int64(20000), donations.donation.RefundedAmount)
+
+	// Refund sebagian kedua memakai status yang sama dengan total refund yang lebih besar
+	result, err = donationService.HandleNotification(ctx, signedRefundNotification("partial_refund", "50000.00", "35000.00"))
+	require.NoError(t, err)
+	assert.Equal(t, service.NotificationApplied, result)
+	assert.Equal(t, int64(35000), donations.donation.RefundedAmount)
+
+	result, err = donationService.HandleNotification(ctx, signedRefundNotification("partial_refund", "50000.00", "35000.00"))
+	require.NoError(t, err)
+	assert.Equal(t, service.NotificationDuplicate, result)
+
+	result, err = donationService.HandleNotification(ctx, signedRefundNotification("refund", "50000.00", "50000.00"))
+	require.NoError(t, err)
+	assert.Equal(t, service.NotificationApplied, result)
+	assert.Equal(t, payment.StatusRefunded, donations.donation.Status)
+	assert.Equal(t, int64(50000), donations.donation.RefundedAmount)
+}
+
+func TestDonationHandleNotificationRejectsForgery(t *testing.T) {
+	ctx := context.Background()
+	donations := &fakeDonationsRepository{donation: &entity.Donation{Id: 1, OrderId: "ORDER-7-20241018100000", Amount: 50000, Status: payment.StatusPending}}
+	notifications := &fakePaymentNotificationRepository{}
+	donationService := service.NewDonationService(donations, notifications, &fakePaymentDiscrepancyRepository{}, nil, &fakeFundRepository{}, donationUsers(), passthroughTransactor{}, &fakeOutboxService{}, newMidtransGateway(t), &configs.PaymentConfig{})
+
+	forged := map[string]string{"order_id": "ORDER-7-20241018100000", "status_code": "200", "gross_amount": "50000.00", "transaction_status": "settlement", "signature_key": "palsu"}
+	payload, err := json.Marshal(forged)
+	require.NoError(t, err)
+
+	result, err := donationService.HandleNotification(ctx, payload)
+	assert.ErrorIs(t, err, service.ErrNotificationSignature)
+	assert.Equal(t, service.NotificationRejected, result)
+	assert.Equal(t, payment.StatusPending, donations.donation.Status)
+	require.Len(t, notifications.notifications, 1)
+	assert.False(t, notifications.notifications[0].SignatureValid)
+
+	// Signature valid tetapi nominal berbeda dari donasi
+	result, err = donationService.HandleNotification(ctx, signedNotification("settlement", "", "10.00"))
+	require.NoError(t, err)
+	assert.Equal(t, service.NotificationRejected, result)
+	assert.Equal(t, payment.StatusPending, donations.donation.Status)
+
+	_, err = donationService.HandleNotification(ctx, []byte("bukan json"))
+	assert.ErrorIs(t, err, service.ErrNotificationPayload)
+}
+
+func TestDonationCreateTransactionAndRefund(t *testing.T) {
+	ctx := context.Background()
+	gateway, err := fake.NewGateway(&configs.PaymentConfig{PublicURL: "http://localhost:8081"})
+	require.NoError(t, err)
+	donations := &fakeDonationsRepository{}
+	donationService := service.NewDonationService(donations, &fakePaymentNotificationRepository{}, &fakePaymentDiscrepancyRepository{}, nil, &fakeFundRepository{}, donationUsers(), passthroughTransactor{}, &fakeOutboxService{}, gateway, &configs.PaymentConfig{})
+
+	redirectURL, err := donationService.CreateTransaction(ctx, dto.PaymentRequest{UserId: 7, Amount: 50000})
+	require.NoError(t, err)
+	require.NotNil(t, donations.donation)
+	assert.Regexp(t, `^DC-\d{8}-[0-9A-F]{16}$`, donations.donation.OrderId)
+	assert.Contains(t, redirectURL, "payment/fake/checkout/"+donations.donation.OrderId)
+	assert.Equal(t, payment.StatusPending, donations.donation.Status)
+	assert.Equal(t, "fake", donations.donation.Gateway)
+
+	// Setiap transaksi mendapat order id baru
+	firstOrderId := donations.donation.OrderId
+	_, err = donationService.CreateTransaction(ctx, dto.PaymentRequest{UserId: 7, Amount: 50000})
+	require.NoError(t, err)
+	assert.NotEqual(t, firstOrderId, donations.donation.OrderId)
+
+	// Donasi yang belum dibayar tidak bisa direfund
+	err = donationService.Refund(ctx, dto.DonationRefundRequest{Id: donations.donation.Id, Reason: "batal"})
+	assert.ErrorIs(t, err, service.ErrDonationNotRefundable)
+}
+
+func TestDonationCreateTransactionTarget(t *testing.T) {
+	ctx := context.Background()
+	deadline := time.Now().Add(-time.Hour)
+	funds := &fakeFundRepository{funds: map[int64]*entity.Fund{
+		1: {Id: 1, GoalAmount: 1000000, Status: service.FundActive},
+		2: {Id: 2, GoalAmount: 1000000, Status: service.FundClosed},
+		3: {Id: 3, GoalAmount: 1000000, Status: service.FundActive, Deadline: &deadline},
+	}}
+	donations := &fakeDonationsRepository{}
+	donationService := service.NewDonationService(donations, &fakePaymentNotificationRepository{}, &fakePaymentDiscrepancyRepository{}, nil, funds, donationUsers(), passthroughTransactor{}, &fakeOutboxService{}, &stubGateway{}, &configs.PaymentConfig{})
+
+	fundId := int64(1)
+	_, err := donationService.CreateTransaction(ctx, dto.PaymentRequest{UserId: 7, Amount: 50000, FundId: &fundId})
+	require.NoError(t, err)
+	require.NotNil(t, donations.donation.FundId)
+	assert.Equal(t, int64(1), *donations.donation.FundId)
+
+	for _, id := range []int64{2, 3} {
+		fundId := id
+		_, err = donationService.CreateTransaction(ctx, dto.PaymentRequest{UserId: 7, Amount: 50000, FundId: &fundId})
+		assert.ErrorIs(t, err, service.ErrDonationTargetClosed)
+	}
+
+	fundId = 99
+	_, err = donationService.CreateTransaction(ctx, dto.PaymentRequest{UserId: 7, Amount: 50000, FundId: &fundId})
+	assert.ErrorIs(t, err, service.ErrDonationTargetNotFound)
+
+	bloodRequestId := int64(1)
+	fundId = 1
+	_, err = donationService.CreateTransaction(ctx, dto.PaymentRequest{UserId: 7, Amount: 50000, FundId: &fundId, BloodRequestId: &bloodRequestId})
+	assert.Error(t, err)
+}
+
+func TestDonationReconcile(t *testing.T) {
+	ctx := context.Background()
+	cfg := &configs.PaymentConfig{ReconcileAfter: 15 * time.Minute, ReconcileBatchSize: 10, ExpireAfter: 24 * time.Hour}
+	gateway := &stubGateway{statuses: map[string]*payment.Notification{
+		"ORDER-7-20241018100000": {OrderId: "ORDER-7-20241018100000", TransactionStatus: "settlement", StatusCode: "200", GrossAmount: "50000.00", Status: payment.StatusSuccess, SignatureValid: true},
+	}}
+
+	// Webhook settlement hilang, status diambil dari gateway
+	donations := &fakeDonationsRepository{donation: &entity.Donation{Id: 1, UserId: 7, OrderId: "ORDER-7-20241018100000", Amount: 50000, Status: payment.StatusPending, Gateway: "stub", CreatedAt: time.Now().Add(-time.Hour)}}
+	notifications := &fakePaymentNotificationRepository{}
+	discrepancies := &fakePaymentDiscrepancyRepository{}
+	donationService := service.NewDonationService(donations, notifications, discrepancies, nil, &fakeFundRepository{}, donationUsers(), passthroughTransactor{}, &fakeOutboxService{}, gateway, cfg)
+
+	found, err := donationService.Reconcile(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, found)
+	assert.Equal(t, payment.StatusSuccess, donations.donation.Status)
+	require.Len(t, discrepancies.discrepancies, 1)
+	assert.Equal(t, service.NotificationApplied, discrepancies.discrepancies[0].Action)
+	assert.Equal(t, "settlement", discrepancies.discrepancies[0].GatewayStatus)
+	assert.Equal(t, []string{service.NotificationApplied}, notifications.results())
+
+	// Donasi yang sudah berhasil tidak lagi direkonsiliasi
+	found, err = donationService.Reconcile(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, found)
+
+	// Order yang belum ada di gateway dibiarkan sampai batas waktu pembayaran lewat
+	donations = &fakeDonationsRepository{donation: &entity.Donation{Id: 2, UserId: 7, OrderId: "ORDER-7-20241018110000", Amount: 50000, Status: payment.StatusPending, Gateway: "stub", CreatedAt: time.Now().Add(-time.Hour)}}
+	discrepancies = &fakePaymentDiscrepancyRepository{}
+	donationService = service.NewDonationService(donations, notifications, discrepancies, nil, &fakeFundRepository{}, donationUsers(), passthroughTransactor{}, &fakeOutboxService{}, gateway, cfg)
+
+	found, err = donationService.Reconcile(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, found)
+	assert.Equal(t, payment.StatusPending, donations.donation.Status)
+
+	donations.donation.CreatedAt = time.Now().Add(-25 * time.Hour)
+	found, err = donationService.Reconcile(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, found)
+	assert.Equal(t, payment.StatusExpired, donations.donation.Status)
+	require.Len(t, discrepancies.discrepancies, 1)
+	assert.Equal(t, service.DiscrepancyExpired, discrepancies.discrepancies[0].Action)
+}
+
+func TestDonationStatement(t *testing.T) {
+	ctx := context.Background()
+	fundId := int64(1)
+	donations := &fakeDonationsRepository{successful: []entity.Donation{
+		{Id: 1, UserId: 7, OrderId: "DC-20240105-0000000000000001", Amount: 50000, Status: payment.StatusSuccess, Gateway: "midtrans", TransactionTime: time.Date(2024, time.January, 5, 10, 0, 0, 0, time.Local)},
+		{Id: 2, UserId: 7, OrderId: "DC-20240610-0000000000000002", Amount: 125000, Status: payment.StatusSuccess, Gateway: "midtrans", FundId: &fundId, TransactionTime: time.Date(2024, time.June, 10, 10, 0, 0, 0, time.Local)},
+		{Id: 3, UserId: 7, OrderId: "DC-20250101-0000000000000003", Amount: 75000, Status: payment.StatusSuccess, Gateway: "midtrans", TransactionTime: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local)},
+		{Id: 4, UserId: 7, OrderId: "DC-20240901-0000000000000004", Amount: 100000, RefundedAmount: 40000, Status: payment.StatusPartiallyRefunded, Gateway: "midtrans", TransactionTime: time.Date(2024, time.September, 1, 10, 0, 0, 0, time.Local)},
+	}}
+	funds := &fakeFundRepository{funds: map[int64]*entity.Fund{1: {Id: 1, Title: "Dana Darurat PMI"}}}
+	donationService := service.NewDonationService(donations, &fakePaymentNotificationRepository{}, &fakePaymentDiscrepancyRepository{}, nil, funds, donationUsers(), passthroughTransactor{}, &fakeOutboxService{}, &stubGateway{}, &configs.PaymentConfig{})
+
+	statement, err := donationService.Statement(ctx, 7, 2024)
+	require.NoError(t, err)
+	assert.Equal(t, "Budi", statement.DonorName)
+	require.Len(t, statement.Donations, 3)
+	assert.Equal(t, int64(235000), statement.TotalAmount)
+	assert.Equal(t, "", statement.Donations[0].Target)
+	assert.Equal(t, "Dana Darurat PMI", statement.Donations[1].Target)
+
+	// Donasi yang direfund sebagian dihitung dengan nilai bersihnya
+	assert.Equal(t, int64(100000), statement.Donations[2].Amount)
+	assert.Equal(t, int64(40000), statement.Donations[2].Refunded)
+
+	csv, err := donationService.RenderStatement(ctx, dto.DonationStatementRequest{UserId: 7, Year: 2024, Format: "csv"})
+	require.NoError(t, err)
+	assert.Contains(t, string(csv), "DC-20240901-0000000000000004,,midtrans,100000,40000,60000")
+	assert.Contains(t, string(csv), "total,,,,,,235000")
+
+	pdf, err := donationService.RenderStatement(ctx, dto.DonationStatementRequest{UserId: 7, Year: 2025})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(pdf), "%PDF-"))
+}
+
+func donationUsers() *fakeUserRepository {
+	return &fakeUserRepository{user: &entity.User{Id: 7, Name: "Budi", Email: "budi@example.com"}}
+}
+
+func newMidtransGateway(t *testing.T) payment.PaymentGateway {
+	gateway, err := midtrans.NewGateway(&configs.MidtransConfig{ServerKey: testServerKey})
+	require.NoError(t, err)
+	return gateway
+}
+
+func signedNotification(transactionStatus, fraudStatus, grossAmount string) []byte {
+	notification := map[string]string{
+		"order_id":           "ORDER-7-20241018100000",
+		"status_code":        "200",
+		"gross_amount":       grossAmount,
+		"transaction_id":     "trx-1",
+		"transaction_status": transactionStatus,
+		"transaction_time":   "2024-10-18 10:00:00",
+		"fraud_status":       fraudStatus,
+	}
+	notification["signature_key"] = midtrans.SignatureKey(notification["order_id"], notification["status_code"], grossAmount, testServerKey)
+	payload, _ := json.Marshal(notification)
+	return payload
+}
+
+// signedRefundNotification menambahkan refund_amount, yang tidak ikut ditandatangani Midtrans
+func signedRefundNotification(transactionStatus, grossAmount, refundAmount string) []byte {
+	notification := make(map[string]string)
+	_ = json.Unmarshal(signedNotification(transactionStatus, "", grossAmount), &notification)
+	notification["refund_amount"] = refundAmount
+	payload, _ := json.Marshal(notification)
+	return payload
+}
+
+type fakeDonationsRepository struct {
+	donation   *entity.Donation
+	successful []entity.Donation
+	created    int
+	updates    int
+}
+
+func (r *fakeDonationsRepository) Create(ctx context.Context, donation *entity.Donation) error {
+	r.created++
+	donation.Id = int64(r.created)
+	r.donation = donation
+	return nil
+}
+
+func (r *fakeDonationsRepository) Update(ctx context.Context, orderId string, donation *entity.Donation) error {
+	r.updates++
+	r.donation = donation
+	return nil
+}
+
+func (r *fakeDonationsRepository) GetById(ctx context.Context, id int64) (*entity.Donation, error) {
+	if r.donation == nil || r.donation.Id != id {
+		return nil, errors.New("record not found")
+	}
+	copied := *r.donation
+	return &copied, nil
+}
+
+func (r *fakeDonationsRepository) GetByOrderIdForUpdate(ctx context.Context, orderId string) (*entity.Donation, error) {
+	if r.donation == nil || r.donation.OrderId != orderId {
+		return nil, errors.New("record not found")
+	}
+	copied := *r.donation
+	return &copied, nil
+}
+
+func (r *fakeDonationsRepository) GetPendingBefore(ctx context.Context, createdBefore time.Time, afterId int64, limit int) ([]entity.Donation, error) {
+	if r.donation == nil || r.donation.Status != payment.StatusPending || !r.donation.CreatedAt.Before(createdBefore) || r.donation.Id <= afterId {
+		return nil, nil
+	}
+	return []entity.Donation{*r.donation}, nil
+}
+
+func (r *fakeDonationsRepository) GetBloodRequestProgress(ctx context.Context, bloodRequestId int64) (*entity.FundraisingProgress, error) {
+	return &entity.FundraisingProgress{}, nil
+}
+
+func (r *fakeDonationsRepository) GetFundProgress(ctx context.Context, fundId int64) (*entity.FundraisingProgress, error) {
+	return &entity.FundraisingProgress{}, nil
+}
+
+func (r *fakeDonationsRepository) GetSuccessfulByUser(ctx context.Context, userId int64, from, to time.Time) ([]entity.Donation, error) {
+	result := make([]entity.Donation, 0)
+	for _, donation := range r.successful {
+		if donation.UserId == userId && !donation.TransactionTime.Before(from) && donation.TransactionTime.Before(to) {
+			result = append(result, donation)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeDonationsRepository) GetAllDonation(ctx context.Context, req dto.GetAllDonation) ([]entity.Donation, int64, error) {
+	return []entity.Donation{*r.donation}, 1, nil
+}
+
+type fakePaymentNotificationRepository struct {
+	notifications []*entity.PaymentNotification
+}
+
+func (r *fakePaymentNotificationRepository) Create(ctx context.Context, notification *entity.PaymentNotification) error {
+	notification.Id = int64(len(r.notifications) + 1)
+	r.notifications = append(r.notifications, notification)
+	return nil
+}
+
+func (r *fakePaymentNotificationRepository) MarkProcessed(ctx context.Context, notification *entity.PaymentNotification, result, message string) error {
+	notification.Result = result
+	notification.Message = message
+	return nil
+}
+
+func (r *fakePaymentNotificationRepository) IsApplied(ctx context.Context, orderId, transactionStatus, fraudStatus string) (bool, error) {
+	for _, notification := range r.notifications {
+		if notification.OrderId == orderId && notification.TransactionStatus == transactionStatus && notification.FraudStatus == fraudStatus && notification.Result == service.NotificationApplied {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakePaymentNotificationRepository) results() []string {
+	results := make([]string, 0, len(r.notifications))
+	for _, notification := range r.notifications {
+		results = append(results, notification.Result)
+	}
+	return results
+}
+
+type fakePaymentDiscrepancyRepository struct {
+	discrepancies []*entity.PaymentDiscrepancy
+}
+
+func (r *fakePaymentDiscrepancyRepository) Create(ctx context.Context, discrepancy *entity.PaymentDiscrepancy) error {
+	discrepancy.Id = int64(len(r.discrepancies) + 1)
+	r.discrepancies = append(r.discrepancies, discrepancy)
+	return nil
+}
+
+func (r *fakePaymentDiscrepancyRepository) IsRecorded(ctx context.Context, donationId int64, action, gatewayStatus string) (bool, error) {
+	for _, discrepancy := range r.discrepancies {
+		if discrepancy.DonationId == donationId && discrepancy.Action == action && discrepancy.GatewayStatus == gatewayStatus {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakePaymentDiscrepancyRepository) GetAll(ctx context.Context, req dto.GetAllPaymentDiscrepancyRequest) ([]entity.PaymentDiscrepancy, int64, error) {
+	result := make([]entity.PaymentDiscrepancy, 0, len(r.discrepancies))
+	for _, discrepancy := range r.discrepancies {
+		result = append(result, *discrepancy)
+	}
+	return result, int64(len(result)), nil
+}
+
+type fakeFundRepository struct {
+	funds map[int64]*entity.Fund
+}
+
+func (r *fakeFundRepository) Create(ctx context.Context, fund *entity.Fund) error {
+	fund.Id = int64(len(r.funds) + 1)
+	r.funds[fund.Id] = fund
+	return nil
+}
+
+func (r *fakeFundRepository) Update(ctx context.Context, fund *entity.Fund) error {
+	r.funds[fund.Id] = fund
+	return nil
+}
+
+func (r *fakeFundRepository) GetById(ctx context.Context, id int64) (*entity.Fund, error) {
+	fund, ok := r.funds[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	copied := *fund
+	return &copied, nil
+}
+
+func (r *fakeFundRepository) GetAll(ctx context.Context, req dto.GetAllFundRequest) ([]entity.Fund, int64, error) {
+	result := make([]entity.Fund, 0, len(r.funds))
+	for _, fund := range r.funds {
+		result = append(result, *fund)
+	}
+	return result, int64(len(result)), nil
+}
+
+// stubGateway mengembalikan status transaksi yang sudah disiapkan untuk setiap order
+type stubGateway struct {
+	statuses map[string]*payment.Notification
+}
+
+func (g *stubGateway) Name() string {
+	return "stub"
+}
+
+func (g *stubGateway) CreateCharge(ctx context.Context, charge payment.Charge) (*payment.ChargeResult, error) {
+	return &payment.ChargeResult{RedirectURL: "https://pay.example/" + charge.OrderId}, nil
+}
+
+func (g *stubGateway) ParseNotification(ctx context.Context, payload []byte) (*payment.Notification, error) {
+	return nil, payment.ErrInvalidPayload
+}
+
+func (g *stubGateway) QueryStatus(ctx context.Context, orderId string) (*payment.Notification, error) {
+	notification, ok := g.statuses[orderId]
+	if !ok {
+		return nil, payment.ErrOrderNotFound
+	}
+	copied := *notification
+	return &copied, nil
+}
+
+func (g *stubGateway) Refund(ctx context.Context, orderId string, amount int64, reason string) error {
+	return nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/eligibility"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/timezone"
+)
+
+type EligibilityService interface {
+	Rules() eligibility.Rules
+	Check(ctx context.Context, userId int64) (*eligibility.Result, error)
+	CheckUsers(ctx context.Context, users []entity.User) (map[int64]eligibility.Result, error)
+}
+
+type eligibilityService struct {
+	userRepository          repository.UserRepository
+	bloodDonationRepository repository.BloodDonationRepository
+	rules                   eligibility.Rules
+}
+
+func NewEligibilityService(
+	userRepository repository.UserRepository,
+	bloodDonationRepository repository.BloodDonationRepository,
+	cfg *configs.EligibilityConfig,
+) EligibilityService {
+	rules := eligibility.Rules{
+		MinAge:            cfg.MinAge,
+		MaxAge:            cfg.MaxAge,
+		IntervalDays:      cfg.IntervalDays,
+		YearlyLimitMale:   cfg.YearlyLimitMale,
+		YearlyLimitFemale: cfg.YearlyLimitFemale,
+	}
+	return &eligibilityService{userRepository, bloodDonationRepository, rules}
+}
+
+func (s *eligibilityService) Rules() eligibility.Rules {
+	return s.rules
+}
+
+// Check menilai kelayakan donor seorang pengguna berdasarkan usia, jenis kelamin, dan riwayat donor
+func (s *eligibilityService) Check(ctx context.Context, userId int64) (*eligibility.Result, error) {
+	user, err := s.userRepository.GetById(ctx, userId)
+	if err != nil {
+		return nil, errors.New("Pengguna tidak ditemukan")
+	}
+
+	results, err := s.CheckUsers(ctx, []entity.User{*user})
+	if err != nil {
+		return nil, err
+	}
+	result := results[user.Id]
+	return &result, nil
+}
+
+// CheckUsers menilai kelayakan donor beberapa pengguna sekaligus dengan satu query riwayat donor
+func (s *eligibilityService) CheckUsers(ctx context.Context, users []entity.User) (map[int64]eligibility.Result, error) {
+	now := time.Now().In(timezone.JakartaLocation)
+
+	userIds := make([]int64, 0, len(users))
+	for _, user := range users {
+		userIds = append(userIds, user.Id)
+	}
+
+	bloodDonations, err := s.bloodDonationRepository.GetCompletedByUserIds(ctx, userIds, s.rules.HistorySince(now))
+	if err != nil {
+		return nil, errors.New("Gagal mendapatkan riwayat donor")
+	}
+
+	donations := make(map[int64][]time.Time, len(users))
+	for _, bloodDonation := range bloodDonations {
+		donations[bloodDonation.UserId] = append(donations[bloodDonation.UserId], bloodDonation.DonationDate)
+	}
+
+	results := make(map[int64]eligibility.Result, len(users))
+	for _, user := range users {
+		results[user.Id] = s.rules.Evaluate(eligibility.Donor{
+			BirthDate: user.BirthDate,
+			Gender:    user.Gender,
+			Donations: donations[user.Id],
+		}, now)
+	}
+	return results, nil
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/compatibility"
+)
+
+// urgentLevels adalah tingkat urgensi yang memicu notifikasi darurat ke pendonor terdekat
+var urgentLevels = map[string]bool{
+	"high":     true,
+	"critical": true,
+	"urgent":   true,
+}
+
+type EmergencyAlertService interface {
+	IsUrgent(bloodRequest *entity.BloodRequest) bool
+	Dispatch(ctx context.Context, bloodRequest *entity.BloodRequest) (int, error)
+}
+
+type emergencyAlertService struct {
+	emergencyAlertRepository repository.EmergencyAlertRepository
+	eligibilityService       EligibilityService
+	outboxService            OutboxService
+	cfg                      *configs.EmergencyAlertConfig
+}
+
+func NewEmergencyAlertService(
+	emergencyAlertRepository repository.EmergencyAlertRepository,
+	eligibilityService EligibilityService,
+	outboxService OutboxService,
+	cfg *configs.EmergencyAlertConfig,
+) EmergencyAlertService {
+	return &emergencyAlertService{emergencyAlertRepository, eligibilityService, outboxService, cfg}
+}
+
+func (s *emergencyAlertService) IsUrgent(bloodRequest *entity.BloodRequest) bool {
+	return urgentLevels[bloodRequest.UrgencyLevel]
+}
+
+// Dispatch menyimpan alert serta mengantrekan notifikasi dan email ke outbox untuk setiap pendonor
+// yang memenuhi syarat. Panggil di dalam Transactor.WithinTransaction bersama perubahan status
+// permintaan darah agar alert yang tersimpan selalu punya pesan di outbox; dispatcher mengirimnya
+// setelah commit dan mengulang yang gagal. Mengembalikan jumlah pendonor yang dinotifikasi.
+func (s *emergencyAlertService) Dispatch(ctx context.Context, bloodRequest *entity.BloodRequest) (int, error) {
+	if !s.IsUrgent(bloodRequest) {
+		return 0, nil
+	}
+
+	bloodType, err := compatibility.Normalize(bloodRequest.BloodType)
+	if err != nil {
+		return 0, errors.New("Golongan darah tidak valid")
+	}
+
+	now := time.Now()
+	donors, err := s.emergencyAlertRepository.FindEligibleDonors(ctx, dto.EmergencyDonorFilter{
+		RequestId:          bloodRequest.Id,
+		ExcludeUserId:      bloodRequest.UserId,
+		BloodTypes:         compatibility.DonorsFor(bloodType, compatibility.RedCells),
+		Lat:                bloodRequest.Hospital.Latitude,
+		Lng:                bloodRequest.Hospital.Longitude,
+		RadiusKm:           s.cfg.RadiusKm,
+		LastDonationBefore: now.AddDate(0, 0, -s.eligibilityService.Rules().IntervalDays),
+		AlertedSince:       now.Add(-24 * time.Hour),
+		DailyLimit:         s.cfg.DailyLimitPerDonor,
+		Limit:              s.cfg.MaxRecipients,
+	})
+	if err != nil {
+		return 0, errors.New("Gagal mencari pendonor terdekat")
+	}
+
+	// Query hanya menyaring jeda donor, usia dan batas tahunan dinilai oleh aturan kelayakan
+	users := make([]entity.User, 0, len(donors))
+	for _, donor := range donors {
+		users = append(users, entity.User{Id: donor.Id, Gender: donor.Gender, BirthDate: donor.BirthDate})
+	}
+	results, err := s.eligibilityService.CheckUsers(ctx, users)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, donor := range donors {
+		if !results[donor.Id].Eligible {
+			continue
+		}
+
+		alert := &entity.EmergencyAlert{
+			RequestId:     bloodRequest.Id,
+			UserId:        donor.Id,
+			DistanceKm:    donor.DistanceKm,
+			EmailQueuedAt: &now,
+		}
+		inserted, err := s.emergencyAlertRepository.Create(ctx, alert)
+		if err != nil {
+			return sent, errors.New("Gagal menyimpan notifikasi darurat")
+		}
+		if !inserted {
+			// pendonor sudah dinotifikasi untuk permintaan ini oleh proses lain
+			continue
+		}
+
+		if err := s.outboxService.Notify(ctx, NotificationCreatePayload{
+			UserId:           donor.Id,
+			Title:            "Permintaan Darah Darurat",
+			Message:          fmt.Sprintf("Dibutuhkan darah %s segera di %s (%.1f km dari lokasi Anda).", bloodRequest.BloodType, bloodRequest.Hospital.Name, donor.DistanceKm),
+			NotificationType: "emergency",
+		}); err != nil {
+			return sent, err
+		}
+		if err := s.outboxService.SendEmail(ctx, EmailSendPayload{
+			To:       donor.Email,
+			Subject:  "Permintaan Darah Darurat - Darah Connect",
+			Template: "emergency-blood-request.html",
+			Data: map[string]interface{}{
+				"Name":         donor.Name,
+				"BloodType":    bloodRequest.BloodType,
+				"Quantity":     bloodRequest.Quantity,
+				"HospitalName": bloodRequest.Hospital.Name,
+				"Address":      bloodRequest.Hospital.Address,
+				"DistanceKm":   fmt.Sprintf("%.1f", donor.DistanceKm),
+			},
+		}); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+
+	return sent, nil
+}
@@ -0,0 +1,205 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/cloudinary"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/timezone"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEmergencyAlertService(t *testing.T, alerts *fakeEmergencyAlertRepository, outbox service.OutboxService) service.EmergencyAlertService {
+	require.NoError(t, timezone.InitTimezone())
+	eligibilityService := service.NewEligibilityService(nil, &fakeBloodDonationRepository{}, &configs.EligibilityConfig{
+		MinAge: 17, MaxAge: 65, IntervalDays: 60, YearlyLimitMale: 5, YearlyLimitFemale: 4,
+	})
+	return service.NewEmergencyAlertService(alerts, eligibilityService, outbox, &configs.EmergencyAlertConfig{
+		RadiusKm:           25,
+		MaxRecipients:      200,
+		DailyLimitPerDonor: 2,
+	})
+}
+
+func emergencyDonor(id int64, bloodType string) dto.EmergencyDonor {
+	return dto.EmergencyDonor{
+		Id:         id,
+		Name:       "Pendonor",
+		Email:      "pendonor@example.com",
+		Gender:     "male",
+		BirthDate:  time.Now().AddDate(-30, 0, 0),
+		BloodType:  bloodType,
+		DistanceKm: 2.5,
+	}
+}
+
+func urgentRequest(id int64, bloodType string) *entity.BloodRequest {
+	return &entity.BloodRequest{Id: id, UserId: 99, BloodType: bloodType, Quantity: 2, UrgencyLevel: "critical", Hospital: entity.Hospital{Name: "RSUP Dr. Kariadi"}}
+}
+
+func TestEmergencyAlertDispatchDedup(t *testing.T) {
+	ctx := context.Background()
+	alerts := &fakeEmergencyAlertRepository{donors: []dto.EmergencyDonor{emergencyDonor(1, "A+"), emergencyDonor(2, "O-")}}
+	outbox := &fakeOutboxService{}
+	emergencyAlertService := newEmergencyAlertService(t, alerts, outbox)
+
+	sent, err := emergencyAlertService.Dispatch(ctx, urgentRequest(10, "A+"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	require.Len(t, outbox.emails, 2)
+	assert.Equal(t, "emergency-blood-request.html", outbox.emails[0].Template)
+	assert.Equal(t, "RSUP Dr. Kariadi", outbox.emails[0].Data["HospitalName"])
+	assert.Equal(t, []string{service.OutboxNotificationCreate, service.OutboxEmailSend, service.OutboxNotificationCreate, service.OutboxEmailSend}, outbox.events)
+	for _, alert := range alerts.alerts {
+		assert.NotNil(t, alert.EmailQueuedAt)
+	}
+
+	// Verifikasi ulang permintaan yang sama tidak menotifikasi pendonor dua kali
+	sent, err = emergencyAlertService.Dispatch(ctx, urgentRequest(10, "A+"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	// Proses lain yang membaca kandidat sebelum alert tersimpan ditahan unique constraint
+	alerts.ignoreRequestFilter = true
+	sent, err = emergencyAlertService.Dispatch(ctx, urgentRequest(10, "A+"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Len(t, outbox.events, 4)
+	assert.Len(t, alerts.alerts, 2)
+}
+
+func TestEmergencyAlertDispatchDailyCap(t *testing.T) {
+	ctx := context.Background()
+	alerts := &fakeEmergencyAlertRepository{donors: []dto.EmergencyDonor{emergencyDonor(1, "A+"), emergencyDonor(2, "B+")}}
+	outbox := &fakeOutboxService{}
+	emergencyAlertService := newEmergencyAlertService(t, alerts, outbox)
+
+	for _, requestId := range []int64{10, 11} {
+		sent, err := emergencyAlertService.Dispatch(ctx, urgentRequest(requestId, "A+"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+	}
+	require.NotEmpty(t, alerts.filters)
+	filter := alerts.filters[len(alerts.filters)-1]
+	assert.Equal(t, 2, filter.DailyLimit)
+	assert.WithinDuration(t, time.Now().Add(-24*time.Hour), filter.AlertedSince, time.Minute)
+
+	// Pendonor 1 sudah mencapai batas harian, pendonor lain tetap dinotifikasi
+	sent, err := emergencyAlertService.Dispatch(ctx, urgentRequest(12, "AB+"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, []int64{1, 1, 2}, alerts.userIds())
+
+	// Batas dihitung per 24 jam terakhir, notifikasi lama tidak lagi dihitung
+	for i := range alerts.alerts {
+		alerts.alerts[i].CreatedAt = time.Now().Add(-25 * time.Hour)
+	}
+	sent, err = emergencyAlertService.Dispatch(ctx, urgentRequest(13, "A+"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, []int64{1, 1, 2, 1}, alerts.userIds())
+	assert.Len(t, outbox.emails, 4)
+}
+
+func TestEmergencyAlertDispatchOutboxFailure(t *testing.T) {
+	ctx := context.Background()
+	alerts := &fakeEmergencyAlertRepository{donors: []dto.EmergencyDonor{emergencyDonor(1, "A+"), emergencyDonor(2, "A+")}}
+	emergencyAlertService := newEmergencyAlertService(t, alerts, &failingOutboxService{})
+
+	// Kegagalan outbox dikembalikan agar transaksi verifikasi ikut dibatalkan beserta alert-nya
+	sent, err := emergencyAlertService.Dispatch(ctx, urgentRequest(10, "A+"))
+	assert.Error(t, err)
+	assert.Equal(t, 0, sent)
+}
+
+func TestBloodRequestVerifyQueuesEmergencyAlerts(t *testing.T) {
+	ctx := context.Background()
+	alerts := &fakeEmergencyAlertRepository{donors: []dto.EmergencyDonor{emergencyDonor(1, "A+")}}
+	outbox := &fakeOutboxService{}
+	requestRepo := &fakeBloodRequestRepository{}
+	auditLogService := service.NewAuditLogService(&fakeAuditLogRepository{}, passthroughTransactor{})
+	bloodRequestService := service.NewBloodRequestService(requestRepo, nil, cloudinary.Service{}, nil, newEmergencyAlertService(t, alerts, outbox), passthroughTransactor{}, auditLogService)
+
+	bloodRequest := urgentRequest(10, "A+")
+	bloodRequest.Status = "pending"
+	bloodRequest.EventType = "blood_request"
+	require.NoError(t, bloodRequestService.UpdateStatus(ctx, dto.BloodRequestUpdateRequest{Status: "verified"}, bloodRequest))
+	require.Len(t, requestRepo.updated, 1)
+	assert.Len(t, alerts.alerts, 1)
+	assert.Len(t, outbox.emails, 1)
+
+	// Perubahan status lain tidak mengirim alert lagi
+	require.NoError(t, bloodRequestService.UpdateStatus(ctx, dto.BloodRequestUpdateRequest{Status: "verified"}, bloodRequest))
+	assert.Len(t, alerts.alerts, 1)
+	assert.Len(t, outbox.emails, 1)
+}
+
+// fakeEmergencyAlertRepository meniru penyaringan FindEligibleDonors terhadap alert yang tersimpan
+type fakeEmergencyAlertRepository struct {
+	donors              []dto.EmergencyDonor
+	alerts              []entity.EmergencyAlert
+	filters             []dto.EmergencyDonorFilter
+	ignoreRequestFilter bool
+}
+
+func (r *fakeEmergencyAlertRepository) FindEligibleDonors(ctx context.Context, filter dto.EmergencyDonorFilter) ([]dto.EmergencyDonor, error) {
+	r.filters = append(r.filters, filter)
+	result := make([]dto.EmergencyDonor, 0)
+	for _, donor := range r.donors {
+		if donor.Id == filter.ExcludeUserId || !containsBloodType(filter.BloodTypes, donor.BloodType) {
+			continue
+		}
+		alerted, recent := false, 0
+		for _, alert := range r.alerts {
+			if alert.UserId != donor.Id {
+				continue
+			}
+			if alert.RequestId == filter.RequestId {
+				alerted = true
+			}
+			if alert.CreatedAt.After(filter.AlertedSince) {
+				recent++
+			}
+		}
+		if (alerted && !r.ignoreRequestFilter) || recent >= filter.DailyLimit {
+			continue
+		}
+		result = append(result, donor)
+	}
+	return result, nil
+}
+
+func (r *fakeEmergencyAlertRepository) Create(ctx context.Context, alert *entity.EmergencyAlert) (bool, error) {
+	for _, existing := range r.alerts {
+		if existing.RequestId == alert.RequestId && existing.UserId == alert.UserId {
+			return false, nil
+		}
+	}
+	alert.Id = int64(len(r.alerts) + 1)
+	alert.CreatedAt = time.Now()
+	r.alerts = append(r.alerts, *alert)
+	return true, nil
+}
+
+func (r *fakeEmergencyAlertRepository) userIds() []int64 {
+	result := make([]int64, 0, len(r.alerts))
+	for _, alert := range r.alerts {
+		result = append(result, alert.UserId)
+	}
+	return result
+}
+
+type failingOutboxService struct {
+	fakeOutboxService
+}
+
+func (s *failingOutboxService) Notify(ctx context.Context, payload service.NotificationCreatePayload) error {
+	return errors.New("outbox tidak tersedia")
+}
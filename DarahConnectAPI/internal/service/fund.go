@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/audit"
+)
+
+// Status dana umum
+const (
+	FundActive = "active"
+	FundClosed = "closed"
+)
+
+var ErrFundNotFound = errors.New("dana tidak ditemukan")
+
+type FundService interface {
+	Create(ctx context.Context, req dto.FundCreateRequest) (*entity.Fund, error)
+	Update(ctx context.Context, req dto.FundUpdateRequest) (*entity.Fund, error)
+	// GetById dan GetAll menyertakan perolehan donasi setiap dana
+	GetById(ctx context.Context, id int64) (*entity.Fund, error)
+	GetAll(ctx context.Context, req dto.GetAllFundRequest) ([]entity.Fund, int64, error)
+}
+
+type fundService struct {
+	fundRepository      repository.FundRepository
+	donationsRepository repository.DonationsRepository
+	transactor          repository.Transactor
+	auditLogService     AuditLogService
+}
+
+func NewFundService(fundRepository repository.FundRepository, donationsRepository repository.DonationsRepository, transactor repository.Transactor, auditLogService AuditLogService) FundService {
+	return &fundService{fundRepository, donationsRepository, transactor, auditLogService}
+}
+
+func (s *fundService) Create(ctx context.Context, req dto.FundCreateRequest) (*entity.Fund, error) {
+	if req.Deadline != nil && !req.Deadline.After(time.Now()) {
+		return nil, errors.New("batas waktu penggalangan dana harus di masa depan")
+	}
+
+	fund := &entity.Fund{
+		Title:       req.Title,
+		Description: req.Description,
+		GoalAmount:  req.GoalAmount,
+		Deadline:    req.Deadline,
+		Status:      FundActive,
+		CreatedBy:   req.CreatedBy,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.fundRepository.Create(ctx, fund); err != nil {
+			return errors.New("gagal membuat dana")
+		}
+		return s.auditLogService.Record(ctx, audit.Entry{Action: "fund.create", EntityType: "fund", EntityId: fund.Id, After: fund})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fund, nil
+}
+
+func (s *fundService) Update(ctx context.Context, req dto.FundUpdateRequest) (*entity.Fund, error) {
+	fund, err := s.fundRepository.GetById(ctx, req.Id)
+	if err != nil {
+		return nil, ErrFundNotFound
+	}
+	before := *fund
+
+	if req.Title != "" {
+		fund.Title = req.Title
+	}
+	if req.Description != "" {
+		fund.Description = req.Description
+	}
+	if req.GoalAmount != 0 {
+		fund.GoalAmount = req.GoalAmount
+	}
+	if req.Deadline != nil {
+		fund.Deadline = req.Deadline
+	}
+	if req.Status != "" {
+		fund.Status = req.Status
+	}
+	fund.UpdatedAt = time.Now()
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.fundRepository.Update(ctx, fund); err != nil {
+			return errors.New("gagal memperbarui dana")
+		}
+		return s.auditLogService.Record(ctx, audit.Entry{Action: "fund.update", EntityType: "fund", EntityId: fund.Id, Before: before, After: fund})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fund, nil
+}
+
+func (s *fundService) GetById(ctx context.Context, id int64) (*entity.Fund, error) {
+	fund, err := s.fundRepository.GetById(ctx, id)
+	if err != nil {
+		return nil, ErrFundNotFound
+	}
+	if err := s.attachProgress(ctx, fund); err != nil {
+		return nil, err
+	}
+	return fund, nil
+}
+
+func (s *fundService) GetAll(ctx context.Context, req dto.GetAllFundRequest) ([]entity.Fund, int64, error) {
+	funds, total, err := s.fundRepository.GetAll(ctx, req)
+	if err != nil {
+		return nil, 0, errors.New("gagal mendapatkan data dana")
+	}
+	for i := range funds {
+		if err := s.attachProgress(ctx, &funds[i]); err != nil {
+			return nil, 0, err
+		}
+	}
+	return funds, total, nil
+}
+
+func (s *fundService) attachProgress(ctx context.Context, fund *entity.Fund) error {
+	progress, err := s.donationsRepository.GetFundProgress(ctx, fund.Id)
+	if err != nil {
+		return errors.New("gagal menghitung perolehan donasi")
+	}
+	fund.Fundraising = fundraisingProgress(progress, fund.GoalAmount, fund.Deadline, fund.Status == FundActive, time.Now())
+	return nil
+}
+
+// fundraisingProgress melengkapi perolehan donasi dengan target dan batas waktunya
+func fundraisingProgress(progress *entity.FundraisingProgress, goalAmount int64, deadline *time.Time, active bool, now time.Time) *entity.FundraisingProgress {
+	progress.GoalAmount = goalAmount
+	progress.Deadline = deadline
+	progress.Open = fundraisingOpen(goalAmount, deadline, active, now)
+	if goalAmount > 0 {
+		progress.Percentage = math.Round(float64(progress.CollectedAmount)/float64(goalAmount)*10000) / 100
+	}
+	return progress
+}
+
+// fundraisingOpen melaporkan apakah target masih menerima donasi. Target yang sudah tercapai
+// tetap menerima donasi sampai batas waktunya.
+func fundraisingOpen(goalAmount int64, deadline *time.Time, active bool, now time.Time) bool {
+	if goalAmount <= 0 || !active {
+		return false
+	}
+	return deadline == nil || now.Before(*deadline)
+}
@@ -0,0 +1,166 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/cloudinary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Pencarian berdasarkan jarak dihitung oleh database, jadi service diuji bersama repository
+// aslinya untuk memastikan filter radius, urutan terdekat, dan kolom distance_km.
+
+const distancePattern = `\(2 \* 6371\.0 \* ASIN\(SQRT\(.+\)\)\)`
+
+func newGeoSearchDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	return gormDB, mock
+}
+
+func TestHospitalSearchByDistance(t *testing.T) {
+	ctx := context.Background()
+	lat, lng := -6.9932, 110.4203 // Semarang
+
+	t.Run("nearest first within radius", func(t *testing.T) {
+		db, mock := newGeoSearchDB(t)
+		hospitalService := service.NewHospitalService(repository.NewHospitalRepository(db))
+
+		mock.ExpectQuery(`SELECT count\(\*\) FROM "public"\."hospitals" WHERE `+distancePattern+` <= \$4$`).
+			WithArgs(lat, lat, lng, 25.0).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+		mock.ExpectQuery(`SELECT hospitals\.\*, `+distancePattern+` AS distance FROM "public"\."hospitals" WHERE `+distancePattern+` <= \$7 ORDER BY `+distancePattern+` ASC LIMIT \$11$`).
+			WithArgs(lat, lat, lng, lat, lat, lng, 25.0, lat, lat, lng, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "distance"}).
+				AddRow(1, "RSUP Dr. Kariadi", 1.8).
+				AddRow(2, "RS Telogorejo", 4.25))
+
+		hospitals, total, err := hospitalService.GetAll(ctx, dto.GetAllHospitalRequest{Lat: &lat, Lng: &lng, RadiusKm: 25})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		require.Len(t, hospitals, 2)
+		require.NotNil(t, hospitals[0].Distance)
+		assert.Equal(t, 1.8, *hospitals[0].Distance)
+		assert.Equal(t, 4.25, *hospitals[1].Distance)
+	})
+
+	t.Run("location without radius only sorts", func(t *testing.T) {
+		db, mock := newGeoSearchDB(t)
+		hospitalService := service.NewHospitalService(repository.NewHospitalRepository(db))
+
+		mock.ExpectQuery(`SELECT count\(\*\) FROM "public"\."hospitals"$`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(`SELECT hospitals\.\*, `+distancePattern+` AS distance FROM "public"\."hospitals" ORDER BY `+distancePattern+` ASC LIMIT \$7$`).
+			WithArgs(lat, lat, lng, lat, lat, lng, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "distance"}).AddRow(1, 120.5))
+
+		hospitals, _, err := hospitalService.GetAll(ctx, dto.GetAllHospitalRequest{Lat: &lat, Lng: &lng})
+		require.NoError(t, err)
+		require.Len(t, hospitals, 1)
+		assert.Equal(t, 120.5, *hospitals[0].Distance)
+	})
+
+	t.Run("explicit sort wins over distance", func(t *testing.T) {
+		db, mock := newGeoSearchDB(t)
+		hospitalService := service.NewHospitalService(repository.NewHospitalRepository(db))
+
+		mock.ExpectQuery(`SELECT count\(\*\) FROM "public"\."hospitals" WHERE `+distancePattern+` <= \$4$`).
+			WithArgs(lat, lat, lng, 5.0).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(`SELECT hospitals\.\*, `+distancePattern+` AS distance FROM "public"\."hospitals" WHERE `+distancePattern+` <= \$7 ORDER BY name asc LIMIT \$8$`).
+			WithArgs(lat, lat, lng, lat, lat, lng, 5.0, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "distance"}).AddRow(1, 3.0))
+
+		_, _, err := hospitalService.GetAll(ctx, dto.GetAllHospitalRequest{Lat: &lat, Lng: &lng, RadiusKm: 5, Sort: "name", Order: "asc"})
+		require.NoError(t, err)
+	})
+
+	t.Run("without location", func(t *testing.T) {
+		db, mock := newGeoSearchDB(t)
+		hospitalService := service.NewHospitalService(repository.NewHospitalRepository(db))
+
+		mock.ExpectQuery(`SELECT count\(\*\) FROM "public"\."hospitals"$`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(`SELECT \* FROM "public"\."hospitals" ORDER BY created_at desc LIMIT \$1$`).
+			WithArgs(10).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		hospitals, _, err := hospitalService.GetAll(ctx, dto.GetAllHospitalRequest{})
+		require.NoError(t, err)
+		require.Len(t, hospitals, 1)
+		assert.Nil(t, hospitals[0].Distance)
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		db, mock := newGeoSearchDB(t)
+		hospitalService := service.NewHospitalService(repository.NewHospitalRepository(db))
+
+		mock.ExpectQuery(`SELECT count\(\*\) FROM "public"\."hospitals"`).WillReturnError(errors.New("connection reset"))
+
+		_, _, err := hospitalService.GetAll(ctx, dto.GetAllHospitalRequest{Lat: &lat, Lng: &lng, RadiusKm: 25})
+		assert.Error(t, err)
+	})
+}
+
+func TestBloodRequestSearchByDistance(t *testing.T) {
+	ctx := context.Background()
+	lat, lng := -6.9932, 110.4203
+	hospitalDistance := `\(SELECT ` + distancePattern + ` FROM hospitals AS geo_hospitals WHERE geo_hospitals\.id = blood_requests\.hospital_id\)`
+
+	t.Run("compatible requests at nearby hospitals", func(t *testing.T) {
+		db, mock := newGeoSearchDB(t)
+		bloodRequestService := service.NewBloodRequestService(repository.NewBloodRequestRepository(db), nil, cloudinary.Service{}, nil, nil, passthroughTransactor{}, nil)
+
+		// Pendonor AB+ hanya bisa membantu penerima AB+
+		mock.ExpectQuery(`SELECT count\(\*\) FROM "public"\."blood_requests" WHERE \(event_type = \$1 AND status = \$2\) AND UPPER\(blood_requests\.blood_type\) IN \(\$3\) AND `+hospitalDistance+` <= \$7$`).
+			WithArgs("blood_request", "verified", "AB+", lat, lat, lng, 10.0).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(`SELECT blood_requests\.\*, `+hospitalDistance+` AS distance FROM "public"\."blood_requests" WHERE .+ <= \$10 ORDER BY `+hospitalDistance+` ASC LIMIT \$14$`).
+			WithArgs(lat, lat, lng, "blood_request", "verified", "AB+", lat, lat, lng, 10.0, lat, lat, lng, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "hospital_id", "blood_type", "distance"}).AddRow(4, 5, 2, "AB+", 2.75))
+		mock.ExpectQuery(`SELECT \* FROM "public"\."hospitals" WHERE "hospitals"\."id" = \$1`).
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "RSUP Dr. Kariadi"))
+		mock.ExpectQuery(`SELECT \* FROM "public"\."users" WHERE "users"\."id" = \$1`).
+			WithArgs(5).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+
+		bloodRequests, total, err := bloodRequestService.GetAllBloodRequest(ctx, dto.GetAllBloodRequestRequest{BloodType: "ab+", Lat: &lat, Lng: &lng, RadiusKm: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, bloodRequests, 1)
+		require.NotNil(t, bloodRequests[0].Distance)
+		assert.Equal(t, 2.75, *bloodRequests[0].Distance)
+		assert.Equal(t, "RSUP Dr. Kariadi", bloodRequests[0].Hospital.Name)
+		assert.Nil(t, bloodRequests[0].Hospital.Distance)
+	})
+
+	t.Run("campaigns nearest first", func(t *testing.T) {
+		db, mock := newGeoSearchDB(t)
+		bloodRequestService := service.NewBloodRequestService(repository.NewBloodRequestRepository(db), nil, cloudinary.Service{}, nil, nil, passthroughTransactor{}, nil)
+
+		mock.ExpectQuery(`SELECT count\(\*\) FROM "public"\."blood_requests" WHERE .+$`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(`SELECT blood_requests\.\*, ` + hospitalDistance + ` AS distance FROM "public"\."blood_requests" WHERE .+ ORDER BY ` + hospitalDistance + ` ASC LIMIT \$\d+$`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		bloodRequests, _, err := bloodRequestService.GetAllCampaign(ctx, dto.GetAllBloodRequestRequest{Lat: &lat, Lng: &lng})
+		require.NoError(t, err)
+		assert.Empty(t, bloodRequests)
+	})
+}
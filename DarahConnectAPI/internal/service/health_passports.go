@@ -3,66 +3,101 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/audit"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/screening"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/timezone"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/utils"
 )
 
+// masa berlaku health passport setelah lolos skrining
+const healthPassportValidity = 24 * time.Hour
+
+var (
+	ErrHealthPassportUnderReview = errors.New("Hasil skrining sebelumnya masih ditinjau petugas")
+	ErrHealthPassportDeferred    = errors.New("Masa penundaan donor belum berakhir")
+)
+
 type HealthPassportService interface {
-	Create(ctx context.Context, userId int64) error
+	GetQuestionnaire() screening.Questionnaire
+	Create(ctx context.Context, req dto.HealthScreeningRequest) (*entity.HealthPassport, error)
 	GetById(ctx context.Context, id int64) (*entity.HealthPassport, error)
 	GetAll(ctx context.Context, req dto.GetAllHealthPassportRequest) ([]entity.HealthPassport, int64, error)
 	GetByUserId(ctx context.Context, userId int64) (*entity.HealthPassport, error)
-	UpdateByUser(ctx context.Context, healthPassport *entity.HealthPassport) error
+	UpdateByUser(ctx context.Context, req dto.HealthScreeningRequest, healthPassport *entity.HealthPassport) error
 	Update(ctx context.Context, req dto.HealthPassportUpdateRequest, healthPassport *entity.HealthPassport) error
 	Delete(ctx context.Context, id int64) error
 }
 
 type healthPassportService struct {
-	healthPassportRepository repository.HealthPassportRepository
+	healthPassportRepository  repository.HealthPassportRepository
+	healthScreeningRepository repository.HealthScreeningRepository
+	transactor                repository.Transactor
+	auditLogService           AuditLogService
+}
+
+func NewHealthPassportService(
+	healthPassportRepository repository.HealthPassportRepository,
+	healthScreeningRepository repository.HealthScreeningRepository,
+	transactor repository.Transactor,
+	auditLogService AuditLogService,
+) HealthPassportService {
+	return &healthPassportService{healthPassportRepository, healthScreeningRepository, transactor, auditLogService}
 }
 
-func NewHealthPassportService(healthPassportRepository repository.HealthPassportRepository) HealthPassportService {
-	return &healthPassportService{healthPassportRepository}
+func (s *healthPassportService) GetQuestionnaire() screening.Questionnaire {
+	return screening.Current()
 }
 
-func (s *healthPassportService) Create(ctx context.Context, userId int64) error {
+func (s *healthPassportService) Create(ctx context.Context, req dto.HealthScreeningRequest) (*entity.HealthPassport, error) {
+	if req.QuestionnaireVersion != screening.CurrentVersion {
+		return nil, errors.New("Versi kuesioner sudah tidak berlaku, silahkan muat ulang kuesioner")
+	}
+
+	now := time.Now().In(timezone.JakartaLocation)
+	result := screening.Evaluate(screeningAnswers(req), now)
 
 	healthPassport := new(entity.HealthPassport)
-	healthPassport.UserId = userId
-	healthPassport.ExpiryDate = time.Now().In(timezone.JakartaLocation).Add(24 * time.Hour)
-	healthPassport.Status = "active"
+	healthPassport.UserId = req.UserId
+	applyScreeningResult(healthPassport, result, now)
 
-	// Generate nomor paspor yang unik
+	// Generate nomor paspor yang unik. Paspor dan skrining pertamanya disimpan dalam satu
+	// transaksi; nomor yang bentrok diulang dengan transaksi baru
 	var err error
 	for i := 0; i < 5; i++ { // Maksimal 5 kali percobaan
 		healthPassport.PassportNumber = utils.GenerateRandomPassportNumber()
-		// Coba membuat health passport
-		err = s.healthPassportRepository.Create(ctx, healthPassport)
+		var createErr error
+		err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			if createErr = s.healthPassportRepository.Create(ctx, healthPassport); createErr != nil {
+				return createErr
+			}
+			return s.createScreening(ctx, req, healthPassport, result)
+		})
 		if err == nil {
 			break // Berhasil membuat health passport dengan nomor unik
 		}
+		// Skrining gagal disimpan setelah paspor dibuat
+		if createErr == nil {
+			return nil, err
+		}
 		// Jika error bukan karena duplikasi, return error
-		if !strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
-			return errors.New("Riwayat kesehatan gagal dibuat")
+		if !strings.Contains(createErr.Error(), "duplicate key value violates unique constraint") {
+			return nil, errors.New("Riwayat kesehatan gagal dibuat")
 		}
 		// Jika error karena duplikasi, lanjut ke iterasi berikutnya untuk generate nomor baru
 	}
 
 	// Jika setelah 5 kali percobaan masih gagal
 	if err != nil {
-		return errors.New("Gagal membuat nomor paspor unik setelah beberapa percobaan")
+		return nil, errors.New("Gagal membuat nomor paspor unik setelah beberapa percobaan")
 	}
-	// if err := s.healthPassportRepository.Create(ctx, healthPassport); err != nil {
-	// 	return errors.New("Gagal membuat riwayat kesehatan" + err.Error())
-	// }
-
-	return nil
+	return healthPassport, nil
 }
 
 func (s *healthPassportService) GetAll(ctx context.Context, req dto.GetAllHealthPassportRequest) ([]entity.HealthPassport, int64, error) {
@@ -90,36 +125,148 @@ func (s *healthPassportService) GetByUserId(ctx context.Context, userId int64) (
 	return healthPassports, nil
 }
 
+// Update dipakai admin untuk mengubah status health passport, termasuk memutuskan hasil
+// skrining yang menunggu peninjauan.
 func (s *healthPassportService) Update(ctx context.Context, req dto.HealthPassportUpdateRequest, healthPassport *entity.HealthPassport) error {
-	healthPassport.ExpiryDate = time.Now()
-	if req.Status != "" {
+	before := *healthPassport
+	now := time.Now().In(timezone.JakartaLocation)
+	switch req.Status {
+	case "active":
+		healthPassport.Status = "active"
+		healthPassport.ExpiryDate = now.Add(healthPassportValidity)
+		healthPassport.DeferredUntil = nil
+	case "deferred":
+		if req.DeferredUntil == nil || !req.DeferredUntil.After(now) {
+			return errors.New("Tanggal penundaan harus setelah hari ini")
+		}
+		healthPassport.Status = "deferred"
+		healthPassport.ExpiryDate = now
+		healthPassport.DeferredUntil = req.DeferredUntil
+	default:
+		healthPassport.ExpiryDate = now
 		healthPassport.Status = req.Status
 	}
 
-	if err := s.healthPassportRepository.Update(ctx, healthPassport); err != nil {
-		return errors.New("Riwayat kesehatan gagal diperbarui")
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.healthPassportRepository.Update(ctx, healthPassport); err != nil {
+			return errors.New("Riwayat kesehatan gagal diperbarui")
+		}
+		if err := s.auditLogService.Record(ctx, audit.Entry{Action: "health_passport.status", EntityType: "health_passport", EntityId: healthPassport.Id, Before: before, After: healthPassport}); err != nil {
+			return err
+		}
+
+		// Catat keputusan admin pada skrining terakhir yang menunggu peninjauan
+		healthScreening, err := s.healthScreeningRepository.GetLatestByPassportId(ctx, healthPassport.Id)
+		if err != nil || healthScreening.Outcome != string(screening.OutcomeReview) || healthScreening.ReviewedAt != nil {
+			return nil
+		}
+		healthScreening.ReviewedBy = &req.ReviewerId
+		healthScreening.ReviewedAt = &now
+		healthScreening.ReviewDecision = healthPassport.Status
+		healthScreening.ReviewNote = req.Note
+		if err := s.healthScreeningRepository.Update(ctx, healthScreening); err != nil {
+			return errors.New("Gagal menyimpan hasil peninjauan skrining")
+		}
+		return nil
+	})
+}
+
+// UpdateByUser memperbarui health passport dengan hasil pengisian ulang kuesioner skrining
+func (s *healthPassportService) UpdateByUser(ctx context.Context, req dto.HealthScreeningRequest, healthPassport *entity.HealthPassport) error {
+	if req.QuestionnaireVersion != screening.CurrentVersion {
+		return errors.New("Versi kuesioner sudah tidak berlaku, silahkan muat ulang kuesioner")
 	}
-	return nil
+
+	// Pengisian ulang tidak boleh melewati peninjauan petugas atau memperpendek penundaan yang
+	// masih berjalan; keduanya hanya bisa diubah admin lewat Update
+	now := time.Now().In(timezone.JakartaLocation)
+	if healthPassport.Status == "pending_review" {
+		return ErrHealthPassportUnderReview
+	}
+	if healthPassport.DeferredUntil != nil && healthPassport.DeferredUntil.After(now) {
+		return fmt.Errorf("%w sampai %s", ErrHealthPassportDeferred, healthPassport.DeferredUntil.In(timezone.JakartaLocation).Format("2006-01-02"))
+	}
+
+	result := screening.Evaluate(screeningAnswers(req), now)
+	applyScreeningResult(healthPassport, result, now)
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.healthPassportRepository.Update(ctx, healthPassport); err != nil {
+			return errors.New("Riwayat kesehatan gagal diperbarui")
+		}
+		return s.createScreening(ctx, req, healthPassport, result)
+	})
 }
 
-func (s *healthPassportService) UpdateByUser(ctx context.Context, healthPassport *entity.HealthPassport) error {
-	healthPassport.ExpiryDate = time.Now().In(timezone.JakartaLocation).Add(24 * time.Hour)
-	healthPassport.Status = "active"
+func (s *healthPassportService) createScreening(ctx context.Context, req dto.HealthScreeningRequest, healthPassport *entity.HealthPassport, result screening.Result) error {
+	reasons := make([]entity.ScreeningReason, 0, len(result.Reasons))
+	for _, reason := range result.Reasons {
+		reasons = append(reasons, entity.ScreeningReason{Code: reason.Code, Message: reason.Message, Until: reason.Until})
+	}
 
-	if err := s.healthPassportRepository.Update(ctx, healthPassport); err != nil {
-		return errors.New("Riwayat kesehatan gagal diperbarui")
+	healthScreening := &entity.HealthScreening{
+		HealthPassportId:        healthPassport.Id,
+		UserId:                  healthPassport.UserId,
+		QuestionnaireVersion:    req.QuestionnaireVersion,
+		RecentIllness:           req.RecentIllness,
+		TakingMedication:        req.TakingMedication,
+		MedicationNotes:         req.MedicationNotes,
+		MalariaTravelReturnDate: req.MalariaTravelReturnDate,
+		TattooDate:              req.TattooDate,
+		Hemoglobin:              req.Hemoglobin,
+		WeightKg:                req.WeightKg,
+		Systolic:                req.Systolic,
+		Diastolic:               req.Diastolic,
+		Outcome:                 string(result.Outcome),
+		DeferredUntil:           result.DeferredUntil,
+		Reasons:                 reasons,
+	}
+	if err := s.healthScreeningRepository.Create(ctx, healthScreening); err != nil {
+		return errors.New("Gagal menyimpan hasil skrining kesehatan")
 	}
+	healthPassport.Screenings = []entity.HealthScreening{*healthScreening}
 	return nil
 }
 
+func screeningAnswers(req dto.HealthScreeningRequest) screening.Answers {
+	return screening.Answers{
+		RecentIllness:           req.RecentIllness,
+		TakingMedication:        req.TakingMedication,
+		MalariaTravelReturnDate: req.MalariaTravelReturnDate,
+		TattooDate:              req.TattooDate,
+		Hemoglobin:              req.Hemoglobin,
+		WeightKg:                req.WeightKg,
+		Systolic:                req.Systolic,
+		Diastolic:               req.Diastolic,
+	}
+}
+
+// applyScreeningResult menurunkan status health passport dari hasil skrining
+func applyScreeningResult(healthPassport *entity.HealthPassport, result screening.Result, now time.Time) {
+	switch result.Outcome {
+	case screening.OutcomeActive:
+		healthPassport.Status = "active"
+		healthPassport.ExpiryDate = now.Add(healthPassportValidity)
+	case screening.OutcomeDeferred:
+		healthPassport.Status = "deferred"
+		healthPassport.ExpiryDate = now
+	case screening.OutcomeReview:
+		healthPassport.Status = "pending_review"
+		healthPassport.ExpiryDate = now
+	}
+	healthPassport.DeferredUntil = result.DeferredUntil
+}
+
 func (s *healthPassportService) Delete(ctx context.Context, id int64) error {
 	healthPassport, err := s.healthPassportRepository.GetById(ctx, id)
 	if err != nil {
 		return errors.New("Riwayat kesehatan tidak ditemukan")
 	}
 
-	if err := s.healthPassportRepository.Delete(ctx, healthPassport); err != nil {
-		return errors.New("Riwayat kesehatan gagal dihapus")
-	}
-	return nil
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.healthPassportRepository.Delete(ctx, healthPassport); err != nil {
+			return errors.New("Riwayat kesehatan gagal dihapus")
+		}
+		return s.auditLogService.Record(ctx, audit.Entry{Action: "health_passport.delete", EntityType: "health_passport", EntityId: healthPassport.Id, Before: healthPassport})
+	})
 }
@@ -0,0 +1,119 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/screening"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/timezone"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func healthyScreeningRequest() dto.HealthScreeningRequest {
+	return dto.HealthScreeningRequest{
+		UserId:               7,
+		QuestionnaireVersion: screening.CurrentVersion,
+		Hemoglobin:           14,
+		WeightKg:             60,
+		Systolic:             120,
+		Diastolic:            80,
+	}
+}
+
+func TestHealthPassportUpdateByUserRefusedWhileUnderReview(t *testing.T) {
+	require.NoError(t, timezone.InitTimezone())
+	passports := &fakeHealthPassportRepository{}
+	screenings := &fakeHealthScreeningRepository{}
+	healthPassportService := service.NewHealthPassportService(passports, screenings, passthroughTransactor{}, nil)
+
+	healthPassport := &entity.HealthPassport{Id: 1, UserId: 7, Status: "pending_review"}
+	err := healthPassportService.UpdateByUser(context.Background(), healthyScreeningRequest(), healthPassport)
+	assert.ErrorIs(t, err, service.ErrHealthPassportUnderReview)
+	assert.Equal(t, "pending_review", healthPassport.Status)
+	assert.Zero(t, passports.updates)
+	assert.Empty(t, screenings.screenings)
+}
+
+func TestHealthPassportUpdateByUserKeepsRunningDeferral(t *testing.T) {
+	require.NoError(t, timezone.InitTimezone())
+	passports := &fakeHealthPassportRepository{}
+	screenings := &fakeHealthScreeningRepository{}
+	healthPassportService := service.NewHealthPassportService(passports, screenings, passthroughTransactor{}, nil)
+
+	deferredUntil := time.Now().AddDate(0, 3, 0)
+	healthPassport := &entity.HealthPassport{Id: 1, UserId: 7, Status: "deferred", DeferredUntil: &deferredUntil}
+	err := healthPassportService.UpdateByUser(context.Background(), healthyScreeningRequest(), healthPassport)
+	assert.ErrorIs(t, err, service.ErrHealthPassportDeferred)
+	assert.Equal(t, "deferred", healthPassport.Status)
+	require.NotNil(t, healthPassport.DeferredUntil)
+	assert.True(t, healthPassport.DeferredUntil.Equal(deferredUntil))
+	assert.Zero(t, passports.updates)
+	assert.Empty(t, screenings.screenings)
+
+	// Setelah penundaan berakhir pendonor boleh mengisi ulang kuesioner
+	ended := time.Now().AddDate(0, 0, -1)
+	healthPassport.DeferredUntil = &ended
+	require.NoError(t, healthPassportService.UpdateByUser(context.Background(), healthyScreeningRequest(), healthPassport))
+	assert.Equal(t, "active", healthPassport.Status)
+	assert.Nil(t, healthPassport.DeferredUntil)
+	assert.Equal(t, 1, passports.updates)
+	assert.Len(t, screenings.screenings, 1)
+}
+
+type fakeHealthPassportRepository struct {
+	passports []entity.HealthPassport
+	updates   int
+}
+
+func (r *fakeHealthPassportRepository) Create(ctx context.Context, healthPassport *entity.HealthPassport) error {
+	healthPassport.Id = int64(len(r.passports) + 1)
+	r.passports = append(r.passports, *healthPassport)
+	return nil
+}
+
+func (r *fakeHealthPassportRepository) GetById(ctx context.Context, id int64) (*entity.HealthPassport, error) {
+	return nil, nil
+}
+
+func (r *fakeHealthPassportRepository) GetAll(ctx context.Context, req dto.GetAllHealthPassportRequest) ([]entity.HealthPassport, int64, error) {
+	return r.passports, int64(len(r.passports)), nil
+}
+
+func (r *fakeHealthPassportRepository) GetByUserId(ctx context.Context, userId int64) (*entity.HealthPassport, error) {
+	return nil, nil
+}
+
+func (r *fakeHealthPassportRepository) Update(ctx context.Context, healthPassport *entity.HealthPassport) error {
+	r.updates++
+	return nil
+}
+
+func (r *fakeHealthPassportRepository) Delete(ctx context.Context, healthPassport *entity.HealthPassport) error {
+	return nil
+}
+
+type fakeHealthScreeningRepository struct {
+	screenings []entity.HealthScreening
+}
+
+func (r *fakeHealthScreeningRepository) Create(ctx context.Context, healthScreening *entity.HealthScreening) error {
+	healthScreening.Id = int64(len(r.screenings) + 1)
+	r.screenings = append(r.screenings, *healthScreening)
+	return nil
+}
+
+func (r *fakeHealthScreeningRepository) GetLatestByPassportId(ctx context.Context, healthPassportId int64) (*entity.HealthScreening, error) {
+	if len(r.screenings) == 0 {
+		return nil, nil
+	}
+	return &r.screenings[len(r.screenings)-1], nil
+}
+
+func (r *fakeHealthScreeningRepository) Update(ctx context.Context, healthScreening *entity.HealthScreening) error {
+	return nil
+}
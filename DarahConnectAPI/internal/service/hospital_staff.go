@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/audit"
+)
+
+var ErrHospitalStaffNotFound = errors.New("Pengguna bukan staf rumah sakit ini")
+
+type HospitalStaffService interface {
+	// HospitalIds memenuhi rbac.HospitalResolver untuk membatasi akses staf
+	HospitalIds(ctx context.Context, userId int64) ([]int64, error)
+	GetByHospital(ctx context.Context, hospitalId int64) ([]entity.HospitalStaff, error)
+	Add(ctx context.Context, hospitalId, userId int64) (*entity.HospitalStaff, error)
+	Remove(ctx context.Context, hospitalId, userId int64) error
+}
+
+type hospitalStaffService struct {
+	hospitalStaffRepository repository.HospitalStaffRepository
+	hospitalRepository      repository.HospitalRepository
+	userRepository          repository.UserRepository
+	transactor              repository.Transactor
+	auditLogService         AuditLogService
+}
+
+func NewHospitalStaffService(
+	hospitalStaffRepository repository.HospitalStaffRepository,
+	hospitalRepository repository.HospitalRepository,
+	userRepository repository.UserRepository,
+	transactor repository.Transactor,
+	auditLogService AuditLogService,
+) HospitalStaffService {
+	return &hospitalStaffService{hospitalStaffRepository, hospitalRepository, userRepository, transactor, auditLogService}
+}
+
+func (s *hospitalStaffService) HospitalIds(ctx context.Context, userId int64) ([]int64, error) {
+	return s.hospitalStaffRepository.GetHospitalIds(ctx, userId)
+}
+
+func (s *hospitalStaffService) GetByHospital(ctx context.Context, hospitalId int64) ([]entity.HospitalStaff, error) {
+	if _, err := s.hospitalRepository.GetById(ctx, hospitalId); err != nil {
+		return nil, errors.New("Rumah sakit tidak ditemukan")
+	}
+
+	staff, err := s.hospitalStaffRepository.GetByHospitalId(ctx, hospitalId)
+	if err != nil {
+		return nil, errors.New("Gagal mendapatkan data staf rumah sakit")
+	}
+	return staff, nil
+}
+
+func (s *hospitalStaffService) Add(ctx context.Context, hospitalId, userId int64) (*entity.HospitalStaff, error) {
+	if _, err := s.hospitalRepository.GetById(ctx, hospitalId); err != nil {
+		return nil, errors.New("Rumah sakit tidak ditemukan")
+	}
+
+	user, err := s.userRepository.GetById(ctx, userId)
+	if err != nil {
+		return nil, errors.New("Pengguna tidak ditemukan")
+	}
+
+	staff := &entity.HospitalStaff{
+		UserId:     user.Id,
+		HospitalId: hospitalId,
+		CreatedAt:  time.Now(),
+	}
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.hospitalStaffRepository.Create(ctx, staff); err != nil {
+			return errors.New("Gagal menambahkan staf rumah sakit")
+		}
+		return s.auditLogService.Record(ctx, audit.Entry{Action: "hospital_staff.add", EntityType: "hospital", EntityId: hospitalId, After: staff})
+	})
+	if err != nil {
+		return nil, err
+	}
+	staff.User = *user
+	return staff, nil
+}
+
+func (s *hospitalStaffService) Remove(ctx context.Context, hospitalId, userId int64) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		removed, err := s.hospitalStaffRepository.Delete(ctx, hospitalId, userId)
+		if err != nil {
+			return errors.New("Gagal menghapus staf rumah sakit")
+		}
+		if !removed {
+			return ErrHospitalStaffNotFound
+		}
+		return s.auditLogService.Record(ctx, audit.Entry{
+			Action:     "hospital_staff.remove",
+			EntityType: "hospital",
+			EntityId:   hospitalId,
+			Before:     entity.HospitalStaff{UserId: userId, HospitalId: hospitalId},
+		})
+	})
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/compatibility"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/utils"
+)
+
+// Masa simpan tiap komponen darah sejak diambil
+var componentShelfLife = map[string]time.Duration{
+	"whole_blood": 35 * 24 * time.Hour,
+	"red_cells":   42 * 24 * time.Hour,
+	"plasma":      365 * 24 * time.Hour,
+	"platelets":   5 * 24 * time.Hour,
+}
+
+// Volume standar satu kantong darah lengkap dari satu kali donor
+const wholeBloodVolumeMl = 450
+
+// ErrBloodStockUnavailable menandai permintaan darah yang tidak bisa dipenuhi dari stok rumah sakit
+var ErrBloodStockUnavailable = errors.New("Permintaan darah tidak bisa dipenuhi dari stok")
+
+type InventoryService interface {
+	Create(ctx context.Context, req dto.BloodBagCreateRequest) (*entity.BloodBag, error)
+	GetAll(ctx context.Context, req dto.GetAllBloodBagRequest) ([]entity.BloodBag, int64, error)
+	GetById(ctx context.Context, id int64) (*entity.BloodBag, error)
+	GetStock(ctx context.Context, req dto.GetBloodStockRequest) ([]dto.BloodStockResponse, error)
+	Update(ctx context.Context, req dto.BloodBagUpdateRequest, bloodBag *entity.BloodBag) error
+	Delete(ctx context.Context, id int64) error
+	AddFromDonation(ctx context.Context, bloodDonation *entity.BloodDonation) (*entity.BloodBag, error)
+	ConsumeForRequest(ctx context.Context, bloodRequest *entity.BloodRequest) ([]entity.BloodBag, error)
+	// ExpireOutdated menandai kantong darah yang sudah melewati tanggal kedaluwarsa. Dijalankan
+	// berkala oleh worker; pembacaan stok tidak menunggunya karena sudah menyaring expiry_date.
+	ExpireOutdated(ctx context.Context) (int64, error)
+}
+
+type inventoryService struct {
+	bloodBagRepository repository.BloodBagRepository
+}
+
+func NewInventoryService(bloodBagRepository repository.BloodBagRepository) InventoryService {
+	return &inventoryService{bloodBagRepository}
+}
+
+func (s *inventoryService) Create(ctx context.Context, req dto.BloodBagCreateRequest) (*entity.BloodBag, error) {
+	bloodType, err := compatibility.Normalize(req.BloodType)
+	if err != nil {
+		return nil, errors.New("Golongan darah tidak valid")
+	}
+
+	bloodBag := new(entity.BloodBag)
+	bloodBag.BagNumber = generateBagNumber()
+	bloodBag.HospitalId = req.HospitalId
+	bloodBag.BloodType = bloodType
+	bloodBag.Component = req.Component
+	bloodBag.VolumeMl = req.VolumeMl
+	bloodBag.CollectedAt = req.CollectedAt
+	bloodBag.ExpiryDate = req.ExpiryDate
+	bloodBag.Status = "available"
+
+	if bloodBag.CollectedAt.IsZero() {
+		bloodBag.CollectedAt = time.Now()
+	}
+	if bloodBag.ExpiryDate.IsZero() {
+		bloodBag.ExpiryDate = bloodBag.CollectedAt.Add(componentShelfLife[bloodBag.Component])
+	}
+	if !bloodBag.ExpiryDate.After(bloodBag.CollectedAt) {
+		return nil, errors.New("Tanggal kedaluwarsa harus setelah tanggal pengambilan")
+	}
+
+	if err := s.bloodBagRepository.Create(ctx, bloodBag); err != nil {
+		return nil, errors.New("Gagal menambahkan kantong darah")
+	}
+	return bloodBag, nil
+}
+
+func (s *inventoryService) GetAll(ctx context.Context, req dto.GetAllBloodBagRequest) ([]entity.BloodBag, int64, error) {
+	bloodBags, total, err := s.bloodBagRepository.GetAll(ctx, req)
+	if err != nil {
+		return nil, 0, errors.New("Gagal mengambil data kantong darah")
+	}
+
+	now := time.Now()
+	for i := range bloodBags {
+		markExpired(&bloodBags[i], now)
+	}
+	return bloodBags, total, nil
+}
+
+func (s *inventoryService) GetById(ctx context.Context, id int64) (*entity.BloodBag, error) {
+	bloodBag, err := s.bloodBagRepository.GetById(ctx, id)
+	if err != nil {
+		return nil, errors.New("Kantong darah tidak ditemukan")
+	}
+	markExpired(bloodBag, time.Now())
+	return bloodBag, nil
+}
+
+// markExpired menampilkan kantong yang sudah lewat tanggal kedaluwarsa sebagai expired
+// meskipun worker belum menandainya di database
+func markExpired(bloodBag *entity.BloodBag, now time.Time) {
+	if bloodBag.Status == "available" && !bloodBag.ExpiryDate.After(now) {
+		bloodBag.Status = "expired"
+	}
+}
+
+func (s *inventoryService) GetStock(ctx context.Context, req dto.GetBloodStockRequest) ([]dto.BloodStockResponse, error) {
+	stock, err := s.bloodBagRepository.GetStock(ctx, req)
+	if err != nil {
+		return nil, errors.New("Gagal mengambil stok darah")
+	}
+	return stock, nil
+}
+
+func (s *inventoryService) Update(ctx context.Context, req dto.BloodBagUpdateRequest, bloodBag *entity.BloodBag) error {
+	if bloodBag.Status == "used" {
+		return errors.New("Kantong darah yang sudah terpakai tidak bisa diubah")
+	}
+	if req.Status != "" {
+		bloodBag.Status = req.Status
+		if req.Status == "used" {
+			usedAt := time.Now()
+			bloodBag.UsedAt = &usedAt
+		}
+	}
+	if req.VolumeMl != 0 {
+		bloodBag.VolumeMl = req.VolumeMl
+	}
+	if !req.ExpiryDate.IsZero() {
+		bloodBag.ExpiryDate = req.ExpiryDate
+	}
+
+	if err := s.bloodBagRepository.Update(ctx, bloodBag); err != nil {
+		return errors.New("Gagal memperbarui kantong darah")
+	}
+	return nil
+}
+
+func (s *inventoryService) Delete(ctx context.Context, id int64) error {
+	bloodBag, err := s.bloodBagRepository.GetById(ctx, id)
+	if err != nil {
+		return errors.New("Kantong darah tidak ditemukan")
+	}
+
+	if err := s.bloodBagRepository.Delete(ctx, bloodBag); err != nil {
+		return errors.New("Gagal menghapus kantong darah")
+	}
+	return nil
+}
+
+// AddFromDonation mencatat satu kantong darah lengkap ke stok rumah sakit dari donasi yang telah selesai
+func (s *inventoryService) AddFromDonation(ctx context.Context, bloodDonation *entity.BloodDonation) (*entity.BloodBag, error) {
+	if existing, err := s.bloodBagRepository.GetByDonationId(ctx, bloodDonation.Id); err == nil {
+		return existing, nil
+	}
+
+	bloodType, err := compatibility.Normalize(bloodDonation.BloodType)
+	if err != nil {
+		return nil, errors.New("Golongan darah donasi tidak valid")
+	}
+
+	collectedAt := bloodDonation.DonationDate
+	if collectedAt.IsZero() {
+		collectedAt = time.Now()
+	}
+
+	donationId := bloodDonation.Id
+	bloodBag := new(entity.BloodBag)
+	bloodBag.BagNumber = generateBagNumber()
+	bloodBag.HospitalId = bloodDonation.HospitalId
+	bloodBag.DonationId = &donationId
+	bloodBag.BloodType = bloodType
+	bloodBag.Component = "whole_blood"
+	bloodBag.VolumeMl = wholeBloodVolumeMl
+	bloodBag.CollectedAt = collectedAt
+	bloodBag.ExpiryDate = collectedAt.Add(componentShelfLife["whole_blood"])
+	bloodBag.Status = "available"
+
+	if err := s.bloodBagRepository.Create(ctx, bloodBag); err != nil {
+		return nil, errors.New("Gagal menambahkan kantong darah ke stok")
+	}
+	return bloodBag, nil
+}
+
+func (s *inventoryService) ExpireOutdated(ctx context.Context) (int64, error) {
+	expired, err := s.bloodBagRepository.ExpireOutdated(ctx, time.Now())
+	if err != nil {
+		return 0, errors.New("Gagal memperbarui kantong darah kedaluwarsa")
+	}
+	return expired, nil
+}
+
+// ConsumeForRequest memakai kantong darah dari stok rumah sakit sesuai jumlah permintaan darah
+func (s *inventoryService) ConsumeForRequest(ctx context.Context, bloodRequest *entity.BloodRequest) ([]entity.BloodBag, error) {
+	if bloodRequest.Quantity <= 0 {
+		return nil, errors.New("Jumlah kantong pada permintaan darah tidak valid")
+	}
+
+	bloodType, err := compatibility.Normalize(bloodRequest.BloodType)
+	if err != nil {
+		return nil, errors.New("Golongan darah permintaan tidak valid")
+	}
+
+	// Kantong darah lengkap harus ABO identik, sel darah merah boleh dari golongan yang kompatibel
+	candidates := map[string][]string{
+		string(compatibility.WholeBlood): compatibility.DonorsFor(bloodType, compatibility.WholeBlood),
+		string(compatibility.RedCells):   compatibility.DonorsFor(bloodType, compatibility.RedCells),
+	}
+
+	bloodBags, err := s.bloodBagRepository.Consume(ctx, bloodRequest.HospitalId, bloodType, candidates, bloodRequest.Quantity, bloodRequest.Id)
+	if err != nil {
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			return nil, errors.New("Stok darah " + bloodRequest.BloodType + " di rumah sakit tidak mencukupi")
+		}
+		return nil, errors.New("Gagal memakai stok darah")
+	}
+	return bloodBags, nil
+}
+
+func generateBagNumber() string {
+	return "BAG-" + time.Now().Format("20060102") + "-" + strings.ToUpper(utils.RandomString(8))
+}
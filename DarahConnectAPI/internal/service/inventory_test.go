@@ -0,0 +1,282 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/cloudinary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventoryCreateDefaultsExpiry(t *testing.T) {
+	repo := &fakeBloodBagRepository{}
+	inventoryService := service.NewInventoryService(repo)
+
+	collectedAt := time.Date(2024, time.October, 18, 9, 0, 0, 0, time.UTC)
+	bloodBag, err := inventoryService.Create(context.Background(), dto.BloodBagCreateRequest{
+		HospitalId:  1,
+		BloodType:   "o+",
+		Component:   "platelets",
+		VolumeMl:    250,
+		CollectedAt: collectedAt,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "O+", bloodBag.BloodType)
+	assert.Equal(t, "available", bloodBag.Status)
+	assert.Equal(t, collectedAt.Add(5*24*time.Hour), bloodBag.ExpiryDate)
+	assert.Len(t, repo.bags, 1)
+
+	_, err = inventoryService.Create(context.Background(), dto.BloodBagCreateRequest{HospitalId: 1, BloodType: "X+", Component: "plasma"})
+	assert.Error(t, err)
+
+	_, err = inventoryService.Create(context.Background(), dto.BloodBagCreateRequest{
+		HospitalId:  1,
+		BloodType:   "A-",
+		Component:   "plasma",
+		CollectedAt: collectedAt,
+		ExpiryDate:  collectedAt.Add(-time.Hour),
+	})
+	assert.Error(t, err)
+	assert.Len(t, repo.bags, 1)
+}
+
+func TestInventoryAddFromDonationIsIdempotent(t *testing.T) {
+	repo := &fakeBloodBagRepository{}
+	inventoryService := service.NewInventoryService(repo)
+
+	donation := &entity.BloodDonation{Id: 7, HospitalId: 3, BloodType: "AB-", DonationDate: time.Date(2024, time.October, 1, 8, 0, 0, 0, time.UTC)}
+	first, err := inventoryService.AddFromDonation(context.Background(), donation)
+	require.NoError(t, err)
+	assert.Equal(t, "whole_blood", first.Component)
+	assert.Equal(t, int64(450), first.VolumeMl)
+	assert.Equal(t, donation.DonationDate.Add(35*24*time.Hour), first.ExpiryDate)
+
+	second, err := inventoryService.AddFromDonation(context.Background(), donation)
+	require.NoError(t, err)
+	assert.Equal(t, first.Id, second.Id)
+	assert.Len(t, repo.bags, 1)
+}
+
+func TestInventoryReadsReportExpiryWithoutWriting(t *testing.T) {
+	repo := &fakeBloodBagRepository{bags: []entity.BloodBag{
+		{Id: 1, Status: "available", ExpiryDate: time.Now().Add(-time.Hour)},
+		{Id: 2, Status: "available", ExpiryDate: time.Now().Add(time.Hour)},
+	}}
+	inventoryService := service.NewInventoryService(repo)
+
+	bloodBags, _, err := inventoryService.GetAll(context.Background(), dto.GetAllBloodBagRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "expired", bloodBags[0].Status)
+	assert.Equal(t, "available", bloodBags[1].Status)
+	assert.Equal(t, 2, repo.countStatus("available"))
+
+	// Hanya worker yang menandai kantong kedaluwarsa di database
+	expired, err := inventoryService.ExpireOutdated(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), expired)
+	assert.Equal(t, 1, repo.countStatus("expired"))
+}
+
+func TestInventoryConsumeForRequest(t *testing.T) {
+	repo := &fakeBloodBagRepository{}
+	inventoryService := service.NewInventoryService(repo)
+	for _, bloodType := range []string{"O-", "O-", "A-"} {
+		_, err := inventoryService.Create(context.Background(), dto.BloodBagCreateRequest{HospitalId: 1, BloodType: bloodType, Component: "red_cells", VolumeMl: 250})
+		require.NoError(t, err)
+	}
+
+	t.Run("invalid quantity", func(t *testing.T) {
+		_, err := inventoryService.ConsumeForRequest(context.Background(), &entity.BloodRequest{Id: 1, HospitalId: 1, BloodType: "O-", Quantity: 0})
+		assert.Error(t, err)
+	})
+
+	t.Run("insufficient stock", func(t *testing.T) {
+		_, err := inventoryService.ConsumeForRequest(context.Background(), &entity.BloodRequest{Id: 2, HospitalId: 1, BloodType: "O-", Quantity: 3})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "tidak mencukupi")
+		assert.Equal(t, 3, repo.countStatus("available"))
+	})
+
+	t.Run("compatible donors only", func(t *testing.T) {
+		bloodBags, err := inventoryService.ConsumeForRequest(context.Background(), &entity.BloodRequest{Id: 3, HospitalId: 1, BloodType: "A+", Quantity: 3})
+		require.NoError(t, err)
+		assert.Len(t, bloodBags, 3)
+		assert.Equal(t, 0, repo.countStatus("available"))
+		assert.ElementsMatch(t, []string{"O-", "O+", "A-", "A+"}, repo.lastCandidates["red_cells"])
+		assert.ElementsMatch(t, []string{"A-", "A+"}, repo.lastCandidates["whole_blood"])
+	})
+}
+
+func TestInventoryUpdateRejectsUsedBag(t *testing.T) {
+	inventoryService := service.NewInventoryService(&fakeBloodBagRepository{})
+
+	bloodBag := &entity.BloodBag{Id: 1, Status: "available"}
+	require.NoError(t, inventoryService.Update(context.Background(), dto.BloodBagUpdateRequest{Status: "used"}, bloodBag))
+	assert.Equal(t, "used", bloodBag.Status)
+	assert.NotNil(t, bloodBag.UsedAt)
+
+	assert.Error(t, inventoryService.Update(context.Background(), dto.BloodBagUpdateRequest{Status: "available"}, bloodBag))
+}
+
+func TestBloodRequestUpdateStatusConsumesStock(t *testing.T) {
+	newService := func() (service.BloodRequestService, *fakeBloodBagRepository, *fakeBloodRequestRepository) {
+		bagRepo := &fakeBloodBagRepository{}
+		requestRepo := &fakeBloodRequestRepository{}
+		auditLogService := service.NewAuditLogService(&fakeAuditLogRepository{}, passthroughTransactor{})
+		inventoryService := service.NewInventoryService(bagRepo)
+		_, err := inventoryService.Create(context.Background(), dto.BloodBagCreateRequest{HospitalId: 1, BloodType: "B+", Component: "whole_blood", VolumeMl: 450})
+		require.NoError(t, err)
+		return service.NewBloodRequestService(requestRepo, nil, cloudinary.Service{}, inventoryService, nil, passthroughTransactor{}, auditLogService), bagRepo, requestRepo
+	}
+
+	t.Run("fulfilled", func(t *testing.T) {
+		bloodRequestService, bagRepo, requestRepo := newService()
+		bloodRequest := &entity.BloodRequest{Id: 1, HospitalId: 1, BloodType: "B+", Quantity: 1, Status: "verified", EventType: "blood_request"}
+
+		require.NoError(t, bloodRequestService.UpdateStatus(context.Background(), dto.BloodRequestUpdateRequest{Status: "fulfilled"}, bloodRequest))
+		assert.Equal(t, 0, bagRepo.countStatus("available"))
+		require.Len(t, requestRepo.updated, 1)
+		assert.Equal(t, "fulfilled", requestRepo.updated[0].Status)
+	})
+
+	t.Run("insufficient stock", func(t *testing.T) {
+		bloodRequestService, bagRepo, requestRepo := newService()
+		bloodRequest := &entity.BloodRequest{Id: 2, HospitalId: 1, BloodType: "B+", Quantity: 2, Status: "verified", EventType: "blood_request"}
+
+		err := bloodRequestService.UpdateStatus(context.Background(), dto.BloodRequestUpdateRequest{Status: "fulfilled"}, bloodRequest)
+		assert.ErrorIs(t, err, service.ErrBloodStockUnavailable)
+		assert.Equal(t, 1, bagRepo.countStatus("available"))
+		assert.Empty(t, requestRepo.updated)
+		assert.Equal(t, "verified", bloodRequest.Status)
+	})
+
+	t.Run("campaign does not touch stock", func(t *testing.T) {
+		bloodRequestService, bagRepo, requestRepo := newService()
+		bloodRequest := &entity.BloodRequest{Id: 3, HospitalId: 1, BloodType: "B+", Quantity: 5, Status: "verified", EventType: "campaign"}
+
+		require.NoError(t, bloodRequestService.UpdateStatus(context.Background(), dto.BloodRequestUpdateRequest{Status: "fulfilled"}, bloodRequest))
+		assert.Equal(t, 1, bagRepo.countStatus("available"))
+		assert.Len(t, requestRepo.updated, 1)
+	})
+}
+
+// fakeBloodBagRepository meniru penguncian Consume di repository: semua atau tidak sama sekali
+type fakeBloodBagRepository struct {
+	bags           []entity.BloodBag
+	lastCandidates map[string][]string
+}
+
+func (r *fakeBloodBagRepository) countStatus(status string) int {
+	count := 0
+	for _, bloodBag := range r.bags {
+		if bloodBag.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *fakeBloodBagRepository) Create(ctx context.Context, bloodBag *entity.BloodBag) error {
+	bloodBag.Id = int64(len(r.bags) + 1)
+	r.bags = append(r.bags, *bloodBag)
+	return nil
+}
+
+func (r *fakeBloodBagRepository) GetById(ctx context.Context, id int64) (*entity.BloodBag, error) {
+	for i := range r.bags {
+		if r.bags[i].Id == id {
+			bloodBag := r.bags[i]
+			return &bloodBag, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeBloodBagRepository) GetAll(ctx context.Context, req dto.GetAllBloodBagRequest) ([]entity.BloodBag, int64, error) {
+	return append([]entity.BloodBag(nil), r.bags...), int64(len(r.bags)), nil
+}
+
+func (r *fakeBloodBagRepository) GetByDonationId(ctx context.Context, donationId int64) (*entity.BloodBag, error) {
+	for i := range r.bags {
+		if r.bags[i].DonationId != nil && *r.bags[i].DonationId == donationId {
+			bloodBag := r.bags[i]
+			return &bloodBag, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeBloodBagRepository) GetStock(ctx context.Context, req dto.GetBloodStockRequest) ([]dto.BloodStockResponse, error) {
+	return nil, nil
+}
+
+func (r *fakeBloodBagRepository) Consume(ctx context.Context, hospitalId int64, bloodType string, candidates map[string][]string, quantity int64, requestId int64) ([]entity.BloodBag, error) {
+	r.lastCandidates = candidates
+	picked := make([]int, 0, quantity)
+	for i, bloodBag := range r.bags {
+		if int64(len(picked)) == quantity {
+			break
+		}
+		if bloodBag.HospitalId == hospitalId && bloodBag.Status == "available" && containsBloodType(candidates[bloodBag.Component], bloodBag.BloodType) {
+			picked = append(picked, i)
+		}
+	}
+	if int64(len(picked)) < quantity {
+		return nil, repository.ErrInsufficientStock
+	}
+
+	usedAt := time.Now()
+	result := make([]entity.BloodBag, 0, len(picked))
+	for _, i := range picked {
+		r.bags[i].Status = "used"
+		r.bags[i].RequestId = &requestId
+		r.bags[i].UsedAt = &usedAt
+		result = append(result, r.bags[i])
+	}
+	return result, nil
+}
+
+func (r *fakeBloodBagRepository) ExpireOutdated(ctx context.Context, now time.Time) (int64, error) {
+	var expired int64
+	for i := range r.bags {
+		if r.bags[i].Status == "available" && !r.bags[i].ExpiryDate.After(now) {
+			r.bags[i].Status = "expired"
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+func (r *fakeBloodBagRepository) Update(ctx context.Context, bloodBag *entity.BloodBag) error {
+	return nil
+}
+
+func (r *fakeBloodBagRepository) Delete(ctx context.Context, bloodBag *entity.BloodBag) error {
+	return nil
+}
+
+type fakeBloodRequestRepository struct {
+	repository.BloodRequestRepository
+	updated []entity.BloodRequest
+}
+
+func (r *fakeBloodRequestRepository) Update(ctx context.Context, bloodRequest *entity.BloodRequest) error {
+	r.updated = append(r.updated, *bloodRequest)
+	return nil
+}
+
+func containsBloodType(items []string, item string) bool {
+	for _, candidate := range items {
+		if candidate == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,392 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/secretbox"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/totp"
+	"github.com/skip2/go-qrcode"
+)
+
+var (
+	ErrMFATokenInvalid   = errors.New("Token verifikasi dua langkah tidak valid atau sudah kedaluwarsa")
+	ErrMFACodeInvalid    = errors.New("Kode verifikasi tidak valid")
+	ErrMFANotEnrolled    = errors.New("Verifikasi dua langkah belum didaftarkan")
+	ErrMFAAlreadyEnabled = errors.New("Verifikasi dua langkah sudah aktif")
+	ErrMFAEnforced       = errors.New("Verifikasi dua langkah wajib untuk role anda")
+)
+
+type MFAService interface {
+	// StartLogin mengembalikan token mfa pending jika pengguna wajib atau sudah memakai TOTP,
+	// atau nil jika JWT boleh langsung diterbitkan
+	StartLogin(ctx context.Context, user *entity.User) (*dto.MFAChallengeResponse, error)
+	EnrollWithToken(ctx context.Context, mfaToken string) (*dto.MFAEnrollResponse, error)
+	CompleteLogin(ctx context.Context, req dto.MFALoginRequest) (*entity.User, []string, error)
+	Status(ctx context.Context, user *entity.User) (*dto.MFAStatusResponse, error)
+	Enroll(ctx context.Context, user *entity.User) (*dto.MFAEnrollResponse, error)
+	Activate(ctx context.Context, userId int64, code string) ([]string, error)
+	Disable(ctx context.Context, user *entity.User, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userId int64, code string) ([]string, error)
+}
+
+type mfaService struct {
+	userMFARepository      repository.UserMFARepository
+	mfaChallengeRepository repository.MFAChallengeRepository
+	userRepository         repository.UserRepository
+	transactor             repository.Transactor
+	cfg                    *configs.MFAConfig
+	sealingCfg             *configs.SealingConfig
+}
+
+func NewMFAService(
+	userMFARepository repository.UserMFARepository,
+	mfaChallengeRepository repository.MFAChallengeRepository,
+	userRepository repository.UserRepository,
+	transactor repository.Transactor,
+	cfg *configs.MFAConfig,
+	sealingCfg *configs.SealingConfig,
+) MFAService {
+	return &mfaService{userMFARepository, mfaChallengeRepository, userRepository, transactor, cfg, sealingCfg}
+}
+
+func (s *mfaService) StartLogin(ctx context.Context, user *entity.User) (*dto.MFAChallengeResponse, error) {
+	mfa, err := s.userMFARepository.GetByUserId(ctx, user.Id)
+	if err != nil {
+		return nil, errors.New("Gagal membaca pengaturan verifikasi dua langkah")
+	}
+	enabled := mfa != nil && mfa.EnabledAt != nil
+	if !enabled && !s.enforced(user.Role) {
+		return nil, nil
+	}
+
+	purpose := entity.MFAChallengeVerify
+	if !enabled {
+		purpose = entity.MFAChallengeEnroll
+	}
+
+	mfaToken, err := newOpaqueToken()
+	if err != nil {
+		return nil, errors.New("ada kesalahan di server")
+	}
+	challenge := &entity.MFAChallenge{
+		UserId:    user.Id,
+		TokenHash: hashToken(mfaToken),
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(s.cfg.ChallengeTTL),
+		CreatedAt: time.Now(),
+	}
+	if err := s.mfaChallengeRepository.Create(ctx, challenge); err != nil {
+		return nil, errors.New("Gagal membuat token verifikasi dua langkah")
+	}
+
+	return &dto.MFAChallengeResponse{
+		MFARequired:        true,
+		EnrollmentRequired: purpose == entity.MFAChallengeEnroll,
+		MFAToken:           mfaToken,
+		ExpiresAt:          challenge.ExpiresAt,
+	}, nil
+}
+
+// EnrollWithToken membuat secret TOTP untuk pengguna dengan role wajib MFA yang login
+// sebelum pernah mendaftarkan TOTP
+func (s *mfaService) EnrollWithToken(ctx context.Context, mfaToken string) (*dto.MFAEnrollResponse, error) {
+	challenge, err := s.challenge(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.Purpose != entity.MFAChallengeEnroll {
+		return nil, ErrMFATokenInvalid
+	}
+
+	user, err := s.userRepository.GetById(ctx, challenge.UserId)
+	if err != nil {
+		return nil, ErrMFATokenInvalid
+	}
+	return s.Enroll(ctx, user)
+}
+
+// CompleteLogin memverifikasi kode lalu menandai token mfa pending sudah dipakai. Jika token
+// berasal dari pendaftaran wajib, TOTP langsung diaktifkan dan kode pemulihan dikembalikan.
+func (s *mfaService) CompleteLogin(ctx context.Context, req dto.MFALoginRequest) (*entity.User, []string, error) {
+	challenge, err := s.challenge(ctx, req.MFAToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mfa, err := s.userMFARepository.GetByUserId(ctx, challenge.UserId)
+	if err != nil {
+		return nil, nil, errors.New("Gagal membaca pengaturan verifikasi dua langkah")
+	}
+	if mfa == nil {
+		return nil, nil, ErrMFANotEnrolled
+	}
+	// TOTP dinonaktifkan setelah token diterbitkan
+	if challenge.Purpose == entity.MFAChallengeVerify && mfa.EnabledAt == nil {
+		return nil, nil, ErrMFATokenInvalid
+	}
+
+	if req.RecoveryCode != "" && mfa.EnabledAt != nil {
+		err = s.useRecoveryCode(ctx, mfa.UserId, req.RecoveryCode)
+	} else {
+		err = s.verifyCode(ctx, mfa, req.Code)
+	}
+	if err != nil {
+		if errors.Is(err, ErrMFACodeInvalid) {
+			if err := s.mfaChallengeRepository.IncrementAttempts(ctx, challenge); err != nil {
+				return nil, nil, errors.New("Gagal memperbarui token verifikasi dua langkah")
+			}
+		}
+		return nil, nil, err
+	}
+
+	var recoveryCodes []string
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		used, err := s.mfaChallengeRepository.MarkUsed(ctx, challenge)
+		if err != nil {
+			return errors.New("Gagal memperbarui token verifikasi dua langkah")
+		}
+		if !used {
+			return ErrMFATokenInvalid
+		}
+		if mfa.EnabledAt == nil {
+			recoveryCodes, err = s.enable(ctx, mfa)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.userRepository.GetById(ctx, challenge.UserId)
+	if err != nil {
+		return nil, nil, ErrMFATokenInvalid
+	}
+	return user, recoveryCodes, nil
+}
+
+func (s *mfaService) Status(ctx context.Context, user *entity.User) (*dto.MFAStatusResponse, error) {
+	mfa, err := s.userMFARepository.GetByUserId(ctx, user.Id)
+	if err != nil {
+		return nil, errors.New("Gagal membaca pengaturan verifikasi dua langkah")
+	}
+
+	status := &dto.MFAStatusResponse{Enforced: s.enforced(user.Role)}
+	if mfa != nil && mfa.EnabledAt != nil {
+		status.Enabled = true
+		if status.RecoveryCodesRemaining, err = s.userMFARepository.CountRecoveryCodes(ctx, user.Id); err != nil {
+			return nil, errors.New("Gagal membaca kode pemulihan")
+		}
+	}
+	return status, nil
+}
+
+// Enroll membuat secret TOTP baru. TOTP belum aktif sampai kode pertama dikonfirmasi lewat
+// Activate atau CompleteLogin, jadi memanggil Enroll lagi cukup mengganti secret.
+func (s *mfaService) Enroll(ctx context.Context, user *entity.User) (*dto.MFAEnrollResponse, error) {
+	mfa, err := s.userMFARepository.GetByUserId(ctx, user.Id)
+	if err != nil {
+		return nil, errors.New("Gagal membaca pengaturan verifikasi dua langkah")
+	}
+	if mfa != nil && mfa.EnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errors.New("ada kesalahan di server")
+	}
+	sealed, err := secretbox.Seal([]byte(secret), s.sealingCfg.Key)
+	if err != nil {
+		return nil, errors.New("ada kesalahan di server")
+	}
+
+	if mfa == nil {
+		mfa = &entity.UserMFA{UserId: user.Id, Secret: sealed, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+		err = s.userMFARepository.Create(ctx, mfa)
+	} else {
+		mfa.Secret = sealed
+		mfa.LastUsedStep = 0
+		mfa.UpdatedAt = time.Now()
+		err = s.userMFARepository.Update(ctx, mfa)
+	}
+	if err != nil {
+		return nil, errors.New("Gagal menyimpan secret verifikasi dua langkah")
+	}
+
+	uri := totp.ProvisioningURI(s.cfg.Issuer, user.Email, secret)
+	qr, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return nil, errors.New("Gagal membuat QR code")
+	}
+	return &dto.MFAEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: uri,
+		QRCode:          "data:image/png;base64," + base64.StdEncoding.EncodeToString(qr),
+	}, nil
+}
+
+func (s *mfaService) Activate(ctx context.Context, userId int64, code string) ([]string, error) {
+	mfa, err := s.userMFARepository.GetByUserId(ctx, userId)
+	if err != nil {
+		return nil, errors.New("Gagal membaca pengaturan verifikasi dua langkah")
+	}
+	if mfa == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if mfa.EnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if err := s.verifyCode(ctx, mfa, code); err != nil {
+		return nil, err
+	}
+
+	var recoveryCodes []string
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		recoveryCodes, err = s.enable(ctx, mfa)
+		return err
+	})
+	return recoveryCodes, err
+}
+
+func (s *mfaService) Disable(ctx context.Context, user *entity.User, code string) error {
+	if s.enforced(user.Role) {
+		return ErrMFAEnforced
+	}
+
+	mfa, err := s.enabledMFA(ctx, user.Id)
+	if err != nil {
+		return err
+	}
+	if err := s.verifyCode(ctx, mfa, code); err != nil {
+		return err
+	}
+	if err := s.userMFARepository.Delete(ctx, user.Id); err != nil {
+		return errors.New("Gagal menonaktifkan verifikasi dua langkah")
+	}
+	return nil
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userId int64, code string) ([]string, error) {
+	mfa, err := s.enabledMFA(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyCode(ctx, mfa, code); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(ctx, userId)
+}
+
+func (s *mfaService) enforced(role string) bool {
+	return slices.Contains(s.cfg.EnforcedRoles, role)
+}
+
+// challenge mencari token mfa pending yang masih bisa dipakai
+func (s *mfaService) challenge(ctx context.Context, mfaToken string) (*entity.MFAChallenge, error) {
+	challenge, err := s.mfaChallengeRepository.GetByTokenHash(ctx, hashToken(mfaToken))
+	if err != nil {
+		return nil, ErrMFATokenInvalid
+	}
+	if challenge.UsedAt != nil || time.Now().After(challenge.ExpiresAt) || challenge.Attempts >= s.cfg.MaxAttempts {
+		return nil, ErrMFATokenInvalid
+	}
+	return challenge, nil
+}
+
+func (s *mfaService) enabledMFA(ctx context.Context, userId int64) (*entity.UserMFA, error) {
+	mfa, err := s.userMFARepository.GetByUserId(ctx, userId)
+	if err != nil {
+		return nil, errors.New("Gagal membaca pengaturan verifikasi dua langkah")
+	}
+	if mfa == nil || mfa.EnabledAt == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	return mfa, nil
+}
+
+// verifyCode memeriksa kode TOTP dan menolak kode dari periode yang sudah pernah dipakai
+func (s *mfaService) verifyCode(ctx context.Context, mfa *entity.UserMFA, code string) error {
+	secret, err := secretbox.Open(mfa.Secret, s.sealingCfg.Key)
+	if err != nil {
+		return errors.New("Gagal membuka secret verifikasi dua langkah")
+	}
+	step, ok := totp.Validate(string(secret), code, time.Now(), 1)
+	if !ok {
+		return ErrMFACodeInvalid
+	}
+	fresh, err := s.userMFARepository.MarkStepUsed(ctx, mfa.UserId, step)
+	if err != nil {
+		return errors.New("Gagal memperbarui verifikasi dua langkah")
+	}
+	if !fresh {
+		return ErrMFACodeInvalid
+	}
+	mfa.LastUsedStep = step
+	return nil
+}
+
+func (s *mfaService) useRecoveryCode(ctx context.Context, userId int64, code string) error {
+	used, err := s.userMFARepository.UseRecoveryCode(ctx, userId, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return errors.New("Gagal memperbarui kode pemulihan")
+	}
+	if !used {
+		return ErrMFACodeInvalid
+	}
+	return nil
+}
+
+func (s *mfaService) enable(ctx context.Context, mfa *entity.UserMFA) ([]string, error) {
+	now := time.Now()
+	mfa.EnabledAt = &now
+	mfa.UpdatedAt = now
+	if err := s.userMFARepository.Update(ctx, mfa); err != nil {
+		return nil, errors.New("Gagal mengaktifkan verifikasi dua langkah")
+	}
+	return s.replaceRecoveryCodes(ctx, mfa.UserId)
+}
+
+// replaceRecoveryCodes membuat kode pemulihan baru dan membatalkan yang lama. Kode asli hanya
+// dikembalikan sekali ke pengguna.
+func (s *mfaService) replaceRecoveryCodes(ctx context.Context, userId int64) ([]string, error) {
+	codes := make([]string, 0, s.cfg.RecoveryCodeCount)
+	hashes := make([]string, 0, s.cfg.RecoveryCodeCount)
+	for range s.cfg.RecoveryCodeCount {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, errors.New("ada kesalahan di server")
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashToken(normalizeRecoveryCode(code)))
+	}
+	if err := s.userMFARepository.ReplaceRecoveryCodes(ctx, userId, hashes); err != nil {
+		return nil, errors.New("Gagal menyimpan kode pemulihan")
+	}
+	return codes, nil
+}
+
+// newRecoveryCode membuat kode 50 bit dengan format xxxxx-xxxxx
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(buf))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
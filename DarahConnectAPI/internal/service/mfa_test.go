@@ -0,0 +1,182 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFAEnforcedLoginWithTOTPAndRecoveryCode(t *testing.T) {
+	ctx := context.Background()
+	admin := &entity.User{Id: 1, Name: "Admin", Email: "admin@example.com", Role: "Administrator"}
+	mfaRepository := &fakeUserMFARepository{}
+	challenges := &fakeMFAChallengeRepository{}
+	mfaService := service.NewMFAService(mfaRepository, challenges, &fakeUserRepository{user: admin}, passthroughTransactor{}, &configs.MFAConfig{
+		Issuer:            "DarahConnect",
+		EnforcedRoles:     []string{"Administrator"},
+		ChallengeTTL:      time.Minute,
+		MaxAttempts:       3,
+		RecoveryCodeCount: 4,
+	}, &configs.SealingConfig{Key: "mfa-test-sealing-key-0123456789abcdef"})
+
+	// Pengguna biasa tanpa TOTP langsung mendapat JWT
+	challenge, err := mfaService.StartLogin(ctx, &entity.User{Id: 2, Role: "User"})
+	require.NoError(t, err)
+	assert.Nil(t, challenge)
+
+	// Administrator tanpa TOTP wajib mendaftar sebelum mendapat JWT
+	challenge, err = mfaService.StartLogin(ctx, admin)
+	require.NoError(t, err)
+	require.NotNil(t, challenge)
+	assert.True(t, challenge.EnrollmentRequired)
+
+	enrollment, err := mfaService.EnrollWithToken(ctx, challenge.MFAToken)
+	require.NoError(t, err)
+	assert.Contains(t, enrollment.ProvisioningURI, "otpauth://totp/")
+	assert.NotEqual(t, enrollment.Secret, mfaRepository.mfa.Secret)
+
+	step := totp.Step(time.Now())
+	code, err := totp.Code(enrollment.Secret, step)
+	require.NoError(t, err)
+	user, recoveryCodes, err := mfaService.CompleteLogin(ctx, dto.MFALoginRequest{MFAToken: challenge.MFAToken, Code: code})
+	require.NoError(t, err)
+	assert.Equal(t, admin.Id, user.Id)
+	assert.Len(t, recoveryCodes, 4)
+	assert.NotNil(t, mfaRepository.mfa.EnabledAt)
+
+	// Token mfa pending hanya bisa ditukar sekali
+	_, _, err = mfaService.CompleteLogin(ctx, dto.MFALoginRequest{MFAToken: challenge.MFAToken, Code: code})
+	assert.ErrorIs(t, err, service.ErrMFATokenInvalid)
+
+	// Kode dari periode yang sudah dipakai ditolak
+	challenge, err = mfaService.StartLogin(ctx, admin)
+	require.NoError(t, err)
+	assert.False(t, challenge.EnrollmentRequired)
+	_, _, err = mfaService.CompleteLogin(ctx, dto.MFALoginRequest{MFAToken: challenge.MFAToken, Code: code})
+	assert.ErrorIs(t, err, service.ErrMFACodeInvalid)
+
+	nextCode, err := totp.Code(enrollment.Secret, step+1)
+	require.NoError(t, err)
+	_, _, err = mfaService.CompleteLogin(ctx, dto.MFALoginRequest{MFAToken: challenge.MFAToken, Code: nextCode})
+	require.NoError(t, err)
+
+	// Kode pemulihan hanya berlaku sekali
+	challenge, err = mfaService.StartLogin(ctx, admin)
+	require.NoError(t, err)
+	_, _, err = mfaService.CompleteLogin(ctx, dto.MFALoginRequest{MFAToken: challenge.MFAToken, RecoveryCode: recoveryCodes[0]})
+	require.NoError(t, err)
+
+	challenge, err = mfaService.StartLogin(ctx, admin)
+	require.NoError(t, err)
+	_, _, err = mfaService.CompleteLogin(ctx, dto.MFALoginRequest{MFAToken: challenge.MFAToken, RecoveryCode: recoveryCodes[0]})
+	assert.ErrorIs(t, err, service.ErrMFACodeInvalid)
+
+	// Token mfa pending tidak bisa dipakai lagi setelah terlalu banyak percobaan
+	for range 2 {
+		_, _, err = mfaService.CompleteLogin(ctx, dto.MFALoginRequest{MFAToken: challenge.MFAToken, Code: "000000"})
+		assert.ErrorIs(t, err, service.ErrMFACodeInvalid)
+	}
+	_, _, err = mfaService.CompleteLogin(ctx, dto.MFALoginRequest{MFAToken: challenge.MFAToken, RecoveryCode: recoveryCodes[1]})
+	assert.ErrorIs(t, err, service.ErrMFATokenInvalid)
+
+	// Role wajib MFA tidak boleh menonaktifkan TOTP
+	assert.ErrorIs(t, mfaService.Disable(ctx, admin, nextCode), service.ErrMFAEnforced)
+}
+
+type fakeUserMFARepository struct {
+	repository.UserMFARepository
+	mfa           *entity.UserMFA
+	recoveryCodes map[string]bool
+}
+
+func (r *fakeUserMFARepository) GetByUserId(ctx context.Context, userId int64) (*entity.UserMFA, error) {
+	if r.mfa == nil || r.mfa.UserId != userId {
+		return nil, nil
+	}
+	found := *r.mfa
+	return &found, nil
+}
+
+func (r *fakeUserMFARepository) Create(ctx context.Context, mfa *entity.UserMFA) error {
+	stored := *mfa
+	r.mfa = &stored
+	return nil
+}
+
+func (r *fakeUserMFARepository) Update(ctx context.Context, mfa *entity.UserMFA) error {
+	stored := *mfa
+	r.mfa = &stored
+	return nil
+}
+
+func (r *fakeUserMFARepository) MarkStepUsed(ctx context.Context, userId int64, step int64) (bool, error) {
+	if r.mfa.LastUsedStep >= step {
+		return false, nil
+	}
+	r.mfa.LastUsedStep = step
+	return true, nil
+}
+
+func (r *fakeUserMFARepository) ReplaceRecoveryCodes(ctx context.Context, userId int64, codeHashes []string) error {
+	r.recoveryCodes = map[string]bool{}
+	for _, codeHash := range codeHashes {
+		r.recoveryCodes[codeHash] = false
+	}
+	return nil
+}
+
+func (r *fakeUserMFARepository) UseRecoveryCode(ctx context.Context, userId int64, codeHash string) (bool, error) {
+	used, ok := r.recoveryCodes[codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	r.recoveryCodes[codeHash] = true
+	return true, nil
+}
+
+type fakeMFAChallengeRepository struct {
+	repository.MFAChallengeRepository
+	challenges []*entity.MFAChallenge
+}
+
+func (r *fakeMFAChallengeRepository) Create(ctx context.Context, challenge *entity.MFAChallenge) error {
+	challenge.Id = int64(len(r.challenges) + 1)
+	r.challenges = append(r.challenges, challenge)
+	return nil
+}
+
+func (r *fakeMFAChallengeRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.MFAChallenge, error) {
+	for _, challenge := range r.challenges {
+		if challenge.TokenHash == tokenHash {
+			found := *challenge
+			return &found, nil
+		}
+	}
+	return nil, assert.AnError
+}
+
+func (r *fakeMFAChallengeRepository) IncrementAttempts(ctx context.Context, challenge *entity.MFAChallenge) error {
+	r.challenges[challenge.Id-1].Attempts++
+	challenge.Attempts++
+	return nil
+}
+
+func (r *fakeMFAChallengeRepository) MarkUsed(ctx context.Context, challenge *entity.MFAChallenge) (bool, error) {
+	stored := r.challenges[challenge.Id-1]
+	if stored.UsedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	stored.UsedAt = &now
+	challenge.UsedAt = &now
+	return true, nil
+}
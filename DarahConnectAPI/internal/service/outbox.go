@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/mailer"
+)
+
+// Jenis event outbox yang dikirim oleh dispatcher
+const (
+	OutboxCertificateMint    = "certificate.mint"
+	OutboxEmailSend          = "email.send"
+	OutboxNotificationCreate = "notification.create"
+)
+
+type CertificateMintPayload struct {
+	CertificateId int64 `json:"certificate_id"`
+}
+
+type EmailSendPayload struct {
+	To       string                 `json:"to"`
+	Subject  string                 `json:"subject"`
+	Template string                 `json:"template"`
+	Data     map[string]interface{} `json:"data"`
+}
+
+type NotificationCreatePayload struct {
+	UserId           int64  `json:"user_id"`
+	Title            string `json:"title"`
+	Message          string `json:"message"`
+	NotificationType string `json:"notification_type"`
+}
+
+// OutboxHandler memproses payload satu pesan outbox. Handler harus idempoten karena
+// pesan bisa dikirim ulang jika worker berhenti sebelum pesan ditandai selesai.
+type OutboxHandler func(ctx context.Context, payload []byte) error
+
+type OutboxService interface {
+	Enqueue(ctx context.Context, eventType, aggregateType string, aggregateId int64, payload interface{}) error
+	Notify(ctx context.Context, payload NotificationCreatePayload) error
+	SendEmail(ctx context.Context, payload EmailSendPayload) error
+}
+
+type outboxService struct {
+	outboxRepository repository.OutboxRepository
+}
+
+func NewOutboxService(outboxRepository repository.OutboxRepository) OutboxService {
+	return &outboxService{outboxRepository}
+}
+
+// Enqueue menyimpan pesan outbox. Panggil di dalam Transactor.WithinTransaction agar pesan
+// hanya tersimpan jika perubahan data utamanya juga tersimpan.
+func (s *outboxService) Enqueue(ctx context.Context, eventType, aggregateType string, aggregateId int64, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("gagal membuat payload outbox: %w", err)
+	}
+
+	message := &entity.OutboxMessage{
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateId:   aggregateId,
+		Payload:       string(body),
+		Status:        "pending",
+		NextAttemptAt: time.Now(),
+	}
+	if err := s.outboxRepository.Create(ctx, message); err != nil {
+		return errors.New("Gagal menyimpan pesan outbox")
+	}
+	return nil
+}
+
+func (s *outboxService) Notify(ctx context.Context, payload NotificationCreatePayload) error {
+	return s.Enqueue(ctx, OutboxNotificationCreate, "user", payload.UserId, payload)
+}
+
+func (s *outboxService) SendEmail(ctx context.Context, payload EmailSendPayload) error {
+	return s.Enqueue(ctx, OutboxEmailSend, "email", 0, payload)
+}
+
+// NewOutboxHandlers memetakan jenis event outbox ke service yang mengerjakannya
+func NewOutboxHandlers(
+	certificateService CertificateService,
+	notificationService NotificationService,
+	emailSender *mailer.Mailer,
+) map[string]OutboxHandler {
+	return map[string]OutboxHandler{
+		OutboxCertificateMint: func(ctx context.Context, payload []byte) error {
+			var data CertificateMintPayload
+			if err := json.Unmarshal(payload, &data); err != nil {
+				return err
+			}
+			return certificateService.Mint(ctx, data.CertificateId)
+		},
+		OutboxNotificationCreate: func(ctx context.Context, payload []byte) error {
+			var data NotificationCreatePayload
+			if err := json.Unmarshal(payload, &data); err != nil {
+				return err
+			}
+			return notificationService.Create(ctx, dto.NotificationCreateRequest{
+				UserId:           data.UserId,
+				Title:            data.Title,
+				Message:          data.Message,
+				NotificationType: data.NotificationType,
+			})
+		},
+		OutboxEmailSend: func(ctx context.Context, payload []byte) error {
+			var data EmailSendPayload
+			if err := json.Unmarshal(payload, &data); err != nil {
+				return err
+			}
+			return emailSender.SendEmail("./templates/email/"+data.Template, mailer.EmailData{
+				To:       data.To,
+				Subject:  data.Subject,
+				Template: data.Template,
+				Data:     data.Data,
+			})
+		},
+	}
+}
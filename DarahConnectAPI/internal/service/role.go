@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/audit"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/rbac"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("Role tidak ditemukan")
+	ErrRoleExists        = errors.New("Role dengan nama tersebut sudah ada")
+	ErrRoleSystem        = errors.New("Role bawaan tidak dapat dihapus")
+	ErrRoleAdministrator = errors.New("Permission role Administrator tidak dapat diubah")
+	ErrRoleInUse         = errors.New("Role masih dipakai oleh pengguna")
+)
+
+type RoleService interface {
+	// Load memuat ulang permission setiap role dari database ke Authorizer
+	Load(ctx context.Context) error
+	GetAll(ctx context.Context) ([]entity.Role, error)
+	GetPermissions(ctx context.Context) ([]entity.Permission, error)
+	Create(ctx context.Context, req dto.CreateRoleRequest) (*entity.Role, error)
+	Update(ctx context.Context, req dto.UpdateRoleRequest) (*entity.Role, error)
+	Delete(ctx context.Context, id int64) error
+	AssignRole(ctx context.Context, req dto.AssignRoleRequest) (*entity.User, error)
+}
+
+type roleService struct {
+	roleRepository  repository.RoleRepository
+	userRepository  repository.UserRepository
+	transactor      repository.Transactor
+	authorizer      *rbac.Authorizer
+	auditLogService AuditLogService
+}
+
+func NewRoleService(
+	roleRepository repository.RoleRepository,
+	userRepository repository.UserRepository,
+	transactor repository.Transactor,
+	authorizer *rbac.Authorizer,
+	auditLogService AuditLogService,
+) RoleService {
+	return &roleService{roleRepository, userRepository, transactor, authorizer, auditLogService}
+}
+
+func (s *roleService) Load(ctx context.Context) error {
+	roles, err := s.roleRepository.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	rolePermissions := make(map[string][]string, len(roles))
+	for _, role := range roles {
+		permissions := make([]string, 0, len(role.Permissions))
+		for _, permission := range role.Permissions {
+			permissions = append(permissions, permission.Name)
+		}
+		rolePermissions[role.Name] = permissions
+	}
+	s.authorizer.Replace(rolePermissions)
+	return nil
+}
+
+func (s *roleService) GetAll(ctx context.Context) ([]entity.Role, error) {
+	roles, err := s.roleRepository.GetAll(ctx)
+	if err != nil {
+		return nil, errors.New("Gagal mendapatkan data role")
+	}
+	return roles, nil
+}
+
+func (s *roleService) GetPermissions(ctx context.Context) ([]entity.Permission, error) {
+	permissions, err := s.roleRepository.GetPermissions(ctx)
+	if err != nil {
+		return nil, errors.New("Gagal mendapatkan data permission")
+	}
+	return permissions, nil
+}
+
+func (s *roleService) Create(ctx context.Context, req dto.CreateRoleRequest) (*entity.Role, error) {
+	name := strings.TrimSpace(req.Name)
+	if existing, err := s.roleRepository.GetByName(ctx, name); err == nil && existing != nil {
+		return nil, ErrRoleExists
+	}
+
+	permissions, err := s.permissions(ctx, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &entity.Role{
+		Name:        name,
+		Description: req.Description,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.roleRepository.Create(ctx, role); err != nil {
+			return errors.New("Gagal membuat role")
+		}
+		if err := s.replacePermissions(ctx, role, permissions); err != nil {
+			return err
+		}
+		return s.auditLogService.Record(ctx, audit.Entry{Action: "role.create", EntityType: "role", EntityId: role.Id, After: role})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.reload(ctx)
+	return role, nil
+}
+
+func (s *roleService) Update(ctx context.Context, req dto.UpdateRoleRequest) (*entity.Role, error) {
+	role, err := s.roleRepository.GetById(ctx, req.Id)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+	// Administrator selalu memegang semua permission agar tidak ada yang terkunci dari pengelolaan role
+	if role.Name == rbac.RoleAdministrator {
+		return nil, ErrRoleAdministrator
+	}
+
+	permissions, err := s.permissions(ctx, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *role
+	role.Description = req.Description
+	role.UpdatedAt = time.Now()
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.roleRepository.Update(ctx, role); err != nil {
+			return errors.New("Gagal memperbarui role")
+		}
+		if err := s.replacePermissions(ctx, role, permissions); err != nil {
+			return err
+		}
+		return s.auditLogService.Record(ctx, audit.Entry{Action: "role.update", EntityType: "role", EntityId: role.Id, Before: before, After: role})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.reload(ctx)
+	return role, nil
+}
+
+func (s *roleService) Delete(ctx context.Context, id int64) error {
+	role, err := s.roleRepository.GetById(ctx, id)
+	if err != nil {
+		return ErrRoleNotFound
+	}
+	if role.IsSystem {
+		return ErrRoleSystem
+	}
+
+	count, err := s.roleRepository.CountUsers(ctx, role.Name)
+	if err != nil {
+		return errors.New("Gagal memeriksa pengguna role")
+	}
+	if count > 0 {
+		return ErrRoleInUse
+	}
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.roleRepository.Delete(ctx, role); err != nil {
+			return errors.New("Gagal menghapus role")
+		}
+		return s.auditLogService.Record(ctx, audit.Entry{Action: "role.delete", EntityType: "role", EntityId: role.Id, Before: role})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.reload(ctx)
+	return nil
+}
+
+func (s *roleService) AssignRole(ctx context.Context, req dto.AssignRoleRequest) (*entity.User, error) {
+	role, err := s.roleRepository.GetByName(ctx, req.Role)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	user, err := s.userRepository.GetById(ctx, req.Id)
+	if err != nil {
+		return nil, errors.New("Pengguna tidak ditemukan")
+	}
+
+	before := *user
+	user.Role = role.Name
+	user.UpdatedAt = time.Now()
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepository.Update(ctx, user); err != nil {
+			return errors.New("Gagal memperbarui role pengguna")
+		}
+		return s.auditLogService.Record(ctx, audit.Entry{Action: "user.role", EntityType: "user", EntityId: user.Id, Before: before, After: user})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// permissions memastikan semua permission yang diminta terdaftar di database
+func (s *roleService) permissions(ctx context.Context, names []string) ([]entity.Permission, error) {
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	permissions, err := s.roleRepository.GetPermissionsByName(ctx, names)
+	if err != nil {
+		return nil, errors.New("Gagal mendapatkan data permission")
+	}
+	if len(permissions) != len(names) {
+		for _, name := range names {
+			if !slices.ContainsFunc(permissions, func(permission entity.Permission) bool { return permission.Name == name }) {
+				return nil, fmt.Errorf("Permission %s tidak dikenal", name)
+			}
+		}
+	}
+	return permissions, nil
+}
+
+func (s *roleService) replacePermissions(ctx context.Context, role *entity.Role, permissions []entity.Permission) error {
+	permissionIds := make([]int64, 0, len(permissions))
+	for _, permission := range permissions {
+		permissionIds = append(permissionIds, permission.Id)
+	}
+	if err := s.roleRepository.ReplacePermissions(ctx, role.Id, permissionIds); err != nil {
+		return errors.New("Gagal menyimpan permission role")
+	}
+	role.Permissions = permissions
+	return nil
+}
+
+// reload menerapkan perubahan di instance ini seketika; instance lain menyusul lewat PermissionReloader
+func (s *roleService) reload(ctx context.Context) {
+	if err := s.Load(ctx); err != nil {
+		log.Printf("Gagal memuat ulang permission: %v", err)
+	}
+}
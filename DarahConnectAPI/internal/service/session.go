@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/revocation"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/token"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("Refresh token tidak valid atau sudah kedaluwarsa")
+	ErrRefreshTokenReused  = errors.New("Refresh token sudah pernah dipakai, silahkan login kembali")
+)
+
+type SessionService interface {
+	Issue(ctx context.Context, user *entity.User) (*dto.TokenResponse, error)
+	Refresh(ctx context.Context, refreshToken string) (*dto.TokenResponse, error)
+	Logout(ctx context.Context, claims *token.JwtCustomClaims, refreshToken string) error
+	LogoutAll(ctx context.Context, userId int64) error
+}
+
+type sessionService struct {
+	refreshTokenRepository repository.RefreshTokenRepository
+	userRepository         repository.UserRepository
+	transactor             repository.Transactor
+	revocations            revocation.Store
+	tokenUseCase           token.TokenUseCase
+	cfg                    *configs.JWTConfig
+}
+
+func NewSessionService(
+	refreshTokenRepository repository.RefreshTokenRepository,
+	userRepository repository.UserRepository,
+	transactor repository.Transactor,
+	revocations revocation.Store,
+	tokenUseCase token.TokenUseCase,
+	cfg *configs.JWTConfig,
+) SessionService {
+	return &sessionService{refreshTokenRepository, userRepository, transactor, revocations, tokenUseCase, cfg}
+}
+
+// Issue memulai sesi baru dengan family refresh token baru
+func (s *sessionService) Issue(ctx context.Context, user *entity.User) (*dto.TokenResponse, error) {
+	response, _, err := s.issue(ctx, user, uuid.NewString())
+	return response, err
+}
+
+// Refresh menukar refresh token dengan pasangan token baru. Refresh token yang sudah dirotasi
+// lalu dipakai lagi berarti token tersebut bocor, sehingga seluruh family-nya dicabut.
+func (s *sessionService) Refresh(ctx context.Context, refreshToken string) (*dto.TokenResponse, error) {
+	current, err := s.refreshTokenRepository.GetByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if current.RevokedAt != nil {
+		if current.ReplacedById != nil {
+			s.revokeFamily(ctx, current.FamilyId)
+			return nil, ErrRefreshTokenReused
+		}
+		return nil, ErrRefreshTokenInvalid
+	}
+	if time.Now().After(current.ExpiresAt) {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	user, err := s.userRepository.GetById(ctx, current.UserId)
+	if err != nil {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	var response *dto.TokenResponse
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		issued, next, err := s.issue(ctx, user, current.FamilyId)
+		if err != nil {
+			return err
+		}
+		rotated, err := s.refreshTokenRepository.Rotate(ctx, current, next.Id)
+		if err != nil {
+			return errors.New("Gagal memperbarui sesi")
+		}
+		// Permintaan lain sudah merotasi token ini lebih dulu
+		if !rotated {
+			return ErrRefreshTokenReused
+		}
+		response = issued
+		return nil
+	})
+	if errors.Is(err, ErrRefreshTokenReused) {
+		s.revokeFamily(ctx, current.FamilyId)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Logout mencabut access token yang sedang dipakai dan, jika dikirim, family refresh token-nya
+func (s *sessionService) Logout(ctx context.Context, claims *token.JwtCustomClaims, refreshToken string) error {
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		if err := s.revocations.RevokeToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			return errors.New("Gagal mencabut token")
+		}
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+	current, err := s.refreshTokenRepository.GetByHash(ctx, hashToken(refreshToken))
+	if err != nil || current.UserId != claims.Id {
+		return nil
+	}
+	if err := s.refreshTokenRepository.RevokeFamily(ctx, current.FamilyId); err != nil {
+		return errors.New("Gagal mencabut refresh token")
+	}
+	return nil
+}
+
+// LogoutAll mencabut semua refresh token pengguna dan semua access token yang terbit sampai saat ini
+func (s *sessionService) LogoutAll(ctx context.Context, userId int64) error {
+	if err := s.refreshTokenRepository.RevokeByUser(ctx, userId); err != nil {
+		return errors.New("Gagal mencabut refresh token")
+	}
+
+	now := time.Now()
+	if err := s.revocations.RevokeUser(ctx, userId, now, now.Add(s.cfg.AccessTTL)); err != nil {
+		return errors.New("Gagal mencabut token")
+	}
+	return nil
+}
+
+func (s *sessionService) issue(ctx context.Context, user *entity.User, familyId string) (*dto.TokenResponse, *entity.RefreshToken, error) {
+	now := time.Now()
+	jti := uuid.NewString()
+
+	claims := token.JwtCustomClaims{
+		Id:       user.Id,
+		Email:    user.Email,
+		Name:     user.Name,
+		Role:     user.Role,
+		Metamask: user.WalletAddress != "",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Issuer:    "Darah Connect",
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.cfg.AccessTTL)),
+		},
+	}
+	accessToken, err := s.tokenUseCase.GenerateAccessToken(claims)
+	if err != nil {
+		return nil, nil, errors.New("ada kesalahan di server")
+	}
+
+	refreshToken, err := newOpaqueToken()
+	if err != nil {
+		return nil, nil, errors.New("ada kesalahan di server")
+	}
+	stored := &entity.RefreshToken{
+		UserId:    user.Id,
+		FamilyId:  familyId,
+		TokenHash: hashToken(refreshToken),
+		AccessJti: jti,
+		ExpiresAt: now.Add(s.cfg.RefreshTTL),
+		CreatedAt: now,
+	}
+	if err := s.refreshTokenRepository.Create(ctx, stored); err != nil {
+		return nil, nil, errors.New("Gagal menyimpan refresh token")
+	}
+
+	return &dto.TokenResponse{
+		Token:            accessToken,
+		RefreshToken:     refreshToken,
+		ExpiresIn:        int64(s.cfg.AccessTTL.Seconds()),
+		RefreshExpiresAt: stored.ExpiresAt,
+	}, stored, nil
+}
+
+// revokeFamily mencabut seluruh refresh token dalam family beserta access token yang terbit
+// bersamanya dan mungkin masih berlaku. Kegagalan hanya dicatat karena klien tetap ditolak.
+func (s *sessionService) revokeFamily(ctx context.Context, familyId string) {
+	if err := s.refreshTokenRepository.RevokeFamily(ctx, familyId); err != nil {
+		log.Printf("Gagal mencabut family refresh token %s: %v", familyId, err)
+	}
+
+	refreshTokens, err := s.refreshTokenRepository.GetByFamily(ctx, familyId, time.Now().Add(-s.cfg.AccessTTL))
+	if err != nil {
+		log.Printf("Gagal membaca family refresh token %s: %v", familyId, err)
+		return
+	}
+	for _, refreshToken := range refreshTokens {
+		if err := s.revocations.RevokeToken(ctx, refreshToken.AccessJti, refreshToken.CreatedAt.Add(s.cfg.AccessTTL)); err != nil {
+			log.Printf("Gagal mencabut access token %s: %v", refreshToken.AccessJti, err)
+		}
+	}
+}
+
+// newOpaqueToken membuat token acak 256 bit; database hanya menyimpan hashToken-nya
+func newOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
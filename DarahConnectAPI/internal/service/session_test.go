@@ -0,0 +1,176 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRefreshRotationAndReuse(t *testing.T) {
+	ctx := context.Background()
+	refreshTokens := &fakeRefreshTokenRepository{}
+	revocations := newFakeRevocationStore()
+	users := &fakeUserRepository{user: &entity.User{Id: 7, Name: "Budi", Email: "budi@example.com", Role: "User"}}
+	keys := newTestKeySet(t)
+
+	sessionService := service.NewSessionService(refreshTokens, users, passthroughTransactor{}, revocations, token.NewTokenUseCase(keys), &configs.JWTConfig{
+		AccessTTL:  15 * time.Minute,
+		RefreshTTL: time.Hour,
+	})
+
+	first, err := sessionService.Issue(ctx, users.user)
+	require.NoError(t, err)
+	assert.Equal(t, int64(900), first.ExpiresIn)
+	firstClaims := parseClaims(t, keys, first.Token)
+	assert.Equal(t, int64(7), firstClaims.Id)
+	assert.NotEmpty(t, firstClaims.ID)
+
+	second, err := sessionService.Refresh(ctx, first.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+	secondClaims := parseClaims(t, keys, second.Token)
+	require.Len(t, refreshTokens.tokens, 2)
+	assert.Equal(t, refreshTokens.tokens[0].FamilyId, refreshTokens.tokens[1].FamilyId)
+
+	// Refresh token lama dipakai lagi: seluruh family dan access token-nya dicabut
+	_, err = sessionService.Refresh(ctx, first.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrRefreshTokenReused)
+	_, err = sessionService.Refresh(ctx, second.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrRefreshTokenInvalid)
+	assert.Contains(t, revocations.tokens, firstClaims.ID)
+	assert.Contains(t, revocations.tokens, secondClaims.ID)
+
+	_, err = sessionService.Refresh(ctx, "tidak-dikenal")
+	assert.ErrorIs(t, err, service.ErrRefreshTokenInvalid)
+
+	// Logout mencabut access token saat ini dan family refresh token-nya
+	third, err := sessionService.Issue(ctx, users.user)
+	require.NoError(t, err)
+	thirdClaims := parseClaims(t, keys, third.Token)
+	require.NoError(t, sessionService.Logout(ctx, thirdClaims, third.RefreshToken))
+	assert.Contains(t, revocations.tokens, thirdClaims.ID)
+	_, err = sessionService.Refresh(ctx, third.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrRefreshTokenInvalid)
+
+	fourth, err := sessionService.Issue(ctx, users.user)
+	require.NoError(t, err)
+	require.NoError(t, sessionService.LogoutAll(ctx, 7))
+	assert.Contains(t, revocations.users, int64(7))
+	_, err = sessionService.Refresh(ctx, fourth.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrRefreshTokenInvalid)
+}
+
+func newTestKeySet(t *testing.T) *token.KeySet {
+	privateKey, err := token.GenerateKey(token.AlgorithmEdDSA)
+	require.NoError(t, err)
+	return token.NewKeySet(token.Key{
+		Kid:         "test",
+		Algorithm:   token.AlgorithmEdDSA,
+		PrivateKey:  privateKey,
+		ActivatesAt: time.Now().Add(-time.Minute),
+	})
+}
+
+func parseClaims(t *testing.T, keys *token.KeySet, accessToken string) *token.JwtCustomClaims {
+	claims := new(token.JwtCustomClaims)
+	_, err := jwt.ParseWithClaims(accessToken, claims, keys.Keyfunc)
+	require.NoError(t, err)
+	return claims
+}
+
+type fakeRefreshTokenRepository struct {
+	repository.RefreshTokenRepository
+	tokens []*entity.RefreshToken
+}
+
+func (r *fakeRefreshTokenRepository) Create(ctx context.Context, refreshToken *entity.RefreshToken) error {
+	refreshToken.Id = int64(len(r.tokens) + 1)
+	r.tokens = append(r.tokens, refreshToken)
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	for _, refreshToken := range r.tokens {
+		if refreshToken.TokenHash == tokenHash {
+			found := *refreshToken
+			return &found, nil
+		}
+	}
+	return nil, assert.AnError
+}
+
+func (r *fakeRefreshTokenRepository) Rotate(ctx context.Context, refreshToken *entity.RefreshToken, replacedById int64) (bool, error) {
+	stored := r.tokens[refreshToken.Id-1]
+	if stored.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	stored.RevokedAt = &now
+	stored.ReplacedById = &replacedById
+	return true, nil
+}
+
+func (r *fakeRefreshTokenRepository) GetByFamily(ctx context.Context, familyId string, createdAfter time.Time) ([]entity.RefreshToken, error) {
+	var result []entity.RefreshToken
+	for _, refreshToken := range r.tokens {
+		if refreshToken.FamilyId == familyId && refreshToken.CreatedAt.After(createdAfter) {
+			result = append(result, *refreshToken)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeRefreshTokenRepository) RevokeFamily(ctx context.Context, familyId string) error {
+	r.revoke(func(refreshToken *entity.RefreshToken) bool { return refreshToken.FamilyId == familyId })
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) RevokeByUser(ctx context.Context, userId int64) error {
+	r.revoke(func(refreshToken *entity.RefreshToken) bool { return refreshToken.UserId == userId })
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) revoke(match func(*entity.RefreshToken) bool) {
+	now := time.Now()
+	for _, refreshToken := range r.tokens {
+		if match(refreshToken) && refreshToken.RevokedAt == nil {
+			refreshToken.RevokedAt = &now
+		}
+	}
+}
+
+type fakeRevocationStore struct {
+	tokens map[string]time.Time
+	users  map[int64]time.Time
+}
+
+func newFakeRevocationStore() *fakeRevocationStore {
+	return &fakeRevocationStore{tokens: map[string]time.Time{}, users: map[int64]time.Time{}}
+}
+
+func (s *fakeRevocationStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	s.tokens[jti] = expiresAt
+	return nil
+}
+
+func (s *fakeRevocationStore) RevokeUser(ctx context.Context, userId int64, revokedAt, expiresAt time.Time) error {
+	s.users[userId] = revokedAt
+	return nil
+}
+
+func (s *fakeRevocationStore) IsRevoked(ctx context.Context, jti string, userId int64, issuedAt time.Time) (bool, error) {
+	if _, ok := s.tokens[jti]; ok {
+		return true, nil
+	}
+	revokedAt, ok := s.users[userId]
+	return ok && !issuedAt.After(revokedAt), nil
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/token"
+)
+
+type SigningKeyService interface {
+	// Rotate membuat kunci baru jika belum ada atau sudah waktunya diganti, menetapkan masa
+	// berlaku kunci yang digantikan, lalu memuat ulang KeySet dari database.
+	Rotate(ctx context.Context) error
+}
+
+type signingKeyService struct {
+	signingKeyRepository repository.SigningKeyRepository
+	transactor           repository.Transactor
+	keys                 *token.KeySet
+	cfg                  *configs.JWTConfig
+	sealingCfg           *configs.SealingConfig
+}
+
+func NewSigningKeyService(
+	signingKeyRepository repository.SigningKeyRepository,
+	transactor repository.Transactor,
+	keys *token.KeySet,
+	cfg *configs.JWTConfig,
+	sealingCfg *configs.SealingConfig,
+) SigningKeyService {
+	return &signingKeyService{signingKeyRepository, transactor, keys, cfg, sealingCfg}
+}
+
+func (s *signingKeyService) Rotate(ctx context.Context) error {
+	var loaded []token.Key
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.signingKeyRepository.Lock(ctx); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		stored, err := s.signingKeyRepository.GetUnexpired(ctx, now)
+		if err != nil {
+			return err
+		}
+		loaded = s.open(stored)
+
+		if s.rotationDue(loaded, now) {
+			activatesAt := now.Add(s.cfg.KeyPublishAhead)
+			// Tanpa kunci aktif token tidak bisa diterbitkan, jadi kunci pertama langsung dipakai
+			if len(loaded) == 0 {
+				activatesAt = now
+			}
+			key, err := s.create(ctx, activatesAt)
+			if err != nil {
+				return err
+			}
+			stored = append(stored, *key)
+			loaded = s.open(stored)
+		}
+
+		if err := s.expireSuperseded(ctx, stored, loaded, now); err != nil {
+			return err
+		}
+		return s.signingKeyRepository.DeleteExpired(ctx, now)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.keys.Replace(loaded)
+	return nil
+}
+
+func (s *signingKeyService) rotationDue(loaded []token.Key, now time.Time) bool {
+	if len(loaded) == 0 {
+		return true
+	}
+	newest := loaded[len(loaded)-1]
+	if newest.Algorithm != s.cfg.Algorithm {
+		return true
+	}
+	return !now.Before(newest.ActivatesAt.Add(s.cfg.KeyRotationInterval - s.cfg.KeyPublishAhead))
+}
+
+func (s *signingKeyService) create(ctx context.Context, activatesAt time.Time) (*entity.SigningKey, error) {
+	privateKey, err := token.GenerateKey(s.cfg.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := token.SealPrivateKey(privateKey, s.sealingCfg.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &entity.SigningKey{
+		Kid:         uuid.NewString(),
+		Algorithm:   s.cfg.Algorithm,
+		PrivateKey:  sealed,
+		ActivatesAt: activatesAt,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.signingKeyRepository.Create(ctx, key); err != nil {
+		return nil, err
+	}
+	log.Printf("Signing key %s (%s) dibuat, aktif mulai %s", key.Kid, key.Algorithm, key.ActivatesAt.Format(time.RFC3339))
+	return key, nil
+}
+
+// expireSuperseded memberi batas berlaku pada kunci yang sudah digantikan kunci aktif berikutnya.
+// Kunci lama tetap diterima selama umur token terpanjang yang mungkin ditandatanganinya.
+func (s *signingKeyService) expireSuperseded(ctx context.Context, stored []entity.SigningKey, loaded []token.Key, now time.Time) error {
+	for i := range stored {
+		key := &stored[i]
+		if key.ExpiresAt != nil {
+			continue
+		}
+		for _, next := range loaded {
+			if next.ActivatesAt.After(key.ActivatesAt) && !next.ActivatesAt.After(now) {
+				expiresAt := next.ActivatesAt.Add(s.cfg.AccessTTL)
+				key.ExpiresAt = &expiresAt
+				if err := s.signingKeyRepository.Update(ctx, key); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+
+	for i := range loaded {
+		for _, key := range stored {
+			if key.Kid == loaded[i].Kid {
+				loaded[i].ExpiresAt = key.ExpiresAt
+			}
+		}
+	}
+	return nil
+}
+
+// open mendekripsi kunci yang tersimpan. Kunci yang tidak bisa dibuka, misalnya karena
+// SEALING_KEY berubah, dilewati sehingga kunci baru dibuat.
+func (s *signingKeyService) open(stored []entity.SigningKey) []token.Key {
+	keys := make([]token.Key, 0, len(stored))
+	for _, key := range stored {
+		privateKey, err := token.OpenPrivateKey(key.PrivateKey, s.sealingCfg.Key)
+		if err != nil {
+			log.Printf("Signing key %s dilewati: %v", key.Kid, err)
+			continue
+		}
+		keys = append(keys, token.Key{
+			Kid:         key.Kid,
+			Algorithm:   key.Algorithm,
+			PrivateKey:  privateKey,
+			ActivatesAt: key.ActivatesAt,
+			ExpiresAt:   key.ExpiresAt,
+		})
+	}
+	return keys
+}
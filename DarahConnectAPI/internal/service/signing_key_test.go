@@ -0,0 +1,96 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigningKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	signingKeys := &fakeSigningKeyRepository{}
+	keys := token.NewKeySet()
+	cfg := &configs.JWTConfig{
+		Algorithm:           token.AlgorithmEdDSA,
+		AccessTTL:           15 * time.Minute,
+		KeyRotationInterval: 720 * time.Hour,
+		KeyPublishAhead:     time.Hour,
+	}
+	signingKeyService := service.NewSigningKeyService(signingKeys, passthroughTransactor{}, keys, cfg, &configs.SealingConfig{Key: "signing-key-test-sealing-key-0123456789"})
+
+	// Kunci pertama langsung aktif
+	require.NoError(t, signingKeyService.Rotate(ctx))
+	require.Len(t, signingKeys.keys, 1)
+	first, err := keys.SigningKey()
+	require.NoError(t, err)
+	assert.Equal(t, signingKeys.keys[0].Kid, first.Kid)
+
+	// Belum waktunya rotasi
+	require.NoError(t, signingKeyService.Rotate(ctx))
+	require.Len(t, signingKeys.keys, 1)
+
+	// Mendekati jadwal rotasi: kunci berikutnya dipublikasikan lebih dulu, kunci lama tetap menandatangani
+	signingKeys.keys[0].ActivatesAt = time.Now().Add(-cfg.KeyRotationInterval + 30*time.Minute)
+	require.NoError(t, signingKeyService.Rotate(ctx))
+	require.Len(t, signingKeys.keys, 2)
+	assert.Len(t, keys.JWKS().Keys, 2)
+	current, err := keys.SigningKey()
+	require.NoError(t, err)
+	assert.Equal(t, first.Kid, current.Kid)
+	assert.Nil(t, signingKeys.keys[0].ExpiresAt)
+
+	// Setelah kunci berikutnya aktif, kunci lama hanya diterima selama umur access token
+	signingKeys.keys[1].ActivatesAt = time.Now().Add(-time.Minute)
+	require.NoError(t, signingKeyService.Rotate(ctx))
+	current, err = keys.SigningKey()
+	require.NoError(t, err)
+	assert.Equal(t, signingKeys.keys[1].Kid, current.Kid)
+	require.NotNil(t, signingKeys.keys[0].ExpiresAt)
+	assert.WithinDuration(t, signingKeys.keys[1].ActivatesAt.Add(cfg.AccessTTL), *signingKeys.keys[0].ExpiresAt, time.Second)
+
+	// Mengganti algoritma langsung menjadwalkan kunci baru
+	cfg.Algorithm = token.AlgorithmRS256
+	require.NoError(t, signingKeyService.Rotate(ctx))
+	require.Len(t, signingKeys.keys, 3)
+	assert.Equal(t, token.AlgorithmRS256, signingKeys.keys[2].Algorithm)
+}
+
+type fakeSigningKeyRepository struct {
+	keys []entity.SigningKey
+}
+
+func (r *fakeSigningKeyRepository) Lock(ctx context.Context) error {
+	return nil
+}
+
+func (r *fakeSigningKeyRepository) GetUnexpired(ctx context.Context, now time.Time) ([]entity.SigningKey, error) {
+	var result []entity.SigningKey
+	for _, key := range r.keys {
+		if key.ExpiresAt == nil || key.ExpiresAt.After(now) {
+			result = append(result, key)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeSigningKeyRepository) Create(ctx context.Context, key *entity.SigningKey) error {
+	key.Id = int64(len(r.keys) + 1)
+	r.keys = append(r.keys, *key)
+	return nil
+}
+
+func (r *fakeSigningKeyRepository) Update(ctx context.Context, key *entity.SigningKey) error {
+	r.keys[key.Id-1] = *key
+	return nil
+}
+
+func (r *fakeSigningKeyRepository) DeleteExpired(ctx context.Context, now time.Time) error {
+	return nil
+}
@@ -11,8 +11,11 @@ import (
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/audit"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/cloudinary"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/compatibility"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/mailer"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/rbac"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/timezone"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/token"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/utils"
@@ -24,7 +27,8 @@ import (
 type UserService interface {
 	GetAll(ctx context.Context, req dto.GetAllUserRequest) ([]entity.User, int64, error)
 	GetById(ctx context.Context, id int64) (*entity.User, error)
-	Login(ctx context.Context, email, password string) (string, bool, error)
+	GetCompatibleDonors(ctx context.Context, recipientBloodType string, req dto.GetAllUserRequest) ([]entity.User, int64, error)
+	Login(ctx context.Context, email, password string) (*entity.User, error)
 	Register(ctx context.Context, req dto.UserRegisterRequest) error
 	CheckGoogleOAuth(ctx context.Context, email string, user *goth.User) (*entity.User, bool, error)
 	Update(ctx context.Context, req dto.UpdateUserRequest) error
@@ -33,7 +37,6 @@ type UserService interface {
 	VerifyEmail(ctx context.Context, req dto.VerifyEmailRequest) error
 	RequestResetPassword(ctx context.Context, email string) error
 	ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) error
-	WalletAddress(ctx context.Context, user *entity.User, req dto.WalletAddressRequest) error
 }
 
 type userService struct {
@@ -42,6 +45,8 @@ type userService struct {
 	mailer            *mailer.Mailer
 	cfg               *configs.Config
 	cloudinaryService *cloudinary.Service
+	transactor        repository.Transactor
+	auditLogService   AuditLogService
 }
 
 func NewUserService(
@@ -50,49 +55,25 @@ func NewUserService(
 	cfg *configs.Config,
 	mailer *mailer.Mailer,
 	cloudinaryService *cloudinary.Service,
+	transactor repository.Transactor,
+	auditLogService AuditLogService,
 ) UserService {
-	return &userService{userRepository, tokenUseCase, mailer, cfg, cloudinaryService}
+	return &userService{userRepository, tokenUseCase, mailer, cfg, cloudinaryService, transactor, auditLogService}
 }
 
-func (s *userService) Login(ctx context.Context, email string, password string) (string, bool, error) {
-	metamask := true
+// Login memeriksa email dan password. Token diterbitkan oleh SessionService setelah
+// email pengguna terverifikasi.
+func (s *userService) Login(ctx context.Context, email string, password string) (*entity.User, error) {
 	user, err := s.userRepository.GetByEmail(ctx, email)
 	if err != nil {
-		return "", false, errors.New("Email atau password salah")
-	}
-	if user.WalletAddress == "" {
-		metamask = false
+		return nil, errors.New("Email atau password salah")
 	}
 
 	if bcryptErr := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); bcryptErr != nil {
-		return "", false, errors.New("Email atau password salah")
-	}
-
-	if !user.IsVerified {
-		TokenExpiresAt := user.TokenExpiresAt.Format(time.RFC3339)
-		return TokenExpiresAt, false, nil
-	}
-
-	expiredTime := time.Now().Add(time.Hour * 12)
-
-	claims := token.JwtCustomClaims{
-		Id:    user.Id,
-		Email: user.Email,
-		Name:  user.Name,
-		Role:  user.Role,
-		Metamask: metamask,
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    "Darah Connect",
-			ExpiresAt: jwt.NewNumericDate(expiredTime),
-		},
-	}
-
-	token, err := s.tokenUseCase.GenerateAccessToken(claims)
-	if err != nil {
-		return "", false, errors.New("ada kesalahan di server")
+		return nil, errors.New("Email atau password salah")
 	}
 
-	return token, true, nil
+	return user, nil
 }
 
 func (s *userService) Register(ctx context.Context, req dto.UserRegisterRequest) error {
@@ -161,6 +142,26 @@ func (s *userService) GetById(ctx context.Context, id int64) (*entity.User, erro
 	return s.userRepository.GetById(ctx, id)
 }
 
+// GetCompatibleDonors mencari pendonor yang golongan darahnya cocok untuk penerima. Pendonor tidak
+// ditentukan oleh role: pengguna dengan role dari database seperti Hospital Staff tetap bisa
+// mendonor, hanya akun Administrator yang tidak ditampilkan.
+func (s *userService) GetCompatibleDonors(ctx context.Context, recipientBloodType string, req dto.GetAllUserRequest) ([]entity.User, int64, error) {
+	bloodType, err := compatibility.Normalize(recipientBloodType)
+	if err != nil {
+		return nil, 0, errors.New("Golongan darah permintaan tidak valid")
+	}
+	req.BloodType = ""
+	req.BloodTypes = compatibility.DonorsFor(bloodType, compatibility.RedCells)
+	req.Role = ""
+	req.ExcludeRole = rbac.RoleAdministrator
+
+	users, total, err := s.userRepository.GetAll(ctx, req)
+	if err != nil {
+		return nil, 0, errors.New("Gagal mendapatkan data pendonor")
+	}
+	return users, total, nil
+}
+
 func (s *userService) Update(ctx context.Context, req dto.UpdateUserRequest) error {
 	var oldPublicId string
 	var newPublicId string
@@ -208,6 +209,10 @@ func (s *userService) Update(ctx context.Context, req dto.UpdateUserRequest) err
 	if req.Address != "" {
 		user.Address = req.Address
 	}
+	if req.Latitude != nil && req.Longitude != nil {
+		user.Latitude = req.Latitude
+		user.Longitude = req.Longitude
+	}
 	if req.BirthDate != "" {
 		birthDate, err := time.Parse("2006-01-02", req.BirthDate)
 		if err != nil {
@@ -236,7 +241,12 @@ func (s *userService) Update(ctx context.Context, req dto.UpdateUserRequest) err
 }
 
 func (s *userService) Delete(ctx context.Context, user *entity.User) error {
-	return s.userRepository.Delete(ctx, user)
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepository.Delete(ctx, user); err != nil {
+			return err
+		}
+		return s.auditLogService.Record(ctx, audit.Entry{Action: "user.delete", EntityType: "user", EntityId: user.Id, Before: user})
+	})
 }
 
 func (s *userService) ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) error {
@@ -380,14 +390,6 @@ func (s *userService) CheckGoogleOAuth(ctx context.Context, email string, user *
 	return existingUser, false, nil
 }
 
-func (s *userService) WalletAddress(ctx context.Context, user *entity.User, req dto.WalletAddressRequest) error {
-	user.WalletAddress = req.WalletAddress
-	if err := s.userRepository.Update(ctx, user); err != nil {
-		return err
-	}
-	return nil
-}
-
 // func (s *userService) GetAll(ctx context.Context) (result []entity.User, err error) {
 // 	keyFindAll := "github.com/mhusainh/DarahConnect/DarahConnectAPI-api:users:find-all"
 // 	data := s.cacheable.Get(keyFindAll)
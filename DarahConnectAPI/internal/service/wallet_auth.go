@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/walletauth"
+)
+
+var (
+	ErrWalletSignatureInvalid = errors.New("Tanda tangan wallet tidak valid atau sudah kedaluwarsa")
+	ErrWalletAlreadyLinked    = errors.New("Alamat wallet sudah terhubung dengan akun lain")
+)
+
+type WalletAuthService interface {
+	Challenge(ctx context.Context, userId *int64, purpose string, req dto.WalletChallengeRequest) (*dto.WalletChallengeResponse, error)
+	Link(ctx context.Context, user *entity.User, req dto.WalletAddressRequest) error
+	Login(ctx context.Context, req dto.WalletLoginRequest) (*entity.User, error)
+}
+
+type walletAuthService struct {
+	walletChallengeRepository repository.WalletChallengeRepository
+	userRepository            repository.UserRepository
+	cfg                       *configs.WalletAuthConfig
+}
+
+func NewWalletAuthService(
+	walletChallengeRepository repository.WalletChallengeRepository,
+	userRepository repository.UserRepository,
+	cfg *configs.WalletAuthConfig,
+) WalletAuthService {
+	return &walletAuthService{walletChallengeRepository, userRepository, cfg}
+}
+
+// Challenge membuat pesan bernonce yang harus ditandatangani wallet. Tantangan untuk
+// menghubungkan wallet terikat pada pengguna yang memintanya.
+func (s *walletAuthService) Challenge(ctx context.Context, userId *int64, purpose string, req dto.WalletChallengeRequest) (*dto.WalletChallengeResponse, error) {
+	nonce, err := walletauth.NewNonce()
+	if err != nil {
+		return nil, errors.New("Gagal membuat nonce")
+	}
+
+	statement := "Masuk ke DarahConnect dengan wallet ini."
+	if purpose == entity.WalletChallengeLink {
+		statement = "Hubungkan wallet ini ke akun DarahConnect anda."
+	}
+
+	now := time.Now()
+	address := common.HexToAddress(req.WalletAddress).Hex()
+	message := walletauth.Message{
+		Domain:    s.cfg.Domain,
+		Address:   address,
+		Statement: statement,
+		URI:       s.cfg.URI,
+		ChainId:   s.cfg.ChainId,
+		Nonce:     nonce,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(s.cfg.ChallengeTTL),
+	}
+
+	challenge := &entity.WalletChallenge{
+		UserId:        userId,
+		WalletAddress: address,
+		Purpose:       purpose,
+		Nonce:         nonce,
+		Message:       message.String(),
+		ExpiresAt:     message.ExpiresAt,
+	}
+	if err := s.walletChallengeRepository.Create(ctx, challenge); err != nil {
+		return nil, errors.New("Gagal menyimpan tantangan wallet")
+	}
+
+	return &dto.WalletChallengeResponse{
+		Nonce:     challenge.Nonce,
+		Message:   challenge.Message,
+		ExpiresAt: challenge.ExpiresAt,
+	}, nil
+}
+
+// Link menyimpan alamat wallet setelah tanda tangan atas tantangan terbukti dibuat oleh wallet tersebut
+func (s *walletAuthService) Link(ctx context.Context, user *entity.User, req dto.WalletAddressRequest) error {
+	challenge, err := s.verify(ctx, entity.WalletChallengeLink, req.WalletAddress, req.Nonce, req.Signature)
+	if err != nil {
+		return err
+	}
+	if challenge.UserId == nil || *challenge.UserId != user.Id {
+		return ErrWalletSignatureInvalid
+	}
+
+	if existing, err := s.userRepository.GetByWalletAddress(ctx, challenge.WalletAddress); err == nil && existing.Id != user.Id {
+		return ErrWalletAlreadyLinked
+	}
+
+	previous := user.WalletAddress
+	user.WalletAddress = challenge.WalletAddress
+	if err := s.userRepository.Update(ctx, user); err != nil {
+		user.WalletAddress = previous
+		if errors.Is(err, repository.ErrWalletAddressTaken) {
+			return ErrWalletAlreadyLinked
+		}
+		return errors.New("Gagal menyimpan alamat wallet")
+	}
+	return nil
+}
+
+// Login mencari pengguna pemilik wallet; sesinya diterbitkan SessionService seperti login email
+func (s *walletAuthService) Login(ctx context.Context, req dto.WalletLoginRequest) (*entity.User, error) {
+	challenge, err := s.verify(ctx, entity.WalletChallengeLogin, req.WalletAddress, req.Nonce, req.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepository.GetByWalletAddress(ctx, challenge.WalletAddress)
+	if err != nil {
+		return nil, errors.New("Wallet belum terhubung dengan akun manapun")
+	}
+	if !user.IsVerified {
+		return nil, errors.New("Email belum diverifikasi, silahkan verifikasi email anda")
+	}
+	return user, nil
+}
+
+// verify memeriksa tantangan lalu memulihkan penanda tangan pesan yang tersimpan di server,
+// bukan pesan dari klien, dan menandai tantangan sudah dipakai.
+func (s *walletAuthService) verify(ctx context.Context, purpose, address, nonce, signature string) (*entity.WalletChallenge, error) {
+	challenge, err := s.walletChallengeRepository.GetByNonce(ctx, nonce)
+	if err != nil {
+		return nil, ErrWalletSignatureInvalid
+	}
+	if challenge.Purpose != purpose || challenge.UsedAt != nil || time.Now().After(challenge.ExpiresAt) {
+		return nil, ErrWalletSignatureInvalid
+	}
+	if !strings.EqualFold(challenge.WalletAddress, address) {
+		return nil, ErrWalletSignatureInvalid
+	}
+	if err := walletauth.Verify(challenge.Message, signature, challenge.WalletAddress); err != nil {
+		return nil, ErrWalletSignatureInvalid
+	}
+
+	used, err := s.walletChallengeRepository.MarkUsed(ctx, challenge)
+	if err != nil {
+		return nil, errors.New("Gagal memperbarui tantangan wallet")
+	}
+	if !used {
+		return nil, ErrWalletSignatureInvalid
+	}
+	return challenge, nil
+}
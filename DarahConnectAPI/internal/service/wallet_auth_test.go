@@ -0,0 +1,279 @@
+package service_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWalletAuthService(challenges *fakeWalletChallengeRepository, users *fakeWalletUserRepository) service.WalletAuthService {
+	return service.NewWalletAuthService(challenges, users, &configs.WalletAuthConfig{
+		Domain:       "darahconnect.id",
+		URI:          "https://darahconnect.id",
+		ChainId:      11155111,
+		ChallengeTTL: 5 * time.Minute,
+	})
+}
+
+// walletSign meniru personal_sign MetaMask atas pesan tantangan
+func walletSign(t *testing.T, key *ecdsa.PrivateKey, message string) string {
+	sig, err := crypto.Sign(accounts.TextHash([]byte(message)), key)
+	require.NoError(t, err)
+	sig[crypto.RecoveryIDOffset] += 27
+	return hexutil.Encode(sig)
+}
+
+func newWallet(t *testing.T) (*ecdsa.PrivateKey, string) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return key, crypto.PubkeyToAddress(key.PublicKey).Hex()
+}
+
+func TestWalletAuthChallenge(t *testing.T) {
+	challenges := &fakeWalletChallengeRepository{}
+	walletAuthService := newWalletAuthService(challenges, &fakeWalletUserRepository{})
+	_, address := newWallet(t)
+
+	userId := int64(7)
+	response, err := walletAuthService.Challenge(context.Background(), &userId, entity.WalletChallengeLink, dto.WalletChallengeRequest{WalletAddress: strings.ToLower(address)})
+	require.NoError(t, err)
+	assert.NotEmpty(t, response.Nonce)
+	assert.Contains(t, response.Message, address, "alamat disimpan dalam bentuk checksum")
+	assert.Contains(t, response.Message, "Nonce: "+response.Nonce)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), response.ExpiresAt, time.Minute)
+
+	require.Len(t, challenges.challenges, 1)
+	assert.Equal(t, address, challenges.challenges[0].WalletAddress)
+	assert.Equal(t, &userId, challenges.challenges[0].UserId)
+}
+
+func TestWalletAuthLink(t *testing.T) {
+	ctx := context.Background()
+	key, address := newWallet(t)
+
+	link := func(t *testing.T, walletAuthService service.WalletAuthService, user *entity.User) error {
+		response, err := walletAuthService.Challenge(ctx, &user.Id, entity.WalletChallengeLink, dto.WalletChallengeRequest{WalletAddress: address})
+		require.NoError(t, err)
+		return walletAuthService.Link(ctx, user, dto.WalletAddressRequest{
+			WalletAddress: address,
+			Nonce:         response.Nonce,
+			Signature:     walletSign(t, key, response.Message),
+		})
+	}
+
+	t.Run("success", func(t *testing.T) {
+		users := &fakeWalletUserRepository{users: []*entity.User{{Id: 7}}}
+		walletAuthService := newWalletAuthService(&fakeWalletChallengeRepository{}, users)
+
+		user := &entity.User{Id: 7}
+		require.NoError(t, link(t, walletAuthService, user))
+		assert.Equal(t, address, user.WalletAddress)
+		assert.Equal(t, address, users.users[0].WalletAddress)
+	})
+
+	t.Run("linked to another user", func(t *testing.T) {
+		users := &fakeWalletUserRepository{users: []*entity.User{{Id: 7}, {Id: 8, WalletAddress: strings.ToLower(address)}}}
+		walletAuthService := newWalletAuthService(&fakeWalletChallengeRepository{}, users)
+
+		user := &entity.User{Id: 7}
+		assert.ErrorIs(t, link(t, walletAuthService, user), service.ErrWalletAlreadyLinked)
+		assert.Empty(t, user.WalletAddress)
+	})
+
+	t.Run("unique index violation", func(t *testing.T) {
+		// Permintaan lain menghubungkan wallet yang sama setelah pengecekan GetByWalletAddress
+		users := &fakeWalletUserRepository{users: []*entity.User{{Id: 7}}, updateErr: repository.ErrWalletAddressTaken}
+		walletAuthService := newWalletAuthService(&fakeWalletChallengeRepository{}, users)
+
+		user := &entity.User{Id: 7}
+		assert.ErrorIs(t, link(t, walletAuthService, user), service.ErrWalletAlreadyLinked)
+		assert.Empty(t, user.WalletAddress)
+	})
+
+	t.Run("challenge issued to another user", func(t *testing.T) {
+		walletAuthService := newWalletAuthService(&fakeWalletChallengeRepository{}, &fakeWalletUserRepository{})
+
+		otherId := int64(8)
+		response, err := walletAuthService.Challenge(ctx, &otherId, entity.WalletChallengeLink, dto.WalletChallengeRequest{WalletAddress: address})
+		require.NoError(t, err)
+		err = walletAuthService.Link(ctx, &entity.User{Id: 7}, dto.WalletAddressRequest{
+			WalletAddress: address,
+			Nonce:         response.Nonce,
+			Signature:     walletSign(t, key, response.Message),
+		})
+		assert.ErrorIs(t, err, service.ErrWalletSignatureInvalid)
+	})
+}
+
+func TestWalletAuthLogin(t *testing.T) {
+	ctx := context.Background()
+	key, address := newWallet(t)
+
+	challenge := func(t *testing.T, walletAuthService service.WalletAuthService) *dto.WalletChallengeResponse {
+		response, err := walletAuthService.Challenge(ctx, nil, entity.WalletChallengeLogin, dto.WalletChallengeRequest{WalletAddress: address})
+		require.NoError(t, err)
+		return response
+	}
+
+	t.Run("success", func(t *testing.T) {
+		users := &fakeWalletUserRepository{users: []*entity.User{{Id: 7, IsVerified: true, WalletAddress: address}}}
+		walletAuthService := newWalletAuthService(&fakeWalletChallengeRepository{}, users)
+
+		response := challenge(t, walletAuthService)
+		user, err := walletAuthService.Login(ctx, dto.WalletLoginRequest{WalletAddress: address, Nonce: response.Nonce, Signature: walletSign(t, key, response.Message)})
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), user.Id)
+	})
+
+	t.Run("nonce reuse", func(t *testing.T) {
+		users := &fakeWalletUserRepository{users: []*entity.User{{Id: 7, IsVerified: true, WalletAddress: address}}}
+		walletAuthService := newWalletAuthService(&fakeWalletChallengeRepository{}, users)
+
+		response := challenge(t, walletAuthService)
+		req := dto.WalletLoginRequest{WalletAddress: address, Nonce: response.Nonce, Signature: walletSign(t, key, response.Message)}
+		_, err := walletAuthService.Login(ctx, req)
+		require.NoError(t, err)
+
+		_, err = walletAuthService.Login(ctx, req)
+		assert.ErrorIs(t, err, service.ErrWalletSignatureInvalid)
+	})
+
+	t.Run("concurrent nonce use", func(t *testing.T) {
+		// Permintaan lain sudah menandai tantangan terpakai setelah GetByNonce
+		challenges := &fakeWalletChallengeRepository{markUsedLost: true}
+		users := &fakeWalletUserRepository{users: []*entity.User{{Id: 7, IsVerified: true, WalletAddress: address}}}
+		walletAuthService := newWalletAuthService(challenges, users)
+
+		response := challenge(t, walletAuthService)
+		_, err := walletAuthService.Login(ctx, dto.WalletLoginRequest{WalletAddress: address, Nonce: response.Nonce, Signature: walletSign(t, key, response.Message)})
+		assert.ErrorIs(t, err, service.ErrWalletSignatureInvalid)
+	})
+
+	t.Run("expired challenge", func(t *testing.T) {
+		challenges := &fakeWalletChallengeRepository{}
+		users := &fakeWalletUserRepository{users: []*entity.User{{Id: 7, IsVerified: true, WalletAddress: address}}}
+		walletAuthService := newWalletAuthService(challenges, users)
+
+		response := challenge(t, walletAuthService)
+		challenges.challenges[0].ExpiresAt = time.Now().Add(-time.Second)
+		_, err := walletAuthService.Login(ctx, dto.WalletLoginRequest{WalletAddress: address, Nonce: response.Nonce, Signature: walletSign(t, key, response.Message)})
+		assert.ErrorIs(t, err, service.ErrWalletSignatureInvalid)
+	})
+
+	t.Run("link challenge cannot log in", func(t *testing.T) {
+		users := &fakeWalletUserRepository{users: []*entity.User{{Id: 7, IsVerified: true, WalletAddress: address}}}
+		walletAuthService := newWalletAuthService(&fakeWalletChallengeRepository{}, users)
+
+		userId := int64(7)
+		response, err := walletAuthService.Challenge(ctx, &userId, entity.WalletChallengeLink, dto.WalletChallengeRequest{WalletAddress: address})
+		require.NoError(t, err)
+		_, err = walletAuthService.Login(ctx, dto.WalletLoginRequest{WalletAddress: address, Nonce: response.Nonce, Signature: walletSign(t, key, response.Message)})
+		assert.ErrorIs(t, err, service.ErrWalletSignatureInvalid)
+	})
+
+	t.Run("signed by another wallet", func(t *testing.T) {
+		users := &fakeWalletUserRepository{users: []*entity.User{{Id: 7, IsVerified: true, WalletAddress: address}}}
+		walletAuthService := newWalletAuthService(&fakeWalletChallengeRepository{}, users)
+		otherKey, _ := newWallet(t)
+
+		response := challenge(t, walletAuthService)
+		_, err := walletAuthService.Login(ctx, dto.WalletLoginRequest{WalletAddress: address, Nonce: response.Nonce, Signature: walletSign(t, otherKey, response.Message)})
+		assert.ErrorIs(t, err, service.ErrWalletSignatureInvalid)
+	})
+
+	t.Run("unlinked wallet", func(t *testing.T) {
+		walletAuthService := newWalletAuthService(&fakeWalletChallengeRepository{}, &fakeWalletUserRepository{})
+
+		response := challenge(t, walletAuthService)
+		_, err := walletAuthService.Login(ctx, dto.WalletLoginRequest{WalletAddress: address, Nonce: response.Nonce, Signature: walletSign(t, key, response.Message)})
+		assert.Error(t, err)
+	})
+
+	t.Run("unverified user", func(t *testing.T) {
+		users := &fakeWalletUserRepository{users: []*entity.User{{Id: 7, WalletAddress: address}}}
+		walletAuthService := newWalletAuthService(&fakeWalletChallengeRepository{}, users)
+
+		response := challenge(t, walletAuthService)
+		_, err := walletAuthService.Login(ctx, dto.WalletLoginRequest{WalletAddress: address, Nonce: response.Nonce, Signature: walletSign(t, key, response.Message)})
+		assert.Error(t, err)
+	})
+}
+
+// fakeWalletChallengeRepository meniru MarkUsed yang hanya berhasil sekali per tantangan
+type fakeWalletChallengeRepository struct {
+	challenges   []*entity.WalletChallenge
+	markUsedLost bool
+}
+
+func (r *fakeWalletChallengeRepository) Create(ctx context.Context, challenge *entity.WalletChallenge) error {
+	challenge.Id = int64(len(r.challenges) + 1)
+	stored := *challenge
+	r.challenges = append(r.challenges, &stored)
+	return nil
+}
+
+func (r *fakeWalletChallengeRepository) GetByNonce(ctx context.Context, nonce string) (*entity.WalletChallenge, error) {
+	for _, challenge := range r.challenges {
+		if challenge.Nonce == nonce {
+			result := *challenge
+			return &result, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeWalletChallengeRepository) MarkUsed(ctx context.Context, challenge *entity.WalletChallenge) (bool, error) {
+	if r.markUsedLost {
+		return false, nil
+	}
+	for _, stored := range r.challenges {
+		if stored.Id == challenge.Id && stored.UsedAt == nil {
+			now := time.Now()
+			stored.UsedAt = &now
+			challenge.UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type fakeWalletUserRepository struct {
+	repository.UserRepository
+	users     []*entity.User
+	updateErr error
+}
+
+func (r *fakeWalletUserRepository) GetByWalletAddress(ctx context.Context, walletAddress string) (*entity.User, error) {
+	for _, user := range r.users {
+		if user.WalletAddress != "" && strings.EqualFold(user.WalletAddress, walletAddress) {
+			return user, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeWalletUserRepository) Update(ctx context.Context, user *entity.User) error {
+	if r.updateErr != nil {
+		return r.updateErr
+	}
+	for _, stored := range r.users {
+		if stored.Id == user.Id {
+			stored.WalletAddress = user.WalletAddress
+		}
+	}
+	return nil
+}
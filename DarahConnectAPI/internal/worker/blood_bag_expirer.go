@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"context"
+	"log"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/worker"
+)
+
+// BloodBagExpirer menandai kantong darah yang sudah melewati tanggal kedaluwarsa secara
+// berkala sehingga pembacaan stok tidak perlu menulis ke database.
+type BloodBagExpirer struct {
+	inventoryService service.InventoryService
+	cfg              *configs.InventoryConfig
+}
+
+var _ worker.Worker = (*BloodBagExpirer)(nil)
+
+func NewBloodBagExpirer(inventoryService service.InventoryService, cfg *configs.InventoryConfig) *BloodBagExpirer {
+	return &BloodBagExpirer{inventoryService, cfg}
+}
+
+func (e *BloodBagExpirer) Name() string {
+	return "blood-bag-expirer"
+}
+
+func (e *BloodBagExpirer) Run(ctx context.Context) error {
+	return worker.Every(ctx, e.cfg.ExpireInterval, func(ctx context.Context) {
+		expired, err := e.inventoryService.ExpireOutdated(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Blood bag expirer: %v", err)
+		}
+		if expired > 0 {
+			log.Printf("Blood bag expirer: %d kantong darah kedaluwarsa", expired)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/worker"
+)
+
+// CertificateIndexer mengindeks event SertifikatDibuat. Event baru dari subscription memicu
+// sinkronisasi segera; jika node tidak mendukung subscription, indexer hanya memakai polling.
+type CertificateIndexer struct {
+	indexerService service.CertificateIndexerService
+	cfg            *configs.BlockchainConfig
+}
+
+var _ worker.Worker = (*CertificateIndexer)(nil)
+
+func NewCertificateIndexer(indexerService service.CertificateIndexerService, cfg *configs.BlockchainConfig) *CertificateIndexer {
+	return &CertificateIndexer{indexerService, cfg}
+}
+
+func (w *CertificateIndexer) Name() string {
+	return "certificate-indexer"
+}
+
+func (w *CertificateIndexer) Run(ctx context.Context) error {
+	trigger := make(chan struct{}, 1)
+	var subErr <-chan error
+	sub, err := w.indexerService.Watch(ctx, trigger)
+	if err != nil {
+		log.Printf("Certificate indexer: subscription tidak tersedia, memakai polling: %v", err)
+	} else {
+		defer sub.Unsubscribe()
+		subErr = sub.Err()
+	}
+
+	ticker := time.NewTicker(w.cfg.IndexerPollInterval)
+	defer ticker.Stop()
+	for {
+		if err := w.indexerService.Sync(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Certificate indexer: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-trigger:
+		case err := <-subErr:
+			log.Printf("Certificate indexer: subscription terputus, memakai polling: %v", err)
+			subErr = nil
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"context"
+	"log"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/worker"
+)
+
+// CertificateReceiptPoller memantau receipt transaksi mintSertifikat sampai sertifikat
+// dikonfirmasi atau gagal.
+type CertificateReceiptPoller struct {
+	certificateService service.CertificateService
+	cfg                *configs.BlockchainConfig
+}
+
+var _ worker.Worker = (*CertificateReceiptPoller)(nil)
+
+func NewCertificateReceiptPoller(certificateService service.CertificateService, cfg *configs.BlockchainConfig) *CertificateReceiptPoller {
+	return &CertificateReceiptPoller{certificateService, cfg}
+}
+
+func (p *CertificateReceiptPoller) Name() string {
+	return "certificate-receipt-poller"
+}
+
+func (p *CertificateReceiptPoller) Run(ctx context.Context) error {
+	// Sertifikat yang dibuat saat blockchain tidak aktif dikirim begitu worker berjalan
+	if count, err := p.certificateService.RequeueMints(ctx); err != nil {
+		log.Printf("Certificate receipt poller: %v", err)
+	} else if count > 0 {
+		log.Printf("Certificate receipt poller: %d sertifikat queued diantrekan ulang", count)
+	}
+
+	return worker.Every(ctx, p.cfg.ReceiptPollInterval, func(ctx context.Context) {
+		if err := p.certificateService.RefreshChainStatuses(ctx); err != nil {
+			log.Printf("Certificate receipt poller: %v", err)
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/worker"
+)
+
+// OutboxDispatcher mengambil pesan outbox yang jatuh tempo dan menjalankan handler sesuai
+// jenis event. Pesan yang gagal dijadwalkan ulang dengan exponential backoff.
+type OutboxDispatcher struct {
+	outboxRepository repository.OutboxRepository
+	handlers         map[string]service.OutboxHandler
+	cfg              *configs.OutboxConfig
+}
+
+var _ worker.Worker = (*OutboxDispatcher)(nil)
+
+func NewOutboxDispatcher(
+	outboxRepository repository.OutboxRepository,
+	handlers map[string]service.OutboxHandler,
+	cfg *configs.OutboxConfig,
+) *OutboxDispatcher {
+	return &OutboxDispatcher{outboxRepository, handlers, cfg}
+}
+
+func (d *OutboxDispatcher) Name() string {
+	return "outbox-dispatcher"
+}
+
+func (d *OutboxDispatcher) Run(ctx context.Context) error {
+	return worker.Every(ctx, d.cfg.PollInterval, func(ctx context.Context) {
+		// Kuras antrean selama batch penuh, lalu tunggu interval berikutnya
+		for ctx.Err() == nil {
+			processed, err := d.DispatchOnce(ctx)
+			if err != nil {
+				log.Printf("Outbox dispatcher gagal mengambil pesan: %v", err)
+				return
+			}
+			if processed < d.cfg.BatchSize {
+				return
+			}
+		}
+	})
+}
+
+// DispatchOnce memproses satu batch pesan dan mengembalikan jumlah pesan yang diambil
+func (d *OutboxDispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	messages, err := d.outboxRepository.ClaimDue(ctx, d.cfg.BatchSize, d.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range messages {
+		d.dispatch(ctx, &messages[i])
+	}
+	return len(messages), nil
+}
+
+func (d *OutboxDispatcher) dispatch(ctx context.Context, message *entity.OutboxMessage) {
+	handler, ok := d.handlers[message.EventType]
+	if !ok {
+		d.retry(ctx, message, "handler untuk event "+message.EventType+" tidak ditemukan", true)
+		return
+	}
+
+	if err := handler(ctx, []byte(message.Payload)); err != nil {
+		log.Printf("Pesan outbox %d (%s) gagal pada percobaan ke-%d: %v", message.Id, message.EventType, message.Attempts, err)
+		d.retry(ctx, message, err.Error(), message.Attempts >= d.cfg.MaxAttempts)
+		return
+	}
+
+	if err := d.outboxRepository.MarkDone(ctx, message); err != nil {
+		log.Printf("Gagal menandai pesan outbox %d selesai: %v", message.Id, err)
+	}
+}
+
+func (d *OutboxDispatcher) retry(ctx context.Context, message *entity.OutboxMessage, lastError string, failed bool) {
+	nextAttemptAt := time.Now().Add(Backoff(message.Attempts, d.cfg.BaseBackoff, d.cfg.MaxBackoff))
+	if err := d.outboxRepository.MarkRetry(ctx, message, lastError, nextAttemptAt, failed); err != nil {
+		log.Printf("Gagal menjadwalkan ulang pesan outbox %d: %v", message.Id, err)
+	}
+}
+
+// Backoff menghitung jeda percobaan berikutnya: base * 2^(attempts-1), dibatasi max
+func Backoff(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
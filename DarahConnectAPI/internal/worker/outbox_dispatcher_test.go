@@ -0,0 +1,194 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff(t *testing.T) {
+	base, max := 10*time.Second, time.Hour
+
+	assert.Equal(t, 10*time.Second, worker.Backoff(0, base, max))
+	assert.Equal(t, 10*time.Second, worker.Backoff(1, base, max))
+	assert.Equal(t, 20*time.Second, worker.Backoff(2, base, max))
+	assert.Equal(t, 80*time.Second, worker.Backoff(4, base, max))
+	assert.Equal(t, time.Hour, worker.Backoff(10, base, max))
+	assert.Equal(t, time.Hour, worker.Backoff(100, base, max))
+	assert.Equal(t, time.Minute, worker.Backoff(1, 2*time.Minute, time.Minute))
+}
+
+func TestOutboxDispatcherRetriesThenDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeOutboxRepository{now: time.Now()}
+	repo.add("email.send", `{"to":"budi@example.com"}`)
+
+	calls := 0
+	dispatcher := worker.NewOutboxDispatcher(repo, map[string]service.OutboxHandler{
+		"email.send": func(ctx context.Context, payload []byte) error {
+			calls++
+			return errors.New("mailjet tidak tersedia")
+		},
+	}, outboxConfig())
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		processed, err := dispatcher.DispatchOnce(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, processed, "percobaan ke-%d", attempt)
+
+		message := repo.messages[0]
+		assert.Equal(t, attempt, message.Attempts)
+		assert.Equal(t, "mailjet tidak tersedia", message.LastError)
+		if attempt < 3 {
+			assert.Equal(t, "pending", message.Status)
+			assert.WithinDuration(t, time.Now().Add(worker.Backoff(attempt, 10*time.Second, time.Minute)), message.NextAttemptAt, time.Second)
+
+			// Belum jatuh tempo, jadi tidak diambil lagi sebelum backoff selesai
+			processed, err = dispatcher.DispatchOnce(ctx)
+			require.NoError(t, err)
+			assert.Zero(t, processed)
+			repo.now = message.NextAttemptAt
+		}
+	}
+
+	message := repo.messages[0]
+	assert.Equal(t, "failed", message.Status)
+	assert.Nil(t, message.ProcessedAt)
+
+	// Pesan failed tidak pernah diambil lagi
+	repo.now = repo.now.Add(24 * time.Hour)
+	processed, err := dispatcher.DispatchOnce(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, processed)
+	assert.Equal(t, 3, calls)
+}
+
+func TestOutboxDispatcherRecoversAfterTransientFailure(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeOutboxRepository{now: time.Now()}
+	repo.add("certificate.mint", `{"certificate_id":1}`)
+
+	calls := 0
+	dispatcher := worker.NewOutboxDispatcher(repo, map[string]service.OutboxHandler{
+		"certificate.mint": func(ctx context.Context, payload []byte) error {
+			calls++
+			if calls == 1 {
+				return errors.New("rpc timeout")
+			}
+			return nil
+		},
+	}, outboxConfig())
+
+	_, err := dispatcher.DispatchOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "pending", repo.messages[0].Status)
+
+	repo.now = repo.messages[0].NextAttemptAt
+	_, err = dispatcher.DispatchOnce(ctx)
+	require.NoError(t, err)
+
+	message := repo.messages[0]
+	assert.Equal(t, "done", message.Status)
+	assert.Equal(t, 2, message.Attempts)
+	assert.Empty(t, message.LastError)
+	assert.NotNil(t, message.ProcessedAt)
+}
+
+func TestOutboxDispatcherUnknownEventFailsImmediately(t *testing.T) {
+	repo := &fakeOutboxRepository{now: time.Now()}
+	repo.add("unknown.event", `{}`)
+	dispatcher := worker.NewOutboxDispatcher(repo, map[string]service.OutboxHandler{}, outboxConfig())
+
+	_, err := dispatcher.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "failed", repo.messages[0].Status)
+	assert.Equal(t, 1, repo.messages[0].Attempts)
+	assert.Contains(t, repo.messages[0].LastError, "unknown.event")
+}
+
+func outboxConfig() *configs.OutboxConfig {
+	return &configs.OutboxConfig{
+		BatchSize:   10,
+		MaxAttempts: 3,
+		BaseBackoff: 10 * time.Second,
+		MaxBackoff:  time.Minute,
+		Lease:       5 * time.Minute,
+	}
+}
+
+// fakeOutboxRepository meniru ClaimDue dengan jam yang bisa dimajukan: hanya pesan pending
+// atau processing yang sudah jatuh tempo yang diambil, dan attempts bertambah setiap diambil
+type fakeOutboxRepository struct {
+	messages []*entity.OutboxMessage
+	now      time.Time
+}
+
+func (r *fakeOutboxRepository) add(eventType, payload string) {
+	r.messages = append(r.messages, &entity.OutboxMessage{
+		Id:            int64(len(r.messages) + 1),
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        "pending",
+		NextAttemptAt: r.now,
+	})
+}
+
+func (r *fakeOutboxRepository) Create(ctx context.Context, message *entity.OutboxMessage) error {
+	message.Id = int64(len(r.messages) + 1)
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func (r *fakeOutboxRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]entity.OutboxMessage, error) {
+	result := make([]entity.OutboxMessage, 0)
+	for _, message := range r.messages {
+		if len(result) == limit {
+			break
+		}
+		if (message.Status != "pending" && message.Status != "processing") || message.NextAttemptAt.After(r.now) {
+			continue
+		}
+		message.Status = "processing"
+		message.Attempts++
+		message.NextAttemptAt = r.now.Add(lease)
+		result = append(result, *message)
+	}
+	return result, nil
+}
+
+func (r *fakeOutboxRepository) MarkDone(ctx context.Context, message *entity.OutboxMessage) error {
+	stored := r.find(message.Id)
+	now := time.Now()
+	stored.Status = "done"
+	stored.ProcessedAt = &now
+	stored.LastError = ""
+	return nil
+}
+
+func (r *fakeOutboxRepository) MarkRetry(ctx context.Context, message *entity.OutboxMessage, lastError string, nextAttemptAt time.Time, failed bool) error {
+	stored := r.find(message.Id)
+	stored.Status = "pending"
+	if failed {
+		stored.Status = "failed"
+	}
+	stored.LastError = lastError
+	stored.NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func (r *fakeOutboxRepository) find(id int64) *entity.OutboxMessage {
+	for _, message := range r.messages {
+		if message.Id == id {
+			return message
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"context"
+	"log"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/worker"
+)
+
+// PaymentReconciler menanyakan status donasi pending ke gateway secara berkala agar donasi
+// yang webhook-nya hilang tidak tertahan sebagai pending.
+type PaymentReconciler struct {
+	donationService service.DonationsService
+	cfg             *configs.PaymentConfig
+}
+
+var _ worker.Worker = (*PaymentReconciler)(nil)
+
+func NewPaymentReconciler(donationService service.DonationsService, cfg *configs.PaymentConfig) *PaymentReconciler {
+	return &PaymentReconciler{donationService, cfg}
+}
+
+func (r *PaymentReconciler) Name() string {
+	return "payment-reconciler"
+}
+
+func (r *PaymentReconciler) Run(ctx context.Context) error {
+	return worker.Every(ctx, r.cfg.ReconcileInterval, func(ctx context.Context) {
+		found, err := r.donationService.Reconcile(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Payment reconciler: %v", err)
+		}
+		if found > 0 {
+			log.Printf("Payment reconciler: %d selisih status donasi ditemukan", found)
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package worker
+
+import (
+	"context"
+	"log"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/worker"
+)
+
+// PermissionReloader memuat ulang permission role secara berkala agar perubahan role yang
+// dilakukan lewat instance lain ikut berlaku di instance ini.
+type PermissionReloader struct {
+	roleService service.RoleService
+	cfg         *configs.RBACConfig
+}
+
+var _ worker.Worker = (*PermissionReloader)(nil)
+
+func NewPermissionReloader(roleService service.RoleService, cfg *configs.RBACConfig) *PermissionReloader {
+	return &PermissionReloader{roleService, cfg}
+}
+
+func (r *PermissionReloader) Name() string {
+	return "permission-reloader"
+}
+
+func (r *PermissionReloader) Run(ctx context.Context) error {
+	return worker.Every(ctx, r.cfg.ReloadInterval, func(ctx context.Context) {
+		if err := r.roleService.Load(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Permission reloader: %v", err)
+		}
+	})
+}
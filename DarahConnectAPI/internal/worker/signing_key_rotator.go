@@ -0,0 +1,35 @@
+package worker
+
+import (
+	"context"
+	"log"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/worker"
+)
+
+// SigningKeyRotator merotasi signing key JWT sesuai jadwal dan memuat ulang kunci yang dibuat
+// instance lain, sehingga semua instance menandatangani dan memverifikasi dengan kunci yang sama.
+type SigningKeyRotator struct {
+	signingKeyService service.SigningKeyService
+	cfg               *configs.JWTConfig
+}
+
+var _ worker.Worker = (*SigningKeyRotator)(nil)
+
+func NewSigningKeyRotator(signingKeyService service.SigningKeyService, cfg *configs.JWTConfig) *SigningKeyRotator {
+	return &SigningKeyRotator{signingKeyService, cfg}
+}
+
+func (r *SigningKeyRotator) Name() string {
+	return "signing-key-rotator"
+}
+
+func (r *SigningKeyRotator) Run(ctx context.Context) error {
+	return worker.Every(ctx, r.cfg.KeyReloadInterval, func(ctx context.Context) {
+		if err := r.signingKeyService.Rotate(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Signing key rotator: %v", err)
+		}
+	})
+}
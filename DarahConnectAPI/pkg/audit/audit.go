@@ -0,0 +1,58 @@
+// Package audit menyediakan bahan audit log: metadata permintaan di context, diff perubahan
+// entitas, dan hash berantai agar perubahan atau penghapusan baris audit dapat dideteksi.
+package audit
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Entry adalah satu aksi yang dicatat. Before bernilai nil untuk data baru dan After bernilai
+// nil untuk data yang dihapus.
+type Entry struct {
+	Action     string
+	EntityType string
+	EntityId   int64
+	Before     any
+	After      any
+}
+
+// Recorder menyimpan entry audit bersama metadata permintaan di context
+type Recorder interface {
+	Record(ctx context.Context, entry Entry) error
+}
+
+// Metadata menjelaskan siapa yang melakukan aksi dan dari permintaan mana
+type Metadata struct {
+	ActorId   int64
+	ActorRole string
+	IP        string
+	RequestId string
+	Method    string
+	Path      string
+
+	recorded atomic.Int32
+}
+
+// MarkRecorded menandai bahwa service sudah mencatat aksi untuk permintaan ini
+func (m *Metadata) MarkRecorded() {
+	m.recorded.Add(1)
+}
+
+// Recorded melaporkan apakah ada aksi yang sudah dicatat service selama permintaan ini
+func (m *Metadata) Recorded() bool {
+	return m.recorded.Load() > 0
+}
+
+type metadataKey struct{}
+
+func WithMetadata(ctx context.Context, metadata *Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, metadata)
+}
+
+// MetadataFrom mengembalikan metadata permintaan; ok bernilai false untuk proses di luar
+// permintaan HTTP seperti worker
+func MetadataFrom(ctx context.Context) (metadata *Metadata, ok bool) {
+	metadata, ok = ctx.Value(metadataKey{}).(*Metadata)
+	return metadata, ok
+}
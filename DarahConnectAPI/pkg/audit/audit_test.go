@@ -0,0 +1,64 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type record struct {
+	Id       int64  `json:"id"`
+	Status   string `json:"status"`
+	Password string `json:"password"`
+}
+
+func TestDiff(t *testing.T) {
+	changes, err := audit.Diff(record{Id: 1, Status: "pending", Password: "a"}, record{Id: 1, Status: "verified", Password: "b"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]audit.Change{
+		"status":   {Before: "pending", After: "verified"},
+		"password": {Before: audit.Redacted, After: audit.Redacted},
+	}, changes)
+
+	// Data yang dihapus mencatat semua field sebelumnya
+	var deleted *record
+	changes, err = audit.Diff(&record{Id: 2, Status: "active"}, deleted)
+	require.NoError(t, err)
+	assert.Equal(t, audit.Change{Before: "active"}, changes["status"])
+	assert.Equal(t, audit.Change{Before: float64(2)}, changes["id"])
+}
+
+func TestHashChain(t *testing.T) {
+	createdAt := time.Date(2024, 10, 18, 10, 0, 0, 123456789, time.UTC)
+	record := audit.Record{
+		ActorId:    1,
+		Action:     "blood_request.update",
+		EntityType: "blood_request",
+		EntityId:   7,
+		Changes:    json.RawMessage(`{"status": {"before": "pending", "after": "verified"}}`),
+		CreatedAt:  createdAt,
+	}
+
+	first, err := audit.Hash("", record)
+	require.NoError(t, err)
+
+	// Hash tetap sama setelah JSONB menyusun ulang key dan timestamp kehilangan nanodetik
+	record.Changes = json.RawMessage(`{"status":{"after":"verified","before":"pending"}}`)
+	record.CreatedAt = createdAt.Truncate(time.Microsecond).In(time.FixedZone("WIB", 7*3600))
+	same, err := audit.Hash("", record)
+	require.NoError(t, err)
+	assert.Equal(t, first, same)
+
+	chained, err := audit.Hash(first, record)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, chained)
+
+	record.EntityId = 8
+	tampered, err := audit.Hash("", record)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, tampered)
+}
@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// Record adalah isi baris audit log yang ikut di-hash. Urutan field menentukan hash sehingga
+// tidak boleh diubah tanpa migrasi ulang hash yang sudah ada.
+type Record struct {
+	ActorId    int64           `json:"actor_id"`
+	ActorRole  string          `json:"actor_role"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityId   int64           `json:"entity_id"`
+	Changes    json.RawMessage `json:"changes"`
+	IP         string          `json:"ip"`
+	RequestId  string          `json:"request_id"`
+	Method     string          `json:"method"`
+	Path       string          `json:"path"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// Hash menghitung SHA-256 dari hash baris sebelumnya dan isi record. Changes dinormalisasi
+// dulu karena JSONB di PostgreSQL tidak menyimpan urutan key dan spasi aslinya, dan
+// CreatedAt dibulatkan ke mikrodetik sesuai presisi TIMESTAMPTZ.
+func Hash(prevHash string, record Record) (string, error) {
+	changes, err := canonical(record.Changes)
+	if err != nil {
+		return "", err
+	}
+	record.Changes = changes
+	record.CreatedAt = record.CreatedAt.UTC().Truncate(time.Microsecond)
+
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(append([]byte(prevHash+"\n"), payload...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func canonical(raw json.RawMessage) (json.RawMessage, error) {
+	if len(raw) == 0 {
+		return json.RawMessage("null"), nil
+	}
+
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
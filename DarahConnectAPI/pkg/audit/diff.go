@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Redacted menggantikan nilai field rahasia agar tidak tersimpan di audit log
+const Redacted = "[REDACTED]"
+
+var redactedFields = map[string]struct{}{
+	"password":             {},
+	"reset_password_token": {},
+	"verify_email_token":   {},
+	"secret":               {},
+}
+
+// Change adalah nilai sebuah field sebelum dan sesudah aksi
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Diff membandingkan representasi JSON before dan after lalu mengembalikan field yang berubah.
+// Untuk data baru semua field after dicatat, dan untuk data yang dihapus semua field before.
+func Diff(before, after any) (map[string]Change, error) {
+	beforeFields, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]Change)
+	for name, value := range beforeFields {
+		if next, ok := afterFields[name]; !ok || !reflect.DeepEqual(value, next) {
+			changes[name] = Change{Before: value, After: afterFields[name]}
+		}
+	}
+	for name, value := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			changes[name] = Change{After: value}
+		}
+	}
+
+	for name, change := range changes {
+		if _, ok := redactedFields[name]; !ok {
+			continue
+		}
+		if change.Before != nil {
+			change.Before = Redacted
+		}
+		if change.After != nil {
+			change.After = Redacted
+		}
+		changes[name] = change
+	}
+	return changes, nil
+}
+
+// fields mengubah struct atau map menjadi map field JSON tingkat atas
+func fields(value any) (map[string]any, error) {
+	result := map[string]any{}
+	if value == nil || reflect.ValueOf(value).Kind() == reflect.Pointer && reflect.ValueOf(value).IsNil() {
+		return result, nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,136 @@
+// Package certificatepdf membuat PDF sertifikat donor darah beserta QR code menuju halaman
+// verifikasi publik. Seluruh proses berjalan di Go tanpa layanan eksternal.
+package certificatepdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/skip2/go-qrcode"
+)
+
+// Data adalah isi sertifikat yang dicetak
+type Data struct {
+	CertificateNumber string
+	DonorName         string
+	BloodType         string
+	HospitalName      string
+	HospitalAddress   string
+	DonationDate      time.Time
+	TxHash            string // kosong jika belum tercatat di blockchain
+	VerifyURL         string // tujuan QR code
+}
+
+// Warna DarahConnect
+var (
+	brandRed  = [3]int{185, 28, 28}
+	textDark  = [3]int{31, 41, 55}
+	textMuted = [3]int{107, 114, 128}
+)
+
+var months = [...]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+
+// Render menulis sertifikat A4 landscape ke w
+func Render(w io.Writer, data Data) error {
+	qr, err := qrcode.Encode(data.VerifyURL, qrcode.Medium, 512)
+	if err != nil {
+		return fmt.Errorf("gagal membuat QR code: %w", err)
+	}
+
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetTitle("Sertifikat Donor Darah "+data.CertificateNumber, true)
+	pdf.SetAuthor("DarahConnect", true)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddPage()
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	width, height := pdf.GetPageSize()
+
+	// Bingkai
+	pdf.SetDrawColor(brandRed[0], brandRed[1], brandRed[2])
+	pdf.SetLineWidth(2)
+	pdf.Rect(8, 8, width-16, height-16, "D")
+	pdf.SetLineWidth(0.4)
+	pdf.Rect(12, 12, width-24, height-24, "D")
+
+	// Judul
+	pdf.SetTextColor(brandRed[0], brandRed[1], brandRed[2])
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.SetXY(20, 22)
+	pdf.CellFormat(width-40, 8, "DarahConnect", "", 1, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 30)
+	pdf.SetX(20)
+	pdf.CellFormat(width-40, 16, "SERTIFIKAT DONOR DARAH", "", 1, "C", false, 0, "")
+
+	pdf.SetTextColor(textMuted[0], textMuted[1], textMuted[2])
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.SetX(20)
+	pdf.CellFormat(width-40, 10, "Diberikan kepada", "", 1, "C", false, 0, "")
+
+	pdf.SetTextColor(textDark[0], textDark[1], textDark[2])
+	pdf.SetFont("Helvetica", "B", 26)
+	pdf.SetX(20)
+	pdf.CellFormat(width-40, 14, tr(data.DonorName), "", 1, "C", false, 0, "")
+
+	pdf.SetTextColor(textMuted[0], textMuted[1], textMuted[2])
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.SetX(30)
+	pdf.MultiCell(width-60, 6, tr("Atas kepedulian dan kesediaannya mendonorkan darah untuk membantu sesama."), "", "C", false)
+
+	// Rincian donor
+	rows := [][2]string{
+		{"Golongan darah", data.BloodType},
+		{"Rumah sakit", data.HospitalName},
+		{"Alamat", data.HospitalAddress},
+		{"Tanggal donor", formatDate(data.DonationDate)},
+		{"Nomor sertifikat", data.CertificateNumber},
+	}
+	y := 90.0
+	for _, row := range rows {
+		pdf.SetXY(40, y)
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.SetTextColor(textMuted[0], textMuted[1], textMuted[2])
+		pdf.CellFormat(42, 8, row[0], "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.SetTextColor(textDark[0], textDark[1], textDark[2])
+		pdf.CellFormat(140, 8, tr(row[1]), "", 0, "L", false, 0, "")
+		y += 8
+	}
+
+	// Bukti blockchain
+	pdf.SetXY(40, y+4)
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(textMuted[0], textMuted[1], textMuted[2])
+	if data.TxHash != "" {
+		pdf.CellFormat(42, 5, "Transaction hash", "", 0, "L", false, 0, "")
+		pdf.SetFont("Courier", "", 8)
+		pdf.CellFormat(140, 5, data.TxHash, "", 0, "L", false, 0, "")
+	} else {
+		pdf.CellFormat(182, 5, "Sertifikat sedang dicatat di blockchain", "", 0, "L", false, 0, "")
+	}
+
+	// QR code verifikasi
+	qrSize := 42.0
+	qrX, qrY := width-40-qrSize, height-22-qrSize
+	pdf.RegisterImageOptionsReader("qr", fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(qr))
+	pdf.ImageOptions("qr", qrX, qrY, qrSize, qrSize, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	pdf.SetXY(qrX-10, qrY+qrSize)
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.SetTextColor(textMuted[0], textMuted[1], textMuted[2])
+	pdf.CellFormat(qrSize+20, 5, "Pindai untuk verifikasi", "", 0, "C", false, 0, "")
+
+	if err := pdf.Error(); err != nil {
+		return fmt.Errorf("gagal membuat PDF: %w", err)
+	}
+	return pdf.Output(w)
+}
+
+func formatDate(date time.Time) string {
+	if date.IsZero() {
+		return "-"
+	}
+	return fmt.Sprintf("%d %s %d", date.Day(), months[date.Month()-1], date.Year())
+}
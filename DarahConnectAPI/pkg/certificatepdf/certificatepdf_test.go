@@ -0,0 +1,40 @@
+package certificatepdf_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/certificatepdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	testCases := []struct {
+		name   string
+		txHash string
+	}{
+		{name: "recorded on chain", txHash: "0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060"},
+		{name: "waiting for chain"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := certificatepdf.Render(&buf, certificatepdf.Data{
+				CertificateNumber: "20241018001",
+				DonorName:         "Siti Nurhaliza Äbdullah",
+				BloodType:         "O+",
+				HospitalName:      "RSUP Dr. Kariadi",
+				HospitalAddress:   "Jl. Dr. Sutomo No.16, Semarang, Jawa Tengah",
+				DonationDate:      time.Date(2024, time.October, 18, 9, 0, 0, 0, time.UTC),
+				TxHash:            tc.txHash,
+				VerifyURL:         "http://localhost:8081/api/v1/certificate/verify/20241018001",
+			})
+			require.NoError(t, err)
+			assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+			assert.Contains(t, buf.String(), "/Subtype /Image")
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// Package compatibility berisi aturan kecocokan golongan darah ABO/Rh antara
+// pendonor dan penerima untuk setiap komponen darah.
+package compatibility
+
+import (
+	"errors"
+	"strings"
+)
+
+type Component string
+
+const (
+	WholeBlood Component = "whole_blood"
+	RedCells   Component = "red_cells"
+	Plasma     Component = "plasma"
+	Platelets  Component = "platelets"
+)
+
+// BloodTypes adalah seluruh golongan darah ABO/Rh yang dikenali, dalam bentuk kanonik
+var BloodTypes = []string{"O-", "O+", "A-", "A+", "B-", "B+", "AB-", "AB+"}
+
+var ErrInvalidBloodType = errors.New("golongan darah tidak valid")
+
+// antigen ABO yang dimiliki sel darah merah tiap golongan
+var aboAntigens = map[string][]string{
+	"O":  {},
+	"A":  {"A"},
+	"B":  {"B"},
+	"AB": {"A", "B"},
+}
+
+// Normalize mengubah penulisan golongan darah seperti "ab +", "o pos" atau "A-"
+// menjadi bentuk kanonik ("AB+", "O+", "A-").
+func Normalize(bloodType string) (string, error) {
+	value := strings.ToUpper(strings.Join(strings.Fields(bloodType), ""))
+	switch {
+	case strings.HasSuffix(value, "POSITIVE"):
+		value = strings.TrimSuffix(value, "POSITIVE") + "+"
+	case strings.HasSuffix(value, "NEGATIVE"):
+		value = strings.TrimSuffix(value, "NEGATIVE") + "-"
+	case strings.HasSuffix(value, "POS"):
+		value = strings.TrimSuffix(value, "POS") + "+"
+	case strings.HasSuffix(value, "NEG"):
+		value = strings.TrimSuffix(value, "NEG") + "-"
+	}
+
+	for _, bloodType := range BloodTypes {
+		if value == bloodType {
+			return value, nil
+		}
+	}
+	return "", ErrInvalidBloodType
+}
+
+// IsValidComponent memeriksa apakah komponen darah dikenali
+func IsValidComponent(component Component) bool {
+	switch component {
+	case WholeBlood, RedCells, Plasma, Platelets:
+		return true
+	}
+	return false
+}
+
+// CanDonate menentukan apakah komponen darah dari pendonor aman diberikan kepada penerima.
+//
+//   - whole_blood: ABO harus identik, Rh- boleh ke Rh+ tetapi tidak sebaliknya.
+//   - red_cells: antigen ABO pendonor harus dimiliki penerima, aturan Rh sama seperti di atas.
+//   - plasma: kebalikan sel darah merah, antibodi pendonor tidak boleh menyerang antigen penerima; Rh diabaikan.
+//   - platelets: ABO mengikuti aturan plasma, Rh mengikuti aturan sel darah merah karena
+//     trombosit masih membawa sedikit sel darah merah.
+func CanDonate(donor, recipient string, component Component) bool {
+	donorABO, donorRh, err := split(donor)
+	if err != nil {
+		return false
+	}
+	recipientABO, recipientRh, err := split(recipient)
+	if err != nil {
+		return false
+	}
+
+	rhCompatible := donorRh == "-" || recipientRh == "+"
+
+	switch component {
+	case WholeBlood:
+		return donorABO == recipientABO && rhCompatible
+	case RedCells:
+		return containsAll(aboAntigens[recipientABO], aboAntigens[donorABO]) && rhCompatible
+	case Plasma:
+		return containsAll(aboAntigens[donorABO], aboAntigens[recipientABO])
+	case Platelets:
+		return containsAll(aboAntigens[donorABO], aboAntigens[recipientABO]) && rhCompatible
+	}
+	return false
+}
+
+// DonorsFor mengembalikan golongan darah pendonor yang cocok untuk penerima
+func DonorsFor(recipient string, component Component) []string {
+	result := make([]string, 0, len(BloodTypes))
+	for _, donor := range BloodTypes {
+		if CanDonate(donor, recipient, component) {
+			result = append(result, donor)
+		}
+	}
+	return result
+}
+
+// RecipientsFor mengembalikan golongan darah penerima yang dapat menerima darah dari pendonor
+func RecipientsFor(donor string, component Component) []string {
+	result := make([]string, 0, len(BloodTypes))
+	for _, recipient := range BloodTypes {
+		if CanDonate(donor, recipient, component) {
+			result = append(result, recipient)
+		}
+	}
+	return result
+}
+
+func split(bloodType string) (string, string, error) {
+	normalized, err := Normalize(bloodType)
+	if err != nil {
+		return "", "", err
+	}
+	return normalized[:len(normalized)-1], normalized[len(normalized)-1:], nil
+}
+
+func containsAll(set, subset []string) bool {
+	for _, item := range subset {
+		found := false
+		for _, candidate := range set {
+			if candidate == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+package compatibility_test
+
+import (
+	"testing"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/compatibility"
+	"github.com/stretchr/testify/assert"
+)
+
+// Daftar penerima yang boleh menerima komponen dari tiap golongan darah pendonor
+var expectedRecipients = map[compatibility.Component]map[string][]string{
+	compatibility.WholeBlood: {
+		"O-":  {"O-", "O+"},
+		"O+":  {"O+"},
+		"A-":  {"A-", "A+"},
+		"A+":  {"A+"},
+		"B-":  {"B-", "B+"},
+		"B+":  {"B+"},
+		"AB-": {"AB-", "AB+"},
+		"AB+": {"AB+"},
+	},
+	compatibility.RedCells: {
+		"O-":  {"O-", "O+", "A-", "A+", "B-", "B+", "AB-", "AB+"},
+		"O+":  {"O+", "A+", "B+", "AB+"},
+		"A-":  {"A-", "A+", "AB-", "AB+"},
+		"A+":  {"A+", "AB+"},
+		"B-":  {"B-", "B+", "AB-", "AB+"},
+		"B+":  {"B+", "AB+"},
+		"AB-": {"AB-", "AB+"},
+		"AB+": {"AB+"},
+	},
+	compatibility.Plasma: {
+		"O-":  {"O-", "O+"},
+		"O+":  {"O-", "O+"},
+		"A-":  {"O-", "O+", "A-", "A+"},
+		"A+":  {"O-", "O+", "A-", "A+"},
+		"B-":  {"O-", "O+", "B-", "B+"},
+		"B+":  {"O-", "O+", "B-", "B+"},
+		"AB-": {"O-", "O+", "A-", "A+", "B-", "B+", "AB-", "AB+"},
+		"AB+": {"O-", "O+", "A-", "A+", "B-", "B+", "AB-", "AB+"},
+	},
+	compatibility.Platelets: {
+		"O-":  {"O-", "O+"},
+		"O+":  {"O+"},
+		"A-":  {"O-", "O+", "A-", "A+"},
+		"A+":  {"O+", "A+"},
+		"B-":  {"O-", "O+", "B-", "B+"},
+		"B+":  {"O+", "B+"},
+		"AB-": {"O-", "O+", "A-", "A+", "B-", "B+", "AB-", "AB+"},
+		"AB+": {"O+", "A+", "B+", "AB+"},
+	},
+}
+
+func TestCanDonate(t *testing.T) {
+	type testCase struct {
+		name      string
+		donor     string
+		recipient string
+		component compatibility.Component
+		expected  bool
+	}
+
+	testCases := make([]testCase, 0)
+	for component, donors := range expectedRecipients {
+		for _, donor := range compatibility.BloodTypes {
+			allowed := donors[donor]
+			for _, recipient := range compatibility.BloodTypes {
+				testCases = append(testCases, testCase{
+					name:      string(component) + " " + donor + " to " + recipient,
+					donor:     donor,
+					recipient: recipient,
+					component: component,
+					expected:  contains(allowed, recipient),
+				})
+			}
+		}
+	}
+
+	assert.Len(t, testCases, 4*8*8)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, compatibility.CanDonate(tc.donor, tc.recipient, tc.component))
+		})
+	}
+}
+
+func TestRecipientsFor(t *testing.T) {
+	for component, donors := range expectedRecipients {
+		for donor, recipients := range donors {
+			t.Run(string(component)+" "+donor, func(t *testing.T) {
+				assert.ElementsMatch(t, recipients, compatibility.RecipientsFor(donor, component))
+			})
+		}
+	}
+}
+
+func TestDonorsFor(t *testing.T) {
+	testCases := []struct {
+		name      string
+		recipient string
+		component compatibility.Component
+		expected  []string
+	}{
+		{"red cells universal recipient", "AB+", compatibility.RedCells, compatibility.BloodTypes},
+		{"red cells O negative recipient", "O-", compatibility.RedCells, []string{"O-"}},
+		{"red cells A positive recipient", "A+", compatibility.RedCells, []string{"O-", "O+", "A-", "A+"}},
+		{"red cells B negative recipient", "B-", compatibility.RedCells, []string{"O-", "B-"}},
+		{"plasma O recipient", "O+", compatibility.Plasma, compatibility.BloodTypes},
+		{"plasma AB recipient", "AB-", compatibility.Plasma, []string{"AB-", "AB+"}},
+		{"platelets O negative recipient", "O-", compatibility.Platelets, []string{"O-", "A-", "B-", "AB-"}},
+		{"whole blood A negative recipient", "A-", compatibility.WholeBlood, []string{"A-"}},
+		{"invalid recipient", "C+", compatibility.RedCells, []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ElementsMatch(t, tc.expected, compatibility.DonorsFor(tc.recipient, tc.component))
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"A+", "A+", false},
+		{"a+", "A+", false},
+		{" ab - ", "AB-", false},
+		{"O pos", "O+", false},
+		{"B negative", "B-", false},
+		{"AB POSITIVE", "AB+", false},
+		{"A", "", true},
+		{"C+", "", true},
+		{"", "", true},
+		{"ABO+", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			result, err := compatibility.Normalize(tc.input)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, compatibility.ErrInvalidBloodType)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestCanDonateUnknownComponent(t *testing.T) {
+	assert.False(t, compatibility.CanDonate("O-", "O-", compatibility.Component("cryo")))
+	assert.False(t, compatibility.IsValidComponent(compatibility.Component("cryo")))
+	assert.True(t, compatibility.IsValidComponent(compatibility.Platelets))
+}
+
+func contains(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
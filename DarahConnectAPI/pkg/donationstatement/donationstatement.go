@@ -0,0 +1,192 @@
+// Package donationstatement membuat laporan tahunan donasi uang seorang pendonor dalam bentuk
+// PDF atau CSV, misalnya sebagai lampiran pelaporan pajak.
+package donationstatement
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Data adalah isi laporan donasi satu tahun
+type Data struct {
+	Year        int
+	DonorName   string
+	DonorEmail  string
+	Donations   []Row
+	TotalAmount int64
+	GeneratedAt time.Time
+}
+
+// Row adalah satu donasi yang sudah dibayar
+type Row struct {
+	Date     time.Time
+	OrderId  string
+	Target   string // judul campaign atau dana, kosong untuk donasi umum
+	Gateway  string
+	Amount   int64
+	Refunded int64 // nominal yang sudah direfund sebagian
+}
+
+// Net adalah nominal donasi setelah dikurangi refund
+func (r Row) Net() int64 {
+	return r.Amount - r.Refunded
+}
+
+// Warna DarahConnect
+var (
+	brandRed  = [3]int{185, 28, 28}
+	textDark  = [3]int{31, 41, 55}
+	textMuted = [3]int{107, 114, 128}
+)
+
+var months = [...]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+
+// RenderPDF menulis laporan A4 portrait ke w. Tabel berlanjut ke halaman berikutnya jika
+// donasinya banyak.
+func RenderPDF(w io.Writer, data Data) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(fmt.Sprintf("Laporan Donasi %d", data.Year), true)
+	pdf.SetAuthor("DarahConnect", true)
+	pdf.SetAutoPageBreak(true, 20)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-14)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(textMuted[0], textMuted[1], textMuted[2])
+		pdf.CellFormat(0, 5, fmt.Sprintf("Dibuat %s - halaman %d", FormatDate(data.GeneratedAt), pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	// Judul
+	pdf.SetTextColor(brandRed[0], brandRed[1], brandRed[2])
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 8, "DarahConnect", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.CellFormat(0, 12, fmt.Sprintf("Laporan Donasi Tahun %d", data.Year), "", 1, "L", false, 0, "")
+
+	pdf.SetTextColor(textDark[0], textDark[1], textDark[2])
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(0, 6, tr("Nama: "+data.DonorName), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, tr("Email: "+data.DonorEmail), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	// Tabel donasi
+	widths := []float64{26, 48, 52, 22, 22}
+	header := func() {
+		pdf.SetFillColor(brandRed[0], brandRed[1], brandRed[2])
+		pdf.SetTextColor(255, 255, 255)
+		pdf.SetFont("Helvetica", "B", 10)
+		for i, title := range []string{"Tanggal", "Order ID", "Tujuan", "Refund", "Nominal"} {
+			align := "L"
+			if i >= 3 {
+				align = "R"
+			}
+			pdf.CellFormat(widths[i], 8, title, "1", 0, align, true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetTextColor(textDark[0], textDark[1], textDark[2])
+		pdf.SetFont("Helvetica", "", 9)
+	}
+	header()
+
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	for _, row := range data.Donations {
+		if pdf.GetY()+7 > pageHeight-bottom {
+			pdf.AddPage()
+			header()
+		}
+		target := row.Target
+		if target == "" {
+			target = "Donasi umum"
+		}
+		pdf.CellFormat(widths[0], 7, FormatDate(row.Date), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], 7, row.OrderId, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[2], 7, tr(truncate(target, 30)), "1", 0, "L", false, 0, "")
+		refunded := "-"
+		if row.Refunded > 0 {
+			refunded = FormatRupiah(row.Refunded)
+		}
+		pdf.CellFormat(widths[3], 7, refunded, "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], 7, FormatRupiah(row.Net()), "1", 1, "R", false, 0, "")
+	}
+	if len(data.Donations) == 0 {
+		pdf.SetTextColor(textMuted[0], textMuted[1], textMuted[2])
+		pdf.CellFormat(widths[0]+widths[1]+widths[2]+widths[3]+widths[4], 8, "Tidak ada donasi yang berhasil pada tahun ini", "1", 1, "C", false, 0, "")
+		pdf.SetTextColor(textDark[0], textDark[1], textDark[2])
+	}
+
+	// Total
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(widths[0]+widths[1]+widths[2]+widths[3], 8, fmt.Sprintf("Total (%d donasi)", len(data.Donations)), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[4], 8, FormatRupiah(data.TotalAmount), "1", 1, "R", false, 0, "")
+
+	pdf.Ln(6)
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(textMuted[0], textMuted[1], textMuted[2])
+	pdf.MultiCell(0, 5, "Laporan ini memuat donasi yang sudah dibayar. Donasi yang direfund sebagian dihitung setelah dikurangi refund. Terima kasih atas dukungan anda untuk DarahConnect.", "", "L", false)
+
+	if err := pdf.Error(); err != nil {
+		return fmt.Errorf("gagal membuat PDF: %w", err)
+	}
+	return pdf.Output(w)
+}
+
+// RenderCSV menulis satu baris per donasi dengan nominal, refund, dan nominal bersih dalam
+// rupiah tanpa pemisah ribuan, diakhiri baris total nominal bersih
+func RenderCSV(w io.Writer, data Data) error {
+	writer := csv.NewWriter(w)
+	records := [][]string{{"tanggal", "order_id", "tujuan", "gateway", "nominal", "refund", "bersih"}}
+	for _, row := range data.Donations {
+		records = append(records, []string{
+			row.Date.Format("2006-01-02"),
+			row.OrderId,
+			row.Target,
+			row.Gateway,
+			strconv.FormatInt(row.Amount, 10),
+			strconv.FormatInt(row.Refunded, 10),
+			strconv.FormatInt(row.Net(), 10),
+		})
+	}
+	records = append(records, []string{"total", "", "", "", "", "", strconv.FormatInt(data.TotalAmount, 10)})
+
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("gagal membuat CSV: %w", err)
+	}
+	return nil
+}
+
+// FormatRupiah menulis nominal seperti Rp1.250.000
+func FormatRupiah(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	digits := strconv.FormatInt(amount, 10)
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "." + digits[i:]
+	}
+	return sign + "Rp" + digits
+}
+
+// FormatDate menulis tanggal seperti 18 Oktober 2024
+func FormatDate(date time.Time) string {
+	if date.IsZero() {
+		return "-"
+	}
+	return fmt.Sprintf("%d %s %d", date.Day(), months[date.Month()-1], date.Year())
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
@@ -0,0 +1,79 @@
+package donationstatement_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/donationstatement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func statement(count int) donationstatement.Data {
+	data := donationstatement.Data{
+		Year:        2024,
+		DonorName:   "Siti Nurhaliza Äbdullah",
+		DonorEmail:  "siti@example.com",
+		GeneratedAt: time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC),
+	}
+	for i := 0; i < count; i++ {
+		data.Donations = append(data.Donations, donationstatement.Row{
+			Date:    time.Date(2024, time.October, 18, 9, 0, 0, 0, time.UTC),
+			OrderId: fmt.Sprintf("DC-20241018-%016X", i),
+			Target:  "Darurat stok darah, RSUP Dr. Kariadi",
+			Gateway: "midtrans",
+			Amount:  50000,
+		})
+		data.TotalAmount += 50000
+	}
+	return data
+}
+
+func TestRenderPDF(t *testing.T) {
+	for _, count := range []int{0, 3, 80} {
+		t.Run(fmt.Sprintf("%d donations", count), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, donationstatement.RenderPDF(&buf, statement(count)))
+			assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+		})
+	}
+}
+
+func TestRenderCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, donationstatement.RenderCSV(&buf, statement(2)))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, []string{"2024-10-18", "DC-20241018-0000000000000000", "Darurat stok darah, RSUP Dr. Kariadi", "midtrans", "50000", "0", "50000"}, records[1])
+	assert.Equal(t, []string{"total", "", "", "", "", "", "100000"}, records[3])
+}
+
+func TestRenderPartiallyRefunded(t *testing.T) {
+	data := statement(1)
+	data.Donations[0].Refunded = 20000
+	data.TotalAmount = data.Donations[0].Net()
+
+	var buf bytes.Buffer
+	require.NoError(t, donationstatement.RenderCSV(&buf, data))
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, []string{"50000", "20000", "30000"}, records[1][4:])
+	assert.Equal(t, "30000", records[2][6])
+
+	buf.Reset()
+	require.NoError(t, donationstatement.RenderPDF(&buf, data))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+}
+
+func TestFormatRupiah(t *testing.T) {
+	assert.Equal(t, "Rp0", donationstatement.FormatRupiah(0))
+	assert.Equal(t, "Rp500", donationstatement.FormatRupiah(500))
+	assert.Equal(t, "Rp50.000", donationstatement.FormatRupiah(50000))
+	assert.Equal(t, "Rp1.250.000", donationstatement.FormatRupiah(1250000))
+}
@@ -0,0 +1,153 @@
+// Package eligibility berisi aturan kelayakan pendonor darah lengkap: batas usia,
+// jeda minimal antar donor, dan batas jumlah donor per tahun berdasarkan jenis kelamin.
+package eligibility
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Code string
+
+const (
+	CodeMissingBirthDate Code = "missing_birth_date"
+	CodeUnderAge         Code = "under_age"
+	CodeOverAge          Code = "over_age"
+	CodeDeferralInterval Code = "deferral_interval"
+	CodeYearlyLimit      Code = "yearly_limit"
+)
+
+// Rules adalah parameter aturan kelayakan donor
+type Rules struct {
+	MinAge            int
+	MaxAge            int
+	IntervalDays      int
+	YearlyLimitMale   int
+	YearlyLimitFemale int
+}
+
+// DefaultRules mengikuti ketentuan umum donor darah lengkap PMI
+var DefaultRules = Rules{
+	MinAge:            17,
+	MaxAge:            65,
+	IntervalDays:      60,
+	YearlyLimitMale:   5,
+	YearlyLimitFemale: 4,
+}
+
+// Donor adalah data pendonor yang dibutuhkan untuk menilai kelayakan
+type Donor struct {
+	BirthDate time.Time
+	Gender    string
+	Donations []time.Time // tanggal donor darah lengkap yang sudah selesai
+}
+
+// Reason menjelaskan satu aturan yang tidak terpenuhi
+type Reason struct {
+	Code    Code       `json:"code"`
+	Message string     `json:"message"`
+	Until   *time.Time `json:"until,omitempty"`
+}
+
+// Result adalah hasil penilaian kelayakan donor
+type Result struct {
+	Eligible          bool       `json:"eligible"`
+	Reasons           []Reason   `json:"reasons"`
+	NextEligibleDate  *time.Time `json:"next_eligible_date"`
+	LastDonationDate  *time.Time `json:"last_donation_date"`
+	DonationsLastYear int        `json:"donations_last_year"`
+	YearlyLimit       int        `json:"yearly_limit"`
+}
+
+// HistorySince mengembalikan batas awal riwayat donor yang perlu dimuat untuk Evaluate
+func (r Rules) HistorySince(now time.Time) time.Time {
+	since := now.AddDate(-1, 0, 0)
+	if interval := now.AddDate(0, 0, -r.IntervalDays); interval.Before(since) {
+		since = interval
+	}
+	return since
+}
+
+// YearlyLimit mengembalikan batas donor per tahun sesuai jenis kelamin
+func (r Rules) YearlyLimit(gender string) int {
+	switch strings.ToLower(strings.TrimSpace(gender)) {
+	case "female", "perempuan", "wanita", "f":
+		return r.YearlyLimitFemale
+	}
+	return r.YearlyLimitMale
+}
+
+// Evaluate menilai kelayakan donor pada waktu now. NextEligibleDate berisi tanggal paling awal
+// donor boleh mendonorkan darah lagi, atau nil jika sudah layak atau tidak akan pernah layak
+// (misalnya melewati batas usia maksimal).
+func (r Rules) Evaluate(donor Donor, now time.Time) Result {
+	result := Result{
+		Reasons:     []Reason{},
+		YearlyLimit: r.YearlyLimit(donor.Gender),
+	}
+
+	var next time.Time
+	permanent := false
+	addDeferral := func(code Code, message string, until time.Time) {
+		result.Reasons = append(result.Reasons, Reason{Code: code, Message: message, Until: &until})
+		if until.After(next) {
+			next = until
+		}
+	}
+
+	// Usia
+	if donor.BirthDate.IsZero() {
+		result.Reasons = append(result.Reasons, Reason{Code: CodeMissingBirthDate, Message: "Tanggal lahir belum diisi"})
+		permanent = true
+	} else {
+		minAgeDate := donor.BirthDate.AddDate(r.MinAge, 0, 0)
+		// batas usia maksimal masih berlaku sampai sehari sebelum ulang tahun ke MaxAge+1
+		maxAgeDate := donor.BirthDate.AddDate(r.MaxAge+1, 0, 0)
+		if now.Before(minAgeDate) {
+			addDeferral(CodeUnderAge, fmt.Sprintf("Usia minimal pendonor adalah %d tahun", r.MinAge), minAgeDate)
+		} else if !now.Before(maxAgeDate) {
+			result.Reasons = append(result.Reasons, Reason{Code: CodeOverAge, Message: fmt.Sprintf("Usia maksimal pendonor adalah %d tahun", r.MaxAge)})
+			permanent = true
+		}
+	}
+
+	donations := make([]time.Time, 0, len(donor.Donations))
+	for _, donation := range donor.Donations {
+		if !donation.After(now) {
+			donations = append(donations, donation)
+		}
+	}
+	sort.Slice(donations, func(i, j int) bool { return donations[i].Before(donations[j]) })
+
+	// Jeda minimal antar donor
+	if len(donations) > 0 {
+		last := donations[len(donations)-1]
+		result.LastDonationDate = &last
+		if until := last.AddDate(0, 0, r.IntervalDays); now.Before(until) {
+			addDeferral(CodeDeferralInterval, fmt.Sprintf("Jarak minimal antar donor adalah %d hari", r.IntervalDays), until)
+		}
+	}
+
+	// Batas donor dalam 12 bulan terakhir
+	yearAgo := now.AddDate(-1, 0, 0)
+	lastYear := make([]time.Time, 0, len(donations))
+	for _, donation := range donations {
+		if donation.After(yearAgo) {
+			lastYear = append(lastYear, donation)
+		}
+	}
+	result.DonationsLastYear = len(lastYear)
+	if result.YearlyLimit > 0 && len(lastYear) >= result.YearlyLimit {
+		// kuota kembali setelah donor tertua yang masih dihitung keluar dari jendela 12 bulan
+		until := lastYear[len(lastYear)-result.YearlyLimit].AddDate(1, 0, 0)
+		addDeferral(CodeYearlyLimit, fmt.Sprintf("Batas donor adalah %d kali dalam 12 bulan", result.YearlyLimit), until)
+	}
+
+	result.Eligible = len(result.Reasons) == 0
+	if !result.Eligible && !permanent {
+		result.NextEligibleDate = &next
+	}
+	return result
+}
@@ -0,0 +1,172 @@
+package eligibility_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/eligibility"
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2024, time.October, 18, 9, 0, 0, 0, time.UTC)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+}
+
+func TestEvaluate(t *testing.T) {
+	rules := eligibility.DefaultRules
+	adult := date(1995, time.May, 1)
+
+	testCases := []struct {
+		name         string
+		donor        eligibility.Donor
+		eligible     bool
+		codes        []eligibility.Code
+		nextEligible *time.Time
+	}{
+		{
+			name:     "first time donor",
+			donor:    eligibility.Donor{BirthDate: adult, Gender: "Male"},
+			eligible: true,
+		},
+		{
+			name:     "exactly minimum age",
+			donor:    eligibility.Donor{BirthDate: date(2007, time.October, 18), Gender: "Female"},
+			eligible: true,
+		},
+		{
+			name:         "under minimum age",
+			donor:        eligibility.Donor{BirthDate: date(2008, time.January, 2), Gender: "Male"},
+			codes:        []eligibility.Code{eligibility.CodeUnderAge},
+			nextEligible: ptr(date(2025, time.January, 2)),
+		},
+		{
+			name:     "still 65 years old",
+			donor:    eligibility.Donor{BirthDate: date(1958, time.October, 19), Gender: "Male"},
+			eligible: true,
+		},
+		{
+			name:  "over maximum age",
+			donor: eligibility.Donor{BirthDate: date(1958, time.October, 18), Gender: "Male"},
+			codes: []eligibility.Code{eligibility.CodeOverAge},
+		},
+		{
+			name:  "missing birth date",
+			donor: eligibility.Donor{Gender: "Male"},
+			codes: []eligibility.Code{eligibility.CodeMissingBirthDate},
+		},
+		{
+			name: "within deferral interval",
+			donor: eligibility.Donor{BirthDate: adult, Gender: "Male", Donations: []time.Time{
+				date(2024, time.September, 1),
+			}},
+			codes:        []eligibility.Code{eligibility.CodeDeferralInterval},
+			nextEligible: ptr(date(2024, time.October, 31)),
+		},
+		{
+			name: "deferral interval just passed",
+			donor: eligibility.Donor{BirthDate: adult, Gender: "Male", Donations: []time.Time{
+				date(2024, time.August, 19),
+			}},
+			eligible: true,
+		},
+		{
+			name: "female yearly limit reached",
+			donor: eligibility.Donor{BirthDate: adult, Gender: "Female", Donations: []time.Time{
+				date(2024, time.June, 1),
+				date(2023, time.December, 1),
+				date(2024, time.March, 1),
+				date(2024, time.August, 1),
+			}},
+			codes:        []eligibility.Code{eligibility.CodeYearlyLimit},
+			nextEligible: ptr(date(2024, time.December, 1)),
+		},
+		{
+			name: "male allowed a fifth donation",
+			donor: eligibility.Donor{BirthDate: adult, Gender: "Male", Donations: []time.Time{
+				date(2023, time.December, 1),
+				date(2024, time.March, 1),
+				date(2024, time.June, 1),
+				date(2024, time.August, 1),
+			}},
+			eligible: true,
+		},
+		{
+			name: "donations older than a year are not counted",
+			donor: eligibility.Donor{BirthDate: adult, Gender: "Female", Donations: []time.Time{
+				date(2023, time.October, 18),
+				date(2024, time.January, 1),
+				date(2024, time.April, 1),
+				date(2024, time.July, 1),
+			}},
+			eligible: true,
+		},
+		{
+			name: "interval and yearly limit use the latest date",
+			donor: eligibility.Donor{BirthDate: adult, Gender: "Female", Donations: []time.Time{
+				date(2023, time.November, 1),
+				date(2024, time.February, 1),
+				date(2024, time.May, 1),
+				date(2024, time.October, 1),
+			}},
+			codes:        []eligibility.Code{eligibility.CodeDeferralInterval, eligibility.CodeYearlyLimit},
+			nextEligible: ptr(date(2024, time.November, 30)),
+		},
+		{
+			name: "over age is permanent even with other deferrals",
+			donor: eligibility.Donor{BirthDate: date(1950, time.January, 1), Gender: "Male", Donations: []time.Time{
+				date(2024, time.October, 1),
+			}},
+			codes: []eligibility.Code{eligibility.CodeOverAge, eligibility.CodeDeferralInterval},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := rules.Evaluate(tc.donor, now)
+
+			codes := make([]eligibility.Code, 0, len(result.Reasons))
+			for _, reason := range result.Reasons {
+				codes = append(codes, reason.Code)
+			}
+			assert.Equal(t, tc.eligible, result.Eligible)
+			assert.ElementsMatch(t, tc.codes, codes)
+			assert.Equal(t, tc.nextEligible, result.NextEligibleDate)
+		})
+	}
+}
+
+func TestEvaluateSummary(t *testing.T) {
+	result := eligibility.DefaultRules.Evaluate(eligibility.Donor{
+		BirthDate: date(1995, time.May, 1),
+		Gender:    "Female",
+		Donations: []time.Time{date(2023, time.June, 1), date(2024, time.July, 1), date(2024, time.March, 1)},
+	}, now)
+
+	assert.True(t, result.Eligible)
+	assert.Equal(t, 2, result.DonationsLastYear)
+	assert.Equal(t, 4, result.YearlyLimit)
+	assert.Equal(t, ptr(date(2024, time.July, 1)), result.LastDonationDate)
+	assert.Nil(t, result.NextEligibleDate)
+}
+
+func TestYearlyLimit(t *testing.T) {
+	rules := eligibility.DefaultRules
+	assert.Equal(t, 5, rules.YearlyLimit("Male"))
+	assert.Equal(t, 4, rules.YearlyLimit("Female"))
+	assert.Equal(t, 4, rules.YearlyLimit("perempuan"))
+	assert.Equal(t, 5, rules.YearlyLimit(""))
+}
+
+func TestHistorySince(t *testing.T) {
+	assert.Equal(t, date(2023, time.October, 18), eligibility.DefaultRules.HistorySince(now))
+
+	rules := eligibility.DefaultRules
+	rules.IntervalDays = 400
+	assert.Equal(t, now.AddDate(0, 0, -400), rules.HistorySince(now))
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
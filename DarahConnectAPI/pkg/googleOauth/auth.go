@@ -4,16 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"time"
+	"net/url"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/gothic"
 	"github.com/markbates/goth/providers/google"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/service"
-	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/token"
 )
 
 type GoogleAuthService interface {
@@ -21,15 +19,17 @@ type GoogleAuthService interface {
 	Callback(ctx echo.Context) error
 }
 type Service struct {
-	tokenService token.TokenUseCase
-	userService  service.UserService
-	cfg          *configs.GoogleOauth
+	sessionService service.SessionService
+	userService    service.UserService
+	mfaService     service.MFAService
+	cfg            *configs.GoogleOauth
 }
 
-func NewGoogleOAuthService(tokenService token.TokenUseCase, userService service.UserService, cfg *configs.GoogleOauth) *Service {
+func NewGoogleOAuthService(sessionService service.SessionService, userService service.UserService, mfaService service.MFAService, cfg *configs.GoogleOauth) *Service {
 	return &Service{
-		tokenService,
+		sessionService,
 		userService,
+		mfaService,
 		cfg,
 	}
 }
@@ -114,38 +114,28 @@ func (s *Service) Callback(ctx echo.Context) (string, error) {
 	log.Printf("Successfully authenticated user: %s", user.Email)
 
 	// Check if user already exists in the database
-	metamask := false
 	userEntity, IsNew, err := s.userService.CheckGoogleOAuth(ctx.Request().Context(), user.Email, &user)
 	if err != nil {
 		log.Printf("Error checking Google OAuth user: %v", err)
 		return "", errors.New("ada kesalahan saat check google oauth")
 	}
-	if userEntity.WalletAddress != "" {
-		metamask = true
-	}
 
-	// Buat JWT claims dari data Google OAuth
-	claims := &token.GoogleOAuthClaims{
-		Id:         userEntity.Id,
-		Email:      user.Email,
-		Name:       userEntity.Name,
-		Role:       userEntity.Role,
-		PictureURL: user.AvatarURL,
-		Provider:   "google",
-		Metamask:   metamask,
-		IsNew:      IsNew,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(12 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+	// Login Google tidak melewati verifikasi dua langkah; frontend menukar mfa_token lewat login/mfa
+	challenge, err := s.mfaService.StartLogin(ctx.Request().Context(), userEntity)
+	if err != nil {
+		log.Printf("Error starting MFA login: %v", err)
+		return "", errors.New("ada kesalahan saat memulai verifikasi dua langkah")
+	}
+	if challenge != nil {
+		return fmt.Sprintf("%s&mfa_required=true&enrollment_required=%t&mfa_token=%s", s.cfg.RedirectURL, challenge.EnrollmentRequired, challenge.MFAToken), nil
 	}
 
-	// Generate token
-	accessToken, err := s.tokenService.GenerateAccessToken(claims)
+	// Sesi Google sama dengan login biasa: access token berumur pendek ditambah refresh token
+	session, err := s.sessionService.Issue(ctx.Request().Context(), userEntity)
 	if err != nil {
-		log.Printf("Error generating access token: %v", err)
+		log.Printf("Error issuing session: %v", err)
 		return "", errors.New("ada kesalahan saat generate token")
 	}
 	// Return token dan data user
-	return s.cfg.RedirectURL + accessToken, nil
-}
\ No newline at end of file
+	return fmt.Sprintf("%s%s&refresh_token=%s&expires_in=%d&is_new=%t", s.cfg.RedirectURL, session.Token, url.QueryEscape(session.RefreshToken), session.ExpiresIn, IsNew), nil
+}
@@ -16,6 +16,11 @@ type Mailer struct {
 	smtpCfg configs.SMTPConfig
 }
 
+// Sender mengirim email dari template. Dipenuhi oleh *Mailer dan bisa diganti saat pengujian.
+type Sender interface {
+	SendEmail(templatePath string, emailData EmailData) error
+}
+
 // EmailData berisi data yang diperlukan untuk mengirim email
 type EmailData struct {
 	To       string
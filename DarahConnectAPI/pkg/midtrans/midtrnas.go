@@ -2,113 +2,114 @@ package midtrans
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
-	"log"
-	"strconv"
+	"net/http"
 	"strings"
-	"time"
 
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
-	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/entity"
-	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/http/dto"
-	"github.com/mhusainh/DarahConnect/DarahConnectAPI/internal/repository"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/payment"
 	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
 	"github.com/midtrans/midtrans-go/snap"
 )
 
-type MidtransService interface {
-	CreateTransaction(ctx context.Context, req dto.PaymentRequest) (string, error)
-	WebHookTransaction(ctx context.Context, input *dto.DonationsCreate) error
-}
-
-
-type midtransService struct {
-	cfg *configs.MidtransConfig
+// Gateway adalah implementasi payment.PaymentGateway dengan Midtrans Snap untuk pembayaran dan
+// Core API untuk cek status serta refund
+type Gateway struct {
+	cfg        *configs.MidtransConfig
 	snapClient snap.Client
-	DonationsRepository repository.DonationsRepository
-	
+	coreClient coreapi.Client
 }
 
+func NewGateway(cfg *configs.MidtransConfig) (*Gateway, error) {
+	if cfg.ServerKey == "" {
+		return nil, errors.New("MIDTRANS_SERVER_KEY belum diatur")
+	}
 
-func InitMidtrans(cfg *configs.MidtransConfig) (MidtransService, error) {
-	snapClient := snap.Client{}
-
-	snapClient.New(cfg.ServerKey, midtrans.Sandbox)
-	
+	env := midtrans.Sandbox
+	switch strings.ToLower(cfg.Environment) {
+	case "", "sandbox":
+	case "production":
+		env = midtrans.Production
+	default:
+		return nil, errors.New("MIDTRANS_ENVIRONMENT harus sandbox atau production")
+	}
 
-	return &midtransService{
-		cfg:        cfg,
-		snapClient: snapClient,
-	}, nil
+	gateway := &Gateway{cfg: cfg}
+	gateway.snapClient.New(cfg.ServerKey, env)
+	gateway.coreClient.New(cfg.ServerKey, env)
+	return gateway, nil
 }
 
-func NewMidtransService(cfg *configs.MidtransConfig) *midtransService {
-    snapClient := snap.Client{}
-    snapClient.New(cfg.ServerKey, midtrans.Sandbox)
-    
-    return &midtransService{
-        cfg: cfg,
-        snapClient: snapClient,
-    }
+func (g *Gateway) Name() string {
+	return "midtrans"
 }
 
-func (s *midtransService) CreateTransaction(ctx context.Context, req dto.PaymentRequest) (string, error) {
-	donation := new(entity.Donation)
+func (g *Gateway) CreateCharge(ctx context.Context, charge payment.Charge) (*payment.ChargeResult, error) {
 	request := &snap.Request{
 		TransactionDetails: midtrans.TransactionDetails{
-			OrderID:  req.OrderID,
-			GrossAmt: req.Amount,
+			OrderID:  charge.OrderId,
+			GrossAmt: charge.Amount,
 		},
 		CustomerDetail: &midtrans.CustomerDetails{
-			FName: req.Fullname,
-			Email: req.Email,
+			FName: charge.CustomerName,
+			Email: charge.CustomerEmail,
 		},
 	}
-	resp, err := s.snapClient.CreateTransaction(request)
+	resp, err := g.snapClient.CreateTransaction(request)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	return &payment.ChargeResult{Token: resp.Token, RedirectURL: resp.RedirectURL}, nil
+}
 
-	OrderID, errParse := strconv.ParseInt(strings.Split(req.OrderID, "-")[2], 10, 64)
-	if errParse != nil {
-		return "",errors.New("format order id salah")
+func (g *Gateway) ParseNotification(ctx context.Context, payload []byte) (*payment.Notification, error) {
+	var body notificationBody
+	if err := json.Unmarshal(payload, &body); err != nil || body.OrderID == "" {
+		return nil, payment.ErrInvalidPayload
 	}
 
-	donation.UserId = req.UserId
-	donation.Amount = req.Amount
-	donation.OrderId = OrderID
-	donation.CreatedAt = time.Now()
-	donation.UpdatedAt = time.Now()
-	donation.Status = "pending"
-
-	if err := s.DonationsRepository.Create(ctx, donation); err != nil {
-		return "",errors.New("gagal memproses donasi")
+	notification := body.notification()
+	notification.SignatureValid = VerifySignature(body.OrderID, body.StatusCode, body.GrossAmount, body.SignatureKey, g.cfg.ServerKey)
+	if !notification.SignatureValid {
+		return notification, payment.ErrInvalidSignature
 	}
-	return resp.RedirectURL, nil
+	return notification, nil
 }
 
-func (s *midtransService) WebHookTransaction(ctx context.Context, input *dto.DonationsCreate) error {
-	donation := new(entity.Donation)
-
-	OrderID, err := strconv.ParseInt(strings.Split(input.OrderID, "-")[2], 10, 64)
-	if err != nil {
-		return errors.New("format order id salah")
+// QueryStatus membaca status transaksi langsung dari Midtrans sehingga tidak perlu signature
+func (g *Gateway) QueryStatus(ctx context.Context, orderId string) (*payment.Notification, error) {
+	resp, midtransErr := g.coreClient.CheckTransaction(orderId)
+	if midtransErr != nil {
+		if midtransErr.StatusCode == http.StatusNotFound {
+			return nil, payment.ErrOrderNotFound
+		}
+		return nil, midtransErr
+	}
+	if resp.StatusCode == "404" {
+		return nil, payment.ErrOrderNotFound
 	}
 
-	donation.OrderId = OrderID
-	donation.Status = input.Transaction_status
-	donation.UpdatedAt = time.Now()
-
-	// Validate transaction status
-	if input.Transaction_status != "settlement" && input.Transaction_status != "capture" {
-		return errors.New("status transaksi tidak valid")
-	} 
-	donation.Status = "success"
-	log.Printf("data : %v", donation )
-	// Save donation to database
-	if err := s.DonationsRepository.Update(ctx,OrderID, donation); err != nil {
-		return errors.New("gagal memproses donasi")
+	body := notificationBody{
+		OrderID:           resp.OrderID,
+		StatusCode:        resp.StatusCode,
+		GrossAmount:       resp.GrossAmount,
+		TransactionId:     resp.TransactionID,
+		TransactionStatus: resp.TransactionStatus,
+		TransactionTime:   resp.TransactionTime,
+		FraudStatus:       resp.FraudStatus,
+		RefundAmount:      resp.RefundAmount,
 	}
+	notification := body.notification()
+	notification.SignatureValid = true
+	return notification, nil
+}
 
+func (g *Gateway) Refund(ctx context.Context, orderId string, amount int64, reason string) error {
+	_, midtransErr := g.coreClient.RefundTransaction(orderId, &coreapi.RefundReq{Amount: amount, Reason: reason})
+	if midtransErr != nil {
+		return midtransErr
+	}
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,100 @@
+package midtrans
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/payment"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/timezone"
+)
+
+// notificationBody adalah isi HTTP notification Midtrans yang dipakai untuk memproses donasi
+type notificationBody struct {
+	OrderID           string `json:"order_id"`
+	StatusCode        string `json:"status_code"`
+	GrossAmount       string `json:"gross_amount"`
+	SignatureKey      string `json:"signature_key"`
+	TransactionId     string `json:"transaction_id"`
+	TransactionStatus string `json:"transaction_status"`
+	TransactionTime   string `json:"transaction_time"`
+	FraudStatus       string `json:"fraud_status"`
+	RefundAmount      string `json:"refund_amount"` // total refund, hanya ada pada status refund dan partial_refund
+}
+
+func (b notificationBody) notification() *payment.Notification {
+	return &payment.Notification{
+		OrderId:           b.OrderID,
+		TransactionId:     b.TransactionId,
+		TransactionStatus: b.TransactionStatus,
+		FraudStatus:       b.FraudStatus,
+		StatusCode:        b.StatusCode,
+		GrossAmount:       b.GrossAmount,
+		RefundedAmount:    payment.ParseAmount(b.RefundAmount),
+		TransactionTime:   transactionTime(b.TransactionTime),
+		Status:            DonationStatus(b.TransactionStatus, b.FraudStatus),
+	}
+}
+
+// SignatureKey menghitung signature notification Midtrans:
+// SHA512(order_id + status_code + gross_amount + server_key)
+func SignatureKey(orderId, statusCode, grossAmount, serverKey string) string {
+	sum := sha512.Sum512([]byte(orderId + statusCode + grossAmount + serverKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifySignature membandingkan signature_key notification dengan signature yang diharapkan
+func VerifySignature(orderId, statusCode, grossAmount, signatureKey, serverKey string) bool {
+	if serverKey == "" || signatureKey == "" {
+		return false
+	}
+	expected := SignatureKey(orderId, statusCode, grossAmount, serverKey)
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(signatureKey)) == 1
+}
+
+// DonationStatus memetakan transaction_status dan fraud_status Midtrans ke status donasi.
+// Pembayaran kartu yang masih challenge tetap pending sampai diputuskan di dashboard Midtrans.
+// Status yang tidak dikenal menghasilkan string kosong.
+func DonationStatus(transactionStatus, fraudStatus string) string {
+	switch transactionStatus {
+	case "pending":
+		return payment.StatusPending
+	case "capture":
+		switch fraudStatus {
+		case "", "accept":
+			return payment.StatusSuccess
+		case "challenge":
+			return payment.StatusPending
+		default:
+			return payment.StatusFailed
+		}
+	case "settlement":
+		return payment.StatusSuccess
+	case "deny":
+		return payment.StatusFailed
+	case "cancel":
+		return payment.StatusCancelled
+	case "expire":
+		return payment.StatusExpired
+	case "refund":
+		return payment.StatusRefunded
+	case "partial_refund":
+		return payment.StatusPartiallyRefunded
+	default:
+		return ""
+	}
+}
+
+// transactionTime membaca transaction_time Midtrans yang dikirim dalam waktu Jakarta
+func transactionTime(value string) time.Time {
+	location := timezone.JakartaLocation
+	if location == nil {
+		location = time.FixedZone("WIB", 7*60*60)
+	}
+	parsed, err := time.ParseInLocation(time.DateTime, value, location)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
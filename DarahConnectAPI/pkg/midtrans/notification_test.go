@@ -0,0 +1,82 @@
+package midtrans_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/midtrans"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/payment"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifySignature(t *testing.T) {
+	signature := midtrans.SignatureKey("ORDER-7-20241018100000", "200", "50000.00", "server-key")
+	assert.Len(t, signature, 128)
+	assert.True(t, midtrans.VerifySignature("ORDER-7-20241018100000", "200", "50000.00", signature, "server-key"))
+	assert.False(t, midtrans.VerifySignature("ORDER-7-20241018100000", "200", "50000.00", signature, "kunci-lain"))
+	assert.False(t, midtrans.VerifySignature("ORDER-7-20241018100000", "200", "50000.00", signature, ""))
+
+	// Nominal yang diubah membuat signature tidak cocok
+	assert.False(t, midtrans.VerifySignature("ORDER-7-20241018100000", "200", "5000000.00", signature, "server-key"))
+}
+
+func TestParseNotification(t *testing.T) {
+	gateway, err := midtrans.NewGateway(&configs.MidtransConfig{ServerKey: "server-key"})
+	require.NoError(t, err)
+
+	body := map[string]string{
+		"order_id":           "ORDER-7-20241018100000",
+		"status_code":        "200",
+		"gross_amount":       "50000.00",
+		"transaction_status": "settlement",
+		"transaction_time":   "2024-10-18 10:00:00",
+		"signature_key":      midtrans.SignatureKey("ORDER-7-20241018100000", "200", "50000.00", "server-key"),
+	}
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	notification, err := gateway.ParseNotification(context.Background(), payload)
+	require.NoError(t, err)
+	assert.True(t, notification.SignatureValid)
+	assert.Equal(t, payment.StatusSuccess, notification.Status)
+	assert.Equal(t, 2024, notification.TransactionTime.Year())
+
+	body["signature_key"] = "palsu"
+	payload, err = json.Marshal(body)
+	require.NoError(t, err)
+	notification, err = gateway.ParseNotification(context.Background(), payload)
+	assert.ErrorIs(t, err, payment.ErrInvalidSignature)
+	require.NotNil(t, notification)
+	assert.False(t, notification.SignatureValid)
+
+	_, err = gateway.ParseNotification(context.Background(), []byte("bukan json"))
+	assert.ErrorIs(t, err, payment.ErrInvalidPayload)
+}
+
+func TestDonationStatus(t *testing.T) {
+	cases := map[string]struct {
+		transactionStatus string
+		fraudStatus       string
+		want              string
+	}{
+		"pending":           {"pending", "", payment.StatusPending},
+		"capture accept":    {"capture", "accept", payment.StatusSuccess},
+		"capture challenge": {"capture", "challenge", payment.StatusPending},
+		"capture deny":      {"capture", "deny", payment.StatusFailed},
+		"settlement":        {"settlement", "", payment.StatusSuccess},
+		"deny":              {"deny", "", payment.StatusFailed},
+		"cancel":            {"cancel", "", payment.StatusCancelled},
+		"expire":            {"expire", "", payment.StatusExpired},
+		"refund":            {"refund", "", payment.StatusRefunded},
+		"partial_refund":    {"partial_refund", "", payment.StatusPartiallyRefunded},
+		"unknown":           {"authorize", "", ""},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, midtrans.DonationStatus(tc.transactionStatus, tc.fraudStatus))
+		})
+	}
+}
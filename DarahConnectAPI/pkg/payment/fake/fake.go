@@ -0,0 +1,336 @@
+// Package fake adalah gateway pembayaran di dalam proses untuk pengembangan dan pengujian
+// tanpa Midtrans. Pendonor diarahkan ke halaman checkout tiruan, lalu hasil pembayaran dikirim
+// ke webhook donasi seperti notifikasi gateway sungguhan.
+package fake
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/payment"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/route"
+)
+
+// WebhookPath adalah route webhook donasi yang menerima notifikasi gateway
+const WebhookPath = "/api/v1/donation/webhook"
+
+type order struct {
+	Id            string
+	Amount        int64
+	Refunded      int64
+	Status        string
+	TransactionId string
+	CustomerName  string
+	UpdatedAt     time.Time
+}
+
+// notificationBody adalah payload webhook gateway fake. Statusnya langsung status donasi.
+type notificationBody struct {
+	OrderId           string `json:"order_id"`
+	TransactionId     string `json:"transaction_id"`
+	TransactionStatus string `json:"transaction_status"`
+	StatusCode        string `json:"status_code"`
+	GrossAmount       string `json:"gross_amount"`
+	TransactionTime   string `json:"transaction_time"`
+	RefundAmount      string `json:"refund_amount,omitempty"`
+	Signature         string `json:"signature"`
+}
+
+type Gateway struct {
+	publicURL string
+	secret    []byte
+	client    *http.Client
+
+	mu     sync.Mutex
+	orders map[string]*order
+}
+
+func NewGateway(cfg *configs.PaymentConfig) (*Gateway, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return &Gateway{
+		publicURL: strings.TrimRight(cfg.PublicURL, "/"),
+		secret:    secret,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		orders:    make(map[string]*order),
+	}, nil
+}
+
+func (g *Gateway) Name() string {
+	return "fake"
+}
+
+func (g *Gateway) CreateCharge(ctx context.Context, charge payment.Charge) (*payment.ChargeResult, error) {
+	if charge.OrderId == "" || charge.Amount <= 0 {
+		return nil, errors.New("order id dan nominal pembayaran wajib diisi")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, exists := g.orders[charge.OrderId]; exists {
+		return nil, errors.New("order id sudah digunakan")
+	}
+	g.orders[charge.OrderId] = &order{
+		Id:           charge.OrderId,
+		Amount:       charge.Amount,
+		Status:       payment.StatusPending,
+		CustomerName: charge.CustomerName,
+		UpdatedAt:    time.Now(),
+	}
+
+	return &payment.ChargeResult{
+		Token:       randomId(),
+		RedirectURL: g.publicURL + "/api/v1/" + checkoutPath(charge.OrderId),
+	}, nil
+}
+
+func (g *Gateway) ParseNotification(ctx context.Context, payload []byte) (*payment.Notification, error) {
+	var body notificationBody
+	if err := json.Unmarshal(payload, &body); err != nil || body.OrderId == "" {
+		return nil, payment.ErrInvalidPayload
+	}
+
+	notification := body.notification()
+	expected := g.sign(body.OrderId, body.TransactionStatus, body.GrossAmount)
+	notification.SignatureValid = hmac.Equal([]byte(expected), []byte(body.Signature))
+	if !notification.SignatureValid {
+		return notification, payment.ErrInvalidSignature
+	}
+	return notification, nil
+}
+
+func (g *Gateway) QueryStatus(ctx context.Context, orderId string) (*payment.Notification, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	o, ok := g.orders[orderId]
+	if !ok {
+		return nil, payment.ErrOrderNotFound
+	}
+	notification := g.body(o).notification()
+	notification.SignatureValid = true
+	return notification, nil
+}
+
+// Refund mengubah order menjadi refunded atau partially_refunded lalu mengirim webhook
+func (g *Gateway) Refund(ctx context.Context, orderId string, amount int64, reason string) error {
+	g.mu.Lock()
+	o, ok := g.orders[orderId]
+	if !ok {
+		g.mu.Unlock()
+		return payment.ErrOrderNotFound
+	}
+	if amount <= 0 {
+		amount = o.Amount - o.Refunded
+	}
+	status := payment.StatusRefunded
+	if o.Refunded+amount < o.Amount {
+		status = payment.StatusPartiallyRefunded
+	}
+	if !payment.CanTransition(o.Status, status) && !(o.Status == status && status == payment.StatusPartiallyRefunded) {
+		g.mu.Unlock()
+		return fmt.Errorf("order berstatus %s tidak dapat direfund", o.Status)
+	}
+	if o.Refunded+amount > o.Amount {
+		g.mu.Unlock()
+		return errors.New("nominal refund melebihi nominal pembayaran")
+	}
+	o.Refunded += amount
+	o.Status = status
+	o.UpdatedAt = time.Now()
+	body := g.body(o)
+	g.mu.Unlock()
+
+	return g.send(ctx, body)
+}
+
+// Routes menyediakan halaman checkout tiruan tanpa login, sama seperti halaman Snap Midtrans
+func (g *Gateway) Routes() []route.Route {
+	return []route.Route{
+		{
+			Method:  http.MethodGet,
+			Path:    checkoutPath(":order_id"),
+			Handler: g.checkoutPage,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    checkoutPath(":order_id"),
+			Handler: g.checkout,
+		},
+	}
+}
+
+func (g *Gateway) checkoutPage(ctx echo.Context) error {
+	o, ok := g.order(ctx.Param("order_id"))
+	if !ok {
+		return ctx.String(http.StatusNotFound, "order tidak ditemukan")
+	}
+	return g.render(ctx, http.StatusOK, o, "")
+}
+
+// checkout menerapkan pilihan hasil pembayaran lalu mengirim webhook ke API
+func (g *Gateway) checkout(ctx echo.Context) error {
+	status := ctx.FormValue("result")
+	switch status {
+	case payment.StatusSuccess, payment.StatusFailed, payment.StatusExpired, payment.StatusCancelled:
+	default:
+		return ctx.String(http.StatusBadRequest, "hasil pembayaran tidak valid")
+	}
+
+	g.mu.Lock()
+	o, ok := g.orders[ctx.Param("order_id")]
+	if !ok {
+		g.mu.Unlock()
+		return ctx.String(http.StatusNotFound, "order tidak ditemukan")
+	}
+	if !payment.CanTransition(o.Status, status) {
+		snapshot := *o
+		g.mu.Unlock()
+		return g.render(ctx, http.StatusConflict, snapshot, "Order berstatus "+snapshot.Status+" tidak dapat menjadi "+status)
+	}
+	o.Status = status
+	o.UpdatedAt = time.Now()
+	if o.TransactionId == "" {
+		o.TransactionId = randomId()
+	}
+	snapshot := *o
+	body := g.body(o)
+	g.mu.Unlock()
+
+	if err := g.send(ctx.Request().Context(), body); err != nil {
+		return g.render(ctx, http.StatusBadGateway, snapshot, "Webhook gagal dikirim: "+err.Error())
+	}
+	return g.render(ctx, http.StatusOK, snapshot, "Webhook terkirim")
+}
+
+func (g *Gateway) order(orderId string) (order, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	o, ok := g.orders[orderId]
+	if !ok {
+		return order{}, false
+	}
+	return *o, true
+}
+
+// body menyusun notifikasi bertanda tangan dari status order saat ini; dipanggil saat mu terkunci
+func (g *Gateway) body(o *order) notificationBody {
+	grossAmount := fmt.Sprintf("%d.00", o.Amount)
+	var refundAmount string
+	if o.Refunded > 0 {
+		refundAmount = fmt.Sprintf("%d.00", o.Refunded)
+	}
+	return notificationBody{
+		OrderId:           o.Id,
+		TransactionId:     o.TransactionId,
+		TransactionStatus: o.Status,
+		StatusCode:        "200",
+		GrossAmount:       grossAmount,
+		TransactionTime:   o.UpdatedAt.UTC().Format(time.RFC3339),
+		RefundAmount:      refundAmount,
+		Signature:         g.sign(o.Id, o.Status, grossAmount),
+	}
+}
+
+func (g *Gateway) send(ctx context.Context, body notificationBody) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.publicURL+WebhookPath, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook dibalas status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sign menghitung HMAC-SHA256 dengan kunci acak yang hanya dikenal proses ini
+func (g *Gateway) sign(orderId, status, grossAmount string) string {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write([]byte(orderId + "|" + status + "|" + grossAmount))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (b notificationBody) notification() *payment.Notification {
+	notification := &payment.Notification{
+		OrderId:           b.OrderId,
+		TransactionId:     b.TransactionId,
+		TransactionStatus: b.TransactionStatus,
+		StatusCode:        b.StatusCode,
+		GrossAmount:       b.GrossAmount,
+		RefundedAmount:    payment.ParseAmount(b.RefundAmount),
+	}
+	if parsed, err := time.Parse(time.RFC3339, b.TransactionTime); err == nil {
+		notification.TransactionTime = parsed
+	}
+	switch b.TransactionStatus {
+	case payment.StatusPending, payment.StatusSuccess, payment.StatusFailed, payment.StatusCancelled,
+		payment.StatusExpired, payment.StatusRefunded, payment.StatusPartiallyRefunded:
+		notification.Status = b.TransactionStatus
+	}
+	return notification
+}
+
+func checkoutPath(orderId string) string {
+	return "payment/fake/checkout/" + orderId
+}
+
+func randomId() string {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+var checkoutTemplate = template.Must(template.New("checkout").Parse(`<!DOCTYPE html>
+<html lang="id">
+<head><meta charset="utf-8"><title>Checkout Fake - DarahConnect</title></head>
+<body style="font-family: sans-serif; max-width: 480px; margin: 40px auto;">
+<h2>Gateway Pembayaran Fake</h2>
+<p>Order: <b>{{.Order.Id}}</b><br>Nama: {{.Order.CustomerName}}<br>Nominal: Rp{{.Order.Amount}}<br>Status: <b>{{.Order.Status}}</b></p>
+{{if .Message}}<p><i>{{.Message}}</i></p>{{end}}
+{{if eq .Order.Status "pending"}}
+<form method="post">
+<button name="result" value="success">Bayar</button>
+<button name="result" value="failed">Gagal</button>
+<button name="result" value="expired">Kedaluwarsa</button>
+<button name="result" value="cancelled">Batalkan</button>
+</form>
+{{end}}
+</body>
+</html>
+`))
+
+func (g *Gateway) render(ctx echo.Context, code int, o order, message string) error {
+	var buf bytes.Buffer
+	if err := checkoutTemplate.Execute(&buf, map[string]interface{}{"Order": o, "Message": message}); err != nil {
+		return err
+	}
+	return ctx.HTMLBlob(code, buf.Bytes())
+}
@@ -0,0 +1,130 @@
+package fake_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/payment"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/payment/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newServer menjalankan route checkout gateway dan webhook yang mem-parse notifikasi masuk
+func newServer(t *testing.T) (*fake.Gateway, *[]*payment.Notification) {
+	var (
+		mu            sync.Mutex
+		notifications []*payment.Notification
+		gateway       *fake.Gateway
+	)
+
+	e := echo.New()
+	e.POST(fake.WebhookPath, func(ctx echo.Context) error {
+		payload, err := io.ReadAll(ctx.Request().Body)
+		if err != nil {
+			return err
+		}
+		notification, err := gateway.ParseNotification(ctx.Request().Context(), payload)
+		if err != nil {
+			return ctx.NoContent(http.StatusUnauthorized)
+		}
+		mu.Lock()
+		notifications = append(notifications, notification)
+		mu.Unlock()
+		return ctx.NoContent(http.StatusOK)
+	})
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+
+	var err error
+	gateway, err = fake.NewGateway(&configs.PaymentConfig{PublicURL: srv.URL})
+	require.NoError(t, err)
+	for _, r := range gateway.Routes() {
+		e.Add(r.Method, "/api/v1/"+r.Path, r.Handler)
+	}
+	return gateway, &notifications
+}
+
+func TestCheckoutSendsSignedWebhook(t *testing.T) {
+	gateway, notifications := newServer(t)
+	ctx := context.Background()
+
+	charge, err := gateway.CreateCharge(ctx, payment.Charge{OrderId: "ORDER-1-1", Amount: 50000, CustomerName: "Budi"})
+	require.NoError(t, err)
+
+	resp, err := http.Get(charge.RedirectURL)
+	require.NoError(t, err)
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(page), "ORDER-1-1")
+
+	resp, err = http.PostForm(charge.RedirectURL, url.Values{"result": {payment.StatusSuccess}})
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.Len(t, *notifications, 1)
+	notification := (*notifications)[0]
+	assert.True(t, notification.SignatureValid)
+	assert.Equal(t, "ORDER-1-1", notification.OrderId)
+	assert.Equal(t, payment.StatusSuccess, notification.Status)
+	assert.True(t, payment.GrossAmountEquals(notification.GrossAmount, 50000))
+
+	status, err := gateway.QueryStatus(ctx, "ORDER-1-1")
+	require.NoError(t, err)
+	assert.Equal(t, payment.StatusSuccess, status.Status)
+
+	// Order yang sudah dibayar tidak bisa dibayar ulang
+	resp, err = http.PostForm(charge.RedirectURL, url.Values{"result": {payment.StatusFailed}})
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.Len(t, *notifications, 1)
+}
+
+func TestRefund(t *testing.T) {
+	gateway, notifications := newServer(t)
+	ctx := context.Background()
+
+	charge, err := gateway.CreateCharge(ctx, payment.Charge{OrderId: "ORDER-1-2", Amount: 50000})
+	require.NoError(t, err)
+	assert.Error(t, gateway.Refund(ctx, "ORDER-1-2", 0, "belum dibayar"))
+
+	resp, err := http.PostForm(charge.RedirectURL, url.Values{"result": {payment.StatusSuccess}})
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.NoError(t, gateway.Refund(ctx, "ORDER-1-2", 20000, "sebagian"))
+	require.NoError(t, gateway.Refund(ctx, "ORDER-1-2", 0, "sisa"))
+	assert.Error(t, gateway.Refund(ctx, "ORDER-1-2", 0, "lagi"))
+
+	require.Len(t, *notifications, 3)
+	assert.Equal(t, payment.StatusPartiallyRefunded, (*notifications)[1].Status)
+	assert.Equal(t, payment.StatusRefunded, (*notifications)[2].Status)
+
+	_, err = gateway.QueryStatus(ctx, "ORDER-TIDAK-ADA")
+	assert.ErrorIs(t, err, payment.ErrOrderNotFound)
+}
+
+func TestParseNotificationRejectsForgedSignature(t *testing.T) {
+	gateway, err := fake.NewGateway(&configs.PaymentConfig{PublicURL: "http://localhost"})
+	require.NoError(t, err)
+
+	payload := `{"order_id":"ORDER-1-3","transaction_status":"success","gross_amount":"50000.00","signature":"palsu"}`
+	notification, err := gateway.ParseNotification(context.Background(), []byte(payload))
+	assert.ErrorIs(t, err, payment.ErrInvalidSignature)
+	require.NotNil(t, notification)
+	assert.False(t, notification.SignatureValid)
+
+	_, err = gateway.ParseNotification(context.Background(), []byte(strings.Repeat("x", 3)))
+	assert.ErrorIs(t, err, payment.ErrInvalidPayload)
+}
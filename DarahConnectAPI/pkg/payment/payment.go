@@ -0,0 +1,116 @@
+// Package payment mendefinisikan kontrak gateway pembayaran donasi dan status donasi yang
+// sama untuk semua gateway.
+package payment
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/route"
+)
+
+// Status donasi hasil pemetaan status transaksi gateway
+const (
+	StatusPending           = "pending"
+	StatusSuccess           = "success"
+	StatusFailed            = "failed"
+	StatusCancelled         = "cancelled"
+	StatusExpired           = "expired"
+	StatusRefunded          = "refunded"
+	StatusPartiallyRefunded = "partially_refunded"
+)
+
+var (
+	ErrInvalidPayload   = errors.New("format notifikasi pembayaran tidak valid")
+	ErrInvalidSignature = errors.New("signature notifikasi pembayaran tidak valid")
+	ErrOrderNotFound    = errors.New("order tidak ditemukan di gateway pembayaran")
+)
+
+// Charge adalah permintaan pembayaran untuk satu donasi
+type Charge struct {
+	OrderId       string
+	Amount        int64
+	CustomerName  string
+	CustomerEmail string
+}
+
+// ChargeResult berisi halaman pembayaran yang dibuka pendonor
+type ChargeResult struct {
+	Token       string
+	RedirectURL string
+}
+
+// Notification adalah status transaksi yang dikirim atau dilaporkan gateway
+type Notification struct {
+	OrderId           string
+	TransactionId     string
+	TransactionStatus string // status asli gateway, dipakai sebagai kunci idempotensi
+	FraudStatus       string
+	StatusCode        string
+	GrossAmount       string
+	// Total nominal yang sudah direfund menurut gateway; 0 jika tidak dilaporkan
+	RefundedAmount  int64
+	TransactionTime time.Time
+	// Status donasi hasil pemetaan; kosong jika status gateway tidak dikenal
+	Status         string
+	SignatureValid bool
+}
+
+type PaymentGateway interface {
+	// Name dipakai untuk mencatat gateway yang memproses donasi dan notifikasi
+	Name() string
+	CreateCharge(ctx context.Context, charge Charge) (*ChargeResult, error)
+	// ParseNotification membaca webhook gateway. Jika signature tidak valid, notification tetap
+	// dikembalikan bersama ErrInvalidSignature agar bisa disimpan.
+	ParseNotification(ctx context.Context, payload []byte) (*Notification, error)
+	QueryStatus(ctx context.Context, orderId string) (*Notification, error)
+	// Refund meminta pengembalian dana; amount 0 berarti seluruh nominal. Perubahan status
+	// donasi tetap menunggu notifikasi dari gateway.
+	Refund(ctx context.Context, orderId string, amount int64, reason string) error
+}
+
+// RouteProvider diimplementasikan gateway yang membutuhkan route HTTP sendiri, misalnya
+// halaman checkout gateway fake
+type RouteProvider interface {
+	Routes() []route.Route
+}
+
+// transitions berisi perpindahan status donasi yang boleh terjadi. Status yang tidak punya
+// tujuan adalah status akhir.
+var transitions = map[string][]string{
+	StatusPending:           {StatusSuccess, StatusFailed, StatusCancelled, StatusExpired},
+	StatusSuccess:           {StatusCancelled, StatusRefunded, StatusPartiallyRefunded},
+	StatusPartiallyRefunded: {StatusRefunded},
+}
+
+// CanTransition melaporkan apakah donasi boleh pindah dari status from ke status to
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseAmount membaca nominal gateway seperti "50000.00" menjadi rupiah; nilai kosong atau
+// tidak valid menghasilkan 0
+func ParseAmount(amount string) int64 {
+	value, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return 0
+	}
+	return int64(math.Round(value))
+}
+
+// GrossAmountEquals membandingkan gross_amount gateway ("10000.00") dengan nominal donasi
+func GrossAmountEquals(grossAmount string, amount int64) bool {
+	value, err := strconv.ParseFloat(grossAmount, 64)
+	if err != nil {
+		return false
+	}
+	return int64(math.Round(value*100)) == amount*100
+}
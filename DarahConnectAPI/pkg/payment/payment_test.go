@@ -0,0 +1,31 @@
+package payment_test
+
+import (
+	"testing"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/payment"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, payment.CanTransition(payment.StatusPending, payment.StatusSuccess))
+	assert.True(t, payment.CanTransition(payment.StatusSuccess, payment.StatusRefunded))
+	assert.True(t, payment.CanTransition(payment.StatusPartiallyRefunded, payment.StatusRefunded))
+	assert.False(t, payment.CanTransition(payment.StatusExpired, payment.StatusSuccess))
+	assert.False(t, payment.CanTransition(payment.StatusRefunded, payment.StatusSuccess))
+	assert.False(t, payment.CanTransition(payment.StatusSuccess, payment.StatusPending))
+}
+
+func TestParseAmount(t *testing.T) {
+	assert.Equal(t, int64(20000), payment.ParseAmount("20000.00"))
+	assert.Equal(t, int64(20000), payment.ParseAmount("20000"))
+	assert.Equal(t, int64(0), payment.ParseAmount(""))
+	assert.Equal(t, int64(0), payment.ParseAmount("abc"))
+}
+
+func TestGrossAmountEquals(t *testing.T) {
+	assert.True(t, payment.GrossAmountEquals("50000.00", 50000))
+	assert.True(t, payment.GrossAmountEquals("50000", 50000))
+	assert.False(t, payment.GrossAmountEquals("50000.50", 50000))
+	assert.False(t, payment.GrossAmountEquals("abc", 50000))
+}
@@ -0,0 +1,60 @@
+package rbac
+
+// Permission dengan format resource:aksi. Daftar lengkap beserta deskripsinya tersimpan di
+// tabel permissions; permission baru ditambahkan lewat migrasi.
+const (
+	DashboardDonor = "dashboard:donor"
+	DashboardAdmin = "dashboard:admin"
+
+	HealthPassportOwn    = "health_passport:own"
+	HealthPassportRead   = "health_passport:read"
+	HealthPassportVerify = "health_passport:verify"
+	HealthPassportDelete = "health_passport:delete"
+
+	NotificationOwn    = "notification:own"
+	NotificationRead   = "notification:read"
+	NotificationCreate = "notification:create"
+
+	DonorRegistrationCreate = "donor_registration:create"
+	DonorRegistrationRead   = "donor_registration:read"
+	DonorRegistrationUpdate = "donor_registration:update"
+	DonorRegistrationManage = "donor_registration:manage" // melihat dan mengubah milik pengguna lain
+
+	DonorScheduleOwn = "donor_schedule:own"
+
+	DonationCreate = "donation:create"
+	DonationRead   = "donation:read"
+	DonationRefund = "donation:refund"
+
+	CertificateOwn  = "certificate:own"
+	CertificateRead = "certificate:read" // melihat sertifikat milik pengguna lain
+	CertificateMint = "certificate:mint" // memantau rekonsiliasi mint sertifikat
+	WalletLink      = "wallet:link"
+
+	BloodRequestOwn    = "blood_request:own"
+	BloodRequestRead   = "blood_request:read"
+	BloodRequestVerify = "blood_request:verify"
+	BloodRequestDelete = "blood_request:delete"
+	BloodRequestManage = "blood_request:manage" // mengubah dan menghapus milik pengguna lain
+	CampaignManage     = "campaign:manage"
+
+	BloodDonationOwn    = "blood_donation:own"
+	BloodDonationCreate = "blood_donation:create"
+	BloodDonationRead   = "blood_donation:read"
+	BloodDonationDelete = "blood_donation:delete"
+	BloodDonationVerify = "blood_donation:verify"
+	BloodDonationManage = "blood_donation:manage" // melihat semua dan menghapus milik pengguna lain
+
+	HospitalRead   = "hospital:read"
+	HospitalCreate = "hospital:create"
+	HospitalManage = "hospital:manage"
+
+	InventoryRead   = "inventory:read"
+	InventoryManage = "inventory:manage"
+
+	UserRead   = "user:read"
+	UserDelete = "user:delete"
+	RoleManage = "role:manage"
+
+	AuditLogRead = "audit_log:read"
+)
@@ -0,0 +1,49 @@
+package rbac
+
+import "sync"
+
+// Role bawaan yang dibuat migrasi. Administrator mendapat setiap permission yang diberikan
+// migrasi kepadanya dan permission-nya tidak bisa diubah lewat API (ErrRoleAdministrator).
+// Authorizer tidak memperlakukannya secara khusus: permission yang tidak diberikan, seperti
+// hospital:staff, tetap tidak dimiliki.
+const (
+	RoleAdministrator = "Administrator"
+	RoleUser          = "User"
+)
+
+// Authorizer menyimpan permission setiap role di memori agar middleware tidak perlu membaca
+// database pada setiap permintaan. Isinya dimuat ulang dari database oleh RoleService.
+type Authorizer struct {
+	mu    sync.RWMutex
+	roles map[string]map[string]struct{}
+}
+
+func NewAuthorizer() *Authorizer {
+	return &Authorizer{roles: map[string]map[string]struct{}{}}
+}
+
+// Replace mengganti seluruh pemetaan role ke permission
+func (a *Authorizer) Replace(rolePermissions map[string][]string) {
+	roles := make(map[string]map[string]struct{}, len(rolePermissions))
+	for role, permissions := range rolePermissions {
+		set := make(map[string]struct{}, len(permissions))
+		for _, permission := range permissions {
+			set[permission] = struct{}{}
+		}
+		roles[role] = set
+	}
+
+	a.mu.Lock()
+	a.roles = roles
+	a.mu.Unlock()
+}
+
+// Allowed melaporkan apakah role memiliki permission. Role yang tidak dikenal tidak memiliki
+// permission apa pun.
+func (a *Authorizer) Allowed(role, permission string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	_, ok := a.roles[role][permission]
+	return ok
+}
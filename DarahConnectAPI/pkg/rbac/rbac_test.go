@@ -0,0 +1,42 @@
+package rbac_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/rbac"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorizerAllowed(t *testing.T) {
+	authorizer := rbac.NewAuthorizer()
+	assert.False(t, authorizer.Allowed(rbac.RoleUser, rbac.DashboardDonor))
+
+	authorizer.Replace(map[string][]string{
+		rbac.RoleUser:    {rbac.DashboardDonor},
+		"Hospital Staff": {rbac.BloodRequestVerify},
+	})
+	assert.True(t, authorizer.Allowed(rbac.RoleUser, rbac.DashboardDonor))
+	assert.False(t, authorizer.Allowed(rbac.RoleUser, rbac.BloodRequestVerify))
+	assert.True(t, authorizer.Allowed("Hospital Staff", rbac.BloodRequestVerify))
+	assert.False(t, authorizer.Allowed("Tidak Dikenal", rbac.DashboardDonor))
+
+	// Permission yang dicabut tidak berlaku lagi setelah dimuat ulang
+	authorizer.Replace(map[string][]string{rbac.RoleUser: {}})
+	assert.False(t, authorizer.Allowed(rbac.RoleUser, rbac.DashboardDonor))
+	assert.False(t, authorizer.Allowed("Hospital Staff", rbac.BloodRequestVerify))
+}
+
+func TestHospitalScope(t *testing.T) {
+	_, ok := rbac.HospitalScope(context.Background())
+	assert.False(t, ok)
+
+	hospitalIds, ok := rbac.HospitalScope(rbac.WithHospitalScope(context.Background(), []int64{3, 7}))
+	assert.True(t, ok)
+	assert.Equal(t, []int64{3, 7}, hospitalIds)
+
+	// Staf tanpa rumah sakit tetap dibatasi, bukan dianggap tidak dibatasi
+	hospitalIds, ok = rbac.HospitalScope(rbac.WithHospitalScope(context.Background(), nil))
+	assert.True(t, ok)
+	assert.Empty(t, hospitalIds)
+}
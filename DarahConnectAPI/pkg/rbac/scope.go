@@ -0,0 +1,29 @@
+package rbac
+
+import "context"
+
+// HospitalStaff menandai role yang aksesnya dibatasi ke rumah sakit tempat pengguna bertugas
+const HospitalStaff = "hospital:staff"
+
+// HospitalResolver mencari rumah sakit tempat pengguna terdaftar sebagai staf
+type HospitalResolver interface {
+	HospitalIds(ctx context.Context, userId int64) ([]int64, error)
+}
+
+type hospitalScopeKey struct{}
+
+// WithHospitalScope membatasi query repository dalam context ke rumah sakit tersebut. Daftar
+// kosong berarti pengguna tidak boleh melihat data rumah sakit mana pun.
+func WithHospitalScope(ctx context.Context, hospitalIds []int64) context.Context {
+	if hospitalIds == nil {
+		hospitalIds = []int64{}
+	}
+	return context.WithValue(ctx, hospitalScopeKey{}, hospitalIds)
+}
+
+// HospitalScope mengembalikan rumah sakit yang boleh diakses; ok bernilai false jika context
+// tidak dibatasi
+func HospitalScope(ctx context.Context) (hospitalIds []int64, ok bool) {
+	hospitalIds, ok = ctx.Value(hospitalScopeKey{}).([]int64)
+	return hospitalIds, ok
+}
@@ -66,3 +66,10 @@ func ErrorResponse(code int, message string) Response {
 		Data: nil,
 	}
 }
+
+func ErrorResponseWithData(code int, message string, data interface{}) Response {
+	return Response{
+		Meta: Meta{Code: code, Message: message},
+		Data: data,
+	}
+}
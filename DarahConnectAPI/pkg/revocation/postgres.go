@@ -0,0 +1,66 @@
+package revocation
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// tokenRevocation adalah satu baris denylist di tabel token_revocations
+type tokenRevocation struct {
+	Key       string `gorm:"primaryKey"`
+	RevokedAt time.Time
+	ExpiresAt time.Time
+}
+
+func (tokenRevocation) TableName() string {
+	return "public.token_revocations"
+}
+
+type postgresStore struct {
+	db *gorm.DB
+}
+
+func NewPostgresStore(db *gorm.DB) Store {
+	return &postgresStore{db}
+}
+
+func (s *postgresStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	return s.save(ctx, tokenRevocation{Key: tokenKey(jti), RevokedAt: time.Now(), ExpiresAt: expiresAt})
+}
+
+func (s *postgresStore) RevokeUser(ctx context.Context, userId int64, revokedAt, expiresAt time.Time) error {
+	return s.save(ctx, tokenRevocation{Key: userKey(userId), RevokedAt: revokedAt, ExpiresAt: expiresAt})
+}
+
+// save juga membersihkan baris yang sudah kedaluwarsa agar tabel tidak terus membesar
+func (s *postgresStore) save(ctx context.Context, revocation tokenRevocation) error {
+	db := s.db.WithContext(ctx)
+	if err := db.Where("expires_at < ?", time.Now()).Delete(&tokenRevocation{}).Error; err != nil {
+		return err
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"revoked_at", "expires_at"}),
+	}).Create(&revocation).Error
+}
+
+func (s *postgresStore) IsRevoked(ctx context.Context, jti string, userId int64, issuedAt time.Time) (bool, error) {
+	keys := []string{userKey(userId)}
+	if jti != "" {
+		keys = append(keys, tokenKey(jti))
+	}
+
+	var revocations []tokenRevocation
+	if err := s.db.WithContext(ctx).Where("key IN ? AND expires_at > ?", keys, time.Now()).Find(&revocations).Error; err != nil {
+		return false, err
+	}
+	for _, revocation := range revocations {
+		if revocation.Key != userKey(userId) || revokedBefore(issuedAt, revocation.RevokedAt) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
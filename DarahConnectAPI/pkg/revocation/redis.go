@@ -0,0 +1,59 @@
+package revocation
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisStore struct {
+	client *redis.Client
+}
+
+func NewRedisStore(client *redis.Client) Store {
+	return &redisStore{client}
+}
+
+func (s *redisStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(ctx, tokenKey(jti), 1, ttl).Err()
+}
+
+func (s *redisStore) RevokeUser(ctx context.Context, userId int64, revokedAt, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(ctx, userKey(userId), revokedAt.Unix(), ttl).Err()
+}
+
+func (s *redisStore) IsRevoked(ctx context.Context, jti string, userId int64, issuedAt time.Time) (bool, error) {
+	if jti != "" {
+		exists, err := s.client.Exists(ctx, tokenKey(jti)).Result()
+		if err != nil {
+			return false, err
+		}
+		if exists > 0 {
+			return true, nil
+		}
+	}
+
+	value, err := s.client.Get(ctx, userKey(userId)).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	revokedAt, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false, err
+	}
+	return revokedBefore(issuedAt, time.Unix(revokedAt, 0)), nil
+}
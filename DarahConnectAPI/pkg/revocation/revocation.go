@@ -0,0 +1,60 @@
+// Package revocation menyimpan daftar access token yang sudah dicabut (denylist). Token dicabut
+// satu per satu lewat jti saat logout, atau seluruh token seorang pengguna yang terbit sebelum
+// waktu tertentu saat logout dari semua perangkat atau saat akun dihapus.
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
+	"github.com/redis/go-redis/v9"
+
+	"gorm.io/gorm"
+)
+
+type Store interface {
+	// RevokeToken mencabut satu token sampai token tersebut kedaluwarsa
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	// RevokeUser mencabut semua token pengguna yang terbit sebelum atau pada revokedAt
+	RevokeUser(ctx context.Context, userId int64, revokedAt, expiresAt time.Time) error
+	// IsRevoked memeriksa token dengan jti dan waktu terbit issuedAt milik userId
+	IsRevoked(ctx context.Context, jti string, userId int64, issuedAt time.Time) (bool, error)
+}
+
+// New memakai Redis jika bisa dihubungi dan Postgres jika tidak
+func New(ctx context.Context, cfg configs.RedisConfig, db *gorm.DB) Store {
+	if cfg.Host != "" {
+		client := redis.NewClient(&redis.Options{
+			Addr:     net.JoinHostPort(cfg.Host, cfg.Port),
+			Password: cfg.Password,
+		})
+
+		pingCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		defer cancel()
+		err := client.Ping(pingCtx).Err()
+		if err == nil {
+			return NewRedisStore(client)
+		}
+		log.Printf("Redis tidak tersedia, denylist token memakai Postgres: %v", err)
+		client.Close()
+	}
+	return NewPostgresStore(db)
+}
+
+func tokenKey(jti string) string {
+	return "revoked:jti:" + jti
+}
+
+func userKey(userId int64) string {
+	return fmt.Sprintf("revoked:user:%d", userId)
+}
+
+// revokedBefore menilai token yang terbit pada detik yang sama dengan pencabutan juga dicabut,
+// karena iat JWT hanya berpresisi detik.
+func revokedBefore(issuedAt, revokedAt time.Time) bool {
+	return !issuedAt.After(revokedAt.Truncate(time.Second))
+}
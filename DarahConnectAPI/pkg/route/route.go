@@ -6,5 +6,6 @@ type Route struct {
 	Method  string
 	Path    string
 	Handler echo.HandlerFunc
-	Roles   []string
+	// Permission yang wajib dimiliki role pengguna; kosong berarti cukup login
+	Permission string
 }
@@ -0,0 +1,167 @@
+// Package screening berisi kuesioner skrining kesehatan pendonor beserta aturan yang
+// menentukan apakah health passport langsung aktif, ditunda, atau perlu ditinjau admin.
+package screening
+
+import (
+	"fmt"
+	"time"
+)
+
+// CurrentVersion adalah versi kuesioner yang berlaku. Naikkan setiap kali pertanyaan
+// atau aturan berubah agar jawaban lama tetap bisa ditelusuri.
+const CurrentVersion = 1
+
+type Outcome string
+
+const (
+	OutcomeActive   Outcome = "active"
+	OutcomeDeferred Outcome = "deferred"
+	OutcomeReview   Outcome = "review"
+)
+
+// Batas pemeriksaan fisik pendonor
+const (
+	MinWeightKg     = 45.0
+	MinHemoglobin   = 12.5
+	MaxHemoglobin   = 17.0
+	MinSystolic     = 100
+	MaxSystolic     = 170
+	MinDiastolic    = 70
+	MaxDiastolic    = 100
+	SevereSystolic  = 180
+	SevereDiastolic = 110
+)
+
+// Lama penundaan untuk tiap alasan
+const (
+	illnessDeferral       = 14 * 24 * time.Hour
+	hemoglobinDeferral    = 14 * 24 * time.Hour
+	bloodPressureDeferral = 7 * 24 * time.Hour
+	weightDeferral        = 90 * 24 * time.Hour
+	travelDeferralMonths  = 12 // setelah kembali dari daerah endemis malaria
+	tattooDeferralMonths  = 6  // setelah tato atau tindik
+)
+
+// Answers adalah jawaban pendonor untuk satu kali pengisian kuesioner
+type Answers struct {
+	RecentIllness           bool
+	TakingMedication        bool
+	MalariaTravelReturnDate *time.Time
+	TattooDate              *time.Time
+	Hemoglobin              float64
+	WeightKg                float64
+	Systolic                int
+	Diastolic               int
+}
+
+// Reason menjelaskan jawaban yang menyebabkan penundaan atau peninjauan
+type Reason struct {
+	Code    string     `json:"code"`
+	Message string     `json:"message"`
+	Until   *time.Time `json:"until,omitempty"`
+}
+
+// Result adalah hasil penilaian kuesioner
+type Result struct {
+	Outcome       Outcome    `json:"outcome"`
+	DeferredUntil *time.Time `json:"deferred_until"`
+	Reasons       []Reason   `json:"reasons"`
+}
+
+// Question adalah satu pertanyaan kuesioner yang ditampilkan ke pendonor
+type Question struct {
+	Key      string `json:"key"`
+	Question string `json:"question"`
+	Type     string `json:"type"` // boolean, text, date, number
+	Unit     string `json:"unit,omitempty"`
+}
+
+// Questionnaire adalah kuesioner skrining versi tertentu
+type Questionnaire struct {
+	Version   int        `json:"version"`
+	Questions []Question `json:"questions"`
+}
+
+// Current mengembalikan kuesioner yang berlaku
+func Current() Questionnaire {
+	return Questionnaire{
+		Version: CurrentVersion,
+		Questions: []Question{
+			{Key: "recent_illness", Question: "Apakah Anda sakit (demam, flu, diare, atau infeksi) dalam 14 hari terakhir?", Type: "boolean"},
+			{Key: "taking_medication", Question: "Apakah Anda sedang mengonsumsi obat-obatan?", Type: "boolean"},
+			{Key: "medication_notes", Question: "Jika ya, sebutkan obat yang dikonsumsi", Type: "text"},
+			{Key: "malaria_travel_return_date", Question: "Jika dalam 12 bulan terakhir Anda bepergian ke daerah endemis malaria, kapan Anda kembali?", Type: "date"},
+			{Key: "tattoo_date", Question: "Jika dalam 6 bulan terakhir Anda membuat tato atau tindik, kapan tanggalnya?", Type: "date"},
+			{Key: "hemoglobin", Question: "Kadar hemoglobin", Type: "number", Unit: "g/dL"},
+			{Key: "weight_kg", Question: "Berat badan", Type: "number", Unit: "kg"},
+			{Key: "systolic", Question: "Tekanan darah sistolik", Type: "number", Unit: "mmHg"},
+			{Key: "diastolic", Question: "Tekanan darah diastolik", Type: "number", Unit: "mmHg"},
+		},
+	}
+}
+
+// Evaluate menilai jawaban kuesioner pada waktu now. Jawaban yang butuh pertimbangan medis
+// menghasilkan OutcomeReview, penundaan sementara menghasilkan OutcomeDeferred dengan tanggal
+// paling akhir dari semua penundaan, selain itu OutcomeActive.
+func Evaluate(answers Answers, now time.Time) Result {
+	result := Result{Outcome: OutcomeActive, Reasons: []Reason{}}
+
+	var until time.Time
+	review := false
+	addDeferral := func(code, message string, date time.Time) {
+		if !date.After(now) {
+			return
+		}
+		result.Reasons = append(result.Reasons, Reason{Code: code, Message: message, Until: &date})
+		if date.After(until) {
+			until = date
+		}
+	}
+	addReview := func(code, message string) {
+		result.Reasons = append(result.Reasons, Reason{Code: code, Message: message})
+		review = true
+	}
+
+	if answers.RecentIllness {
+		addDeferral("recent_illness", "Sakit dalam 14 hari terakhir", now.Add(illnessDeferral))
+	}
+	if answers.TakingMedication {
+		addReview("medication", "Sedang mengonsumsi obat, perlu ditinjau petugas")
+	}
+	if answers.MalariaTravelReturnDate != nil {
+		addDeferral("malaria_travel", "Baru kembali dari daerah endemis malaria", answers.MalariaTravelReturnDate.AddDate(0, travelDeferralMonths, 0))
+	}
+	if answers.TattooDate != nil {
+		addDeferral("tattoo", "Tato atau tindik dalam 6 bulan terakhir", answers.TattooDate.AddDate(0, tattooDeferralMonths, 0))
+	}
+
+	switch {
+	case answers.Hemoglobin < MinHemoglobin:
+		addDeferral("low_hemoglobin", fmt.Sprintf("Hemoglobin di bawah %.1f g/dL", MinHemoglobin), now.Add(hemoglobinDeferral))
+	case answers.Hemoglobin > MaxHemoglobin:
+		addReview("high_hemoglobin", fmt.Sprintf("Hemoglobin di atas %.1f g/dL", MaxHemoglobin))
+	}
+
+	if answers.WeightKg < MinWeightKg {
+		addDeferral("low_weight", fmt.Sprintf("Berat badan di bawah %.0f kg", MinWeightKg), now.Add(weightDeferral))
+	}
+
+	switch {
+	case answers.Systolic >= SevereSystolic || answers.Diastolic >= SevereDiastolic:
+		addReview("severe_blood_pressure", "Tekanan darah sangat tinggi, perlu ditinjau petugas")
+	case answers.Systolic < MinSystolic || answers.Systolic > MaxSystolic ||
+		answers.Diastolic < MinDiastolic || answers.Diastolic > MaxDiastolic:
+		addDeferral("blood_pressure", fmt.Sprintf("Tekanan darah di luar batas %d-%d/%d-%d mmHg", MinSystolic, MaxSystolic, MinDiastolic, MaxDiastolic), now.Add(bloodPressureDeferral))
+	}
+
+	switch {
+	case review:
+		result.Outcome = OutcomeReview
+	case !until.IsZero():
+		result.Outcome = OutcomeDeferred
+	}
+	if !until.IsZero() {
+		result.DeferredUntil = &until
+	}
+	return result
+}
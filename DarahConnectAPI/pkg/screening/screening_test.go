@@ -0,0 +1,143 @@
+package screening_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/screening"
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2024, time.October, 18, 9, 0, 0, 0, time.UTC)
+
+func healthy() screening.Answers {
+	return screening.Answers{
+		Hemoglobin: 14,
+		WeightKg:   60,
+		Systolic:   120,
+		Diastolic:  80,
+	}
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestEvaluate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		modify        func(a *screening.Answers)
+		outcome       screening.Outcome
+		codes         []string
+		deferredUntil *time.Time
+	}{
+		{
+			name:    "healthy donor",
+			modify:  func(a *screening.Answers) {},
+			outcome: screening.OutcomeActive,
+		},
+		{
+			name:          "recent illness",
+			modify:        func(a *screening.Answers) { a.RecentIllness = true },
+			outcome:       screening.OutcomeDeferred,
+			codes:         []string{"recent_illness"},
+			deferredUntil: ptr(now.AddDate(0, 0, 14)),
+		},
+		{
+			name:    "taking medication",
+			modify:  func(a *screening.Answers) { a.TakingMedication = true },
+			outcome: screening.OutcomeReview,
+			codes:   []string{"medication"},
+		},
+		{
+			name:          "returned from malaria area",
+			modify:        func(a *screening.Answers) { a.MalariaTravelReturnDate = ptr(now.AddDate(0, -2, 0)) },
+			outcome:       screening.OutcomeDeferred,
+			codes:         []string{"malaria_travel"},
+			deferredUntil: ptr(now.AddDate(0, 10, 0)),
+		},
+		{
+			name:    "malaria travel long ago",
+			modify:  func(a *screening.Answers) { a.MalariaTravelReturnDate = ptr(now.AddDate(-2, 0, 0)) },
+			outcome: screening.OutcomeActive,
+		},
+		{
+			name:          "recent tattoo",
+			modify:        func(a *screening.Answers) { a.TattooDate = ptr(now.AddDate(0, -1, 0)) },
+			outcome:       screening.OutcomeDeferred,
+			codes:         []string{"tattoo"},
+			deferredUntil: ptr(now.AddDate(0, 5, 0)),
+		},
+		{
+			name:          "low hemoglobin",
+			modify:        func(a *screening.Answers) { a.Hemoglobin = 11.9 },
+			outcome:       screening.OutcomeDeferred,
+			codes:         []string{"low_hemoglobin"},
+			deferredUntil: ptr(now.AddDate(0, 0, 14)),
+		},
+		{
+			name:    "hemoglobin at lower limit",
+			modify:  func(a *screening.Answers) { a.Hemoglobin = 12.5 },
+			outcome: screening.OutcomeActive,
+		},
+		{
+			name:    "high hemoglobin",
+			modify:  func(a *screening.Answers) { a.Hemoglobin = 18 },
+			outcome: screening.OutcomeReview,
+			codes:   []string{"high_hemoglobin"},
+		},
+		{
+			name:          "underweight",
+			modify:        func(a *screening.Answers) { a.WeightKg = 44 },
+			outcome:       screening.OutcomeDeferred,
+			codes:         []string{"low_weight"},
+			deferredUntil: ptr(now.AddDate(0, 0, 90)),
+		},
+		{
+			name:          "low blood pressure",
+			modify:        func(a *screening.Answers) { a.Systolic = 95 },
+			outcome:       screening.OutcomeDeferred,
+			codes:         []string{"blood_pressure"},
+			deferredUntil: ptr(now.AddDate(0, 0, 7)),
+		},
+		{
+			name:    "severe hypertension",
+			modify:  func(a *screening.Answers) { a.Systolic, a.Diastolic = 185, 105 },
+			outcome: screening.OutcomeReview,
+			codes:   []string{"severe_blood_pressure"},
+		},
+		{
+			name: "review wins over deferral and keeps the latest date",
+			modify: func(a *screening.Answers) {
+				a.RecentIllness = true
+				a.WeightKg = 40
+				a.TakingMedication = true
+			},
+			outcome:       screening.OutcomeReview,
+			codes:         []string{"recent_illness", "low_weight", "medication"},
+			deferredUntil: ptr(now.AddDate(0, 0, 90)),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			answers := healthy()
+			tc.modify(&answers)
+			result := screening.Evaluate(answers, now)
+
+			codes := make([]string, 0, len(result.Reasons))
+			for _, reason := range result.Reasons {
+				codes = append(codes, reason.Code)
+			}
+			assert.Equal(t, tc.outcome, result.Outcome)
+			assert.ElementsMatch(t, tc.codes, codes)
+			assert.Equal(t, tc.deferredUntil, result.DeferredUntil)
+		})
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	questionnaire := screening.Current()
+	assert.Equal(t, screening.CurrentVersion, questionnaire.Version)
+	assert.NotEmpty(t, questionnaire.Questions)
+}
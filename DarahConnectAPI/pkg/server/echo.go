@@ -1,11 +1,17 @@
 package server
 
 import (
+	"log"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/configs"
 
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/audit"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/rbac"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/response"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/revocation"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/route"
 	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/token"
 
@@ -31,8 +37,8 @@ type Server struct {
 	*echo.Echo
 }
 
-func NewServer(cfg *configs.Config,
-	publicRoutes, privateRoutes []route.Route) *Server {
+func NewServer(cfg *configs.Config, keys *token.KeySet, revocations revocation.Store, authorizer *rbac.Authorizer, hospitals rbac.HospitalResolver,
+	audits audit.Recorder, publicRoutes, privateRoutes []route.Route) *Server {
 	e := echo.New()
 	e.HideBanner = true
 	e.Validator = &CustomValidator{validator: validator.New()}
@@ -48,6 +54,12 @@ func NewServer(cfg *configs.Config,
 	// Add logging middleware
 	e.Use(middleware.Logger())
 
+	// Request id dipakai untuk menelusuri audit log ke log permintaan
+	e.Use(middleware.RequestID())
+
+	// Public key untuk memverifikasi token DarahConnect tanpa secret
+	e.GET("/.well-known/jwks.json", JWKSHandler(keys))
+
 	v1 := e.Group("/api/v1/")
 
 	if len(publicRoutes) > 0 {
@@ -58,43 +70,132 @@ func NewServer(cfg *configs.Config,
 
 	if len(privateRoutes) > 0 {
 		for _, route := range privateRoutes {
-			v1.Add(route.Method, route.Path, route.Handler, JWTMiddleware(cfg.JWT.SecretKey), RBACMiddleware(route.Roles))
+			v1.Add(route.Method, route.Path, route.Handler, JWTMiddleware(keys, revocations), PermissionMiddleware(authorizer, route.Permission), HospitalScopeMiddleware(authorizer, hospitals), AuditMiddleware(authorizer, audits, route.Permission))
 		}
 	}
 	return &Server{e}
 }
 
-func JWTMiddleware(secretKey string) echo.MiddlewareFunc {
-	return echojwt.WithConfig(echojwt.Config{
+// JWTMiddleware memvalidasi access token dengan kunci sesuai kid lalu menolak token yang sudah
+// dicabut lewat logout. Jika denylist tidak bisa dibaca, permintaan ditolak.
+func JWTMiddleware(keys *token.KeySet, revocations revocation.Store) echo.MiddlewareFunc {
+	parseToken := echojwt.WithConfig(echojwt.Config{
 		NewClaimsFunc: func(c echo.Context) jwt.Claims {
 			return new(token.JwtCustomClaims)
 		},
-		SigningKey: []byte(secretKey),
+		KeyFunc: keys.Keyfunc,
 		ErrorHandler: func(ctx echo.Context, err error) error {
 			return ctx.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "anda harus login untuk megakses resource ini."))
 		},
 	})
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return parseToken(func(ctx echo.Context) error {
+			claims := ctx.Get("user").(*jwt.Token).Claims.(*token.JwtCustomClaims)
+
+			var issuedAt time.Time
+			if claims.IssuedAt != nil {
+				issuedAt = claims.IssuedAt.Time
+			}
+			revoked, err := revocations.IsRevoked(ctx.Request().Context(), claims.ID, claims.Id, issuedAt)
+			if err != nil {
+				return ctx.JSON(http.StatusServiceUnavailable, response.ErrorResponse(http.StatusServiceUnavailable, "gagal memeriksa sesi, silahkan coba lagi."))
+			}
+			if revoked {
+				return ctx.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "sesi anda sudah berakhir, silahkan login kembali."))
+			}
+			return next(ctx)
+		})
+	}
 }
 
-func RBACMiddleware(roles []string) echo.MiddlewareFunc {
+// JWKSHandler mempublikasikan public key semua signing key yang masih berlaku
+func JWKSHandler(keys *token.KeySet) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		ctx.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=300")
+		return ctx.JSON(http.StatusOK, keys.JWKS())
+	}
+}
+
+// PermissionMiddleware menolak permintaan jika role pada token tidak memiliki permission route.
+// Permission dibaca dari Authorizer sehingga perubahan role berlaku tanpa login ulang.
+func PermissionMiddleware(authorizer *rbac.Authorizer, permission string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(ctx echo.Context) error {
+			if permission == "" {
+				return next(ctx)
+			}
+
 			user := ctx.Get("user").(*jwt.Token)
 			claims := user.Claims.(*token.JwtCustomClaims)
 
-			allowed := false
-			for _, role := range roles {
-				if role == claims.Role {
-					allowed = true
-					break
-				}
+			if !authorizer.Allowed(claims.Role, permission) {
+				return ctx.JSON(http.StatusForbidden, response.ErrorResponse(http.StatusForbidden, "anda tidak diizinkan untuk mengakses resource ini."))
 			}
 
-			if !allowed {
-				return ctx.JSON(http.StatusForbidden, response.ErrorResponse(http.StatusForbidden, "anda tidak diizinkan untuk mengakses resource ini."))
+			return next(ctx)
+		}
+	}
+}
+
+// HospitalScopeMiddleware membatasi query repository ke rumah sakit tempat pengguna bertugas jika
+// role-nya memiliki permission hospital:staff. Role lain tidak dibatasi.
+func HospitalScopeMiddleware(authorizer *rbac.Authorizer, hospitals rbac.HospitalResolver) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			user := ctx.Get("user").(*jwt.Token)
+			claims := user.Claims.(*token.JwtCustomClaims)
+
+			if !authorizer.Allowed(claims.Role, rbac.HospitalStaff) {
+				return next(ctx)
+			}
+
+			hospitalIds, err := hospitals.HospitalIds(ctx.Request().Context(), claims.Id)
+			if err != nil {
+				return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, "gagal memeriksa rumah sakit tempat anda bertugas."))
 			}
 
+			ctx.SetRequest(ctx.Request().WithContext(rbac.WithHospitalScope(ctx.Request().Context(), hospitalIds)))
 			return next(ctx)
 		}
 	}
-}
\ No newline at end of file
+}
+
+// AuditMiddleware menyimpan pelaku, IP, dan request id di context untuk audit log yang dicatat
+// service. Permintaan yang mengubah data dengan permission di luar role User dan tidak dicatat
+// oleh service mana pun tetap dicatat di sini sebagai aksi pada route tersebut.
+func AuditMiddleware(authorizer *rbac.Authorizer, audits audit.Recorder, permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			user := ctx.Get("user").(*jwt.Token)
+			claims := user.Claims.(*token.JwtCustomClaims)
+
+			metadata := &audit.Metadata{
+				ActorId:   claims.Id,
+				ActorRole: claims.Role,
+				IP:        ctx.RealIP(),
+				RequestId: ctx.Response().Header().Get(echo.HeaderXRequestID),
+				Method:    ctx.Request().Method,
+				Path:      ctx.Request().URL.Path,
+			}
+			ctx.SetRequest(ctx.Request().WithContext(audit.WithMetadata(ctx.Request().Context(), metadata)))
+
+			err := next(ctx)
+			if err != nil || ctx.Request().Method == http.MethodGet || ctx.Response().Status >= http.StatusBadRequest || metadata.Recorded() {
+				return err
+			}
+			if permission == "" || authorizer.Allowed(rbac.RoleUser, permission) {
+				return nil
+			}
+
+			entry := audit.Entry{Action: ctx.Request().Method + " " + ctx.Path(), EntityType: "route"}
+			if id, parseErr := strconv.ParseInt(ctx.Param("id"), 10, 64); parseErr == nil {
+				entry.EntityId = id
+			}
+			if recordErr := audits.Record(ctx.Request().Context(), entry); recordErr != nil {
+				log.Printf("Gagal mencatat audit log %s: %v", entry.Action, recordErr)
+			}
+			return nil
+		}
+	}
+}
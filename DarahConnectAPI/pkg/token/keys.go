@@ -0,0 +1,195 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/secretbox"
+)
+
+// Algoritma penandatanganan JWT yang didukung
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var (
+	ErrNoSigningKey    = errors.New("belum ada signing key yang aktif")
+	ErrUnknownKey      = errors.New("kid tidak dikenal")
+	ErrUnsupportedAlgo = errors.New("algoritma JWT tidak didukung")
+)
+
+// Key adalah satu kunci penandatangan JWT yang dikenali lewat kid
+type Key struct {
+	Kid         string
+	Algorithm   string
+	PrivateKey  crypto.Signer
+	ActivatesAt time.Time  // mulai dipakai menandatangani; sebelum itu hanya dipublikasikan
+	ExpiresAt   *time.Time // setelah ini token dengan kid tersebut ditolak
+}
+
+func (k Key) signingMethod() jwt.SigningMethod {
+	if k.Algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// KeySet menyimpan kunci yang sedang dipublikasikan dan aman dipakai bersamaan.
+// Kunci yang paling baru aktif dipakai menandatangani; semua kunci dipakai memverifikasi.
+type KeySet struct {
+	mu   sync.RWMutex
+	keys []Key
+}
+
+func NewKeySet(keys ...Key) *KeySet {
+	s := new(KeySet)
+	s.Replace(keys)
+	return s
+}
+
+// Replace mengganti seluruh isi KeySet, dipanggil setelah kunci dimuat ulang dari database
+func (s *KeySet) Replace(keys []Key) {
+	sorted := append([]Key(nil), keys...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ActivatesAt.Before(sorted[j].ActivatesAt)
+	})
+
+	s.mu.Lock()
+	s.keys = sorted
+	s.mu.Unlock()
+}
+
+// SigningKey mengembalikan kunci aktif yang paling baru
+func (s *KeySet) SigningKey() (Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		key := s.keys[i]
+		if !key.ActivatesAt.After(now) && (key.ExpiresAt == nil || now.Before(*key.ExpiresAt)) {
+			return key, nil
+		}
+	}
+	return Key{}, ErrNoSigningKey
+}
+
+// Keyfunc memilih public key berdasarkan header kid untuk jwt.Parse
+func (s *KeySet) Keyfunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.keys {
+		if key.Kid != kid {
+			continue
+		}
+		if t.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("algoritma %s tidak sesuai dengan kid %s", t.Method.Alg(), kid)
+		}
+		if key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt) {
+			return nil, fmt.Errorf("kid %s sudah tidak berlaku", kid)
+		}
+		return key.PrivateKey.Public(), nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// JWK adalah public key dalam format JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS mengembalikan public key semua kunci yang masih berlaku, termasuk kunci berikutnya
+// yang belum aktif agar pihak lain sempat menyimpannya sebelum dipakai.
+func (s *KeySet) JWKS() JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range s.keys {
+		if key.ExpiresAt != nil && now.After(*key.ExpiresAt) {
+			continue
+		}
+		jwk := JWK{Use: "sig", Alg: key.Algorithm, Kid: key.Kid}
+		switch public := key.PrivateKey.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+// GenerateKey membuat private key baru untuk algoritma RS256 atau EdDSA
+func GenerateKey(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case AlgorithmRS256:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case AlgorithmEdDSA:
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		return private, err
+	default:
+		return nil, ErrUnsupportedAlgo
+	}
+}
+
+// SealPrivateKey mengenkripsi private key (PKCS#8) sehingga salinan database saja tidak cukup
+// untuk memalsukan token.
+func SealPrivateKey(key crypto.Signer, secret string) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	return secretbox.Seal(der, secret)
+}
+
+// OpenPrivateKey membuka private key hasil SealPrivateKey
+func OpenPrivateKey(sealed, secret string) (crypto.Signer, error) {
+	der, err := secretbox.Open(sealed, secret)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuka private key: %w", err)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, ErrUnsupportedAlgo
+	}
+	return signer, nil
+}
@@ -0,0 +1,88 @@
+package token_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySetSignAndVerify(t *testing.T) {
+	for _, algorithm := range []string{token.AlgorithmRS256, token.AlgorithmEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			now := time.Now()
+			oldKey, err := token.GenerateKey(algorithm)
+			require.NoError(t, err)
+			currentKey, err := token.GenerateKey(algorithm)
+			require.NoError(t, err)
+			nextKey, err := token.GenerateKey(algorithm)
+			require.NoError(t, err)
+
+			oldExpiresAt := now.Add(time.Hour)
+			keys := token.NewKeySet(
+				token.Key{Kid: "next", Algorithm: algorithm, PrivateKey: nextKey, ActivatesAt: now.Add(time.Hour)},
+				token.Key{Kid: "old", Algorithm: algorithm, PrivateKey: oldKey, ActivatesAt: now.Add(-48 * time.Hour), ExpiresAt: &oldExpiresAt},
+				token.Key{Kid: "current", Algorithm: algorithm, PrivateKey: currentKey, ActivatesAt: now.Add(-time.Hour)},
+			)
+
+			signingKey, err := keys.SigningKey()
+			require.NoError(t, err)
+			assert.Equal(t, "current", signingKey.Kid)
+
+			signed, err := token.NewTokenUseCase(keys).GenerateAccessToken(&token.JwtCustomClaims{Id: 7})
+			require.NoError(t, err)
+
+			claims := new(token.JwtCustomClaims)
+			parsed, err := jwt.ParseWithClaims(signed, claims, keys.Keyfunc)
+			require.NoError(t, err)
+			assert.Equal(t, "current", parsed.Header["kid"])
+			assert.Equal(t, algorithm, parsed.Method.Alg())
+			assert.Equal(t, int64(7), claims.Id)
+
+			// Token yang sama tidak bisa diverifikasi dengan KeySet tanpa kid tersebut
+			_, err = jwt.ParseWithClaims(signed, new(token.JwtCustomClaims), token.NewKeySet(token.Key{Kid: "other", Algorithm: algorithm, PrivateKey: oldKey}).Keyfunc)
+			assert.ErrorIs(t, err, token.ErrUnknownKey)
+
+			jwks := keys.JWKS()
+			require.Len(t, jwks.Keys, 3)
+			for _, jwk := range jwks.Keys {
+				assert.Equal(t, algorithm, jwk.Alg)
+				assert.Equal(t, "sig", jwk.Use)
+			}
+		})
+	}
+}
+
+func TestKeySetRejectsHS256(t *testing.T) {
+	privateKey, err := token.GenerateKey(token.AlgorithmRS256)
+	require.NoError(t, err)
+	keys := token.NewKeySet(token.Key{Kid: "current", Algorithm: token.AlgorithmRS256, PrivateKey: privateKey})
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &token.JwtCustomClaims{Id: 1})
+	forged.Header["kid"] = "current"
+	signed, err := forged.SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	_, err = jwt.ParseWithClaims(signed, new(token.JwtCustomClaims), keys.Keyfunc)
+	assert.Error(t, err)
+}
+
+func TestSealPrivateKey(t *testing.T) {
+	for _, algorithm := range []string{token.AlgorithmRS256, token.AlgorithmEdDSA} {
+		privateKey, err := token.GenerateKey(algorithm)
+		require.NoError(t, err)
+
+		sealed, err := token.SealPrivateKey(privateKey, "rahasia")
+		require.NoError(t, err)
+
+		opened, err := token.OpenPrivateKey(sealed, "rahasia")
+		require.NoError(t, err)
+		assert.Equal(t, privateKey.Public(), opened.Public())
+
+		_, err = token.OpenPrivateKey(sealed, "salah")
+		assert.Error(t, err)
+	}
+}
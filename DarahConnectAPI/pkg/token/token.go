@@ -9,11 +9,11 @@ type TokenUseCase interface {
 }
 
 type tokenUseCase struct {
-	secretKey string
+	keys *KeySet
 }
 
-func NewTokenUseCase(secretKey string) TokenUseCase {
-	return &tokenUseCase{secretKey}
+func NewTokenUseCase(keys *KeySet) TokenUseCase {
+	return &tokenUseCase{keys}
 }
 
 type JwtCustomClaims struct {
@@ -42,10 +42,17 @@ type GoogleOAuthClaims struct {
 	jwt.RegisteredClaims
 }
 
+// GenerateAccessToken menandatangani claims dengan kunci aktif dan mencantumkan kid-nya di header
 func (t *tokenUseCase) GenerateAccessToken(claims jwt.Claims) (string, error) {
-	plainToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	key, err := t.keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	plainToken := jwt.NewWithClaims(key.signingMethod(), claims)
+	plainToken.Header["kid"] = key.Kid
 
-	encodedToken, err := plainToken.SignedString([]byte(t.secretKey))
+	encodedToken, err := plainToken.SignedString(key.PrivateKey)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,60 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Vektor uji SHA1 dari RFC 6238 lampiran B, dipotong menjadi 6 digit
+func TestCodeRFC6238(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	testCases := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tc := range testCases {
+		code, err := totp.Code(secret, totp.Step(time.Unix(tc.unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, tc.code, code, "t=%d", tc.unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	now := time.Unix(1_700_000_000, 0)
+	code, err := totp.Code(secret, totp.Step(now))
+	require.NoError(t, err)
+
+	step, ok := totp.Validate(secret, code, now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, totp.Step(now), step)
+
+	_, ok = totp.Validate(secret, code, now.Add(totp.Period*time.Second), 1)
+	assert.True(t, ok, "kode periode sebelumnya masih diterima")
+	_, ok = totp.Validate(secret, code, now.Add(2*totp.Period*time.Second), 1)
+	assert.False(t, ok)
+	_, ok = totp.Validate(secret, "12345", now, 1)
+	assert.False(t, ok)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := totp.ProvisioningURI("DarahConnect", "admin@darahconnect.id", "JBSWY3DPEHPK3PXP")
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/DarahConnect:admin@darahconnect.id?"))
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=DarahConnect")
+}
@@ -0,0 +1,90 @@
+// Package walletauth membuat pesan tantangan bergaya Sign-In with Ethereum (EIP-4361) dan
+// memulihkan alamat wallet dari tanda tangan personal_sign MetaMask untuk membuktikan kepemilikan wallet.
+package walletauth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	ErrInvalidSignature = errors.New("tanda tangan tidak valid")
+	ErrAddressMismatch  = errors.New("tanda tangan tidak dibuat oleh alamat wallet tersebut")
+)
+
+// Message adalah isi pesan yang ditandatangani pengguna
+type Message struct {
+	Domain    string
+	Address   string
+	Statement string
+	URI       string
+	ChainId   int64
+	Nonce     string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// String menyusun pesan dengan format EIP-4361 agar MetaMask menampilkannya sebagai permintaan masuk
+func (m Message) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s wants you to sign in with your Ethereum account:\n", m.Domain)
+	fmt.Fprintf(&b, "%s\n\n", common.HexToAddress(m.Address).Hex())
+	if m.Statement != "" {
+		fmt.Fprintf(&b, "%s\n\n", m.Statement)
+	}
+	fmt.Fprintf(&b, "URI: %s\n", m.URI)
+	b.WriteString("Version: 1\n")
+	fmt.Fprintf(&b, "Chain ID: %d\n", m.ChainId)
+	fmt.Fprintf(&b, "Nonce: %s\n", m.Nonce)
+	fmt.Fprintf(&b, "Issued At: %s\n", m.IssuedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Expiration Time: %s", m.ExpiresAt.UTC().Format(time.RFC3339))
+	return b.String()
+}
+
+// NewNonce membuat nonce acak yang hanya berisi huruf dan angka sesuai EIP-4361
+func NewNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// RecoverAddress memulihkan alamat penanda tangan pesan personal_sign. Signature adalah
+// 65 byte hex dengan V 27/28 (MetaMask) atau 0/1.
+func RecoverAddress(message, signature string) (common.Address, error) {
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return common.Address{}, ErrInvalidSignature
+	}
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	publicKey, err := crypto.SigToPub(accounts.TextHash([]byte(message)), sig)
+	if err != nil {
+		return common.Address{}, ErrInvalidSignature
+	}
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
+
+// Verify memastikan pesan ditandatangani oleh address
+func Verify(message, signature, address string) error {
+	recovered, err := RecoverAddress(message, signature)
+	if err != nil {
+		return err
+	}
+	if recovered != common.HexToAddress(address) {
+		return ErrAddressMismatch
+	}
+	return nil
+}
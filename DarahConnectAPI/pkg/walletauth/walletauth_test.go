@@ -0,0 +1,77 @@
+package walletauth_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/walletauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var issuedAt = time.Date(2024, time.October, 18, 9, 0, 0, 0, time.UTC)
+
+// personalSign meniru tanda tangan MetaMask (V 27/28)
+func personalSign(t *testing.T, message string) (string, string) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sig, err := crypto.Sign(accounts.TextHash([]byte(message)), key)
+	require.NoError(t, err)
+	sig[crypto.RecoveryIDOffset] += 27
+	return hexutil.Encode(sig), crypto.PubkeyToAddress(key.PublicKey).Hex()
+}
+
+func TestMessageString(t *testing.T) {
+	message := walletauth.Message{
+		Domain:    "darahconnect.id",
+		Address:   "0x00000000000000000000000000000000000000aa",
+		Statement: "Masuk ke DarahConnect dengan wallet ini.",
+		URI:       "https://darahconnect.id",
+		ChainId:   11155111,
+		Nonce:     "abc123",
+		IssuedAt:  issuedAt,
+		ExpiresAt: issuedAt.Add(5 * time.Minute),
+	}
+
+	assert.Equal(t, strings.Join([]string{
+		"darahconnect.id wants you to sign in with your Ethereum account:",
+		"0x00000000000000000000000000000000000000AA",
+		"",
+		"Masuk ke DarahConnect dengan wallet ini.",
+		"",
+		"URI: https://darahconnect.id",
+		"Version: 1",
+		"Chain ID: 11155111",
+		"Nonce: abc123",
+		"Issued At: 2024-10-18T09:00:00Z",
+		"Expiration Time: 2024-10-18T09:05:00Z",
+	}, "\n"), message.String())
+}
+
+func TestVerify(t *testing.T) {
+	message := "darahconnect.id wants you to sign in with your Ethereum account"
+	signature, address := personalSign(t, message)
+
+	assert.NoError(t, walletauth.Verify(message, signature, address))
+	assert.NoError(t, walletauth.Verify(message, signature, strings.ToLower(address)))
+
+	_, other := personalSign(t, message)
+	assert.ErrorIs(t, walletauth.Verify(message, signature, other), walletauth.ErrAddressMismatch)
+	assert.ErrorIs(t, walletauth.Verify(message+" diubah", signature, address), walletauth.ErrAddressMismatch)
+	assert.ErrorIs(t, walletauth.Verify(message, "0x1234", address), walletauth.ErrInvalidSignature)
+	assert.ErrorIs(t, walletauth.Verify(message, "bukan hex", address), walletauth.ErrInvalidSignature)
+}
+
+func TestNewNonce(t *testing.T) {
+	first, err := walletauth.NewNonce()
+	require.NoError(t, err)
+	second, err := walletauth.NewNonce()
+	require.NoError(t, err)
+
+	assert.Len(t, first, 32)
+	assert.NotEqual(t, first, second)
+}
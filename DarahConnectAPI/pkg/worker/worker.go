@@ -0,0 +1,49 @@
+// Package worker menjalankan proses latar belakang (outbox dispatcher, poller, dan sejenisnya)
+// bersamaan dengan server HTTP dan menghentikannya saat context dibatalkan.
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Worker adalah proses latar belakang yang berjalan sampai context dibatalkan
+type Worker interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+// Start menjalankan semua worker di goroutine masing-masing. Fungsi wait yang dikembalikan
+// menunggu sampai semua worker berhenti.
+func Start(ctx context.Context, workers ...Worker) (wait func()) {
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		wg.Add(1)
+		go func(w Worker) {
+			defer wg.Done()
+			log.Printf("Worker %s dimulai", w.Name())
+			if err := w.Run(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Worker %s berhenti dengan error: %v", w.Name(), err)
+				return
+			}
+			log.Printf("Worker %s berhenti", w.Name())
+		}(w)
+	}
+	return wg.Wait
+}
+
+// Every memanggil fn setiap interval sampai context dibatalkan. fn langsung dipanggil sekali di awal.
+func Every(ctx context.Context, interval time.Duration, fn func(ctx context.Context)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		fn(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
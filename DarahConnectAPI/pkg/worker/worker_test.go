@@ -0,0 +1,43 @@
+package worker_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mhusainh/DarahConnect/DarahConnectAPI/pkg/worker"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingWorker struct {
+	calls atomic.Int32
+}
+
+func (w *countingWorker) Name() string { return "counting" }
+
+func (w *countingWorker) Run(ctx context.Context) error {
+	return worker.Every(ctx, time.Millisecond, func(ctx context.Context) {
+		w.calls.Add(1)
+	})
+}
+
+func TestStartStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &countingWorker{}
+	wait := worker.Start(ctx, w)
+
+	assert.Eventually(t, func() bool { return w.calls.Load() >= 3 }, time.Second, time.Millisecond)
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker tidak berhenti setelah context dibatalkan")
+	}
+}
@@ -29,6 +29,8 @@ const OAuthCallbackPage: React.FC = () => {
     
     const params = new URLSearchParams(location.search);
     const token = params.get('token');
+    const refreshToken = params.get('refresh_token');
+    const isNewUser = params.get('is_new') === 'true';
     
     if (!token) {
       console.error('❌ OAuth Callback - No token found in URL parameters');
@@ -50,20 +52,23 @@ const OAuthCallbackPage: React.FC = () => {
     console.log('👤 OAuth Callback - Decoded user data:', {
       name: decoded.name,
       email: decoded.email,
-      is_new: decoded.is_new
+      is_new: isNewUser
     });
     
     setUserData(decoded);
-    setIsNew(Boolean(decoded.is_new));
+    setIsNew(isNewUser);
     
     // Simpan token untuk digunakan di API calls
     localStorage.setItem('authToken', token);
+    if (refreshToken) {
+      localStorage.setItem('refreshToken', refreshToken);
+    }
     console.log('💾 OAuth Callback - Auth token saved to localStorage');
     
     setLoading(false);
 
     // Jika user lama, langsung login
-    if (!decoded.is_new) {
+    if (!isNewUser) {
       console.log('🎯 OAuth Callback - Existing user, redirecting to dashboard');
       saveAuthData(token, decoded);
       const isAdmin = localStorage.getItem('isAdmin') === 'true';